@@ -1,12 +1,25 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"log"
+	"time"
+	"yiwen/go-ddd/internal/application/authz"
+	"yiwen/go-ddd/internal/application/saga"
 	"yiwen/go-ddd/internal/application/service"
+	"yiwen/go-ddd/internal/domain/repository"
+	"yiwen/go-ddd/internal/infrastructure/audit"
 	"yiwen/go-ddd/internal/infrastructure/config"
-	"yiwen/go-ddd/internal/infrastructure/persistence/model"
+	"yiwen/go-ddd/internal/infrastructure/eventbus"
+	"yiwen/go-ddd/internal/infrastructure/eventstream"
+	"yiwen/go-ddd/internal/infrastructure/jwtkeys"
+	"yiwen/go-ddd/internal/infrastructure/mailer"
+	"yiwen/go-ddd/internal/infrastructure/oidc"
+	"yiwen/go-ddd/internal/infrastructure/outbox"
+	"yiwen/go-ddd/internal/infrastructure/persistence/memory"
+	"yiwen/go-ddd/internal/infrastructure/webhook"
 	"yiwen/go-ddd/internal/interfaces/api/handler"
 	"yiwen/go-ddd/internal/interfaces/api/middleware"
 	"yiwen/go-ddd/internal/interfaces/api/router"
@@ -16,9 +29,7 @@ import (
 	domainservice "yiwen/go-ddd/internal/domain/service"
 
 	"github.com/gin-gonic/gin"
-	"gorm.io/driver/mysql"
 	"gorm.io/gorm"
-	"gorm.io/gorm/logger"
 )
 
 func main() {
@@ -32,22 +43,120 @@ func main() {
 
 	gin.SetMode(cfg.App.Mode)
 
-	db, err := initDatabase(cfg)
+	db, err := mysqlrepo.NewDB(cfg)
 	if err != nil {
 		log.Fatalf("failed to init database: %v", err)
 	}
 
 	userRepo := mysqlrepo.NewUserRepository(db)
+	userAggRepo := newUserAggregateRepository(cfg, db)
 
 	userDomainService := domainservice.NewUserDomainService(userRepo)
+	roleRepo := mysqlrepo.NewRoleRepository(db)
+	roleDomainService := domainservice.NewRoleDomainService(roleRepo, userRepo)
+	authorizer := authz.NewAuthorizer(userRepo, roleDomainService)
+	loginProtection := domainservice.NewLoginProtectionService(newLoginAttemptRepository(cfg, db), domainservice.LockoutPolicy{
+		MaxAccountFailures: cfg.LoginProtection.MaxAccountFailures,
+		MaxIPFailures:      cfg.LoginProtection.MaxIPFailures,
+		FailureWindow:      time.Duration(cfg.LoginProtection.FailureWindowMinute) * time.Minute,
+		LockoutDuration:    time.Duration(cfg.LoginProtection.LockoutMinute) * time.Minute,
+		BaseDelay:          time.Duration(cfg.LoginProtection.BaseDelaySecond) * time.Second,
+		MaxDelay:           time.Duration(cfg.LoginProtection.MaxDelaySecond) * time.Second,
+	})
+
+	webhookRepo := mysqlrepo.NewWebhookRepository(db)
+	auditRepo := mysqlrepo.NewAuditLogRepository(db)
+
+	userApplicationService := service.NewUserApplicationService(userRepo, userAggRepo, *userDomainService, loginProtection, authorizer, cfg.EmailVerification.Required)
+	auditApplicationService := service.NewAuditApplicationService(auditRepo)
+	webhookApplicationService := service.NewWebhookApplicationService(webhookRepo, auditApplicationService)
+	roleApplicationService := service.NewRoleApplicationService(roleRepo, roleDomainService, userApplicationService, auditApplicationService)
+	if err := roleApplicationService.EnsureBuiltinRoles(context.Background()); err != nil {
+		log.Fatalf("failed to init built-in roles: %v", err)
+	}
 
-	userApplicationService := service.NewUserApplicationService(userRepo, *userDomainService)
+	jwtKeys, err := jwtkeys.Load(cfg.JWT)
+	if err != nil {
+		log.Fatalf("failed to load jwt keys: %v", err)
+	}
+	jwtAuth := middleware.NewJWTAuth(jwtKeys, time.Duration(cfg.JWT.AccessExpireMinute)*time.Minute, cfg.JWT.Issuer)
+	authApplicationService := service.NewAuthApplicationService(userRepo, mysqlrepo.NewRefreshTokenRepository(db), mysqlrepo.NewRevokedTokenRepository(db), mysqlrepo.NewSessionRepository(db), jwtAuth, time.Duration(cfg.JWT.RefreshExpireDay)*24*time.Hour)
+	jwtAuth.SetRevocationChecker(authApplicationService)
+	jwtAuth.SetAuthorizer(authorizer)
+	// HS256 签发的 ID Token 下游应用无法验证，只有配置了非对称签名密钥时才提供 OpenID Connect
+	var idTokenIssuer service.IDTokenIssuer
+	if jwtKeys.SigningAsymmetric() {
+		idTokenIssuer = jwtAuth
+	} else {
+		log.Printf("OpenID Connect disabled: configure an asymmetric jwt signing key to issue id tokens")
+	}
+	oauthApplicationService := service.NewOAuthApplicationService(mysqlrepo.NewOAuthRepository(db), userRepo, authApplicationService, idTokenIssuer, time.Duration(cfg.OAuth.AuthorizationCodeExpireSecond)*time.Second)
 
-	jwtAuth := middleware.NewJWTAuth(cfg.JWT.Secret, cfg.JWT.ExpireHour, cfg.JWT.Issuer)
+	twoFactorApplicationService := service.NewTwoFactorApplicationService(mysqlrepo.NewTwoFactorRepository(db), userRepo, userApplicationService, authApplicationService, loginProtection, cfg.TwoFactor.Issuer, time.Duration(cfg.TwoFactor.ChallengeExpireSecond)*time.Second)
+	passkeyApplicationService := service.NewPasskeyApplicationService(mysqlrepo.NewPasskeyRepository(db), userRepo, authApplicationService, cfg.WebAuthn.RPID, cfg.WebAuthn.RPName, cfg.WebAuthn.Origins, time.Duration(cfg.WebAuthn.TimeoutSecond)*time.Second)
+	apiKeyApplicationService := service.NewAPIKeyApplicationService(mysqlrepo.NewAPIKeyRepository(db), userRepo, roleDomainService)
+	jwtAuth.SetAPIKeyAuthenticator(apiKeyApplicationService)
 
-	userHandler := handler.NewUserHandler(userApplicationService, jwtAuth)
+	// 验证邮件和重置密码邮件，未配置邮件服务时写入日志
+	appMailer := mailer.NewLogMailer()
+	actionTokenRepo := mysqlrepo.NewActionTokenRepository(db)
+	emailVerificationApplicationService := service.NewEmailVerificationApplicationService(userRepo, actionTokenRepo, userApplicationService, jwtAuth, appMailer, cfg.EmailVerification.VerifyPageURL, time.Duration(cfg.EmailVerification.TokenExpireHour)*time.Hour, time.Duration(cfg.EmailVerification.ResendIntervalSecond)*time.Second)
+	passwordResetApplicationService := service.NewPasswordResetApplicationService(userRepo, actionTokenRepo, userApplicationService, authApplicationService, jwtAuth, appMailer, cfg.PasswordReset.ResetPageURL, time.Duration(cfg.PasswordReset.TokenExpireMinute)*time.Minute, time.Duration(cfg.PasswordReset.ResendIntervalSecond)*time.Second)
 
-	r := router.NewRouter(userHandler, jwtAuth)
+	ssoProviders, err := oidc.NewProviders(cfg.SSO)
+	if err != nil {
+		log.Fatalf("failed to init sso providers: %v", err)
+	}
+	externalLoginApplicationService := service.NewExternalLoginApplicationService(mysqlrepo.NewExternalIdentityRepository(db), userRepo, userApplicationService, ssoProviders, time.Duration(cfg.SSO.StateExpireSecond)*time.Second)
+
+	// 领域事件先写入 outbox，再由 relay 投递到事件总线
+	// 事件总线使用同步模式，处理器全部成功后 relay 才标记为已投递，失败时按退避重试直至进入死信
+	eventBus := eventbus.NewEventBus(false)
+	relay := outbox.NewRelay(mysqlrepo.NewOutboxRepository(db), eventBus, cfg.Outbox)
+	go relay.Run(context.Background())
+
+	// webhook: 事件总线上的事件生成投递记录，由 worker 签名发送
+	eventBus.SubscribeAll(webhook.NewDispatcher(webhookRepo))
+	go webhook.NewWorker(webhookRepo, cfg.Webhook).Run(context.Background())
+
+	// 审计日志投影
+	eventBus.SubscribeAll(audit.NewProjector(auditRepo))
+
+	// 流程管理器: 注册后续流程、禁用后撤销会话
+	// 注册后续流程只在需要验证邮箱或需要停用未验证用户时启动，超时只在开启 deactivate_unverified 时设置
+	sagas := []saga.Saga{saga.NewBanSaga(authApplicationService)}
+	if cfg.EmailVerification.Required || cfg.Saga.DeactivateUnverified {
+		var verificationTimeout time.Duration
+		if cfg.Saga.DeactivateUnverified {
+			verificationTimeout = time.Duration(cfg.Saga.VerificationTimeoutDay) * 24 * time.Hour
+		}
+		sagas = append(sagas, saga.NewRegistrationSaga(userRepo, emailVerificationApplicationService, userApplicationService, verificationTimeout))
+	}
+	sagaManager := saga.NewManager(mysqlrepo.NewSagaStore(db), sagas...)
+	eventBus.SubscribeAll(sagaManager)
+	go sagaManager.Run(context.Background(), time.Duration(cfg.Saga.PollIntervalSecond)*time.Second, cfg.Saga.BatchSize)
+
+	// 管理后台 SSE 事件流: 每个实例各自读取 outbox，不经过只在一个实例上投递的 relay
+	eventBroker := eventstream.NewBroker(cfg.EventStream.BufferSize)
+	go eventBroker.Run(context.Background(), mysqlrepo.NewOutboxRepository(db), time.Duration(cfg.EventStream.PollIntervalSecond)*time.Second)
+
+	userHandler := handler.NewUserHandler(userApplicationService, authApplicationService, twoFactorApplicationService)
+	webhookHandler := handler.NewWebhookHandler(webhookApplicationService)
+	eventStreamHandler := handler.NewEventStreamHandler(eventBroker, time.Duration(cfg.EventStream.HeartbeatSecond)*time.Second)
+	auditHandler := handler.NewAuditHandler(auditApplicationService)
+	authHandler := handler.NewAuthHandler(authApplicationService)
+	jwksHandler := handler.NewJWKSHandler(jwtKeys)
+	oauthHandler := handler.NewOAuthHandler(oauthApplicationService)
+	oidcHandler := handler.NewOIDCHandler(oauthApplicationService, jwtAuth.Issuer(), cfg.OAuth.AuthorizePageURL, jwtKeys.SigningAlgorithm())
+	externalLoginHandler := handler.NewExternalLoginHandler(externalLoginApplicationService, authApplicationService, twoFactorApplicationService)
+	twoFactorHandler := handler.NewTwoFactorHandler(twoFactorApplicationService)
+	passkeyHandler := handler.NewPasskeyHandler(passkeyApplicationService)
+	apiKeyHandler := handler.NewAPIKeyHandler(apiKeyApplicationService)
+	emailVerificationHandler := handler.NewEmailVerificationHandler(emailVerificationApplicationService)
+	passwordResetHandler := handler.NewPasswordResetHandler(passwordResetApplicationService)
+	roleHandler := handler.NewRoleHandler(roleApplicationService)
+
+	r := router.NewRouter(userHandler, webhookHandler, eventStreamHandler, auditHandler, authHandler, jwksHandler, oauthHandler, oidcHandler, externalLoginHandler, twoFactorHandler, passkeyHandler, apiKeyHandler, emailVerificationHandler, passwordResetHandler, roleHandler, jwtAuth)
 
 	engine := r.Setup()
 
@@ -62,45 +171,18 @@ func main() {
 	}
 }
 
-func initDatabase(cfg *config.Config) (*gorm.DB, error) {
-	var logLevel logger.LogLevel
-	switch cfg.App.Mode {
-	case "debug":
-		logLevel = logger.Info
-	case "test":
-		logLevel = logger.Warn
-	case "production":
-		logLevel = logger.Error
-	default:
-		logLevel = logger.Info
-	}
-
-	db, err := gorm.Open(mysql.Open(cfg.Database.DSN()), &gorm.Config{
-		Logger: logger.Default.LogMode(logLevel),
-	})
-
-	if err != nil {
-		return nil, err
-	}
-
-	sqlDB, err := db.DB()
-	if err != nil {
-		return nil, err
+// newUserAggregateRepository 根据配置选择用户聚合的持久化方式
+func newUserAggregateRepository(cfg *config.Config, db *gorm.DB) repository.UserAggregateRepository {
+	if cfg.Persistence.Mode == config.PersistenceModeEventSourced {
+		return mysqlrepo.NewEventSourcedUserRepository(db, mysqlrepo.NewEventStore(db), mysqlrepo.NewSnapshotStore(db), cfg.Persistence.SnapshotEvery)
 	}
+	return mysqlrepo.NewUserAggregateRepository(db)
+}
 
-	sqlDB.SetMaxIdleConns(cfg.Database.MaxIdleConns)
-	sqlDB.SetMaxOpenConns(cfg.Database.MaxOpenConns)
-
-	// 自动迁移（AutoMigrate）是 GORM 提供的一种功能，用于自动根据模型（如 UserModel）的结构变更数据库中的表结构。
-	// 它会自动创建、修改（字段类型默认兼容）、删除表结构，以确保数据库结构和 Go 代码中的模型结构保持一致。
-	// 但是，自动迁移不会删除已有字段的内容，也不会更改已有字段的类型，它主要用于保持字段的增加或表的创建同步。
-	// 这里示例只在生产环境（production）下才自动迁移，以避免开发或测试时误操作数据库结构。
-	if cfg.App.Mode == "production" {
-		if err := db.AutoMigrate(&model.UserModel{}); err != nil {
-			return nil, err
-		}
+// newLoginAttemptRepository 根据配置选择登录失败记录的存储方式
+func newLoginAttemptRepository(cfg *config.Config, db *gorm.DB) repository.LoginAttemptRepository {
+	if cfg.LoginProtection.Store == config.LoginAttemptStoreMemory {
+		return memory.NewLoginAttemptRepository()
 	}
-
-	return db, nil
-
+	return mysqlrepo.NewLoginAttemptRepository(db)
 }
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"yiwen/go-ddd/internal/infrastructure/config"
+
+	mysqlrepo "yiwen/go-ddd/internal/infrastructure/persistence/mysql"
+)
+
+// 事件流补写工具
+// 从状态存储切换到事件溯源（persistence.mode: event_sourced）之前执行，
+// 为 users 表中还没有事件流的用户补写 user.imported 起始事件，否则这些用户无法加载和修改
+//
+//	go run ./cmd/backfill -config config/config.yaml
+func main() {
+	configPath := flag.String("config", "config/config.yaml", "config file path")
+	batchSize := flag.Int("batch", 500, "number of users read per batch")
+	flag.Parse()
+
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		log.Fatalf("failed to load config: %v", err)
+	}
+
+	db, err := mysqlrepo.NewDB(cfg)
+	if err != nil {
+		log.Fatalf("failed to init database: %v", err)
+	}
+
+	repo := mysqlrepo.NewEventSourcedUserRepository(db, mysqlrepo.NewEventStore(db), mysqlrepo.NewSnapshotStore(db), cfg.Persistence.SnapshotEvery)
+
+	count, err := repo.Backfill(context.Background(), *batchSize)
+	if err != nil {
+		log.Fatalf("failed to backfill event streams after %d users: %v", count, err)
+	}
+	log.Printf("%d event streams backfilled", count)
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"sort"
+	"strings"
+	"yiwen/go-ddd/internal/infrastructure/audit"
+	"yiwen/go-ddd/internal/infrastructure/config"
+	"yiwen/go-ddd/internal/infrastructure/projection"
+
+	mysqlrepo "yiwen/go-ddd/internal/infrastructure/persistence/mysql"
+)
+
+// 投影重建工具
+// 清空指定的投影，再按全局顺序把事件存储中的全部事件重放给投影
+// 事件存储没有包含全部历史时（如状态存储模式下产生的事件）拒绝清空投影，-dry-run 可以查看缺失的数量
+//
+//	go run ./cmd/replay -config config/config.yaml -projection audit            清空并重建审计日志
+//	go run ./cmd/replay -config config/config.yaml -projection audit -resume    从上次中断的检查点继续
+//	go run ./cmd/replay -config config/config.yaml -projection audit -dry-run   只统计需要重放的事件
+func main() {
+	configPath := flag.String("config", "config/config.yaml", "config file path")
+	name := flag.String("projection", "", "projection to rebuild")
+	resume := flag.Bool("resume", false, "resume from the last checkpoint instead of truncating the projection")
+	dryRun := flag.Bool("dry-run", false, "count the events to replay without changing any data")
+	batchSize := flag.Int("batch", 500, "number of events read per batch")
+	flag.Parse()
+
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		log.Fatalf("failed to load config: %v", err)
+	}
+
+	db, err := mysqlrepo.NewDB(cfg)
+	if err != nil {
+		log.Fatalf("failed to init database: %v", err)
+	}
+
+	// 可重建的投影，新增投影时在这里注册
+	projections := make(map[string]projection.Projection)
+	for _, p := range []projection.Projection{
+		audit.NewProjector(mysqlrepo.NewAuditLogRepository(db)),
+	} {
+		projections[p.Name()] = p
+	}
+
+	p, ok := projections[*name]
+	if !ok {
+		names := make([]string, 0, len(projections))
+		for n := range projections {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		log.Fatalf("unknown projection %q, available: %s", *name, strings.Join(names, ", "))
+	}
+
+	replayer := projection.NewReplayer(mysqlrepo.NewEventStore(db), mysqlrepo.NewCheckpointStore(db), *batchSize, func(progress projection.Progress) {
+		percent := 100.0
+		if progress.Total > 0 {
+			percent = float64(progress.Replayed) * 100 / float64(progress.Total)
+		}
+		log.Printf("%s: %d/%d events (%.1f%%), position %d", p.Name(), progress.Replayed, progress.Total, percent, progress.Position)
+	})
+
+	result, err := replayer.Replay(context.Background(), p, projection.Options{Resume: *resume, DryRun: *dryRun})
+	if err != nil {
+		if result != nil {
+			log.Printf("stopped at position %d, rerun with -resume to continue", result.Position)
+		}
+		log.Fatalf("failed to replay projection %s: %v", p.Name(), err)
+	}
+
+	types := make([]string, 0, len(result.ByType))
+	for t := range result.ByType {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+	for _, t := range types {
+		log.Printf("  %s: %d", t, result.ByType[t])
+	}
+
+	if *dryRun {
+		if result.Missing > 0 {
+			log.Printf("dry run: %d aggregates or events are missing from the event store, %s cannot be rebuilt from scratch", result.Missing, p.Name())
+		}
+		log.Printf("dry run: %d events would be replayed into %s", result.Replayed, p.Name())
+		return
+	}
+	log.Printf("%s rebuilt from %d events, checkpoint at position %d", p.Name(), result.Replayed, result.Position)
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"yiwen/go-ddd/internal/infrastructure/config"
+
+	mysqlrepo "yiwen/go-ddd/internal/infrastructure/persistence/mysql"
+)
+
+// 快照重建工具
+// 聚合状态结构变化后（递增 UserSnapshotSchemaVersion），旧快照会在加载时被忽略，
+// 使用该工具重放事件并重新生成快照，避免首次加载时重放全部事件
+//
+//	go run ./cmd/snapshot -config config/config.yaml             重建所有用户快照
+//	go run ./cmd/snapshot -config config/config.yaml -aggregate <uuid>  重建单个用户快照
+func main() {
+	configPath := flag.String("config", "config/config.yaml", "config file path")
+	aggregateID := flag.String("aggregate", "", "rebuild snapshot for a single user uuid")
+	flag.Parse()
+
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		log.Fatalf("failed to load config: %v", err)
+	}
+
+	db, err := mysqlrepo.NewDB(cfg)
+	if err != nil {
+		log.Fatalf("failed to init database: %v", err)
+	}
+
+	repo := mysqlrepo.NewEventSourcedUserRepository(db, mysqlrepo.NewEventStore(db), mysqlrepo.NewSnapshotStore(db), cfg.Persistence.SnapshotEvery)
+	ctx := context.Background()
+
+	if *aggregateID != "" {
+		if err := repo.RebuildSnapshot(ctx, *aggregateID); err != nil {
+			log.Fatalf("failed to rebuild snapshot for %s: %v", *aggregateID, err)
+		}
+		log.Printf("snapshot rebuilt for %s", *aggregateID)
+		return
+	}
+
+	count, err := repo.RebuildAllSnapshots(ctx)
+	if err != nil {
+		log.Fatalf("failed to rebuild snapshots after %d aggregates: %v", count, err)
+	}
+	log.Printf("%d snapshots rebuilt", count)
+}
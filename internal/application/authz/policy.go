@@ -0,0 +1,95 @@
+package authz
+
+import (
+	"context"
+	"yiwen/go-ddd/internal/domain/repository"
+	"yiwen/go-ddd/pkg/errors"
+
+	domainservice "yiwen/go-ddd/internal/domain/service"
+)
+
+var (
+	ErrUnauthenticated = errors.New("authentication required")
+	ErrForbidden       = errors.New("forbidden")
+)
+
+// Policy 声明式授权策略
+// 操作自己的资源需要 OwnerPermission，操作任意资源需要 Permission，为空表示不允许
+// 例如 "所有者或管理员" 为 OwnerOr(entity.PermissionProfileUpdate, entity.PermissionUsersUpdate)
+type Policy struct {
+	OwnerPermission string
+	Permission      string
+}
+
+// Require 只有拥有指定权限的用户可以执行
+func Require(permission string) Policy {
+	return Policy{Permission: permission}
+}
+
+// OwnerOnly 只有资源所有者可以执行，所有者需要拥有指定权限
+func OwnerOnly(ownerPermission string) Policy {
+	return Policy{OwnerPermission: ownerPermission}
+}
+
+// OwnerOr 资源所有者或拥有 permission 的用户可以执行
+func OwnerOr(ownerPermission, permission string) Policy {
+	return Policy{OwnerPermission: ownerPermission, Permission: permission}
+}
+
+// Authorizer 根据 context 中的主体和授权策略判断是否允许执行命令
+// 权限根据用户当前的角色判断，代表用户操作的主体同时受权限范围限制
+type Authorizer struct {
+	userRepo          repository.UserRepository
+	roleDomainService *domainservice.RoleDomainService
+}
+
+// NewAuthorizer 创建授权器
+func NewAuthorizer(userRepo repository.UserRepository, roleDomainService *domainservice.RoleDomainService) *Authorizer {
+	return &Authorizer{userRepo: userRepo, roleDomainService: roleDomainService}
+}
+
+// Authorize 判断 context 中的主体是否可以对 ownerID 所属的资源执行操作，不属于某个用户的资源 ownerID 为 0
+func (a *Authorizer) Authorize(ctx context.Context, policy Policy, ownerID uint64) error {
+	principal, ok := PrincipalFromContext(ctx)
+	if !ok || principal.UserID == 0 {
+		return ErrUnauthenticated
+	}
+
+	var candidates []string
+	if ownerID != 0 && principal.UserID == ownerID && policy.OwnerPermission != "" {
+		candidates = append(candidates, policy.OwnerPermission)
+	}
+	if policy.Permission != "" {
+		candidates = append(candidates, policy.Permission)
+	}
+
+	for _, permission := range candidates {
+		if !principal.AllowsScope(permission) {
+			continue
+		}
+		allowed, err := a.hasPermission(ctx, principal.UserID, permission)
+		if err != nil {
+			return err
+		}
+		if allowed {
+			return nil
+		}
+	}
+	return ErrForbidden
+}
+
+// hasPermission 判断用户当前的角色是否拥有指定权限，用户不存在或未激活时没有任何权限
+func (a *Authorizer) hasPermission(ctx context.Context, userID uint64, permission string) (bool, error) {
+	user, err := a.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		// 用户不存在说明已被删除，其他错误交给调用方处理
+		if errors.Is(err, repository.ErrUserNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	if !user.IsActive() {
+		return false, nil
+	}
+	return a.roleDomainService.HasPermission(ctx, user, permission)
+}
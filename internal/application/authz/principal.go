@@ -0,0 +1,38 @@
+package authz
+
+import (
+	"context"
+	"slices"
+	"yiwen/go-ddd/internal/domain/entity"
+)
+
+// Principal 发起请求的主体
+// 由入口（HTTP 中间件、gRPC 拦截器、CLI）认证后放入 context，应用服务根据授权策略判断是否允许执行命令
+type Principal struct {
+	UserID    uint64
+	Username  string
+	Roles     []string // 认证时的角色，授权时根据用户当前的角色判断权限
+	Scopes    []string // OAuth2 令牌或 API 密钥的权限范围
+	Delegated bool     // 通过 OAuth2 令牌或 API 密钥代表用户操作，只能执行权限范围内的操作
+}
+
+// AllowsScope 判断主体的权限范围是否允许使用指定权限，用户自身登录时不受权限范围限制
+func (p Principal) AllowsScope(permission string) bool {
+	if !p.Delegated {
+		return true
+	}
+	return slices.Contains(p.Scopes, permission) || slices.Contains(p.Scopes, entity.PermissionAll)
+}
+
+type principalKey struct{}
+
+// ContextWithPrincipal 把主体放入 context
+func ContextWithPrincipal(ctx context.Context, p Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// PrincipalFromContext 从 context 中读取主体，匿名请求返回 false
+func PrincipalFromContext(ctx context.Context) (Principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(Principal)
+	return p, ok
+}
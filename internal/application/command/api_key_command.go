@@ -0,0 +1,32 @@
+package command
+
+import "time"
+
+// CreateAPIKeyCommand 创建 API 密钥命令，ExpiresIn 为 0 表示不过期
+type CreateAPIKeyCommand struct {
+	UserID    uint64
+	Name      string
+	Scopes    []string
+	ExpiresIn time.Duration
+}
+
+// NewCreateAPIKeyCommand 创建 API 密钥命令
+func NewCreateAPIKeyCommand(userID uint64, name string, scopes []string, expiresIn time.Duration) *CreateAPIKeyCommand {
+	return &CreateAPIKeyCommand{
+		UserID:    userID,
+		Name:      name,
+		Scopes:    scopes,
+		ExpiresIn: expiresIn,
+	}
+}
+
+// RevokeAPIKeyCommand 删除 API 密钥命令
+type RevokeAPIKeyCommand struct {
+	UserID   uint64
+	APIKeyID uint64
+}
+
+// NewRevokeAPIKeyCommand 创建删除 API 密钥命令
+func NewRevokeAPIKeyCommand(userID, apiKeyID uint64) *RevokeAPIKeyCommand {
+	return &RevokeAPIKeyCommand{UserID: userID, APIKeyID: apiKeyID}
+}
@@ -0,0 +1,79 @@
+package command
+
+import "time"
+
+// RefreshTokenCommand 刷新令牌命令
+// ClientID 为空表示自身登录签发的令牌，否则为通过 OAuth2 认证过的客户端
+type RefreshTokenCommand struct {
+	RefreshToken string
+	ClientID     string
+}
+
+// NewRefreshTokenCommand 创建刷新令牌命令
+func NewRefreshTokenCommand(refreshToken, clientID string) *RefreshTokenCommand {
+	return &RefreshTokenCommand{RefreshToken: refreshToken, ClientID: clientID}
+}
+
+// LogoutCommand 注销命令
+// 撤销当前访问令牌并结束其所属的会话；提供刷新令牌时一并撤销其所在的令牌家族
+type LogoutCommand struct {
+	UserID         uint64
+	TokenID        string
+	TokenExpiresAt time.Time
+	SessionID      string
+	RefreshToken   string
+}
+
+// NewLogoutCommand 创建注销命令
+func NewLogoutCommand(userID uint64, tokenID string, tokenExpiresAt time.Time, sessionID, refreshToken string) *LogoutCommand {
+	return &LogoutCommand{
+		UserID:         userID,
+		TokenID:        tokenID,
+		TokenExpiresAt: tokenExpiresAt,
+		SessionID:      sessionID,
+		RefreshToken:   refreshToken,
+	}
+}
+
+// RevokeSessionCommand 结束会话命令
+type RevokeSessionCommand struct {
+	UserID    uint64
+	SessionID uint64
+}
+
+// NewRevokeSessionCommand 创建结束会话命令
+func NewRevokeSessionCommand(userID, sessionID uint64) *RevokeSessionCommand {
+	return &RevokeSessionCommand{UserID: userID, SessionID: sessionID}
+}
+
+// RevokeUserSessionsCommand 管理员结束用户全部会话命令
+type RevokeUserSessionsCommand struct {
+	UserID uint64
+}
+
+// NewRevokeUserSessionsCommand 创建结束用户全部会话命令
+func NewRevokeUserSessionsCommand(userID uint64) *RevokeUserSessionsCommand {
+	return &RevokeUserSessionsCommand{UserID: userID}
+}
+
+// StartExternalLoginCommand 发起外部登录命令
+type StartExternalLoginCommand struct {
+	Provider string
+}
+
+// NewStartExternalLoginCommand 创建发起外部登录命令
+func NewStartExternalLoginCommand(provider string) *StartExternalLoginCommand {
+	return &StartExternalLoginCommand{Provider: provider}
+}
+
+// CompleteExternalLoginCommand 完成外部登录命令，参数来自提供方的回调
+type CompleteExternalLoginCommand struct {
+	Provider string
+	Code     string
+	State    string
+}
+
+// NewCompleteExternalLoginCommand 创建完成外部登录命令
+func NewCompleteExternalLoginCommand(provider, code, state string) *CompleteExternalLoginCommand {
+	return &CompleteExternalLoginCommand{Provider: provider, Code: code, State: state}
+}
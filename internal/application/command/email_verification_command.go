@@ -0,0 +1,31 @@
+package command
+
+// SendEmailVerificationCommand 发送验证邮件命令，由注册后续流程按 UUID 发出
+type SendEmailVerificationCommand struct {
+	UserUUID string
+}
+
+// NewSendEmailVerificationCommand 创建发送验证邮件命令
+func NewSendEmailVerificationCommand(userUUID string) *SendEmailVerificationCommand {
+	return &SendEmailVerificationCommand{UserUUID: userUUID}
+}
+
+// ResendEmailVerificationCommand 重新发送验证邮件命令
+type ResendEmailVerificationCommand struct {
+	Email string
+}
+
+// NewResendEmailVerificationCommand 创建重新发送验证邮件命令
+func NewResendEmailVerificationCommand(email string) *ResendEmailVerificationCommand {
+	return &ResendEmailVerificationCommand{Email: email}
+}
+
+// VerifyEmailCommand 使用验证令牌验证邮箱命令
+type VerifyEmailCommand struct {
+	Token string
+}
+
+// NewVerifyEmailCommand 创建验证邮箱命令
+func NewVerifyEmailCommand(token string) *VerifyEmailCommand {
+	return &VerifyEmailCommand{Token: token}
+}
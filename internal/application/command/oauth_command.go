@@ -0,0 +1,97 @@
+package command
+
+// CreateOAuthClientCommand 注册 OAuth2 客户端命令
+// Confidential 为 true 时生成客户端密钥，否则为公共客户端
+type CreateOAuthClientCommand struct {
+	Name         string
+	RedirectURIs []string
+	Scopes       []string
+	GrantTypes   []string
+	Confidential bool
+}
+
+// NewCreateOAuthClientCommand 创建注册 OAuth2 客户端命令
+func NewCreateOAuthClientCommand(name string, redirectURIs, scopes, grantTypes []string, confidential bool) *CreateOAuthClientCommand {
+	return &CreateOAuthClientCommand{
+		Name:         name,
+		RedirectURIs: redirectURIs,
+		Scopes:       scopes,
+		GrantTypes:   grantTypes,
+		Confidential: confidential,
+	}
+}
+
+// DeleteOAuthClientCommand 删除 OAuth2 客户端命令
+type DeleteOAuthClientCommand struct {
+	ClientID string
+}
+
+// NewDeleteOAuthClientCommand 创建删除 OAuth2 客户端命令
+func NewDeleteOAuthClientCommand(clientID string) *DeleteOAuthClientCommand {
+	return &DeleteOAuthClientCommand{ClientID: clientID}
+}
+
+// AuthorizeCommand 授权请求命令，字段对应 RFC 6749 和 RFC 7636 的授权请求参数
+type AuthorizeCommand struct {
+	UserID              uint64
+	ResponseType        string
+	ClientID            string
+	RedirectURI         string
+	Scope               string
+	State               string
+	CodeChallenge       string
+	CodeChallengeMethod string
+	Nonce               string // OIDC nonce，原样写入 ID Token
+}
+
+// NewAuthorizeCommand 创建授权请求命令
+func NewAuthorizeCommand(userID uint64, responseType, clientID, redirectURI, scope, state, codeChallenge, codeChallengeMethod, nonce string) *AuthorizeCommand {
+	return &AuthorizeCommand{
+		UserID:              userID,
+		ResponseType:        responseType,
+		ClientID:            clientID,
+		RedirectURI:         redirectURI,
+		Scope:               scope,
+		State:               state,
+		CodeChallenge:       codeChallenge,
+		CodeChallengeMethod: codeChallengeMethod,
+		Nonce:               nonce,
+	}
+}
+
+// ConsentCommand 用户同意或拒绝授权的命令
+type ConsentCommand struct {
+	AuthorizeCommand
+	Approved bool
+}
+
+// NewConsentCommand 创建授权同意命令
+func NewConsentCommand(authorize *AuthorizeCommand, approved bool) *ConsentCommand {
+	return &ConsentCommand{AuthorizeCommand: *authorize, Approved: approved}
+}
+
+// OAuthTokenCommand 令牌请求命令，不同授权类型使用不同的字段
+type OAuthTokenCommand struct {
+	GrantType    string
+	ClientID     string
+	ClientSecret string
+	Code         string
+	RedirectURI  string
+	CodeVerifier string
+	RefreshToken string
+	Scope        string
+}
+
+// NewOAuthTokenCommand 创建令牌请求命令
+func NewOAuthTokenCommand(grantType, clientID, clientSecret, code, redirectURI, codeVerifier, refreshToken, scope string) *OAuthTokenCommand {
+	return &OAuthTokenCommand{
+		GrantType:    grantType,
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		Code:         code,
+		RedirectURI:  redirectURI,
+		CodeVerifier: codeVerifier,
+		RefreshToken: refreshToken,
+		Scope:        scope,
+	}
+}
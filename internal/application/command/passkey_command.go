@@ -0,0 +1,60 @@
+package command
+
+// BeginPasskeyRegistrationCommand 获取通行密钥注册选项命令
+type BeginPasskeyRegistrationCommand struct {
+	UserID uint64
+}
+
+// NewBeginPasskeyRegistrationCommand 创建获取通行密钥注册选项命令
+func NewBeginPasskeyRegistrationCommand(userID uint64) *BeginPasskeyRegistrationCommand {
+	return &BeginPasskeyRegistrationCommand{UserID: userID}
+}
+
+// FinishPasskeyRegistrationCommand 完成通行密钥注册命令，二进制字段为 base64url 编码
+type FinishPasskeyRegistrationCommand struct {
+	UserID            uint64
+	Name              string
+	ClientDataJSON    string
+	AttestationObject string
+}
+
+// NewFinishPasskeyRegistrationCommand 创建完成通行密钥注册命令
+func NewFinishPasskeyRegistrationCommand(userID uint64, name, clientDataJSON, attestationObject string) *FinishPasskeyRegistrationCommand {
+	return &FinishPasskeyRegistrationCommand{
+		UserID:            userID,
+		Name:              name,
+		ClientDataJSON:    clientDataJSON,
+		AttestationObject: attestationObject,
+	}
+}
+
+// FinishPasskeyLoginCommand 通行密钥登录命令，二进制字段为 base64url 编码
+type FinishPasskeyLoginCommand struct {
+	CredentialID      string
+	ClientDataJSON    string
+	AuthenticatorData string
+	Signature         string
+	UserHandle        string
+}
+
+// NewFinishPasskeyLoginCommand 创建通行密钥登录命令
+func NewFinishPasskeyLoginCommand(credentialID, clientDataJSON, authenticatorData, signature, userHandle string) *FinishPasskeyLoginCommand {
+	return &FinishPasskeyLoginCommand{
+		CredentialID:      credentialID,
+		ClientDataJSON:    clientDataJSON,
+		AuthenticatorData: authenticatorData,
+		Signature:         signature,
+		UserHandle:        userHandle,
+	}
+}
+
+// RevokePasskeyCommand 删除通行密钥命令
+type RevokePasskeyCommand struct {
+	UserID    uint64
+	PasskeyID uint64
+}
+
+// NewRevokePasskeyCommand 创建删除通行密钥命令
+func NewRevokePasskeyCommand(userID, passkeyID uint64) *RevokePasskeyCommand {
+	return &RevokePasskeyCommand{UserID: userID, PasskeyID: passkeyID}
+}
@@ -0,0 +1,22 @@
+package command
+
+// ForgotPasswordCommand 找回密码命令，向邮箱发送重置令牌
+type ForgotPasswordCommand struct {
+	Email string
+}
+
+// NewForgotPasswordCommand 创建找回密码命令
+func NewForgotPasswordCommand(email string) *ForgotPasswordCommand {
+	return &ForgotPasswordCommand{Email: email}
+}
+
+// ConfirmPasswordResetCommand 使用重置令牌设置新密码命令
+type ConfirmPasswordResetCommand struct {
+	Token       string
+	NewPassword string
+}
+
+// NewConfirmPasswordResetCommand 创建使用重置令牌设置新密码命令
+func NewConfirmPasswordResetCommand(token, newPassword string) *ConfirmPasswordResetCommand {
+	return &ConfirmPasswordResetCommand{Token: token, NewPassword: newPassword}
+}
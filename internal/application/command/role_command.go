@@ -0,0 +1,59 @@
+package command
+
+// CreateRoleCommand 创建角色命令
+type CreateRoleCommand struct {
+	Name        string
+	Description string
+	ParentID    uint64
+	Permissions []string
+}
+
+// NewCreateRoleCommand 创建创建角色命令
+func NewCreateRoleCommand(name, description string, parentID uint64, permissions []string) *CreateRoleCommand {
+	return &CreateRoleCommand{Name: name, Description: description, ParentID: parentID, Permissions: permissions}
+}
+
+// UpdateRoleCommand 更新角色命令，角色名称不能修改
+type UpdateRoleCommand struct {
+	RoleID      uint64
+	Description string
+	ParentID    uint64
+	Permissions []string
+}
+
+// NewUpdateRoleCommand 创建更新角色命令
+func NewUpdateRoleCommand(roleID uint64, description string, parentID uint64, permissions []string) *UpdateRoleCommand {
+	return &UpdateRoleCommand{RoleID: roleID, Description: description, ParentID: parentID, Permissions: permissions}
+}
+
+// DeleteRoleCommand 删除角色命令
+type DeleteRoleCommand struct {
+	RoleID uint64
+}
+
+// NewDeleteRoleCommand 创建删除角色命令
+func NewDeleteRoleCommand(roleID uint64) *DeleteRoleCommand {
+	return &DeleteRoleCommand{RoleID: roleID}
+}
+
+// AssignRoleCommand 为用户分配角色命令
+type AssignRoleCommand struct {
+	UserID uint64
+	Role   string
+}
+
+// NewAssignRoleCommand 创建为用户分配角色命令
+func NewAssignRoleCommand(userID uint64, role string) *AssignRoleCommand {
+	return &AssignRoleCommand{UserID: userID, Role: role}
+}
+
+// RevokeRoleCommand 收回用户角色命令
+type RevokeRoleCommand struct {
+	UserID uint64
+	Role   string
+}
+
+// NewRevokeRoleCommand 创建收回用户角色命令
+func NewRevokeRoleCommand(userID uint64, role string) *RevokeRoleCommand {
+	return &RevokeRoleCommand{UserID: userID, Role: role}
+}
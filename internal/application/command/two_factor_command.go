@@ -0,0 +1,65 @@
+package command
+
+// SetupTwoFactorCommand 生成两步验证密钥命令
+type SetupTwoFactorCommand struct {
+	UserID uint64
+}
+
+// NewSetupTwoFactorCommand 创建生成两步验证密钥命令
+func NewSetupTwoFactorCommand(userID uint64) *SetupTwoFactorCommand {
+	return &SetupTwoFactorCommand{UserID: userID}
+}
+
+// ConfirmTwoFactorCommand 确认开启两步验证命令
+type ConfirmTwoFactorCommand struct {
+	UserID uint64
+	Code   string
+}
+
+// NewConfirmTwoFactorCommand 创建确认开启两步验证命令
+func NewConfirmTwoFactorCommand(userID uint64, code string) *ConfirmTwoFactorCommand {
+	return &ConfirmTwoFactorCommand{UserID: userID, Code: code}
+}
+
+// DisableTwoFactorCommand 关闭两步验证命令，Code 可以是验证码或恢复码
+type DisableTwoFactorCommand struct {
+	UserID uint64
+	Code   string
+}
+
+// NewDisableTwoFactorCommand 创建关闭两步验证命令
+func NewDisableTwoFactorCommand(userID uint64, code string) *DisableTwoFactorCommand {
+	return &DisableTwoFactorCommand{UserID: userID, Code: code}
+}
+
+// RegenerateRecoveryCodesCommand 重新生成恢复码命令，Code 可以是验证码或恢复码
+type RegenerateRecoveryCodesCommand struct {
+	UserID uint64
+	Code   string
+}
+
+// NewRegenerateRecoveryCodesCommand 创建重新生成恢复码命令
+func NewRegenerateRecoveryCodesCommand(userID uint64, code string) *RegenerateRecoveryCodesCommand {
+	return &RegenerateRecoveryCodesCommand{UserID: userID, Code: code}
+}
+
+// ResetTwoFactorCommand 管理员重置用户两步验证命令
+type ResetTwoFactorCommand struct {
+	UserID uint64
+}
+
+// NewResetTwoFactorCommand 创建重置两步验证命令
+func NewResetTwoFactorCommand(userID uint64) *ResetTwoFactorCommand {
+	return &ResetTwoFactorCommand{UserID: userID}
+}
+
+// VerifyTwoFactorCommand 登录第二步命令，使用 MFA 令牌和验证码换取访问令牌
+type VerifyTwoFactorCommand struct {
+	MFAToken string
+	Code     string
+}
+
+// NewVerifyTwoFactorCommand 创建登录第二步命令
+func NewVerifyTwoFactorCommand(mfaToken, code string) *VerifyTwoFactorCommand {
+	return &VerifyTwoFactorCommand{MFAToken: mfaToken, Code: code}
+}
@@ -18,6 +18,21 @@ func NewRegisterUserCommand(username, email, password, nickname string) *Registe
 	return &RegisterUserCommand{Username: username, Email: email, Password: password, Nickname: nickname}
 }
 
+// RegisterExternalUserCommand 外部身份首次登录时创建用户的命令
+// Username 只是候选用户名，被占用时会追加随机后缀
+type RegisterExternalUserCommand struct {
+	Username      string
+	Email         string
+	EmailVerified bool
+	Nickname      string
+	Avatar        string
+}
+
+// NewRegisterExternalUserCommand 创建外部用户注册命令
+func NewRegisterExternalUserCommand(username, email string, emailVerified bool, nickname, avatar string) *RegisterExternalUserCommand {
+	return &RegisterExternalUserCommand{Username: username, Email: email, EmailVerified: emailVerified, Nickname: nickname, Avatar: avatar}
+}
+
 // UpdateProfileCommand 更新资料命令
 type UpdateProfileCommand struct {
 	UserID   uint64
@@ -45,21 +60,56 @@ func NewChangePasswordCommand(userID uint64, oldPassword, newPassword string) *C
 // DeleteUserCommand 删除用户命令
 type DeleteUserCommand struct {
 	UserID uint64
+	Reason string
 }
 
 // NewDeleteUserCommand 创建删除用户命令
-func NewDeleteUserCommand(userID uint64) *DeleteUserCommand {
-	return &DeleteUserCommand{UserID: userID}
+func NewDeleteUserCommand(userID uint64, reason string) *DeleteUserCommand {
+	return &DeleteUserCommand{UserID: userID, Reason: reason}
 }
 
 // BanUserCommand 禁用用户命令
 type BanUserCommand struct {
 	UserID uint64
+	Reason string
 }
 
 // NewBanUserCommand 创建禁用用户命令
-func NewBanUserCommand(userID uint64) *BanUserCommand {
-	return &BanUserCommand{UserID: userID}
+func NewBanUserCommand(userID uint64, reason string) *BanUserCommand {
+	return &BanUserCommand{UserID: userID, Reason: reason}
+}
+
+// DeactivateUserCommand 禁用未激活用户命令，由流程管理器等内部流程按 UUID 发出
+type DeactivateUserCommand struct {
+	UserUUID string
+}
+
+// NewDeactivateUserCommand 创建禁用未激活用户命令
+func NewDeactivateUserCommand(userUUID string) *DeactivateUserCommand {
+	return &DeactivateUserCommand{UserUUID: userUUID}
+}
+
+// ResetPasswordCommand 重置密码命令，由找回密码流程在校验重置令牌后发出
+// PasswordHash 是已经通过 valueobject.NewPassword 校验并加密的新密码
+type ResetPasswordCommand struct {
+	UserID       uint64
+	PasswordHash string
+}
+
+// NewResetPasswordCommand 创建重置密码命令
+func NewResetPasswordCommand(userID uint64, passwordHash string) *ResetPasswordCommand {
+	return &ResetPasswordCommand{UserID: userID, PasswordHash: passwordHash}
+}
+
+// RevokeUserTokensCommand 撤销用户全部令牌命令，由重置两步验证等内部流程发出
+type RevokeUserTokensCommand struct {
+	UserID uint64
+	Reason string
+}
+
+// NewRevokeUserTokensCommand 创建撤销用户全部令牌命令
+func NewRevokeUserTokensCommand(userID uint64, reason string) *RevokeUserTokensCommand {
+	return &RevokeUserTokensCommand{UserID: userID, Reason: reason}
 }
 
 // PromoteToAdminCommand 提升为管理员命令
@@ -71,3 +121,23 @@ type PromoteToAdminCommand struct {
 func NewPromoteToAdminCommand(userID uint64) *PromoteToAdminCommand {
 	return &PromoteToAdminCommand{UserID: userID}
 }
+
+// ConfirmEmailCommand 确认邮箱命令，由邮箱验证流程在校验验证令牌后发出
+type ConfirmEmailCommand struct {
+	UserID uint64
+}
+
+// NewConfirmEmailCommand 创建确认邮箱命令
+func NewConfirmEmailCommand(userID uint64) *ConfirmEmailCommand {
+	return &ConfirmEmailCommand{UserID: userID}
+}
+
+// UnlockUserCommand 解除账户登录锁定命令
+type UnlockUserCommand struct {
+	UserID uint64
+}
+
+// NewUnlockUserCommand 创建解除账户登录锁定命令
+func NewUnlockUserCommand(userID uint64) *UnlockUserCommand {
+	return &UnlockUserCommand{UserID: userID}
+}
@@ -0,0 +1,46 @@
+package command
+
+// CreateWebhookCommand 注册 webhook 端点命令
+type CreateWebhookCommand struct {
+	URL        string
+	EventTypes []string
+	Secret     string
+}
+
+// NewCreateWebhookCommand 创建注册 webhook 端点命令
+func NewCreateWebhookCommand(url string, eventTypes []string, secret string) *CreateWebhookCommand {
+	return &CreateWebhookCommand{URL: url, EventTypes: eventTypes, Secret: secret}
+}
+
+// UpdateWebhookCommand 更新 webhook 端点命令
+type UpdateWebhookCommand struct {
+	EndpointID uint64
+	URL        string
+	EventTypes []string
+	Active     bool
+}
+
+// NewUpdateWebhookCommand 创建更新 webhook 端点命令
+func NewUpdateWebhookCommand(endpointID uint64, url string, eventTypes []string, active bool) *UpdateWebhookCommand {
+	return &UpdateWebhookCommand{EndpointID: endpointID, URL: url, EventTypes: eventTypes, Active: active}
+}
+
+// DeleteWebhookCommand 删除 webhook 端点命令
+type DeleteWebhookCommand struct {
+	EndpointID uint64
+}
+
+// NewDeleteWebhookCommand 创建删除 webhook 端点命令
+func NewDeleteWebhookCommand(endpointID uint64) *DeleteWebhookCommand {
+	return &DeleteWebhookCommand{EndpointID: endpointID}
+}
+
+// ReplayWebhookDeliveryCommand 重放 webhook 投递命令
+type ReplayWebhookDeliveryCommand struct {
+	DeliveryID uint64
+}
+
+// NewReplayWebhookDeliveryCommand 创建重放 webhook 投递命令
+func NewReplayWebhookDeliveryCommand(deliveryID uint64) *ReplayWebhookDeliveryCommand {
+	return &ReplayWebhookDeliveryCommand{DeliveryID: deliveryID}
+}
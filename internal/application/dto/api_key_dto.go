@@ -0,0 +1,36 @@
+package dto
+
+import "time"
+
+// CreateAPIKeyRequest 创建 API 密钥请求，expires_in_days 为 0 表示不过期
+type CreateAPIKeyRequest struct {
+	Name          string   `json:"name" binding:"required,max=100"`
+	Scopes        []string `json:"scopes" binding:"required,min=1,dive,required,max=100,excludesall= "`
+	ExpiresInDays int      `json:"expires_in_days" binding:"omitempty,min=1,max=3650"`
+}
+
+// APIKeyDTO API 密钥，只展示前缀
+type APIKeyDTO struct {
+	ID         uint64     `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Scopes     []string   `json:"scopes"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// CreatedAPIKeyDTO 新创建的 API 密钥，完整密钥只在创建时返回一次
+type CreatedAPIKeyDTO struct {
+	APIKeyDTO
+	Key string `json:"key"`
+}
+
+// APIKeyPrincipalDTO API 密钥认证通过后代表的用户和权限范围
+type APIKeyPrincipalDTO struct {
+	KeyID    uint64
+	UserID   uint64
+	Username string
+	Roles    []string
+	Scopes   []string
+}
@@ -0,0 +1,65 @@
+package dto
+
+import (
+	"time"
+	"yiwen/go-ddd/internal/domain/entity"
+)
+
+// ListAuditLogsRequest 审计日志查询参数，时间使用 RFC3339 格式，范围为 [from, to)
+type ListAuditLogsRequest struct {
+	ActorID  uint64    `form:"actor_id"`
+	Target   string    `form:"target"`
+	Action   string    `form:"action"`
+	From     time.Time `form:"from" time_format:"2006-01-02T15:04:05Z07:00"`
+	To       time.Time `form:"to" time_format:"2006-01-02T15:04:05Z07:00"`
+	Page     int       `form:"page"`
+	PageSize int       `form:"page_size"`
+}
+
+// BanUserRequest 禁用用户请求
+type BanUserRequest struct {
+	Reason string `json:"reason" binding:"required,max=500"`
+}
+
+type AuditLogDTO struct {
+	ID         uint64    `json:"id"`
+	EventID    string    `json:"event_id"`
+	Source     string    `json:"source"`
+	ActorID    uint64    `json:"actor_id"`
+	ActorName  string    `json:"actor_name"`
+	TargetUUID string    `json:"target_uuid"`
+	Action     string    `json:"action"`
+	Reason     string    `json:"reason"`
+	IP         string    `json:"ip"`
+	UserAgent  string    `json:"user_agent"`
+	OccurredAt time.Time `json:"occurred_at"`
+}
+
+type AuditLogListDTO struct {
+	Total int64         `json:"total"`
+	Items []AuditLogDTO `json:"items"`
+}
+
+func ToAuditLogDTO(log *entity.AuditLog) AuditLogDTO {
+	return AuditLogDTO{
+		ID:         log.ID,
+		EventID:    log.EventID,
+		Source:     string(log.Source),
+		ActorID:    log.ActorID,
+		ActorName:  log.ActorName,
+		TargetUUID: log.TargetUUID,
+		Action:     log.Action,
+		Reason:     log.Reason,
+		IP:         log.IP,
+		UserAgent:  log.UserAgent,
+		OccurredAt: log.OccurredAt,
+	}
+}
+
+func ToAuditLogDTOList(logs []*entity.AuditLog) []AuditLogDTO {
+	dtos := make([]AuditLogDTO, len(logs))
+	for i, log := range logs {
+		dtos[i] = ToAuditLogDTO(log)
+	}
+	return dtos
+}
@@ -0,0 +1,51 @@
+package dto
+
+import "time"
+
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+// LogoutRequest 注销请求，refresh_token 可选
+type LogoutRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+// TokenDTO 访问令牌和刷新令牌
+type TokenDTO struct {
+	Token            string `json:"token"`
+	ExpiresAt        int64  `json:"expires_at"`
+	RefreshToken     string `json:"refresh_token"`
+	RefreshExpiresAt int64  `json:"refresh_expires_at"`
+}
+
+// SessionDTO 登录会话，current 表示发起请求的会话
+type SessionDTO struct {
+	ID         uint64    `json:"id"`
+	Device     string    `json:"device"`
+	UserAgent  string    `json:"user_agent"`
+	IP         string    `json:"ip"`
+	Current    bool      `json:"current"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+}
+
+// ExternalProviderDTO 可用于登录的外部身份提供方
+type ExternalProviderDTO struct {
+	Name        string `json:"name"`
+	DisplayName string `json:"display_name"`
+}
+
+// ExternalLoginDTO 外部登录的跳转地址
+type ExternalLoginDTO struct {
+	AuthorizationURL string `json:"authorization_url"`
+}
+
+// ExternalLoginCallbackRequest 提供方回调参数，用户拒绝授权时只带 error
+type ExternalLoginCallbackRequest struct {
+	Code             string `form:"code"`
+	State            string `form:"state" binding:"required"`
+	Error            string `form:"error"`
+	ErrorDescription string `form:"error_description"`
+}
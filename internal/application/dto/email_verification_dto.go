@@ -0,0 +1,11 @@
+package dto
+
+// VerifyEmailRequest 验证邮箱请求，token 来自验证邮件
+type VerifyEmailRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+// ResendEmailVerificationRequest 重新发送验证邮件请求
+type ResendEmailVerificationRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
@@ -0,0 +1,96 @@
+package dto
+
+import (
+	"time"
+	"yiwen/go-ddd/internal/domain/entity"
+)
+
+// CreateOAuthClientRequest 注册 OAuth2 客户端请求
+type CreateOAuthClientRequest struct {
+	Name         string   `json:"name" binding:"required,max=100"`
+	RedirectURIs []string `json:"redirect_uris" binding:"dive,url,max=500"`
+	Scopes       []string `json:"scopes" binding:"required,min=1"`
+	GrantTypes   []string `json:"grant_types" binding:"required,min=1,dive,oneof=authorization_code refresh_token client_credentials"`
+	Confidential bool     `json:"confidential"`
+}
+
+// AuthorizeRequest 授权请求参数
+type AuthorizeRequest struct {
+	ResponseType        string `form:"response_type" json:"response_type" binding:"required"`
+	ClientID            string `form:"client_id" json:"client_id" binding:"required"`
+	RedirectURI         string `form:"redirect_uri" json:"redirect_uri"`
+	Scope               string `form:"scope" json:"scope"`
+	State               string `form:"state" json:"state"`
+	CodeChallenge       string `form:"code_challenge" json:"code_challenge"`
+	CodeChallengeMethod string `form:"code_challenge_method" json:"code_challenge_method"`
+	Nonce               string `form:"nonce" json:"nonce"`
+}
+
+// ConsentRequest 用户同意或拒绝授权，携带与授权请求相同的参数
+type ConsentRequest struct {
+	AuthorizeRequest
+	Approved bool `json:"approved"`
+}
+
+// OAuthTokenRequest 令牌请求，按 RFC 6749 使用表单提交
+type OAuthTokenRequest struct {
+	GrantType    string `form:"grant_type" binding:"required"`
+	Code         string `form:"code"`
+	RedirectURI  string `form:"redirect_uri"`
+	CodeVerifier string `form:"code_verifier"`
+	RefreshToken string `form:"refresh_token"`
+	Scope        string `form:"scope"`
+	ClientID     string `form:"client_id"`
+	ClientSecret string `form:"client_secret"`
+}
+
+// OAuthClientDTO OAuth2 客户端，密钥只在创建时返回
+type OAuthClientDTO struct {
+	ClientID     string    `json:"client_id"`
+	ClientSecret string    `json:"client_secret,omitempty"`
+	Name         string    `json:"name"`
+	RedirectURIs []string  `json:"redirect_uris"`
+	Scopes       []string  `json:"scopes"`
+	GrantTypes   []string  `json:"grant_types"`
+	Confidential bool      `json:"confidential"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// AuthorizeDTO 授权请求的处理结果
+// 需要用户同意时返回客户端信息和权限范围，否则返回带有授权码或错误信息的回调地址
+type AuthorizeDTO struct {
+	ConsentRequired bool            `json:"consent_required"`
+	Client          *OAuthClientDTO `json:"client,omitempty"`
+	Scopes          []string        `json:"scopes,omitempty"`
+	RedirectTo      string          `json:"redirect_to,omitempty"`
+}
+
+// OAuthTokenDTO RFC 6749 格式的令牌响应
+type OAuthTokenDTO struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int64  `json:"expires_in"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	Scope        string `json:"scope,omitempty"`
+	IDToken      string `json:"id_token,omitempty"`
+}
+
+func ToOAuthClientDTO(client *entity.OAuthClient) OAuthClientDTO {
+	return OAuthClientDTO{
+		ClientID:     client.ClientID,
+		Name:         client.Name,
+		RedirectURIs: client.RedirectURIs,
+		Scopes:       client.Scopes,
+		GrantTypes:   client.GrantTypes,
+		Confidential: client.IsConfidential(),
+		CreatedAt:    client.CreatedAt,
+	}
+}
+
+func ToOAuthClientDTOList(clients []*entity.OAuthClient) []OAuthClientDTO {
+	dtos := make([]OAuthClientDTO, len(clients))
+	for i, client := range clients {
+		dtos[i] = ToOAuthClientDTO(client)
+	}
+	return dtos
+}
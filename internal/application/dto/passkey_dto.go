@@ -0,0 +1,92 @@
+package dto
+
+import "time"
+
+// 通行密钥的选项和凭证使用 WebAuthn 规定的 JSON 格式，二进制字段为 base64url 编码
+// 前端可以直接传给 PublicKeyCredential.parseCreationOptionsFromJSON / parseRequestOptionsFromJSON，
+// 并把 credential.toJSON() 的结果原样提交
+
+// PasskeyRegistrationRequest 注册通行密钥请求
+type PasskeyRegistrationRequest struct {
+	Name     string `json:"name" binding:"max=100"`
+	ID       string `json:"id" binding:"required"`
+	Type     string `json:"type" binding:"required,eq=public-key"`
+	Response struct {
+		ClientDataJSON    string `json:"clientDataJSON" binding:"required"`
+		AttestationObject string `json:"attestationObject" binding:"required"`
+	} `json:"response"`
+}
+
+// PasskeyLoginRequest 通行密钥登录请求
+type PasskeyLoginRequest struct {
+	ID       string `json:"id" binding:"required"`
+	Type     string `json:"type" binding:"required,eq=public-key"`
+	Response struct {
+		ClientDataJSON    string `json:"clientDataJSON" binding:"required"`
+		AuthenticatorData string `json:"authenticatorData" binding:"required"`
+		Signature         string `json:"signature" binding:"required"`
+		UserHandle        string `json:"userHandle"`
+	} `json:"response"`
+}
+
+// PasskeyCredentialDescriptor 凭证描述
+type PasskeyCredentialDescriptor struct {
+	Type string `json:"type"`
+	ID   string `json:"id"`
+}
+
+// PasskeyCredentialParameter 支持的公钥算法
+type PasskeyCredentialParameter struct {
+	Type string `json:"type"`
+	Alg  int64  `json:"alg"`
+}
+
+// PasskeyRelyingParty 依赖方信息
+type PasskeyRelyingParty struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// PasskeyUser 通行密钥所属用户，id 为用户 UUID 的 16 字节
+type PasskeyUser struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	DisplayName string `json:"displayName"`
+}
+
+// PasskeyAuthenticatorSelection 认证器要求，通行密钥必须是可发现凭证并验证用户
+type PasskeyAuthenticatorSelection struct {
+	ResidentKey        string `json:"residentKey"`
+	RequireResidentKey bool   `json:"requireResidentKey"`
+	UserVerification   string `json:"userVerification"`
+}
+
+// PasskeyCreationOptionsDTO 注册选项，对应 PublicKeyCredentialCreationOptionsJSON
+type PasskeyCreationOptionsDTO struct {
+	RP                     PasskeyRelyingParty           `json:"rp"`
+	User                   PasskeyUser                   `json:"user"`
+	Challenge              string                        `json:"challenge"`
+	PubKeyCredParams       []PasskeyCredentialParameter  `json:"pubKeyCredParams"`
+	Timeout                int64                         `json:"timeout"`
+	ExcludeCredentials     []PasskeyCredentialDescriptor `json:"excludeCredentials"`
+	AuthenticatorSelection PasskeyAuthenticatorSelection `json:"authenticatorSelection"`
+	Attestation            string                        `json:"attestation"`
+}
+
+// PasskeyRequestOptionsDTO 登录选项，对应 PublicKeyCredentialRequestOptionsJSON
+// allowCredentials 为空，由用户在认证器中选择通行密钥
+type PasskeyRequestOptionsDTO struct {
+	Challenge        string                        `json:"challenge"`
+	Timeout          int64                         `json:"timeout"`
+	RPID             string                        `json:"rpId"`
+	AllowCredentials []PasskeyCredentialDescriptor `json:"allowCredentials"`
+	UserVerification string                        `json:"userVerification"`
+}
+
+// PasskeyDTO 已注册的通行密钥
+type PasskeyDTO struct {
+	ID         uint64     `json:"id"`
+	Name       string     `json:"name"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+}
@@ -0,0 +1,55 @@
+package dto
+
+import (
+	"time"
+	"yiwen/go-ddd/internal/domain/entity"
+)
+
+// CreateRoleRequest 创建角色请求，parent_id 为 0 表示不继承其他角色
+type CreateRoleRequest struct {
+	Name        string   `json:"name" binding:"required,max=50"`
+	Description string   `json:"description" binding:"max=255"`
+	ParentID    uint64   `json:"parent_id"`
+	Permissions []string `json:"permissions" binding:"dive,required,max=100"`
+}
+
+type UpdateRoleRequest struct {
+	Description string   `json:"description" binding:"max=255"`
+	ParentID    uint64   `json:"parent_id"`
+	Permissions []string `json:"permissions" binding:"dive,required,max=100"`
+}
+
+// AssignRoleRequest 为用户分配角色请求
+type AssignRoleRequest struct {
+	Role string `json:"role" binding:"required,max=50"`
+}
+
+// RoleDTO 角色，effective_permissions 包含从父角色继承的权限
+type RoleDTO struct {
+	ID                   uint64    `json:"id"`
+	Name                 string    `json:"name"`
+	Description          string    `json:"description"`
+	ParentID             uint64    `json:"parent_id"`
+	Permissions          []string  `json:"permissions"`
+	EffectivePermissions []string  `json:"effective_permissions"`
+	CreatedAt            time.Time `json:"created_at"`
+	UpdatedAt            time.Time `json:"updated_at"`
+}
+
+type PermissionDTO struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+func ToRoleDTO(role *entity.Role, effectivePermissions []string) RoleDTO {
+	return RoleDTO{
+		ID:                   role.ID,
+		Name:                 role.Name,
+		Description:          role.Description,
+		ParentID:             role.ParentID,
+		Permissions:          role.Permissions,
+		EffectivePermissions: effectivePermissions,
+		CreatedAt:            role.CreatedAt,
+		UpdatedAt:            role.UpdatedAt,
+	}
+}
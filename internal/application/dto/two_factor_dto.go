@@ -0,0 +1,36 @@
+package dto
+
+// TwoFactorCodeRequest 提交验证码的请求，code 可以是验证码或恢复码
+type TwoFactorCodeRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+// VerifyTwoFactorRequest 登录第二步请求
+type VerifyTwoFactorRequest struct {
+	MFAToken string `json:"mfa_token" binding:"required"`
+	Code     string `json:"code" binding:"required"`
+}
+
+// TwoFactorStatusDTO 两步验证状态
+type TwoFactorStatusDTO struct {
+	Enabled                bool  `json:"enabled"`
+	RecoveryCodesRemaining int64 `json:"recovery_codes_remaining"`
+}
+
+// TwoFactorSetupDTO 待确认的两步验证密钥，provisioning_uri 可生成二维码供认证器应用扫描
+type TwoFactorSetupDTO struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"`
+}
+
+// RecoveryCodesDTO 恢复码，只在生成时返回一次
+type RecoveryCodesDTO struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// TwoFactorChallengeDTO 密码验证通过但需要两步验证时的登录结果
+type TwoFactorChallengeDTO struct {
+	TwoFactorRequired bool   `json:"two_factor_required"`
+	MFAToken          string `json:"mfa_token"`
+	ExpiresAt         int64  `json:"expires_at"`
+}
@@ -26,9 +26,8 @@ type LoginRequest struct {
 }
 
 type LoginResponse struct {
-	Token     string  `json:"token"`
-	ExpiresAt int64   `json:"expires_at"`
-	User      UserDTO `json:"user"`
+	TokenDTO
+	User UserDTO `json:"user"`
 }
 
 type UpdateProfileRequest struct {
@@ -42,15 +41,18 @@ type ChangePasswordRequest struct {
 }
 
 type UserDTO struct {
-	ID       uint64    `json:"id"`
-	UUID     string    `json:"uuid"`
-	Username string    `json:"username"`
-	Email    string    `json:"email"`
-	Nickname string    `json:"nickname"`
-	Avatar   string    `json:"avatar"`
-	Status   int       `json:"status"`
-	Role     string    `json:"role"`
-	CreateAt time.Time `json:"create_at"`
+	ID            uint64    `json:"id"`
+	UUID          string    `json:"uuid"`
+	Username      string    `json:"username"`
+	Email         string    `json:"email"`
+	Nickname      string    `json:"nickname"`
+	Avatar        string    `json:"avatar"`
+	EmailVerified bool      `json:"email_verified"`
+	Status        int       `json:"status"`
+	Roles         []string  `json:"roles"`
+	CreateAt      time.Time `json:"create_at"`
+	// PasswordResetRequired 密码需要通过找回密码重新设置
+	PasswordResetRequired bool `json:"password_reset_required,omitempty"`
 }
 
 type UserListDTO struct {
@@ -60,14 +62,16 @@ type UserListDTO struct {
 
 func ToUserDTO(user *entity.User) UserDTO {
 	return UserDTO{
-		ID:       user.ID,
-		UUID:     user.UUID,
-		Username: user.Username,
-		Email:    user.Email.String(),
-		Nickname: user.Nickname,
-		Avatar:   user.Avatar,
-		Status:   int(user.Status),
-		Role:     string(user.Role),
+		ID:                    user.ID,
+		UUID:                  user.UUID,
+		Username:              user.Username,
+		Email:                 user.Email.String(),
+		Nickname:              user.Nickname,
+		Avatar:                user.Avatar,
+		EmailVerified:         user.EmailVerified,
+		Status:                int(user.Status),
+		Roles:                 user.RoleNames(),
+		PasswordResetRequired: user.PasswordResetRequired,
 	}
 }
 
@@ -0,0 +1,106 @@
+package dto
+
+import (
+	"time"
+	"yiwen/go-ddd/internal/domain/entity"
+)
+
+// CreateWebhookRequest 注册 webhook 端点请求
+// secret 为空时由服务端生成
+type CreateWebhookRequest struct {
+	URL        string   `json:"url" binding:"required,url,max=500"`
+	EventTypes []string `json:"event_types" binding:"required,min=1"`
+	Secret     string   `json:"secret" binding:"omitempty,min=16,max=100"`
+}
+
+type UpdateWebhookRequest struct {
+	URL        string   `json:"url" binding:"required,url,max=500"`
+	EventTypes []string `json:"event_types" binding:"required,min=1"`
+	Active     bool     `json:"active"`
+}
+
+// ListWebhookDeliveriesRequest 投递日志查询参数
+type ListWebhookDeliveriesRequest struct {
+	EndpointID uint64 `form:"endpoint_id"`
+	EventType  string `form:"event_type"`
+	Status     int    `form:"status" binding:"omitempty,oneof=1 2 3"`
+	Page       int    `form:"page"`
+	PageSize   int    `form:"page_size"`
+}
+
+// WebhookDTO webhook 端点，密钥只在创建时返回
+type WebhookDTO struct {
+	ID         uint64    `json:"id"`
+	URL        string    `json:"url"`
+	EventTypes []string  `json:"event_types"`
+	Secret     string    `json:"secret,omitempty"`
+	Active     bool      `json:"active"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+type WebhookDeliveryDTO struct {
+	ID             uint64     `json:"id"`
+	EndpointID     uint64     `json:"endpoint_id"`
+	EventID        string     `json:"event_id"`
+	EventType      string     `json:"event_type"`
+	Payload        string     `json:"payload"`
+	Status         int        `json:"status"`
+	Attempts       int        `json:"attempts"`
+	NextAttemptAt  time.Time  `json:"next_attempt_at"`
+	ResponseStatus int        `json:"response_status"`
+	ResponseBody   string     `json:"response_body"`
+	LastError      string     `json:"last_error"`
+	DeliveredAt    *time.Time `json:"delivered_at"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+type WebhookDeliveryListDTO struct {
+	Total int64                `json:"total"`
+	Items []WebhookDeliveryDTO `json:"items"`
+}
+
+func ToWebhookDTO(endpoint *entity.WebhookEndpoint) WebhookDTO {
+	return WebhookDTO{
+		ID:         endpoint.ID,
+		URL:        endpoint.URL,
+		EventTypes: endpoint.EventTypes,
+		Active:     endpoint.Active,
+		CreatedAt:  endpoint.CreatedAt,
+		UpdatedAt:  endpoint.UpdatedAt,
+	}
+}
+
+func ToWebhookDTOList(endpoints []*entity.WebhookEndpoint) []WebhookDTO {
+	dtos := make([]WebhookDTO, len(endpoints))
+	for i, endpoint := range endpoints {
+		dtos[i] = ToWebhookDTO(endpoint)
+	}
+	return dtos
+}
+
+func ToWebhookDeliveryDTO(delivery *entity.WebhookDelivery) WebhookDeliveryDTO {
+	return WebhookDeliveryDTO{
+		ID:             delivery.ID,
+		EndpointID:     delivery.EndpointID,
+		EventID:        delivery.EventID,
+		EventType:      delivery.EventType,
+		Payload:        delivery.Payload,
+		Status:         int(delivery.Status),
+		Attempts:       delivery.Attempts,
+		NextAttemptAt:  delivery.NextAttemptAt,
+		ResponseStatus: delivery.ResponseStatus,
+		ResponseBody:   delivery.ResponseBody,
+		LastError:      delivery.LastError,
+		DeliveredAt:    delivery.DeliveredAt,
+		CreatedAt:      delivery.CreatedAt,
+	}
+}
+
+func ToWebhookDeliveryDTOList(deliveries []*entity.WebhookDelivery) []WebhookDeliveryDTO {
+	dtos := make([]WebhookDeliveryDTO, len(deliveries))
+	for i, delivery := range deliveries {
+		dtos[i] = ToWebhookDeliveryDTO(delivery)
+	}
+	return dtos
+}
@@ -0,0 +1,36 @@
+package identity
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrAuthenticationFailed 提供方拒绝登录或返回的身份无法通过验证
+// 实现应当包装该错误，网络等临时错误原样返回
+var ErrAuthenticationFailed = errors.New("external authentication failed")
+
+// Identity 外部身份提供方认证后返回的用户信息
+type Identity struct {
+	Subject       string // 提供方内的用户唯一标识
+	Email         string
+	EmailVerified bool // 提供方是否已验证邮箱，只有已验证的邮箱才会用来关联已有用户
+	Username      string
+	Name          string
+	Picture       string
+}
+
+// Provider 外部身份提供方
+// 基础设施层实现具体的登录协议（例如 OIDC），应用层只负责把外部身份关联到用户
+type Provider interface {
+	// Name 提供方标识，出现在登录地址中
+	Name() string
+
+	// DisplayName 展示给用户的名称
+	DisplayName() string
+
+	// AuthCodeURL 返回跳转到提供方登录页面的地址
+	AuthCodeURL(ctx context.Context, state, nonce, codeChallenge string) (string, error)
+
+	// Exchange 使用回调中的授权码换取并验证用户身份
+	Exchange(ctx context.Context, code, codeVerifier, nonce string) (*Identity, error)
+}
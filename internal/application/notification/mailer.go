@@ -0,0 +1,16 @@
+package notification
+
+import "context"
+
+// Message 邮件消息
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer 邮件发送接口
+// 应用层只依赖该接口，具体实现（SMTP、第三方服务、日志）由基础设施层提供
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
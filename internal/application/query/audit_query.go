@@ -0,0 +1,39 @@
+package query
+
+import "time"
+
+// ListAuditLogsQuery 查询审计日志
+type ListAuditLogsQuery struct {
+	ActorID    uint64
+	TargetUUID string
+	Action     string
+	From       time.Time
+	To         time.Time
+	Offset     int
+	Limit      int
+}
+
+// NewListAuditLogsQuery 创建查询审计日志查询
+func NewListAuditLogsQuery(actorID uint64, targetUUID, action string, from, to time.Time, offset, limit int) *ListAuditLogsQuery {
+	if limit <= 0 {
+		limit = 10
+	}
+	if limit > 100 {
+		limit = 100
+	}
+	return &ListAuditLogsQuery{ActorID: actorID, TargetUUID: targetUUID, Action: action, From: from, To: to, Offset: offset, Limit: limit}
+}
+
+// ExportAuditLogsQuery 导出审计日志，导出全部符合条件的记录
+type ExportAuditLogsQuery struct {
+	ActorID    uint64
+	TargetUUID string
+	Action     string
+	From       time.Time
+	To         time.Time
+}
+
+// NewExportAuditLogsQuery 创建导出审计日志查询
+func NewExportAuditLogsQuery(actorID uint64, targetUUID, action string, from, to time.Time) *ExportAuditLogsQuery {
+	return &ExportAuditLogsQuery{ActorID: actorID, TargetUUID: targetUUID, Action: action, From: from, To: to}
+}
@@ -0,0 +1,12 @@
+package query
+
+// UserInfoQuery OIDC userinfo 查询，Scope 为访问令牌中授权的权限范围
+type UserInfoQuery struct {
+	UserID uint64
+	Scope  string
+}
+
+// NewUserInfoQuery 创建 userinfo 查询
+func NewUserInfoQuery(userID uint64, scope string) *UserInfoQuery {
+	return &UserInfoQuery{UserID: userID, Scope: scope}
+}
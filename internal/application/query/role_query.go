@@ -0,0 +1,11 @@
+package query
+
+// GetRoleQuery 根据id查询角色
+type GetRoleQuery struct {
+	RoleID uint64
+}
+
+// NewGetRoleQuery 创建根据id查询角色查询
+func NewGetRoleQuery(roleID uint64) *GetRoleQuery {
+	return &GetRoleQuery{RoleID: roleID}
+}
@@ -0,0 +1,31 @@
+package query
+
+// GetWebhookQuery 根据id查询 webhook 端点
+type GetWebhookQuery struct {
+	EndpointID uint64
+}
+
+// NewGetWebhookQuery 创建根据id查询 webhook 端点查询
+func NewGetWebhookQuery(endpointID uint64) *GetWebhookQuery {
+	return &GetWebhookQuery{EndpointID: endpointID}
+}
+
+// ListWebhookDeliveriesQuery 查询 webhook 投递日志
+type ListWebhookDeliveriesQuery struct {
+	EndpointID uint64
+	EventType  string
+	Status     int
+	Offset     int
+	Limit      int
+}
+
+// NewListWebhookDeliveriesQuery 创建查询 webhook 投递日志查询
+func NewListWebhookDeliveriesQuery(endpointID uint64, eventType string, status, offset, limit int) *ListWebhookDeliveriesQuery {
+	if limit <= 0 {
+		limit = 10
+	}
+	if limit > 100 {
+		limit = 100
+	}
+	return &ListWebhookDeliveriesQuery{EndpointID: endpointID, EventType: eventType, Status: status, Offset: offset, Limit: limit}
+}
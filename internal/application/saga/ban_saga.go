@@ -0,0 +1,52 @@
+package saga
+
+import (
+	"context"
+	"yiwen/go-ddd/internal/domain/event"
+)
+
+// BanSagaName 禁用用户后续流程名称
+const BanSagaName = "ban"
+
+// SessionRevoker 撤销用户的全部登录会话
+type SessionRevoker interface {
+	RevokeAll(ctx context.Context, userUUID string) error
+}
+
+// BanSaga 禁用用户后续流程：撤销该用户的全部会话
+// 每次禁用都是独立的实例，使用事件ID关联
+type BanSaga struct {
+	revoker SessionRevoker
+}
+
+func NewBanSaga(revoker SessionRevoker) *BanSaga {
+	return &BanSaga{revoker: revoker}
+}
+
+func (s *BanSaga) Name() string {
+	return BanSagaName
+}
+
+func (s *BanSaga) CorrelationID(e event.Event) string {
+	if _, ok := e.(*event.UserBannedEvent); ok {
+		return e.EventID()
+	}
+	return ""
+}
+
+func (s *BanSaga) StartedBy(e event.Event) bool {
+	return true
+}
+
+func (s *BanSaga) Handle(ctx context.Context, instance *Instance, e event.Event) error {
+	if err := s.revoker.RevokeAll(ctx, e.AggregateID()); err != nil {
+		return err
+	}
+	instance.Data["user_uuid"] = e.AggregateID()
+	instance.Complete("sessions_revoked")
+	return nil
+}
+
+func (s *BanSaga) HandleTimeout(ctx context.Context, instance *Instance) error {
+	return nil
+}
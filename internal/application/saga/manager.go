@@ -0,0 +1,125 @@
+package saga
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+	"yiwen/go-ddd/internal/domain/event"
+)
+
+// Manager 流程管理器
+// 作为事件处理器订阅事件总线，把事件分发给关心它的流程，并定时处理到期的超时。
+// outbox 的投递语义为至少一次，管理器记录每个流程处理过的事件ID，重复的事件直接忽略
+type Manager struct {
+	store Store
+	sagas map[string]Saga
+	order []Saga
+}
+
+// NewManager 创建流程管理器
+func NewManager(store Store, sagas ...Saga) *Manager {
+	m := &Manager{
+		store: store,
+		sagas: make(map[string]Saga, len(sagas)),
+		order: sagas,
+	}
+	for _, s := range sagas {
+		m.sagas[s.Name()] = s
+	}
+	return m
+}
+
+// Handle 实现 event.EventHandler
+func (m *Manager) Handle(e event.Event) error {
+	ctx := context.Background()
+
+	var errs []error
+	for _, s := range m.order {
+		if err := m.dispatch(ctx, s, e); err != nil {
+			errs = append(errs, fmt.Errorf("saga %s: %w", s.Name(), err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (m *Manager) dispatch(ctx context.Context, s Saga, e event.Event) error {
+	correlationID := s.CorrelationID(e)
+	if correlationID == "" {
+		return nil
+	}
+
+	processed, err := m.store.IsProcessed(ctx, s.Name(), e.EventID())
+	if err != nil || processed {
+		return err
+	}
+
+	instance, err := m.store.Find(ctx, s.Name(), correlationID)
+	if err != nil {
+		return err
+	}
+	if instance == nil {
+		if !s.StartedBy(e) {
+			return nil
+		}
+		instance = NewInstance(s.Name(), correlationID)
+	}
+	if instance.Completed {
+		return nil
+	}
+
+	if err := s.Handle(ctx, instance, e); err != nil {
+		return err
+	}
+
+	if err := m.store.Save(ctx, instance, e.EventID()); err != nil && !errors.Is(err, ErrEventProcessed) {
+		return err
+	}
+	return nil
+}
+
+// Run 启动超时调度循环，直到 ctx 被取消
+func (m *Manager) Run(ctx context.Context, pollInterval time.Duration, batchSize int) {
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := m.ProcessTimeouts(ctx, batchSize); err != nil {
+			log.Printf("saga: process timeouts failed: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// ProcessTimeouts 处理一批到期的超时
+// 调用 HandleTimeout 之前先清除超时，流程需要时可以重新设置
+func (m *Manager) ProcessTimeouts(ctx context.Context, batchSize int) error {
+	instances, err := m.store.FindDue(ctx, time.Now(), batchSize)
+	if err != nil {
+		return err
+	}
+
+	for _, instance := range instances {
+		s, ok := m.sagas[instance.SagaName]
+		if !ok {
+			log.Printf("saga: no saga registered for instance %d (%s)", instance.ID, instance.SagaName)
+			continue
+		}
+
+		instance.CancelTimeout()
+		if err := s.HandleTimeout(ctx, instance); err != nil {
+			log.Printf("saga: %s timeout for %s failed: %v", instance.SagaName, instance.CorrelationID, err)
+			continue
+		}
+		if err := m.store.Save(ctx, instance, ""); err != nil {
+			log.Printf("saga: save %s instance %s failed: %v", instance.SagaName, instance.CorrelationID, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+package saga
+
+import (
+	"context"
+	"errors"
+	"time"
+	"yiwen/go-ddd/internal/application/command"
+	"yiwen/go-ddd/internal/application/service"
+	"yiwen/go-ddd/internal/domain/aggregate"
+	"yiwen/go-ddd/internal/domain/entity"
+	"yiwen/go-ddd/internal/domain/event"
+	"yiwen/go-ddd/internal/domain/repository"
+)
+
+// RegistrationSagaName 注册后续流程名称
+const RegistrationSagaName = "registration"
+
+// 注册后续流程的状态
+const (
+	RegistrationAwaitingVerification = "awaiting_verification"
+	RegistrationVerified             = "verified"
+	RegistrationExpired              = "expired"
+	RegistrationCancelled            = "cancelled"
+)
+
+// RegistrationSaga 注册后续流程
+// 1. 用户注册后发送验证邮件，等待邮箱验证
+// 2. 邮箱验证通过后流程结束
+// 3. 超过 verificationTimeout 仍未验证时将用户设为未激活，执行前重新检查用户状态，已验证或已禁用的用户不处理
+// 4. 用户被删除或被禁用时流程取消
+type RegistrationSaga struct {
+	userRepo            repository.UserRepository
+	verificationService *service.EmailVerificationApplicationService
+	userService         *service.UserApplicationService
+	verificationTimeout time.Duration
+}
+
+// NewRegistrationSaga 创建注册后续流程，verificationTimeout <= 0 表示不限制验证时间
+func NewRegistrationSaga(userRepo repository.UserRepository, verificationService *service.EmailVerificationApplicationService, userService *service.UserApplicationService, verificationTimeout time.Duration) *RegistrationSaga {
+	return &RegistrationSaga{
+		userRepo:            userRepo,
+		verificationService: verificationService,
+		userService:         userService,
+		verificationTimeout: verificationTimeout,
+	}
+}
+
+func (s *RegistrationSaga) Name() string {
+	return RegistrationSagaName
+}
+
+// CorrelationID 使用用户 UUID 关联流程实例
+func (s *RegistrationSaga) CorrelationID(e event.Event) string {
+	switch e.(type) {
+	case *event.UserRegisteredEvent, *event.UserEmailVerifiedEvent, *event.UserBannedEvent, *event.UserDeletedEvent:
+		return e.AggregateID()
+	default:
+		return ""
+	}
+}
+
+func (s *RegistrationSaga) StartedBy(e event.Event) bool {
+	_, ok := e.(*event.UserRegisteredEvent)
+	return ok
+}
+
+func (s *RegistrationSaga) Handle(ctx context.Context, instance *Instance, e event.Event) error {
+	switch ev := e.(type) {
+	case *event.UserRegisteredEvent:
+		if err := s.verificationService.Send(ctx, command.NewSendEmailVerificationCommand(ev.AggregateID())); err != nil {
+			return err
+		}
+
+		instance.Data["email"] = ev.Email
+		instance.Transition(RegistrationAwaitingVerification)
+		if s.verificationTimeout > 0 {
+			instance.ScheduleTimeout(ev.OccurredAt().Add(s.verificationTimeout))
+		}
+	case *event.UserEmailVerifiedEvent:
+		instance.Complete(RegistrationVerified)
+	case *event.UserBannedEvent, *event.UserDeletedEvent:
+		instance.Complete(RegistrationCancelled)
+	}
+	return nil
+}
+
+// HandleTimeout 验证超时，将用户设为未激活
+// 超时可能先于验证、禁用事件到达，执行前以用户当前状态为准
+func (s *RegistrationSaga) HandleTimeout(ctx context.Context, instance *Instance) error {
+	user, err := s.userRepo.FindByUUID(ctx, instance.CorrelationID)
+	if err != nil {
+		return err
+	}
+	if user.EmailVerified {
+		instance.Complete(RegistrationVerified)
+		return nil
+	}
+	if user.Status == entity.UserStatusBanned {
+		instance.Complete(RegistrationCancelled)
+		return nil
+	}
+
+	ctx = event.ContextWithMetadata(ctx, event.Metadata{ActorName: "saga:" + RegistrationSagaName})
+	if err := s.userService.DeactivateUser(ctx, command.NewDeactivateUserCommand(instance.CorrelationID)); err != nil {
+		if errors.Is(err, aggregate.ErrUserBanned) {
+			instance.Complete(RegistrationCancelled)
+			return nil
+		}
+		return err
+	}
+	instance.Complete(RegistrationExpired)
+	return nil
+}
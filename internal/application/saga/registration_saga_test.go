@@ -0,0 +1,303 @@
+package saga
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+	"yiwen/go-ddd/internal/application/command"
+	"yiwen/go-ddd/internal/application/notification"
+	"yiwen/go-ddd/internal/application/service"
+	"yiwen/go-ddd/internal/domain/aggregate"
+	"yiwen/go-ddd/internal/domain/entity"
+	"yiwen/go-ddd/internal/domain/event"
+	"yiwen/go-ddd/internal/domain/repository"
+	domainservice "yiwen/go-ddd/internal/domain/service"
+	"yiwen/go-ddd/internal/domain/valueobject"
+	"yiwen/go-ddd/pkg/errors"
+
+	"github.com/google/uuid"
+)
+
+// memoryUserStore 测试用的内存用户存储，同时实现用户仓库和用户聚合仓库
+type memoryUserStore struct {
+	repository.UserRepository
+	mu    sync.Mutex
+	users map[uint64]*entity.User
+}
+
+func newMemoryUserStore() *memoryUserStore {
+	return &memoryUserStore{users: make(map[uint64]*entity.User)}
+}
+
+func (r *memoryUserStore) find(match func(u *entity.User) bool) (*entity.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, u := range r.users {
+		if match(u) {
+			clone := *u
+			return &clone, nil
+		}
+	}
+	return nil, repository.ErrUserNotFound
+}
+
+func (r *memoryUserStore) FindByID(ctx context.Context, id uint64) (*entity.User, error) {
+	return r.find(func(u *entity.User) bool { return u.ID == id })
+}
+
+func (r *memoryUserStore) FindByUUID(ctx context.Context, uuid string) (*entity.User, error) {
+	return r.find(func(u *entity.User) bool { return u.UUID == uuid })
+}
+
+func (r *memoryUserStore) FindByEmail(ctx context.Context, email string) (*entity.User, error) {
+	return r.find(func(u *entity.User) bool { return u.Email.String() == email })
+}
+
+func (r *memoryUserStore) ExistsByEmail(ctx context.Context, email string) (bool, error) {
+	_, err := r.FindByEmail(ctx, email)
+	return err == nil, nil
+}
+
+// aggregates 以聚合为单位加载和保存同一份用户数据
+func (r *memoryUserStore) aggregates() repository.UserAggregateRepository {
+	return memoryUserAggregateRepository{r}
+}
+
+type memoryUserAggregateRepository struct {
+	store *memoryUserStore
+}
+
+func (r memoryUserAggregateRepository) Load(ctx context.Context, id uint64) (*aggregate.UserAggregate, error) {
+	user, err := r.store.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return aggregate.NewUserAggregate(user), nil
+}
+
+func (r memoryUserAggregateRepository) Save(ctx context.Context, agg *aggregate.UserAggregate) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	if agg.User.ID == 0 {
+		agg.User.ID = uint64(len(r.store.users) + 1)
+	}
+	clone := *agg.User
+	r.store.users[clone.ID] = &clone
+	return nil
+}
+
+// memoryActionTokenRepository 测试用的内存操作令牌仓库
+type memoryActionTokenRepository struct {
+	mu     sync.Mutex
+	tokens []*entity.ActionToken
+}
+
+func (r *memoryActionTokenRepository) Save(ctx context.Context, token *entity.ActionToken) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.tokens = append(r.tokens, token)
+	return nil
+}
+
+func (r *memoryActionTokenRepository) FindLatest(ctx context.Context, userID uint64, purpose string) (*entity.ActionToken, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i := len(r.tokens) - 1; i >= 0; i-- {
+		if t := r.tokens[i]; t.UserID == userID && t.Purpose == purpose {
+			return t, nil
+		}
+	}
+	return nil, repository.ErrActionTokenNotFound
+}
+
+func (r *memoryActionTokenRepository) Take(ctx context.Context, purpose, tokenID string) (*entity.ActionToken, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, t := range r.tokens {
+		if t.Purpose == purpose && t.TokenID == tokenID {
+			r.tokens = append(r.tokens[:i], r.tokens[i+1:]...)
+			return t, nil
+		}
+	}
+	return nil, repository.ErrActionTokenNotFound
+}
+
+// plainTokenIssuer 不签名的操作令牌，格式为 purpose:userID:tokenID
+type plainTokenIssuer struct{}
+
+func (plainTokenIssuer) GenerateActionToken(purpose string, userID uint64, tokenID string, expiresAt time.Time) (string, error) {
+	return fmt.Sprintf("%s:%d:%s", purpose, userID, tokenID), nil
+}
+
+func (plainTokenIssuer) ParseActionToken(purpose, token string) (string, uint64, error) {
+	parts := strings.SplitN(token, ":", 3)
+	if len(parts) != 3 || parts[0] != purpose {
+		return "", 0, errors.New("invalid token")
+	}
+	userID, err := strconv.ParseUint(parts[1], 10, 64)
+	if err != nil {
+		return "", 0, err
+	}
+	return parts[2], userID, nil
+}
+
+// recordingMailer 记录发送的邮件
+type recordingMailer struct {
+	messages []notification.Message
+}
+
+func (m *recordingMailer) Send(ctx context.Context, msg notification.Message) error {
+	m.messages = append(m.messages, msg)
+	return nil
+}
+
+// token 取出邮件中的验证令牌，未配置验证页面时邮件直接给出令牌
+func (m *recordingMailer) token(t *testing.T, i int) string {
+	t.Helper()
+	for _, line := range strings.Split(m.messages[i].Body, "\n") {
+		if strings.HasPrefix(line, entity.ActionTokenEmailVerification+":") {
+			return line
+		}
+	}
+	t.Fatalf("no verification token in message %d", i)
+	return ""
+}
+
+type registrationFixture struct {
+	saga         *RegistrationSaga
+	verification *service.EmailVerificationApplicationService
+	users        *memoryUserStore
+	mailer       *recordingMailer
+}
+
+func newRegistrationFixture() *registrationFixture {
+	users := newMemoryUserStore()
+	mailer := &recordingMailer{}
+	userService := service.NewUserApplicationService(users, users.aggregates(), *domainservice.NewUserDomainService(users), nil, nil, true)
+	verification := service.NewEmailVerificationApplicationService(users, &memoryActionTokenRepository{}, userService, plainTokenIssuer{}, mailer, "", time.Hour, 0)
+	return &registrationFixture{
+		saga:         NewRegistrationSaga(users, verification, userService, 7*24*time.Hour),
+		verification: verification,
+		users:        users,
+		mailer:       mailer,
+	}
+}
+
+// register 注册用户并由流程处理注册事件
+func (f *registrationFixture) register(t *testing.T) (*Instance, *entity.User) {
+	t.Helper()
+	email, err := valueobject.NewEmail("alice@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	password, err := valueobject.NewPassword("Passw0rd!")
+	if err != nil {
+		t.Fatal(err)
+	}
+	agg, err := aggregate.Register(uuid.NewString(), "alice", email, password, "Alice", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := f.users.aggregates().Save(context.Background(), agg); err != nil {
+		t.Fatal(err)
+	}
+
+	instance := NewInstance(RegistrationSagaName, agg.User.UUID)
+	if err := f.saga.Handle(context.Background(), instance, agg.GetEvents()[0]); err != nil {
+		t.Fatalf("handle registered event: %v", err)
+	}
+	return instance, agg.User
+}
+
+// ban 禁用用户，返回禁用事件
+func (f *registrationFixture) ban(t *testing.T, user *entity.User) event.Event {
+	t.Helper()
+	agg, err := f.users.aggregates().Load(context.Background(), user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := agg.Ban("spam"); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.users.aggregates().Save(context.Background(), agg); err != nil {
+		t.Fatal(err)
+	}
+	return agg.GetEvents()[0]
+}
+
+func (f *registrationFixture) status(t *testing.T, user *entity.User) entity.UserStatus {
+	t.Helper()
+	current, err := f.users.FindByID(context.Background(), user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return current.Status
+}
+
+// 禁用事件到达流程之前超时先到期：超时不能把禁用改为未激活，用户也不能借重新验证邮箱解除禁用
+func TestRegistrationTimeoutKeepsBannedUserBanned(t *testing.T) {
+	f := newRegistrationFixture()
+	ctx := context.Background()
+
+	instance, user := f.register(t)
+	if instance.DeadlineAt == nil || len(f.mailer.messages) != 1 {
+		t.Fatalf("registration: deadline = %v, mails = %d, want a deadline and one mail", instance.DeadlineAt, len(f.mailer.messages))
+	}
+	token := f.mailer.token(t, 0)
+
+	f.ban(t, user)
+
+	if err := f.saga.HandleTimeout(ctx, instance); err != nil {
+		t.Fatalf("HandleTimeout: %v", err)
+	}
+	if !instance.Completed || instance.State != RegistrationCancelled {
+		t.Errorf("instance = %s (completed %v), want completed %s", instance.State, instance.Completed, RegistrationCancelled)
+	}
+	if got := f.status(t, user); got != entity.UserStatusBanned {
+		t.Fatalf("after timeout: status = %d, want banned", got)
+	}
+
+	if err := f.verification.Resend(ctx, command.NewResendEmailVerificationCommand(user.Email.String())); err != nil {
+		t.Fatalf("Resend: %v", err)
+	}
+	if len(f.mailer.messages) != 1 {
+		t.Errorf("Resend sent a mail to a banned user")
+	}
+
+	if _, err := f.verification.Verify(ctx, command.NewVerifyEmailCommand(token)); err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+	if got := f.status(t, user); got != entity.UserStatusBanned {
+		t.Fatalf("after verify: status = %d, want banned", got)
+	}
+}
+
+func TestRegistrationCancelledByBan(t *testing.T) {
+	f := newRegistrationFixture()
+
+	instance, user := f.register(t)
+	if err := f.saga.Handle(context.Background(), instance, f.ban(t, user)); err != nil {
+		t.Fatalf("handle banned event: %v", err)
+	}
+	if !instance.Completed || instance.State != RegistrationCancelled || instance.DeadlineAt != nil {
+		t.Errorf("instance = %s (completed %v, deadline %v), want cancelled without deadline", instance.State, instance.Completed, instance.DeadlineAt)
+	}
+}
+
+func TestDeactivateRefusesBannedUser(t *testing.T) {
+	f := newRegistrationFixture()
+
+	_, user := f.register(t)
+	f.ban(t, user)
+	agg, err := f.users.aggregates().Load(context.Background(), user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := agg.Deactivate(); !errors.Is(err, aggregate.ErrUserBanned) {
+		t.Fatalf("Deactivate: err = %v, want ErrUserBanned", err)
+	}
+}
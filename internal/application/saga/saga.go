@@ -0,0 +1,101 @@
+package saga
+
+import (
+	"context"
+	"errors"
+	"time"
+	"yiwen/go-ddd/internal/domain/event"
+)
+
+var (
+	// ErrEventProcessed 事件已经被该流程处理过
+	ErrEventProcessed = errors.New("event already processed by saga")
+	// ErrConcurrencyConflict 流程实例已被并发修改
+	ErrConcurrencyConflict = errors.New("saga instance was modified concurrently")
+)
+
+// Saga 流程定义（process manager）
+// 流程由领域事件驱动，跨越多个事件和较长的时间，状态保存在 Instance 中：
+// 1. 事件通过 CorrelationID 关联到流程实例，StartedBy 的事件会创建新实例
+// 2. Handle 根据事件推进实例状态，可以发出命令、发送通知、设置超时
+// 3. 超时到期后由调度器调用 HandleTimeout
+type Saga interface {
+	// Name 流程名称，与 CorrelationID 一起唯一确定一个实例
+	Name() string
+
+	// CorrelationID 返回事件关联的实例ID，返回空字符串表示不关心该事件
+	CorrelationID(e event.Event) string
+
+	// StartedBy 判断事件是否会创建新的流程实例
+	StartedBy(e event.Event) bool
+
+	// Handle 处理事件，推进实例状态
+	Handle(ctx context.Context, instance *Instance, e event.Event) error
+
+	// HandleTimeout 处理到期的超时
+	HandleTimeout(ctx context.Context, instance *Instance) error
+}
+
+// Instance 流程实例
+type Instance struct {
+	ID            uint64
+	SagaName      string
+	CorrelationID string
+	State         string            // 当前状态，由具体流程定义
+	Data          map[string]string // 流程需要保存的数据
+	DeadlineAt    *time.Time        // 下一次超时时间，nil 表示没有超时
+	Completed     bool              // 流程已结束，不再处理任何事件
+	Version       int               // 乐观锁版本号
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+// NewInstance 创建流程实例
+func NewInstance(sagaName, correlationID string) *Instance {
+	return &Instance{
+		SagaName:      sagaName,
+		CorrelationID: correlationID,
+		Data:          make(map[string]string),
+		CreatedAt:     time.Now(),
+		UpdatedAt:     time.Now(),
+	}
+}
+
+// Transition 切换状态
+func (i *Instance) Transition(state string) {
+	i.State = state
+	i.UpdatedAt = time.Now()
+}
+
+// ScheduleTimeout 设置超时，覆盖之前的超时
+func (i *Instance) ScheduleTimeout(at time.Time) {
+	i.DeadlineAt = &at
+}
+
+// CancelTimeout 取消超时
+func (i *Instance) CancelTimeout() {
+	i.DeadlineAt = nil
+}
+
+// Complete 以给定状态结束流程
+func (i *Instance) Complete(state string) {
+	i.Transition(state)
+	i.CancelTimeout()
+	i.Completed = true
+}
+
+// Store 流程实例存储
+type Store interface {
+	// Find 查询流程实例，不存在时返回 nil
+	Find(ctx context.Context, sagaName, correlationID string) (*Instance, error)
+
+	// Save 保存实例并递增版本号，版本号不一致时返回 ErrConcurrencyConflict
+	// eventID 不为空时在同一事务中记录该事件已处理，重复记录时返回 ErrEventProcessed
+	Save(ctx context.Context, instance *Instance, eventID string) error
+
+	// IsProcessed 判断事件是否已经被流程处理过
+	IsProcessed(ctx context.Context, sagaName, eventID string) (bool, error)
+
+	// FindDue 查询超时已到期且未结束的实例
+	FindDue(ctx context.Context, now time.Time, limit int) ([]*Instance, error)
+}
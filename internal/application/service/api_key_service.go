@@ -0,0 +1,178 @@
+package service
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"time"
+	"yiwen/go-ddd/internal/application/authz"
+	"yiwen/go-ddd/internal/application/command"
+	"yiwen/go-ddd/internal/application/dto"
+	"yiwen/go-ddd/internal/domain/entity"
+	"yiwen/go-ddd/internal/domain/repository"
+	"yiwen/go-ddd/pkg/errors"
+
+	domainservice "yiwen/go-ddd/internal/domain/service"
+)
+
+var (
+	ErrUnknownAPIKeyScope    = errors.New("unknown api key scope")
+	ErrAPIKeyScopeNotAllowed = errors.New("api key scope exceeds the caller's scope")
+)
+
+// apiKeyUsageInterval 使用时间的记录精度，避免每个请求都写数据库
+const apiKeyUsageInterval = time.Minute
+
+// APIKeyApplicationService API 密钥服务
+// 1. 用户创建带名称、权限范围和过期时间的密钥，完整密钥只在创建时返回一次
+// 2. 认证中间件通过 Authorization: Bearer <key> 接受密钥，密钥代表所属用户，只拥有创建时选择的权限范围
+// 3. 用户被禁用或删除后，其密钥随之失效
+// 4. 权限范围只能是系统定义的权限，不能超出调用方自身的权限范围
+type APIKeyApplicationService struct {
+	apiKeyRepo        repository.APIKeyRepository
+	userRepo          repository.UserRepository
+	roleDomainService *domainservice.RoleDomainService
+}
+
+// NewAPIKeyApplicationService 创建 API 密钥服务
+func NewAPIKeyApplicationService(apiKeyRepo repository.APIKeyRepository, userRepo repository.UserRepository, roleDomainService *domainservice.RoleDomainService) *APIKeyApplicationService {
+	return &APIKeyApplicationService{
+		apiKeyRepo:        apiKeyRepo,
+		userRepo:          userRepo,
+		roleDomainService: roleDomainService,
+	}
+}
+
+// Create 创建密钥
+func (s *APIKeyApplicationService) Create(ctx context.Context, cmd *command.CreateAPIKeyCommand) (*dto.CreatedAPIKeyDTO, error) {
+	// 去掉重复的权限范围，保持用户提交的顺序
+	scopes := make([]string, 0, len(cmd.Scopes))
+	for _, scope := range cmd.Scopes {
+		if !slices.Contains(scopes, scope) {
+			scopes = append(scopes, scope)
+		}
+	}
+	if err := s.validateScopes(ctx, cmd.UserID, scopes); err != nil {
+		return nil, err
+	}
+
+	secret, err := generateRefreshToken()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to generate api key")
+	}
+	key := entity.APIKeyPrefix + secret
+
+	var expiresAt *time.Time
+	if cmd.ExpiresIn > 0 {
+		t := time.Now().Add(cmd.ExpiresIn)
+		expiresAt = &t
+	}
+
+	apiKey := entity.NewAPIKey(cmd.UserID, strings.TrimSpace(cmd.Name), key[:entity.APIKeyDisplayLength], hashRefreshToken(key), scopes, expiresAt)
+	if err := s.apiKeyRepo.Save(ctx, apiKey); err != nil {
+		return nil, errors.Wrap(err, "failed to save api key")
+	}
+
+	return &dto.CreatedAPIKeyDTO{APIKeyDTO: toAPIKeyDTO(apiKey), Key: key}, nil
+}
+
+// List 查询用户的密钥
+func (s *APIKeyApplicationService) List(ctx context.Context, userID uint64) ([]dto.APIKeyDTO, error) {
+	keys, err := s.apiKeyRepo.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	dtos := make([]dto.APIKeyDTO, len(keys))
+	for i, key := range keys {
+		dtos[i] = toAPIKeyDTO(key)
+	}
+	return dtos, nil
+}
+
+// Revoke 删除密钥，之后使用该密钥的请求立即失败
+func (s *APIKeyApplicationService) Revoke(ctx context.Context, cmd *command.RevokeAPIKeyCommand) error {
+	return s.apiKeyRepo.Delete(ctx, cmd.UserID, cmd.APIKeyID)
+}
+
+// AuthenticateAPIKey 校验请求携带的密钥，密钥无效、过期或用户不可用时返回 nil
+// 实现 middleware.APIKeyAuthenticator
+func (s *APIKeyApplicationService) AuthenticateAPIKey(ctx context.Context, key string) (*dto.APIKeyPrincipalDTO, error) {
+	apiKey, err := s.apiKeyRepo.FindByHash(ctx, hashRefreshToken(key))
+	if err != nil {
+		if errors.Is(err, repository.ErrAPIKeyNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if apiKey.IsExpired() {
+		return nil, nil
+	}
+
+	// 用户不存在说明已被删除
+	user, err := s.userRepo.FindByID(ctx, apiKey.UserID)
+	if err != nil {
+		return nil, nil
+	}
+	if !user.IsActive() {
+		return nil, nil
+	}
+
+	now := time.Now()
+	if apiKey.LastUsedAt == nil || now.Sub(*apiKey.LastUsedAt) >= apiKeyUsageInterval {
+		if err := s.apiKeyRepo.UpdateLastUsed(ctx, apiKey.ID, now); err != nil {
+			return nil, err
+		}
+	}
+
+	return &dto.APIKeyPrincipalDTO{
+		KeyID:    apiKey.ID,
+		UserID:   user.ID,
+		Username: user.Username,
+		Roles:    user.RoleNames(),
+		Scopes:   apiKey.Scopes,
+	}, nil
+}
+
+// validateScopes 校验权限范围都是系统定义的权限
+// 通过 OAuth2 令牌或 API 密钥调用时，新密钥的权限范围必须在调用方的权限范围之内，避免通过创建密钥提升权限
+// 只有角色拥有全部权限的用户才能创建权限范围为 * 的密钥
+func (s *APIKeyApplicationService) validateScopes(ctx context.Context, userID uint64, scopes []string) error {
+	principal, _ := authz.PrincipalFromContext(ctx)
+	for _, scope := range scopes {
+		if !entity.IsKnownPermission(scope) {
+			return errors.Errorf("%w: %s", ErrUnknownAPIKeyScope, scope)
+		}
+		if principal.Delegated && !principal.AllowsScope(scope) {
+			return ErrAPIKeyScopeNotAllowed
+		}
+	}
+
+	if !slices.Contains(scopes, entity.PermissionAll) {
+		return nil
+	}
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return errors.Wrap(err, "user not found")
+	}
+	all, err := s.roleDomainService.HasPermission(ctx, user, entity.PermissionAll)
+	if err != nil {
+		return err
+	}
+	if !all {
+		return ErrAPIKeyScopeNotAllowed
+	}
+	return nil
+}
+
+func toAPIKeyDTO(key *entity.APIKey) dto.APIKeyDTO {
+	return dto.APIKeyDTO{
+		ID:         key.ID,
+		Name:       key.Name,
+		Prefix:     key.Prefix,
+		Scopes:     key.Scopes,
+		ExpiresAt:  key.ExpiresAt,
+		LastUsedAt: key.LastUsedAt,
+		CreatedAt:  key.CreatedAt,
+	}
+}
@@ -0,0 +1,93 @@
+package service
+
+import (
+	"context"
+	"time"
+	"yiwen/go-ddd/internal/application/dto"
+	"yiwen/go-ddd/internal/application/query"
+	"yiwen/go-ddd/internal/domain/entity"
+	"yiwen/go-ddd/internal/domain/event"
+	"yiwen/go-ddd/internal/domain/repository"
+	"yiwen/go-ddd/pkg/errors"
+
+	"github.com/google/uuid"
+)
+
+// exportBatchSize 导出时每次从数据库读取的记录数
+const exportBatchSize = 500
+
+// AuditApplicationService 审计日志应用服务
+// 用户相关的审计日志由领域事件投影生成；角色、webhook 等不产生领域事件的管理命令通过 Record 写入
+type AuditApplicationService struct {
+	auditRepo repository.AuditLogRepository
+}
+
+func NewAuditApplicationService(auditRepo repository.AuditLogRepository) *AuditApplicationService {
+	return &AuditApplicationService{auditRepo: auditRepo}
+}
+
+// ListAuditLogs 分页查询审计日志
+func (s *AuditApplicationService) ListAuditLogs(ctx context.Context, q *query.ListAuditLogsQuery) (*dto.AuditLogListDTO, error) {
+	filter := repository.AuditLogFilter{ActorID: q.ActorID, TargetUUID: q.TargetUUID, Action: q.Action, From: q.From, To: q.To}
+	logs, total, err := s.auditRepo.List(ctx, filter, q.Offset, q.Limit)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list audit logs")
+	}
+
+	return &dto.AuditLogListDTO{
+		Total: total,
+		Items: dto.ToAuditLogDTOList(logs),
+	}, nil
+}
+
+// ExportAuditLogs 分批读取符合条件的审计日志并逐条交给 write 处理
+// 按 (occurred_at, id) 游标分页，导出过程中新写入的记录不会导致重复或遗漏
+// 未指定结束时间时以导出开始的时间为准，导出开始之后的记录不包含在内
+func (s *AuditApplicationService) ExportAuditLogs(ctx context.Context, q *query.ExportAuditLogsQuery, write func(log dto.AuditLogDTO) error) error {
+	filter := repository.AuditLogFilter{ActorID: q.ActorID, TargetUUID: q.TargetUUID, Action: q.Action, From: q.From, To: q.To}
+	if filter.To.IsZero() {
+		filter.To = time.Now()
+	}
+
+	var after *repository.AuditLogCursor
+	for {
+		logs, err := s.auditRepo.ListAfter(ctx, filter, after, exportBatchSize)
+		if err != nil {
+			return errors.Wrap(err, "failed to export audit logs")
+		}
+
+		for _, log := range logs {
+			if err := write(dto.ToAuditLogDTO(log)); err != nil {
+				return err
+			}
+		}
+		if len(logs) < exportBatchSize {
+			return nil
+		}
+
+		last := logs[len(logs)-1]
+		after = &repository.AuditLogCursor{OccurredAt: last.OccurredAt, ID: last.ID}
+	}
+}
+
+// Record 记录一次管理命令，操作人、IP、User-Agent 取自请求的元数据
+// 在命令生效之后调用，写入失败时命令已经生效，返回的错误用于提示审计日志缺失
+func (s *AuditApplicationService) Record(ctx context.Context, action, target, detail string) error {
+	meta := event.MetadataFromContext(ctx)
+	log := &entity.AuditLog{
+		EventID:    uuid.New().String(),
+		Source:     entity.AuditSourceCommand,
+		ActorID:    meta.ActorID,
+		ActorName:  meta.ActorName,
+		TargetUUID: target,
+		Action:     action,
+		Reason:     detail,
+		IP:         meta.IP,
+		UserAgent:  meta.UserAgent,
+		OccurredAt: time.Now(),
+	}
+	if err := s.auditRepo.Save(ctx, log); err != nil {
+		return errors.Wrap(err, "failed to save audit log")
+	}
+	return nil
+}
@@ -0,0 +1,403 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"time"
+	"yiwen/go-ddd/internal/application/command"
+	"yiwen/go-ddd/internal/application/dto"
+	"yiwen/go-ddd/internal/domain/entity"
+	"yiwen/go-ddd/internal/domain/event"
+	"yiwen/go-ddd/internal/domain/repository"
+	domainservice "yiwen/go-ddd/internal/domain/service"
+	"yiwen/go-ddd/pkg/errors"
+	"yiwen/go-ddd/pkg/useragent"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrInvalidRefreshToken = errors.New("invalid refresh token")
+	ErrRefreshTokenReused  = errors.New("refresh token reused, all sessions of this login have been revoked")
+)
+
+// sessionActivityInterval 会话活跃时间的记录精度，避免每个请求都写数据库
+const sessionActivityInterval = time.Minute
+
+// maxUserAgentLength 会话中保存的 User-Agent 最大长度
+const maxUserAgentLength = 512
+
+// TokenIssuer 访问令牌签发接口，由接口层的 JWTAuth 实现
+// sessionID 只在自身登录签发时设置；clientID、scope 只在通过 OAuth2 签发时设置；客户端凭证令牌的 userID 为 0
+type TokenIssuer interface {
+	GenerateToken(userID uint64, username string, roles []string, tokenVersion int, sessionID, clientID, scope string) (string, int64, error)
+}
+
+// ActionTokenIssuer 一次性操作令牌签发接口，由接口层的 JWTAuth 实现
+// 令牌经过签名且带有用途和过期时间，不能伪造也不能挪作他用；只能使用一次由服务端登记的令牌ID保证
+type ActionTokenIssuer interface {
+	GenerateActionToken(purpose string, userID uint64, tokenID string, expiresAt time.Time) (string, error)
+	ParseActionToken(purpose, token string) (tokenID string, userID uint64, err error)
+}
+
+// AuthApplicationService 认证应用服务
+// 负责签发短期访问令牌和长期刷新令牌：
+// 1. 刷新令牌是随机生成的不透明字符串，数据库只保存其哈希
+// 2. 每次刷新都会轮换刷新令牌，旧令牌立即失效
+// 3. 已轮换的旧令牌再次被使用时撤销整个令牌家族，使盗用者和合法用户都需要重新登录
+// 4. 注销时记录访问令牌的 jti；修改密码、禁用、封禁会递增用户令牌版本，使之前签发的令牌全部失效
+// 5. 每次登录记录一个会话，用户可以查看登录过的设备并结束会话，会话结束后其访问令牌立即失效
+type AuthApplicationService struct {
+	userRepo         repository.UserRepository
+	refreshTokenRepo repository.RefreshTokenRepository
+	revokedTokenRepo repository.RevokedTokenRepository
+	sessionRepo      repository.SessionRepository
+	tokenIssuer      TokenIssuer
+	refreshExpire    time.Duration
+}
+
+// NewAuthApplicationService 创建认证应用服务
+func NewAuthApplicationService(userRepo repository.UserRepository, refreshTokenRepo repository.RefreshTokenRepository, revokedTokenRepo repository.RevokedTokenRepository, sessionRepo repository.SessionRepository, tokenIssuer TokenIssuer, refreshExpire time.Duration) *AuthApplicationService {
+	return &AuthApplicationService{
+		userRepo:         userRepo,
+		refreshTokenRepo: refreshTokenRepo,
+		revokedTokenRepo: revokedTokenRepo,
+		sessionRepo:      sessionRepo,
+		tokenIssuer:      tokenIssuer,
+		refreshExpire:    refreshExpire,
+	}
+}
+
+// IssueTokens 为登录成功的用户签发访问令牌，并开始一个新的刷新令牌家族和会话
+// 会话的设备、IP 取自 ctx 中的请求元数据，接口层需要传入 middleware.RequestContext
+func (s *AuthApplicationService) IssueTokens(ctx context.Context, userID uint64) (*dto.TokenDTO, error) {
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, errors.Wrap(err, "user not found")
+	}
+
+	session, err := s.startSession(ctx, user.ID, uuid.New().String())
+	if err != nil {
+		return nil, err
+	}
+	return s.issue(ctx, user, session.SessionID, "", "", session.SessionID)
+}
+
+// IssueClientTokens 为 OAuth2 客户端签发代表用户的令牌，familyID 不为空时同时签发该刷新令牌家族的第一个令牌
+func (s *AuthApplicationService) IssueClientTokens(ctx context.Context, userID uint64, clientID, scope, familyID string) (*dto.TokenDTO, error) {
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, errors.Wrap(err, "user not found")
+	}
+	if !user.IsActive() {
+		return nil, domainservice.ErrUserNotActive
+	}
+
+	if familyID != "" {
+		return s.issue(ctx, user, "", clientID, scope, familyID)
+	}
+
+	accessToken, expiresAt, err := s.tokenIssuer.GenerateToken(user.ID, user.Username, user.RoleNames(), user.TokenVersion, "", clientID, scope)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to generate access token")
+	}
+	return &dto.TokenDTO{Token: accessToken, ExpiresAt: expiresAt}, nil
+}
+
+// IssueClientCredentialsToken 为客户端自身签发访问令牌，令牌不属于任何用户，也不附带刷新令牌
+func (s *AuthApplicationService) IssueClientCredentialsToken(clientID, scope string) (*dto.TokenDTO, error) {
+	accessToken, expiresAt, err := s.tokenIssuer.GenerateToken(0, clientID, nil, 0, "", clientID, scope)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to generate access token")
+	}
+	return &dto.TokenDTO{Token: accessToken, ExpiresAt: expiresAt}, nil
+}
+
+// Refresh 使用刷新令牌换取新的访问令牌和刷新令牌
+func (s *AuthApplicationService) Refresh(ctx context.Context, cmd *command.RefreshTokenCommand) (*dto.TokenDTO, error) {
+	token, err := s.refreshTokenRepo.FindByHash(ctx, hashRefreshToken(cmd.RefreshToken))
+	if err != nil {
+		if errors.Is(err, repository.ErrRefreshTokenNotFound) {
+			return nil, ErrInvalidRefreshToken
+		}
+		return nil, err
+	}
+
+	if token.IsRevoked() || token.IsExpired() || token.ClientID != cmd.ClientID {
+		return nil, ErrInvalidRefreshToken
+	}
+	if token.IsUsed() {
+		return nil, s.revokeReusedFamily(ctx, token)
+	}
+
+	// 并发使用同一个令牌时只有一个请求能标记成功，其余的按重复使用处理
+	if err := s.refreshTokenRepo.MarkUsed(ctx, token.ID); err != nil {
+		if errors.Is(err, repository.ErrRefreshTokenUsed) {
+			return nil, s.revokeReusedFamily(ctx, token)
+		}
+		return nil, err
+	}
+
+	user, err := s.userRepo.FindByID(ctx, token.UserID)
+	if err != nil {
+		return nil, ErrInvalidRefreshToken
+	}
+	if !user.IsActive() || user.TokenVersion != token.TokenVersion {
+		if err := s.refreshTokenRepo.RevokeFamily(ctx, token.FamilyID); err != nil {
+			return nil, err
+		}
+		return nil, ErrInvalidRefreshToken
+	}
+
+	// 通过 OAuth2 签发的令牌不属于任何会话
+	if token.ClientID != "" {
+		return s.issue(ctx, user, "", token.ClientID, token.Scope, token.FamilyID)
+	}
+
+	if err := s.recordRefresh(ctx, user.ID, token.FamilyID); err != nil {
+		return nil, err
+	}
+	return s.issue(ctx, user, token.FamilyID, "", "", token.FamilyID)
+}
+
+// Logout 注销当前访问令牌并结束其所属的会话，提供刷新令牌时同时撤销其令牌家族
+func (s *AuthApplicationService) Logout(ctx context.Context, cmd *command.LogoutCommand) error {
+	if err := s.revokedTokenRepo.Revoke(ctx, cmd.TokenID, cmd.UserID, cmd.TokenExpiresAt); err != nil {
+		return errors.Wrap(err, "failed to revoke access token")
+	}
+
+	if cmd.SessionID != "" {
+		session, err := s.sessionRepo.FindBySessionID(ctx, cmd.SessionID)
+		if err != nil && !errors.Is(err, repository.ErrSessionNotFound) {
+			return err
+		}
+		if session != nil && session.UserID == cmd.UserID {
+			if err := s.endSession(ctx, session); err != nil {
+				return err
+			}
+		}
+	}
+
+	if cmd.RefreshToken == "" {
+		return nil
+	}
+
+	token, err := s.refreshTokenRepo.FindByHash(ctx, hashRefreshToken(cmd.RefreshToken))
+	if err != nil {
+		if errors.Is(err, repository.ErrRefreshTokenNotFound) {
+			return nil
+		}
+		return err
+	}
+	// 只能注销自己的刷新令牌
+	if token.UserID != cmd.UserID {
+		return nil
+	}
+	return s.refreshTokenRepo.RevokeFamily(ctx, token.FamilyID)
+}
+
+// RevokeTokenFamily 撤销刷新令牌家族中的全部令牌
+func (s *AuthApplicationService) RevokeTokenFamily(ctx context.Context, familyID string) error {
+	return s.refreshTokenRepo.RevokeFamily(ctx, familyID)
+}
+
+// IsTokenRevoked 判断访问令牌是否已注销、所属会话是否已结束，或者签发之后用户令牌版本已经变化
+// 用户不存在（例如已删除）时按已撤销处理，客户端凭证令牌不属于任何用户，只检查注销记录
+func (s *AuthApplicationService) IsTokenRevoked(ctx context.Context, tokenID string, userID uint64, tokenVersion int, sessionID string) (bool, error) {
+	if tokenID != "" {
+		revoked, err := s.revokedTokenRepo.IsRevoked(ctx, tokenID)
+		if err != nil {
+			return false, err
+		}
+		if revoked {
+			return true, nil
+		}
+	}
+
+	if sessionID != "" {
+		active, err := s.touchSession(ctx, sessionID)
+		if err != nil {
+			return false, err
+		}
+		if !active {
+			return true, nil
+		}
+	}
+
+	if userID == 0 {
+		return false, nil
+	}
+
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return true, nil
+	}
+	return user.TokenVersion != tokenVersion, nil
+}
+
+// RevokeAll 撤销用户的全部刷新令牌并结束全部会话
+// 通过 OAuth2 签发、不属于会话的访问令牌在过期前仍然有效
+func (s *AuthApplicationService) RevokeAll(ctx context.Context, userUUID string) error {
+	user, err := s.userRepo.FindByUUID(ctx, userUUID)
+	if err != nil {
+		return errors.Wrap(err, "user not found")
+	}
+	return s.revokeUserSessions(ctx, user.ID)
+}
+
+// ListSessions 查询用户的登录会话，currentSessionID 为发起请求的访问令牌所属的会话
+func (s *AuthApplicationService) ListSessions(ctx context.Context, userID uint64, currentSessionID string) ([]dto.SessionDTO, error) {
+	sessions, err := s.sessionRepo.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	dtos := make([]dto.SessionDTO, len(sessions))
+	for i, session := range sessions {
+		dtos[i] = dto.SessionDTO{
+			ID:         session.ID,
+			Device:     session.Device,
+			UserAgent:  session.UserAgent,
+			IP:         session.IP,
+			Current:    session.SessionID == currentSessionID,
+			CreatedAt:  session.CreatedAt,
+			LastSeenAt: session.LastSeenAt,
+			ExpiresAt:  session.ExpiresAt,
+		}
+	}
+	return dtos, nil
+}
+
+// RevokeSession 结束用户自己的一个会话
+func (s *AuthApplicationService) RevokeSession(ctx context.Context, cmd *command.RevokeSessionCommand) error {
+	session, err := s.sessionRepo.FindByID(ctx, cmd.SessionID)
+	if err != nil {
+		return err
+	}
+	if session.UserID != cmd.UserID {
+		return repository.ErrSessionNotFound
+	}
+	return s.endSession(ctx, session)
+}
+
+// RevokeUserSessions 管理员结束用户的全部会话
+func (s *AuthApplicationService) RevokeUserSessions(ctx context.Context, cmd *command.RevokeUserSessionsCommand) error {
+	return s.revokeUserSessions(ctx, cmd.UserID)
+}
+
+// startSession 根据请求元数据记录新会话
+func (s *AuthApplicationService) startSession(ctx context.Context, userID uint64, sessionID string) (*entity.Session, error) {
+	meta := event.MetadataFromContext(ctx)
+	userAgent := meta.UserAgent
+	if len(userAgent) > maxUserAgentLength {
+		userAgent = userAgent[:maxUserAgentLength]
+	}
+
+	session := entity.NewSession(sessionID, userID, useragent.Parse(meta.UserAgent).String(), userAgent, meta.IP, time.Now().Add(s.refreshExpire))
+	if err := s.sessionRepo.Save(ctx, session); err != nil {
+		return nil, errors.Wrap(err, "failed to save session")
+	}
+	return session, nil
+}
+
+// recordRefresh 刷新令牌时延长会话，上线会话功能之前开始的令牌家族在此补记会话
+func (s *AuthApplicationService) recordRefresh(ctx context.Context, userID uint64, familyID string) error {
+	session, err := s.sessionRepo.FindBySessionID(ctx, familyID)
+	if err != nil {
+		if errors.Is(err, repository.ErrSessionNotFound) {
+			_, err = s.startSession(ctx, userID, familyID)
+		}
+		return err
+	}
+
+	ip := event.MetadataFromContext(ctx).IP
+	if ip == "" {
+		ip = session.IP
+	}
+	return s.sessionRepo.UpdateActivity(ctx, session.ID, ip, time.Now(), time.Now().Add(s.refreshExpire))
+}
+
+// touchSession 判断会话是否仍然有效，并按记录精度更新活跃时间
+func (s *AuthApplicationService) touchSession(ctx context.Context, sessionID string) (bool, error) {
+	session, err := s.sessionRepo.FindBySessionID(ctx, sessionID)
+	if err != nil {
+		if errors.Is(err, repository.ErrSessionNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	if session.IsExpired() {
+		return false, nil
+	}
+
+	if now := time.Now(); now.Sub(session.LastSeenAt) >= sessionActivityInterval {
+		if err := s.sessionRepo.Touch(ctx, session.ID, now); err != nil {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// endSession 结束会话，先撤销刷新令牌，避免删除会话后仍能刷新
+func (s *AuthApplicationService) endSession(ctx context.Context, session *entity.Session) error {
+	if err := s.refreshTokenRepo.RevokeFamily(ctx, session.SessionID); err != nil {
+		return err
+	}
+	return s.sessionRepo.Delete(ctx, session.ID)
+}
+
+func (s *AuthApplicationService) revokeUserSessions(ctx context.Context, userID uint64) error {
+	if err := s.refreshTokenRepo.RevokeByUser(ctx, userID); err != nil {
+		return err
+	}
+	return s.sessionRepo.DeleteByUser(ctx, userID)
+}
+
+func (s *AuthApplicationService) issue(ctx context.Context, user *entity.User, sessionID, clientID, scope, familyID string) (*dto.TokenDTO, error) {
+	accessToken, expiresAt, err := s.tokenIssuer.GenerateToken(user.ID, user.Username, user.RoleNames(), user.TokenVersion, sessionID, clientID, scope)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to generate access token")
+	}
+
+	refreshToken, err := generateRefreshToken()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to generate refresh token")
+	}
+
+	token := entity.NewRefreshToken(user.ID, clientID, scope, familyID, hashRefreshToken(refreshToken), user.TokenVersion, time.Now().Add(s.refreshExpire))
+	if err := s.refreshTokenRepo.Save(ctx, token); err != nil {
+		return nil, errors.Wrap(err, "failed to save refresh token")
+	}
+
+	return &dto.TokenDTO{
+		Token:            accessToken,
+		ExpiresAt:        expiresAt,
+		RefreshToken:     refreshToken,
+		RefreshExpiresAt: token.ExpiresAt.Unix(),
+	}, nil
+}
+
+func (s *AuthApplicationService) revokeReusedFamily(ctx context.Context, token *entity.RefreshToken) error {
+	if err := s.refreshTokenRepo.RevokeFamily(ctx, token.FamilyID); err != nil {
+		return err
+	}
+	return ErrRefreshTokenReused
+}
+
+// generateRefreshToken 生成 256 位随机刷新令牌
+func generateRefreshToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// hashRefreshToken 令牌本身是高熵随机数，直接使用 SHA-256 即可
+func hashRefreshToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,159 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"time"
+	"yiwen/go-ddd/internal/application/command"
+	"yiwen/go-ddd/internal/application/dto"
+	"yiwen/go-ddd/internal/application/notification"
+	"yiwen/go-ddd/internal/domain/entity"
+	"yiwen/go-ddd/internal/domain/repository"
+	"yiwen/go-ddd/pkg/errors"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrInvalidVerificationToken = errors.New("invalid or expired verification token")
+	// ErrVerificationEmailThrottled 距离上次发送验证邮件的时间太短
+	ErrVerificationEmailThrottled = errors.New("verification email sent recently, please try again later")
+)
+
+// EmailVerificationApplicationService 邮箱验证服务
+// 1. 注册后由注册后续流程发送验证邮件，邮件中的令牌经过签名并带有过期时间
+// 2. 令牌ID登记在服务端，验证时取出删除，保证只能使用一次；重新发送后之前的令牌失效
+// 3. 验证通过后标记邮箱已验证，等待验证的用户同时被激活
+// 4. 重新发送按用户限制频率，邮箱不存在或已验证时不发送但返回相同的结果
+type EmailVerificationApplicationService struct {
+	userRepo        repository.UserRepository
+	actionTokenRepo repository.ActionTokenRepository
+	userService     *UserApplicationService
+	tokenIssuer     ActionTokenIssuer
+	mailer          notification.Mailer
+	verifyPageURL   string
+	tokenExpire     time.Duration
+	resendInterval  time.Duration
+}
+
+// NewEmailVerificationApplicationService 创建邮箱验证服务，verifyPageURL 为空时邮件中只包含验证令牌
+func NewEmailVerificationApplicationService(userRepo repository.UserRepository, actionTokenRepo repository.ActionTokenRepository, userService *UserApplicationService, tokenIssuer ActionTokenIssuer, mailer notification.Mailer, verifyPageURL string, tokenExpire, resendInterval time.Duration) *EmailVerificationApplicationService {
+	return &EmailVerificationApplicationService{
+		userRepo:        userRepo,
+		actionTokenRepo: actionTokenRepo,
+		userService:     userService,
+		tokenIssuer:     tokenIssuer,
+		mailer:          mailer,
+		verifyPageURL:   verifyPageURL,
+		tokenExpire:     tokenExpire,
+		resendInterval:  resendInterval,
+	}
+}
+
+// Send 向新注册的用户发送验证邮件，邮箱已验证时（如外部身份创建的用户）不发送
+func (s *EmailVerificationApplicationService) Send(ctx context.Context, cmd *command.SendEmailVerificationCommand) error {
+	user, err := s.userRepo.FindByUUID(ctx, cmd.UserUUID)
+	if err != nil {
+		return errors.Wrap(err, "user not found")
+	}
+	if user.EmailVerified {
+		return nil
+	}
+	return s.send(ctx, user)
+}
+
+// Resend 重新发送验证邮件
+func (s *EmailVerificationApplicationService) Resend(ctx context.Context, cmd *command.ResendEmailVerificationCommand) error {
+	exists, err := s.userRepo.ExistsByEmail(ctx, cmd.Email)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return nil
+	}
+
+	user, err := s.userRepo.FindByEmail(ctx, cmd.Email)
+	if err != nil {
+		return err
+	}
+	if user.EmailVerified || user.Status == entity.UserStatusBanned {
+		return nil
+	}
+
+	latest, err := s.actionTokenRepo.FindLatest(ctx, user.ID, entity.ActionTokenEmailVerification)
+	if err != nil && !errors.Is(err, repository.ErrActionTokenNotFound) {
+		return err
+	}
+	if latest != nil && time.Since(latest.CreatedAt) < s.resendInterval {
+		return ErrVerificationEmailThrottled
+	}
+
+	return s.send(ctx, user)
+}
+
+// Verify 校验验证令牌并确认邮箱
+func (s *EmailVerificationApplicationService) Verify(ctx context.Context, cmd *command.VerifyEmailCommand) (*dto.UserDTO, error) {
+	tokenID, userID, err := s.tokenIssuer.ParseActionToken(entity.ActionTokenEmailVerification, cmd.Token)
+	if err != nil {
+		return nil, ErrInvalidVerificationToken
+	}
+
+	token, err := s.actionTokenRepo.Take(ctx, entity.ActionTokenEmailVerification, tokenID)
+	if err != nil {
+		if errors.Is(err, repository.ErrActionTokenNotFound) {
+			return nil, ErrInvalidVerificationToken
+		}
+		return nil, err
+	}
+	if token.IsExpired() || token.UserID != userID {
+		return nil, ErrInvalidVerificationToken
+	}
+
+	user, err := s.userRepo.FindByID(ctx, token.UserID)
+	if err != nil {
+		return nil, errors.Wrap(err, "user not found")
+	}
+	// 发送之后邮箱被修改时，令牌验证的是旧邮箱，不能用来确认新邮箱
+	if user.Email.String() != token.Email {
+		return nil, ErrInvalidVerificationToken
+	}
+
+	return s.userService.ConfirmEmail(ctx, command.NewConfirmEmailCommand(user.ID))
+}
+
+// send 签发新的验证令牌并发送邮件，之前发送的令牌随之失效
+func (s *EmailVerificationApplicationService) send(ctx context.Context, user *entity.User) error {
+	expiresAt := time.Now().Add(s.tokenExpire)
+	token := entity.NewActionToken(uuid.New().String(), user.ID, entity.ActionTokenEmailVerification, user.Email.String(), expiresAt)
+
+	signed, err := s.tokenIssuer.GenerateActionToken(token.Purpose, token.UserID, token.TokenID, expiresAt)
+	if err != nil {
+		return errors.Wrap(err, "failed to generate verification token")
+	}
+	if err := s.actionTokenRepo.Save(ctx, token); err != nil {
+		return errors.Wrap(err, "failed to save verification token")
+	}
+
+	return s.mailer.Send(ctx, notification.Message{
+		To:      token.Email,
+		Subject: "请验证您的邮箱",
+		Body:    fmt.Sprintf("%s，您好：\n\n感谢注册，请通过以下链接验证您的邮箱地址以完成注册：\n\n%s\n\n链接 %d 小时内有效，只能使用一次。", user.Username, actionTokenLink(s.verifyPageURL, signed), int(s.tokenExpire.Hours())),
+	})
+}
+
+// actionTokenLink 邮件中的操作链接，未配置前端页面时直接给出令牌
+func actionTokenLink(pageURL, token string) string {
+	if pageURL == "" {
+		return token
+	}
+
+	u, err := url.Parse(pageURL)
+	if err != nil {
+		return token
+	}
+	q := u.Query()
+	q.Set("token", token)
+	u.RawQuery = q.Encode()
+	return u.String()
+}
@@ -0,0 +1,210 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"strings"
+	"time"
+	"yiwen/go-ddd/internal/application/command"
+	"yiwen/go-ddd/internal/application/dto"
+	"yiwen/go-ddd/internal/application/identity"
+	"yiwen/go-ddd/internal/domain/entity"
+	"yiwen/go-ddd/internal/domain/repository"
+	domainservice "yiwen/go-ddd/internal/domain/service"
+	"yiwen/go-ddd/pkg/errors"
+)
+
+var (
+	ErrUnknownIdentityProvider = errors.New("unknown identity provider")
+	ErrInvalidLoginState       = errors.New("invalid or expired login state")
+	ErrExternalEmailRequired   = errors.New("identity provider did not return an email")
+	// ErrExternalAccountConflict 邮箱已被本地用户使用，但双方未都验证过该邮箱，不能自动关联
+	ErrExternalAccountConflict = errors.New("email is already registered, sign in with password to link this account")
+)
+
+// ExternalLoginApplicationService 外部身份登录服务
+// 1. 登录前生成 state、nonce 和 PKCE code_verifier 并保存，回调时一次性取出校验，防止 CSRF 和重放
+// 2. 已关联的外部身份直接登录对应用户
+// 3. 首次登录时，提供方已验证的邮箱与本地已验证邮箱的用户相同则自动关联；邮箱未被使用则即时创建用户
+// 4. 返回登录的用户，之后与密码登录相同，按需进行两步验证并签发令牌
+type ExternalLoginApplicationService struct {
+	identityRepo repository.ExternalIdentityRepository
+	userRepo     repository.UserRepository
+	userService  *UserApplicationService
+	providers    map[string]identity.Provider
+	ordered      []identity.Provider
+	stateExpire  time.Duration
+}
+
+// NewExternalLoginApplicationService 创建外部身份登录服务
+func NewExternalLoginApplicationService(identityRepo repository.ExternalIdentityRepository, userRepo repository.UserRepository, userService *UserApplicationService, providers []identity.Provider, stateExpire time.Duration) *ExternalLoginApplicationService {
+	s := &ExternalLoginApplicationService{
+		identityRepo: identityRepo,
+		userRepo:     userRepo,
+		userService:  userService,
+		providers:    make(map[string]identity.Provider, len(providers)),
+		ordered:      providers,
+		stateExpire:  stateExpire,
+	}
+	for _, p := range providers {
+		s.providers[p.Name()] = p
+	}
+	return s
+}
+
+// ListProviders 返回已配置的提供方
+func (s *ExternalLoginApplicationService) ListProviders() []dto.ExternalProviderDTO {
+	result := make([]dto.ExternalProviderDTO, len(s.ordered))
+	for i, p := range s.ordered {
+		result[i] = dto.ExternalProviderDTO{Name: p.Name(), DisplayName: p.DisplayName()}
+	}
+	return result
+}
+
+// StartLogin 生成登录状态并返回提供方的登录地址
+func (s *ExternalLoginApplicationService) StartLogin(ctx context.Context, cmd *command.StartExternalLoginCommand) (*dto.ExternalLoginDTO, error) {
+	provider, ok := s.providers[cmd.Provider]
+	if !ok {
+		return nil, ErrUnknownIdentityProvider
+	}
+
+	var secrets [3]string
+	for i := range secrets {
+		secret, err := generateRefreshToken()
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to generate login state")
+		}
+		secrets[i] = secret
+	}
+	state, nonce, codeVerifier := secrets[0], secrets[1], secrets[2]
+
+	loginState := entity.NewExternalLoginState(hashRefreshToken(state), provider.Name(), nonce, codeVerifier, time.Now().Add(s.stateExpire))
+	if err := s.identityRepo.SaveLoginState(ctx, loginState); err != nil {
+		return nil, errors.Wrap(err, "failed to save login state")
+	}
+
+	sum := sha256.Sum256([]byte(codeVerifier))
+	authURL, err := provider.AuthCodeURL(ctx, state, nonce, base64.RawURLEncoding.EncodeToString(sum[:]))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to build authorization url")
+	}
+	return &dto.ExternalLoginDTO{AuthorizationURL: authURL}, nil
+}
+
+// CompleteLogin 校验回调、换取外部身份，并返回关联的用户
+func (s *ExternalLoginApplicationService) CompleteLogin(ctx context.Context, cmd *command.CompleteExternalLoginCommand) (*dto.UserDTO, error) {
+	provider, ok := s.providers[cmd.Provider]
+	if !ok {
+		return nil, ErrUnknownIdentityProvider
+	}
+
+	// state 无论成功与否都只能使用一次
+	loginState, err := s.identityRepo.TakeLoginState(ctx, hashRefreshToken(cmd.State))
+	if err != nil {
+		if errors.Is(err, repository.ErrExternalLoginStateNotFound) {
+			return nil, ErrInvalidLoginState
+		}
+		return nil, err
+	}
+	if loginState.IsExpired() || loginState.Provider != provider.Name() {
+		return nil, ErrInvalidLoginState
+	}
+	if cmd.Code == "" {
+		return nil, identity.ErrAuthenticationFailed
+	}
+
+	external, err := provider.Exchange(ctx, cmd.Code, loginState.CodeVerifier, loginState.Nonce)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.resolveUser(ctx, provider.Name(), external)
+	if err != nil {
+		return nil, err
+	}
+	if !user.IsActive() {
+		return nil, domainservice.ErrUserNotActive
+	}
+	if user.PasswordResetRequired {
+		return nil, domainservice.ErrPasswordResetRequired
+	}
+
+	result := dto.ToUserDTO(user)
+	return &result, nil
+}
+
+// resolveUser 找到或创建外部身份关联的用户
+func (s *ExternalLoginApplicationService) resolveUser(ctx context.Context, provider string, external *identity.Identity) (*entity.User, error) {
+	linked, err := s.identityRepo.FindIdentity(ctx, provider, external.Subject)
+	if err == nil {
+		linked.RecordLogin(external.Email)
+		if err := s.identityRepo.SaveIdentity(ctx, linked); err != nil {
+			return nil, errors.Wrap(err, "failed to save external identity")
+		}
+		return s.findUser(ctx, linked.UserID)
+	}
+	if !errors.Is(err, repository.ErrExternalIdentityNotFound) {
+		return nil, err
+	}
+
+	if external.Email == "" {
+		return nil, ErrExternalEmailRequired
+	}
+
+	userID, err := s.linkOrCreateUser(ctx, external)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.identityRepo.SaveIdentity(ctx, entity.NewExternalIdentity(userID, provider, external.Subject, external.Email)); err != nil {
+		// 并发的首次登录已经完成关联，按已关联处理
+		if errors.Is(err, repository.ErrExternalIdentityExists) {
+			return s.resolveUser(ctx, provider, external)
+		}
+		return nil, errors.Wrap(err, "failed to save external identity")
+	}
+	return s.findUser(ctx, userID)
+}
+
+// linkOrCreateUser 按邮箱关联已有用户，邮箱未被使用时即时创建用户
+// 只有双方都验证过邮箱时才自动关联，避免有人预先用他人邮箱注册后借此接管外部账户，反之亦然
+func (s *ExternalLoginApplicationService) linkOrCreateUser(ctx context.Context, external *identity.Identity) (uint64, error) {
+	exists, err := s.userRepo.ExistsByEmail(ctx, external.Email)
+	if err != nil {
+		return 0, err
+	}
+
+	if exists {
+		user, err := s.userRepo.FindByEmail(ctx, external.Email)
+		if err != nil {
+			return 0, err
+		}
+		if !external.EmailVerified || !user.EmailVerified {
+			return 0, ErrExternalAccountConflict
+		}
+		return user.ID, nil
+	}
+
+	username := external.Username
+	if username == "" {
+		username, _, _ = strings.Cut(external.Email, "@")
+	}
+	user, err := s.userService.RegisterExternalUser(ctx, command.NewRegisterExternalUserCommand(username, external.Email, external.EmailVerified, external.Name, external.Picture))
+	if err != nil {
+		// 并发注册了同一邮箱
+		if errors.Is(err, domainservice.ErrEmailAlreadyExists) {
+			return 0, ErrExternalAccountConflict
+		}
+		return 0, err
+	}
+	return user.ID, nil
+}
+
+func (s *ExternalLoginApplicationService) findUser(ctx context.Context, userID uint64) (*entity.User, error) {
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, errors.Wrap(err, "user not found")
+	}
+	return user, nil
+}
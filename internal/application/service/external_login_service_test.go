@@ -0,0 +1,208 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+	"yiwen/go-ddd/internal/application/command"
+	"yiwen/go-ddd/internal/application/identity"
+	"yiwen/go-ddd/internal/domain/entity"
+	"yiwen/go-ddd/internal/domain/repository"
+	"yiwen/go-ddd/internal/domain/valueobject"
+	"yiwen/go-ddd/pkg/errors"
+
+	"github.com/google/uuid"
+
+	domainservice "yiwen/go-ddd/internal/domain/service"
+)
+
+// memoryExternalIdentityRepository 测试用的内存外部身份仓库
+type memoryExternalIdentityRepository struct {
+	mu         sync.Mutex
+	identities []*entity.ExternalIdentity
+	states     map[string]*entity.ExternalLoginState
+}
+
+func (r *memoryExternalIdentityRepository) SaveIdentity(ctx context.Context, identity *entity.ExternalIdentity) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.identities = append(r.identities, identity)
+	return nil
+}
+
+func (r *memoryExternalIdentityRepository) FindIdentity(ctx context.Context, provider, subject string) (*entity.ExternalIdentity, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, i := range r.identities {
+		if i.Provider == provider && i.Subject == subject {
+			return i, nil
+		}
+	}
+	return nil, repository.ErrExternalIdentityNotFound
+}
+
+func (r *memoryExternalIdentityRepository) ListIdentitiesByUser(ctx context.Context, userID uint64) ([]*entity.ExternalIdentity, error) {
+	return nil, nil
+}
+
+func (r *memoryExternalIdentityRepository) SaveLoginState(ctx context.Context, state *entity.ExternalLoginState) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.states[state.StateHash] = state
+	return nil
+}
+
+func (r *memoryExternalIdentityRepository) TakeLoginState(ctx context.Context, stateHash string) (*entity.ExternalLoginState, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	state, ok := r.states[stateHash]
+	if !ok {
+		return nil, repository.ErrExternalLoginStateNotFound
+	}
+	delete(r.states, stateHash)
+	return state, nil
+}
+
+// fakeIdentityProvider 记录登录地址中的参数，换取身份时像真实提供方一样校验 PKCE 和 nonce
+type fakeIdentityProvider struct {
+	name          string
+	nonce         string
+	codeChallenge string
+}
+
+func (p *fakeIdentityProvider) Name() string        { return p.name }
+func (p *fakeIdentityProvider) DisplayName() string { return p.name }
+
+func (p *fakeIdentityProvider) AuthCodeURL(ctx context.Context, state, nonce, codeChallenge string) (string, error) {
+	p.nonce, p.codeChallenge = nonce, codeChallenge
+	return "https://idp.example.com/authorize?" + url.Values{"state": {state}}.Encode(), nil
+}
+
+func (p *fakeIdentityProvider) Exchange(ctx context.Context, code, codeVerifier, nonce string) (*identity.Identity, error) {
+	sum := sha256.Sum256([]byte(codeVerifier))
+	if code != "code-1" || base64.RawURLEncoding.EncodeToString(sum[:]) != p.codeChallenge || nonce != p.nonce {
+		return nil, identity.ErrAuthenticationFailed
+	}
+	return &identity.Identity{Subject: "subject-1", Email: "alice@example.com", EmailVerified: true}, nil
+}
+
+type externalLoginFixture struct {
+	service      *ExternalLoginApplicationService
+	identityRepo *memoryExternalIdentityRepository
+	user         *entity.User
+}
+
+func newExternalLoginFixture(t *testing.T) *externalLoginFixture {
+	t.Helper()
+	email, err := valueobject.NewEmail("alice@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	user := entity.NewUser(uuid.NewString(), "alice", email, valueobject.Password{})
+	user.ID = 1
+
+	// 外部身份已经关联到用户，登录时不需要创建用户
+	identityRepo := &memoryExternalIdentityRepository{states: make(map[string]*entity.ExternalLoginState)}
+	identityRepo.identities = append(identityRepo.identities, entity.NewExternalIdentity(user.ID, "primary", "subject-1", "alice@example.com"))
+	userRepo := &memoryUserRepository{users: map[uint64]*entity.User{user.ID: user}}
+
+	providers := []identity.Provider{&fakeIdentityProvider{name: "primary"}, &fakeIdentityProvider{name: "secondary"}}
+	service := NewExternalLoginApplicationService(identityRepo, userRepo, nil, providers, time.Minute)
+	return &externalLoginFixture{service: service, identityRepo: identityRepo, user: user}
+}
+
+// start 发起登录，返回登录地址中的 state
+func (f *externalLoginFixture) start(t *testing.T, provider string) string {
+	t.Helper()
+	result, err := f.service.StartLogin(context.Background(), command.NewStartExternalLoginCommand(provider))
+	if err != nil {
+		t.Fatal(err)
+	}
+	u, err := url.Parse(result.AuthorizationURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return u.Query().Get("state")
+}
+
+func TestExternalLoginStateIsSingleUse(t *testing.T) {
+	f := newExternalLoginFixture(t)
+	state := f.start(t, "primary")
+
+	user, err := f.service.CompleteLogin(context.Background(), command.NewCompleteExternalLoginCommand("primary", "code-1", state))
+	if err != nil {
+		t.Fatalf("CompleteLogin: %v", err)
+	}
+	if user.ID != f.user.ID {
+		t.Errorf("logged in as user %d, want %d", user.ID, f.user.ID)
+	}
+
+	if _, err := f.service.CompleteLogin(context.Background(), command.NewCompleteExternalLoginCommand("primary", "code-1", state)); !errors.Is(err, ErrInvalidLoginState) {
+		t.Fatalf("replayed callback: err = %v, want ErrInvalidLoginState", err)
+	}
+}
+
+func TestExternalLoginRejectsInvalidState(t *testing.T) {
+	tests := []struct {
+		name    string
+		prepare func(f *externalLoginFixture, state string) *command.CompleteExternalLoginCommand
+	}{
+		{
+			name: "unknown state",
+			prepare: func(f *externalLoginFixture, state string) *command.CompleteExternalLoginCommand {
+				return command.NewCompleteExternalLoginCommand("primary", "code-1", state+"x")
+			},
+		},
+		{
+			name: "expired state",
+			prepare: func(f *externalLoginFixture, state string) *command.CompleteExternalLoginCommand {
+				for _, s := range f.identityRepo.states {
+					s.ExpiresAt = time.Now().Add(-time.Second)
+				}
+				return command.NewCompleteExternalLoginCommand("primary", "code-1", state)
+			},
+		},
+		{
+			name: "state issued for another provider",
+			prepare: func(f *externalLoginFixture, state string) *command.CompleteExternalLoginCommand {
+				return command.NewCompleteExternalLoginCommand("secondary", "code-1", state)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newExternalLoginFixture(t)
+			cmd := tt.prepare(f, f.start(t, "primary"))
+			if _, err := f.service.CompleteLogin(context.Background(), cmd); !errors.Is(err, ErrInvalidLoginState) {
+				t.Fatalf("err = %v, want ErrInvalidLoginState", err)
+			}
+		})
+	}
+}
+
+func TestExternalLoginStateIsConsumedOnFailure(t *testing.T) {
+	f := newExternalLoginFixture(t)
+	state := f.start(t, "primary")
+
+	if _, err := f.service.CompleteLogin(context.Background(), command.NewCompleteExternalLoginCommand("primary", "wrong-code", state)); !errors.Is(err, identity.ErrAuthenticationFailed) {
+		t.Fatalf("err = %v, want ErrAuthenticationFailed", err)
+	}
+	if _, err := f.service.CompleteLogin(context.Background(), command.NewCompleteExternalLoginCommand("primary", "code-1", state)); !errors.Is(err, ErrInvalidLoginState) {
+		t.Fatalf("retry with the same state: err = %v, want ErrInvalidLoginState", err)
+	}
+}
+
+func TestExternalLoginRejectsUserRequiringPasswordReset(t *testing.T) {
+	f := newExternalLoginFixture(t)
+	f.user.PasswordResetRequired = true
+	state := f.start(t, "primary")
+
+	if _, err := f.service.CompleteLogin(context.Background(), command.NewCompleteExternalLoginCommand("primary", "code-1", state)); !errors.Is(err, domainservice.ErrPasswordResetRequired) {
+		t.Fatalf("err = %v, want ErrPasswordResetRequired", err)
+	}
+}
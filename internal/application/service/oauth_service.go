@@ -0,0 +1,444 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+	"yiwen/go-ddd/internal/application/command"
+	"yiwen/go-ddd/internal/application/dto"
+	"yiwen/go-ddd/internal/domain/entity"
+	"yiwen/go-ddd/internal/domain/repository"
+	domainservice "yiwen/go-ddd/internal/domain/service"
+	"yiwen/go-ddd/pkg/errors"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrInvalidOAuthClient = errors.New("invalid oauth client")
+	ErrInvalidRedirectURI = errors.New("invalid redirect uri")
+	// ErrOAuthUserRequired 授权码代表用户，客户端凭证令牌等不属于任何用户的主体不能发起授权
+	ErrOAuthUserRequired = errors.New("authorization requires a user")
+)
+
+// OAuth2 错误码，见 RFC 6749 4.1.2.1 和 5.2
+const (
+	OAuthErrInvalidRequest          = "invalid_request"
+	OAuthErrInvalidClient           = "invalid_client"
+	OAuthErrInvalidGrant            = "invalid_grant"
+	OAuthErrUnauthorizedClient      = "unauthorized_client"
+	OAuthErrUnsupportedGrantType    = "unsupported_grant_type"
+	OAuthErrUnsupportedResponseType = "unsupported_response_type"
+	OAuthErrInvalidScope            = "invalid_scope"
+	OAuthErrAccessDenied            = "access_denied"
+)
+
+// OAuthError OAuth2 协议错误，令牌端点按 RFC 6749 的格式返回
+type OAuthError struct {
+	Code        string
+	Description string
+}
+
+func (e *OAuthError) Error() string {
+	return e.Code + ": " + e.Description
+}
+
+func newOAuthError(code, description string) *OAuthError {
+	return &OAuthError{Code: code, Description: description}
+}
+
+// OAuthApplicationService OAuth2 授权服务
+// 1. 授权码模式：已登录用户在授权端点同意后获得一次性授权码，客户端再用授权码换取令牌
+// 2. 公共客户端必须使用 PKCE（仅支持 S256），机密客户端换取令牌时需要客户端密钥
+// 3. 客户端凭证模式：机密客户端以自身身份获取访问令牌
+// 4. 访问令牌与自身登录签发的令牌格式相同，现有的 JWT 中间件可以直接验证
+// 5. 申请了 openid 权限范围时同时签发 OIDC ID Token，未配置非对称签名密钥时 idTokenIssuer 为空，不接受 openid 权限范围
+type OAuthApplicationService struct {
+	oauthRepo     repository.OAuthRepository
+	userRepo      repository.UserRepository
+	authService   *AuthApplicationService
+	idTokenIssuer IDTokenIssuer
+	codeExpire    time.Duration
+}
+
+// NewOAuthApplicationService 创建 OAuth2 授权服务
+func NewOAuthApplicationService(oauthRepo repository.OAuthRepository, userRepo repository.UserRepository, authService *AuthApplicationService, idTokenIssuer IDTokenIssuer, codeExpire time.Duration) *OAuthApplicationService {
+	return &OAuthApplicationService{
+		oauthRepo:     oauthRepo,
+		userRepo:      userRepo,
+		authService:   authService,
+		idTokenIssuer: idTokenIssuer,
+		codeExpire:    codeExpire,
+	}
+}
+
+// CreateClient 注册客户端，机密客户端的密钥只在返回结果中出现一次
+func (s *OAuthApplicationService) CreateClient(ctx context.Context, cmd *command.CreateOAuthClientCommand) (*dto.OAuthClientDTO, error) {
+	if slices.Contains(cmd.GrantTypes, entity.GrantTypeAuthorizationCode) && len(cmd.RedirectURIs) == 0 {
+		return nil, errors.Wrap(ErrInvalidOAuthClient, "authorization_code grant requires redirect uris")
+	}
+	if slices.Contains(cmd.GrantTypes, entity.GrantTypeClientCredentials) && !cmd.Confidential {
+		return nil, errors.Wrap(ErrInvalidOAuthClient, "client_credentials grant requires a confidential client")
+	}
+	for _, uri := range cmd.RedirectURIs {
+		if u, err := url.Parse(uri); err != nil || !u.IsAbs() || u.Fragment != "" {
+			return nil, errors.Wrap(ErrInvalidOAuthClient, "redirect uri must be absolute without fragment")
+		}
+	}
+
+	var secret, secretHash string
+	if cmd.Confidential {
+		var err error
+		if secret, err = generateRefreshToken(); err != nil {
+			return nil, errors.Wrap(err, "failed to generate client secret")
+		}
+		secretHash = hashRefreshToken(secret)
+	}
+
+	client := entity.NewOAuthClient(uuid.New().String(), secretHash, cmd.Name, cmd.RedirectURIs, cmd.Scopes, cmd.GrantTypes)
+	if err := s.oauthRepo.SaveClient(ctx, client); err != nil {
+		return nil, errors.Wrap(err, "failed to save oauth client")
+	}
+
+	result := dto.ToOAuthClientDTO(client)
+	result.ClientSecret = secret
+	return &result, nil
+}
+
+func (s *OAuthApplicationService) ListClients(ctx context.Context) ([]dto.OAuthClientDTO, error) {
+	clients, err := s.oauthRepo.ListClients(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list oauth clients")
+	}
+	return dto.ToOAuthClientDTOList(clients), nil
+}
+
+func (s *OAuthApplicationService) DeleteClient(ctx context.Context, cmd *command.DeleteOAuthClientCommand) error {
+	return s.oauthRepo.DeleteClient(ctx, cmd.ClientID)
+}
+
+// Authorize 处理授权请求
+// 用户已经同意过全部权限范围时直接签发授权码，否则返回需要用户确认的客户端和权限范围
+func (s *OAuthApplicationService) Authorize(ctx context.Context, cmd *command.AuthorizeCommand) (*dto.AuthorizeDTO, error) {
+	if cmd.UserID == 0 {
+		return nil, ErrOAuthUserRequired
+	}
+
+	client, redirectURI, err := s.resolveClient(ctx, cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	scopes, oauthErr := s.validateAuthorizeRequest(client, cmd)
+	if oauthErr != nil {
+		return &dto.AuthorizeDTO{RedirectTo: errorRedirect(redirectURI, cmd.State, oauthErr)}, nil
+	}
+
+	consent, err := s.oauthRepo.FindConsent(ctx, cmd.UserID, client.ClientID)
+	if err != nil && !errors.Is(err, repository.ErrOAuthConsentNotFound) {
+		return nil, err
+	}
+	if consent == nil || !consent.Covers(scopes) {
+		clientDTO := dto.ToOAuthClientDTO(client)
+		return &dto.AuthorizeDTO{ConsentRequired: true, Client: &clientDTO, Scopes: scopes}, nil
+	}
+
+	return s.issueCode(ctx, client, redirectURI, scopes, cmd)
+}
+
+// Consent 处理用户的同意或拒绝，同意时记录授权并签发授权码
+func (s *OAuthApplicationService) Consent(ctx context.Context, cmd *command.ConsentCommand) (*dto.AuthorizeDTO, error) {
+	if cmd.UserID == 0 {
+		return nil, ErrOAuthUserRequired
+	}
+
+	client, redirectURI, err := s.resolveClient(ctx, &cmd.AuthorizeCommand)
+	if err != nil {
+		return nil, err
+	}
+
+	scopes, oauthErr := s.validateAuthorizeRequest(client, &cmd.AuthorizeCommand)
+	if oauthErr != nil {
+		return &dto.AuthorizeDTO{RedirectTo: errorRedirect(redirectURI, cmd.State, oauthErr)}, nil
+	}
+	if !cmd.Approved {
+		return &dto.AuthorizeDTO{RedirectTo: errorRedirect(redirectURI, cmd.State, newOAuthError(OAuthErrAccessDenied, "user denied the request"))}, nil
+	}
+
+	consent, err := s.oauthRepo.FindConsent(ctx, cmd.UserID, client.ClientID)
+	if err != nil {
+		if !errors.Is(err, repository.ErrOAuthConsentNotFound) {
+			return nil, err
+		}
+		consent = entity.NewOAuthConsent(cmd.UserID, client.ClientID)
+	}
+	consent.Grant(scopes)
+	if err := s.oauthRepo.SaveConsent(ctx, consent); err != nil {
+		return nil, errors.Wrap(err, "failed to save oauth consent")
+	}
+
+	return s.issueCode(ctx, client, redirectURI, scopes, &cmd.AuthorizeCommand)
+}
+
+// Token 令牌端点，按授权类型签发令牌
+func (s *OAuthApplicationService) Token(ctx context.Context, cmd *command.OAuthTokenCommand) (*dto.OAuthTokenDTO, error) {
+	client, err := s.authenticateClient(ctx, cmd.ClientID, cmd.ClientSecret)
+	if err != nil {
+		return nil, err
+	}
+	if !client.AllowsGrantType(cmd.GrantType) {
+		switch cmd.GrantType {
+		case entity.GrantTypeAuthorizationCode, entity.GrantTypeRefreshToken, entity.GrantTypeClientCredentials:
+			return nil, newOAuthError(OAuthErrUnauthorizedClient, "grant type not allowed for this client")
+		default:
+			return nil, newOAuthError(OAuthErrUnsupportedGrantType, "unsupported grant type")
+		}
+	}
+
+	switch cmd.GrantType {
+	case entity.GrantTypeAuthorizationCode:
+		return s.exchangeCode(ctx, client, cmd)
+	case entity.GrantTypeRefreshToken:
+		return s.refresh(ctx, client, cmd)
+	default:
+		return s.clientCredentials(client, cmd)
+	}
+}
+
+// resolveClient 校验客户端和回调地址
+// 两者不合法时不能重定向回客户端，直接返回错误
+func (s *OAuthApplicationService) resolveClient(ctx context.Context, cmd *command.AuthorizeCommand) (*entity.OAuthClient, string, error) {
+	client, err := s.oauthRepo.FindClientByClientID(ctx, cmd.ClientID)
+	if err != nil {
+		if errors.Is(err, repository.ErrOAuthClientNotFound) {
+			return nil, "", ErrInvalidOAuthClient
+		}
+		return nil, "", err
+	}
+
+	redirectURI := cmd.RedirectURI
+	if redirectURI == "" && len(client.RedirectURIs) == 1 {
+		redirectURI = client.RedirectURIs[0]
+	}
+	if !client.AllowsRedirectURI(redirectURI) {
+		return nil, "", ErrInvalidRedirectURI
+	}
+	return client, redirectURI, nil
+}
+
+// validateAuthorizeRequest 校验授权请求的其余参数，返回最终的权限范围
+// 未申请权限范围时使用客户端允许的全部范围
+func (s *OAuthApplicationService) validateAuthorizeRequest(client *entity.OAuthClient, cmd *command.AuthorizeCommand) ([]string, *OAuthError) {
+	if cmd.ResponseType != "code" {
+		return nil, newOAuthError(OAuthErrUnsupportedResponseType, "only response_type=code is supported")
+	}
+	if !client.AllowsGrantType(entity.GrantTypeAuthorizationCode) {
+		return nil, newOAuthError(OAuthErrUnauthorizedClient, "authorization_code grant not allowed for this client")
+	}
+
+	scopes := strings.Fields(cmd.Scope)
+	if len(scopes) == 0 {
+		scopes = client.Scopes
+	}
+	if !client.AllowsScopes(scopes) {
+		return nil, newOAuthError(OAuthErrInvalidScope, "requested scope not allowed for this client")
+	}
+	if slices.Contains(scopes, ScopeOpenID) && !s.OIDCEnabled() {
+		return nil, newOAuthError(OAuthErrInvalidScope, ErrOIDCDisabled.Error())
+	}
+
+	if cmd.CodeChallenge == "" {
+		if !client.IsConfidential() {
+			return nil, newOAuthError(OAuthErrInvalidRequest, "code_challenge is required for public clients")
+		}
+	} else if cmd.CodeChallengeMethod != "S256" {
+		return nil, newOAuthError(OAuthErrInvalidRequest, "code_challenge_method must be S256")
+	}
+
+	return scopes, nil
+}
+
+func (s *OAuthApplicationService) issueCode(ctx context.Context, client *entity.OAuthClient, redirectURI string, scopes []string, cmd *command.AuthorizeCommand) (*dto.AuthorizeDTO, error) {
+	code, err := generateRefreshToken()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to generate authorization code")
+	}
+
+	// 保存请求中原始的 redirect_uri，换取令牌时只有授权请求带了该参数才要求一致
+	authCode := entity.NewAuthorizationCode(hashRefreshToken(code), client.ClientID, cmd.UserID, cmd.RedirectURI, scopes, cmd.CodeChallenge, cmd.Nonce, time.Now().Add(s.codeExpire))
+	if err := s.oauthRepo.SaveAuthorizationCode(ctx, authCode); err != nil {
+		return nil, errors.Wrap(err, "failed to save authorization code")
+	}
+
+	params := url.Values{"code": {code}}
+	if cmd.State != "" {
+		params.Set("state", cmd.State)
+	}
+	return &dto.AuthorizeDTO{RedirectTo: appendQuery(redirectURI, params)}, nil
+}
+
+// authenticateClient 认证客户端，机密客户端必须提供正确的密钥
+func (s *OAuthApplicationService) authenticateClient(ctx context.Context, clientID, clientSecret string) (*entity.OAuthClient, error) {
+	if clientID == "" {
+		return nil, newOAuthError(OAuthErrInvalidClient, "client authentication failed")
+	}
+
+	client, err := s.oauthRepo.FindClientByClientID(ctx, clientID)
+	if err != nil {
+		if errors.Is(err, repository.ErrOAuthClientNotFound) {
+			return nil, newOAuthError(OAuthErrInvalidClient, "client authentication failed")
+		}
+		return nil, err
+	}
+
+	if client.IsConfidential() && subtle.ConstantTimeCompare([]byte(hashRefreshToken(clientSecret)), []byte(client.SecretHash)) != 1 {
+		return nil, newOAuthError(OAuthErrInvalidClient, "client authentication failed")
+	}
+	return client, nil
+}
+
+func (s *OAuthApplicationService) exchangeCode(ctx context.Context, client *entity.OAuthClient, cmd *command.OAuthTokenCommand) (*dto.OAuthTokenDTO, error) {
+	code, err := s.oauthRepo.FindAuthorizationCodeByHash(ctx, hashRefreshToken(cmd.Code))
+	if err != nil {
+		if errors.Is(err, repository.ErrAuthorizationCodeNotFound) {
+			return nil, newOAuthError(OAuthErrInvalidGrant, "invalid authorization code")
+		}
+		return nil, err
+	}
+
+	if code.ClientID != client.ClientID {
+		return nil, newOAuthError(OAuthErrInvalidGrant, "invalid authorization code")
+	}
+	if code.IsUsed() {
+		return nil, s.revokeReplayedCode(ctx, code)
+	}
+	if code.IsExpired() {
+		return nil, newOAuthError(OAuthErrInvalidGrant, "invalid authorization code")
+	}
+	if cmd.RedirectURI != code.RedirectURI {
+		return nil, newOAuthError(OAuthErrInvalidGrant, "redirect_uri does not match")
+	}
+	if code.CodeChallenge != "" && !verifyCodeChallenge(cmd.CodeVerifier, code.CodeChallenge) {
+		return nil, newOAuthError(OAuthErrInvalidGrant, "invalid code_verifier")
+	}
+
+	// 并发使用同一个授权码时只有一个请求能标记成功，其余的按重复使用处理
+	if err := s.oauthRepo.MarkAuthorizationCodeUsed(ctx, code.ID); err != nil {
+		if errors.Is(err, repository.ErrAuthorizationCodeUsed) {
+			return nil, s.revokeReplayedCode(ctx, code)
+		}
+		return nil, err
+	}
+
+	familyID := ""
+	if client.AllowsGrantType(entity.GrantTypeRefreshToken) {
+		familyID = codeFamilyID(code)
+	}
+	scope := strings.Join(code.Scopes, " ")
+	tokens, err := s.authService.IssueClientTokens(ctx, code.UserID, client.ClientID, scope, familyID)
+	if err != nil {
+		if errors.Is(err, domainservice.ErrUserNotActive) {
+			return nil, newOAuthError(OAuthErrInvalidGrant, err.Error())
+		}
+		return nil, err
+	}
+
+	result := toOAuthTokenDTO(tokens, scope)
+	if slices.Contains(code.Scopes, ScopeOpenID) {
+		if result.IDToken, err = s.generateIDToken(ctx, code); err != nil {
+			return nil, errors.Wrap(err, "failed to generate id token")
+		}
+	}
+	return result, nil
+}
+
+// revokeReplayedCode 授权码被重复使用说明可能已经泄露，按 RFC 6749 4.1.2 撤销通过它签发的刷新令牌家族
+// 访问令牌不属于会话，在过期前仍然有效
+func (s *OAuthApplicationService) revokeReplayedCode(ctx context.Context, code *entity.AuthorizationCode) error {
+	if err := s.authService.RevokeTokenFamily(ctx, codeFamilyID(code)); err != nil {
+		return err
+	}
+	return newOAuthError(OAuthErrInvalidGrant, "invalid authorization code")
+}
+
+// codeFamilyID 由授权码派生刷新令牌家族，授权码被重复使用时不需要额外记录就能找到它签发的令牌
+func codeFamilyID(code *entity.AuthorizationCode) string {
+	return uuid.NewSHA1(uuid.NameSpaceURL, []byte("oauth-code:"+code.CodeHash)).String()
+}
+
+func (s *OAuthApplicationService) refresh(ctx context.Context, client *entity.OAuthClient, cmd *command.OAuthTokenCommand) (*dto.OAuthTokenDTO, error) {
+	tokens, err := s.authService.Refresh(ctx, command.NewRefreshTokenCommand(cmd.RefreshToken, client.ClientID))
+	if err != nil {
+		if errors.Is(err, ErrInvalidRefreshToken) || errors.Is(err, ErrRefreshTokenReused) {
+			return nil, newOAuthError(OAuthErrInvalidGrant, err.Error())
+		}
+		return nil, err
+	}
+	return toOAuthTokenDTO(tokens, ""), nil
+}
+
+func (s *OAuthApplicationService) clientCredentials(client *entity.OAuthClient, cmd *command.OAuthTokenCommand) (*dto.OAuthTokenDTO, error) {
+	scopes := strings.Fields(cmd.Scope)
+	if len(scopes) == 0 {
+		scopes = client.Scopes
+	}
+	if !client.AllowsScopes(scopes) {
+		return nil, newOAuthError(OAuthErrInvalidScope, "requested scope not allowed for this client")
+	}
+
+	scope := strings.Join(scopes, " ")
+	tokens, err := s.authService.IssueClientCredentialsToken(client.ClientID, scope)
+	if err != nil {
+		return nil, err
+	}
+	return toOAuthTokenDTO(tokens, scope), nil
+}
+
+// verifyCodeChallenge 校验 PKCE：BASE64URL(SHA256(code_verifier)) == code_challenge
+func verifyCodeChallenge(verifier, challenge string) bool {
+	if verifier == "" {
+		return false
+	}
+	sum := sha256.Sum256([]byte(verifier))
+	return subtle.ConstantTimeCompare([]byte(base64.RawURLEncoding.EncodeToString(sum[:])), []byte(challenge)) == 1
+}
+
+func errorRedirect(redirectURI, state string, oauthErr *OAuthError) string {
+	params := url.Values{
+		"error":             {oauthErr.Code},
+		"error_description": {oauthErr.Description},
+	}
+	if state != "" {
+		params.Set("state", state)
+	}
+	return appendQuery(redirectURI, params)
+}
+
+// appendQuery 在回调地址原有的查询参数之后追加参数
+func appendQuery(redirectURI string, params url.Values) string {
+	u, err := url.Parse(redirectURI)
+	if err != nil {
+		return redirectURI
+	}
+	query := u.Query()
+	for key, values := range params {
+		query[key] = values
+	}
+	u.RawQuery = query.Encode()
+	return u.String()
+}
+
+func toOAuthTokenDTO(tokens *dto.TokenDTO, scope string) *dto.OAuthTokenDTO {
+	return &dto.OAuthTokenDTO{
+		AccessToken:  tokens.Token,
+		TokenType:    "Bearer",
+		ExpiresIn:    tokens.ExpiresAt - time.Now().Unix(),
+		RefreshToken: tokens.RefreshToken,
+		Scope:        scope,
+	}
+}
@@ -0,0 +1,87 @@
+package service
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"yiwen/go-ddd/internal/application/query"
+	"yiwen/go-ddd/internal/domain/entity"
+	"yiwen/go-ddd/pkg/errors"
+)
+
+// OIDC 标准权限范围
+const (
+	ScopeOpenID  = "openid"
+	ScopeProfile = "profile"
+	ScopeEmail   = "email"
+)
+
+var (
+	ErrInsufficientScope = errors.New("insufficient scope")
+	ErrOIDCDisabled      = errors.New("openid connect is disabled, an asymmetric jwt signing key is required")
+)
+
+// IDTokenIssuer OIDC ID Token 签发接口，由接口层的 JWTAuth 实现
+type IDTokenIssuer interface {
+	GenerateIDToken(subject, audience, nonce string, claims map[string]interface{}) (string, error)
+}
+
+// OIDCEnabled 是否提供 OpenID Connect
+// 只有配置了非对称签名密钥时才传入 IDTokenIssuer，HS256 签发的 ID Token 下游应用无法在不知道密钥的情况下验证
+func (s *OAuthApplicationService) OIDCEnabled() bool {
+	return s.idTokenIssuer != nil
+}
+
+// UserInfo 返回访问令牌授权范围内的用户信息，访问令牌必须包含 openid 权限范围
+func (s *OAuthApplicationService) UserInfo(ctx context.Context, q *query.UserInfoQuery) (map[string]interface{}, error) {
+	scopes := strings.Fields(q.Scope)
+	if !slices.Contains(scopes, ScopeOpenID) {
+		return nil, ErrInsufficientScope
+	}
+
+	user, err := s.userRepo.FindByID(ctx, q.UserID)
+	if err != nil {
+		return nil, errors.Wrap(err, "user not found")
+	}
+
+	claims := userClaims(user, scopes)
+	claims["sub"] = user.UUID
+	return claims, nil
+}
+
+// generateIDToken 为授权码模式签发 ID Token，sub 使用用户 UUID，aud 为客户端标识
+func (s *OAuthApplicationService) generateIDToken(ctx context.Context, code *entity.AuthorizationCode) (string, error) {
+	if !s.OIDCEnabled() {
+		return "", ErrOIDCDisabled
+	}
+	user, err := s.userRepo.FindByID(ctx, code.UserID)
+	if err != nil {
+		return "", errors.Wrap(err, "user not found")
+	}
+	return s.idTokenIssuer.GenerateIDToken(user.UUID, code.ClientID, code.Nonce, userClaims(user, code.Scopes))
+}
+
+// userClaims 按权限范围返回 OIDC 标准声明，见 OpenID Connect Core 5.4
+func userClaims(user *entity.User, scopes []string) map[string]interface{} {
+	claims := make(map[string]interface{})
+
+	if slices.Contains(scopes, ScopeProfile) {
+		claims["preferred_username"] = user.Username
+		if user.Nickname != "" {
+			claims["name"] = user.Nickname
+		}
+		if user.Avatar != "" {
+			claims["picture"] = user.Avatar
+		}
+		if !user.UpdatedAt.IsZero() {
+			claims["updated_at"] = user.UpdatedAt.Unix()
+		}
+	}
+
+	if slices.Contains(scopes, ScopeEmail) {
+		claims["email"] = user.Email.String()
+		claims["email_verified"] = user.EmailVerified
+	}
+
+	return claims
+}
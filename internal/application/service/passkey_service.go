@@ -0,0 +1,312 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"strings"
+	"time"
+	"yiwen/go-ddd/internal/application/command"
+	"yiwen/go-ddd/internal/application/dto"
+	"yiwen/go-ddd/internal/domain/entity"
+	"yiwen/go-ddd/internal/domain/repository"
+	domainservice "yiwen/go-ddd/internal/domain/service"
+	"yiwen/go-ddd/pkg/errors"
+	"yiwen/go-ddd/pkg/webauthn"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrInvalidPasskey = errors.New("invalid passkey")
+	// ErrInvalidWebAuthnChallenge 挑战值不存在、已使用、已过期或不属于当前仪式
+	ErrInvalidWebAuthnChallenge    = errors.New("invalid or expired webauthn challenge")
+	ErrPasskeyAuthenticationFailed = errors.New("passkey authentication failed")
+)
+
+// defaultPasskeyName 用户没有为通行密钥命名时使用的名称
+const defaultPasskeyName = "Passkey"
+
+// PasskeyApplicationService 通行密钥服务
+// 1. 已登录用户获取注册选项，浏览器调用认证器创建凭证后提交，校验通过后保存公钥
+// 2. 登录时不需要用户名，用户在认证器中选择通行密钥，服务端根据凭证ID找到用户并校验签名
+// 3. 通行密钥本身同时验证了持有设备和用户身份（生物识别或 PIN），登录时不再要求两步验证
+// 4. 每个挑战值只能使用一次，签名计数不递增时视为凭证被克隆，拒绝登录
+type PasskeyApplicationService struct {
+	passkeyRepo repository.PasskeyRepository
+	userRepo    repository.UserRepository
+	authService *AuthApplicationService
+	rp          *webauthn.RelyingParty
+	rpName      string
+	timeout     time.Duration
+}
+
+// NewPasskeyApplicationService 创建通行密钥服务
+func NewPasskeyApplicationService(passkeyRepo repository.PasskeyRepository, userRepo repository.UserRepository, authService *AuthApplicationService, rpID, rpName string, origins []string, timeout time.Duration) *PasskeyApplicationService {
+	return &PasskeyApplicationService{
+		passkeyRepo: passkeyRepo,
+		userRepo:    userRepo,
+		authService: authService,
+		rp:          &webauthn.RelyingParty{ID: rpID, Origins: origins},
+		rpName:      rpName,
+		timeout:     timeout,
+	}
+}
+
+// BeginRegistration 生成注册选项，已注册的通行密钥放入 excludeCredentials，避免同一个认证器重复注册
+func (s *PasskeyApplicationService) BeginRegistration(ctx context.Context, cmd *command.BeginPasskeyRegistrationCommand) (*dto.PasskeyCreationOptionsDTO, error) {
+	user, err := s.userRepo.FindByID(ctx, cmd.UserID)
+	if err != nil {
+		return nil, errors.Wrap(err, "user not found")
+	}
+	userHandle, err := passkeyUserHandle(user)
+	if err != nil {
+		return nil, err
+	}
+
+	passkeys, err := s.passkeyRepo.ListByUser(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	exclude := make([]dto.PasskeyCredentialDescriptor, len(passkeys))
+	for i, passkey := range passkeys {
+		exclude[i] = dto.PasskeyCredentialDescriptor{Type: "public-key", ID: base64.RawURLEncoding.EncodeToString(passkey.CredentialID)}
+	}
+
+	challenge, err := s.beginCeremony(ctx, user.ID, entity.WebAuthnCeremonyRegistration)
+	if err != nil {
+		return nil, err
+	}
+
+	params := make([]dto.PasskeyCredentialParameter, len(webauthn.SupportedAlgorithms))
+	for i, alg := range webauthn.SupportedAlgorithms {
+		params[i] = dto.PasskeyCredentialParameter{Type: "public-key", Alg: alg}
+	}
+
+	displayName := user.Nickname
+	if displayName == "" {
+		displayName = user.Username
+	}
+
+	return &dto.PasskeyCreationOptionsDTO{
+		RP: dto.PasskeyRelyingParty{ID: s.rp.ID, Name: s.rpName},
+		User: dto.PasskeyUser{
+			ID:          base64.RawURLEncoding.EncodeToString(userHandle),
+			Name:        user.Email.String(),
+			DisplayName: displayName,
+		},
+		Challenge:          challenge,
+		PubKeyCredParams:   params,
+		Timeout:            s.timeout.Milliseconds(),
+		ExcludeCredentials: exclude,
+		AuthenticatorSelection: dto.PasskeyAuthenticatorSelection{
+			ResidentKey:        "required",
+			RequireResidentKey: true,
+			UserVerification:   "required",
+		},
+		Attestation: "none",
+	}, nil
+}
+
+// FinishRegistration 校验认证器创建的凭证并保存
+func (s *PasskeyApplicationService) FinishRegistration(ctx context.Context, cmd *command.FinishPasskeyRegistrationCommand) (*dto.PasskeyDTO, error) {
+	clientDataJSON, err1 := decodeBase64URL(cmd.ClientDataJSON)
+	attestationObject, err2 := decodeBase64URL(cmd.AttestationObject)
+	if err1 != nil || err2 != nil {
+		return nil, errors.Wrap(ErrInvalidPasskey, "malformed credential")
+	}
+
+	challenge, err := s.takeCeremony(ctx, clientDataJSON, entity.WebAuthnCeremonyRegistration)
+	if err != nil {
+		return nil, err
+	}
+	if challenge.session.UserID != cmd.UserID {
+		return nil, ErrInvalidWebAuthnChallenge
+	}
+
+	registration, err := s.rp.VerifyRegistration(clientDataJSON, attestationObject, challenge.value, true)
+	if err != nil {
+		if errors.Is(err, webauthn.ErrVerificationFailed) {
+			return nil, errors.Errorf("%w: %v", ErrInvalidPasskey, err)
+		}
+		return nil, err
+	}
+
+	name := strings.TrimSpace(cmd.Name)
+	if name == "" {
+		name = defaultPasskeyName
+	}
+
+	passkey := entity.NewPasskey(cmd.UserID, registration.CredentialID, registration.PublicKey, registration.SignCount, name)
+	if err := s.passkeyRepo.Save(ctx, passkey); err != nil {
+		return nil, err
+	}
+	return toPasskeyDTO(passkey), nil
+}
+
+// BeginLogin 生成登录选项
+func (s *PasskeyApplicationService) BeginLogin(ctx context.Context) (*dto.PasskeyRequestOptionsDTO, error) {
+	challenge, err := s.beginCeremony(ctx, 0, entity.WebAuthnCeremonyAuthentication)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.PasskeyRequestOptionsDTO{
+		Challenge:        challenge,
+		Timeout:          s.timeout.Milliseconds(),
+		RPID:             s.rp.ID,
+		AllowCredentials: []dto.PasskeyCredentialDescriptor{},
+		UserVerification: "required",
+	}, nil
+}
+
+// FinishLogin 校验认证器的签名，通过后签发访问令牌和刷新令牌
+func (s *PasskeyApplicationService) FinishLogin(ctx context.Context, cmd *command.FinishPasskeyLoginCommand) (*dto.LoginResponse, error) {
+	credentialID, err1 := decodeBase64URL(cmd.CredentialID)
+	clientDataJSON, err2 := decodeBase64URL(cmd.ClientDataJSON)
+	authenticatorData, err3 := decodeBase64URL(cmd.AuthenticatorData)
+	signature, err4 := decodeBase64URL(cmd.Signature)
+	userHandle, err5 := decodeBase64URL(cmd.UserHandle)
+	if err1 != nil || err2 != nil || err3 != nil || err4 != nil || err5 != nil {
+		return nil, ErrPasskeyAuthenticationFailed
+	}
+
+	challenge, err := s.takeCeremony(ctx, clientDataJSON, entity.WebAuthnCeremonyAuthentication)
+	if err != nil {
+		return nil, err
+	}
+
+	passkey, err := s.passkeyRepo.FindByCredentialID(ctx, credentialID)
+	if err != nil {
+		if errors.Is(err, repository.ErrPasskeyNotFound) {
+			return nil, ErrPasskeyAuthenticationFailed
+		}
+		return nil, err
+	}
+
+	user, err := s.userRepo.FindByID(ctx, passkey.UserID)
+	if err != nil {
+		return nil, errors.Wrap(err, "user not found")
+	}
+
+	// 可发现凭证必须返回注册时的 user.id，用于确认凭证属于该用户
+	expectedHandle, err := passkeyUserHandle(user)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(userHandle, expectedHandle) {
+		return nil, ErrPasskeyAuthenticationFailed
+	}
+
+	signCount, err := s.rp.VerifyAssertion(clientDataJSON, authenticatorData, signature, challenge.value, passkey.PublicKey, true)
+	if err != nil {
+		if errors.Is(err, webauthn.ErrVerificationFailed) {
+			return nil, ErrPasskeyAuthenticationFailed
+		}
+		return nil, err
+	}
+	if passkey.IsCloned(signCount) {
+		return nil, ErrPasskeyAuthenticationFailed
+	}
+	if err := s.passkeyRepo.UpdateSignCount(ctx, passkey.ID, passkey.SignCount, signCount); err != nil {
+		if errors.Is(err, repository.ErrPasskeySignCountStale) {
+			return nil, ErrPasskeyAuthenticationFailed
+		}
+		return nil, err
+	}
+
+	if !user.IsActive() {
+		return nil, domainservice.ErrUserNotActive
+	}
+	if user.PasswordResetRequired {
+		return nil, domainservice.ErrPasswordResetRequired
+	}
+
+	tokens, err := s.authService.IssueTokens(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	return &dto.LoginResponse{TokenDTO: *tokens, User: dto.ToUserDTO(user)}, nil
+}
+
+// List 查询用户的通行密钥
+func (s *PasskeyApplicationService) List(ctx context.Context, userID uint64) ([]*dto.PasskeyDTO, error) {
+	passkeys, err := s.passkeyRepo.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	dtos := make([]*dto.PasskeyDTO, len(passkeys))
+	for i, passkey := range passkeys {
+		dtos[i] = toPasskeyDTO(passkey)
+	}
+	return dtos, nil
+}
+
+// Revoke 删除通行密钥，之后该凭证无法再登录
+func (s *PasskeyApplicationService) Revoke(ctx context.Context, cmd *command.RevokePasskeyCommand) error {
+	return s.passkeyRepo.Delete(ctx, cmd.UserID, cmd.PasskeyID)
+}
+
+// beginCeremony 生成挑战值并保存仪式，返回给浏览器的挑战值为 base64url 编码
+func (s *PasskeyApplicationService) beginCeremony(ctx context.Context, userID uint64, ceremony string) (string, error) {
+	challenge, err := generateRefreshToken()
+	if err != nil {
+		return "", errors.Wrap(err, "failed to generate webauthn challenge")
+	}
+
+	session := entity.NewWebAuthnSession(hashRefreshToken(challenge), userID, ceremony, time.Now().Add(s.timeout))
+	if err := s.passkeyRepo.SaveSession(ctx, session); err != nil {
+		return "", errors.Wrap(err, "failed to save webauthn session")
+	}
+	return challenge, nil
+}
+
+// ceremonyChallenge 已取出的仪式和对应的挑战值
+type ceremonyChallenge struct {
+	session *entity.WebAuthnSession
+	value   string
+}
+
+// takeCeremony 根据客户端数据中的挑战值取出仪式，挑战值是否与签名内容一致由后续的校验保证
+func (s *PasskeyApplicationService) takeCeremony(ctx context.Context, clientDataJSON []byte, ceremony string) (*ceremonyChallenge, error) {
+	clientData, err := webauthn.ParseClientData(clientDataJSON)
+	if err != nil || clientData.Challenge == "" {
+		return nil, ErrInvalidWebAuthnChallenge
+	}
+
+	session, err := s.passkeyRepo.TakeSession(ctx, hashRefreshToken(clientData.Challenge))
+	if err != nil {
+		if errors.Is(err, repository.ErrWebAuthnSessionNotFound) {
+			return nil, ErrInvalidWebAuthnChallenge
+		}
+		return nil, err
+	}
+	if session.IsExpired() || session.Ceremony != ceremony {
+		return nil, ErrInvalidWebAuthnChallenge
+	}
+	return &ceremonyChallenge{session: session, value: clientData.Challenge}, nil
+}
+
+// passkeyUserHandle 通行密钥中保存的用户标识，使用不含个人信息的 UUID
+func passkeyUserHandle(user *entity.User) ([]byte, error) {
+	id, err := uuid.Parse(user.UUID)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid user uuid")
+	}
+	return id[:], nil
+}
+
+// decodeBase64URL 解码 WebAuthn JSON 中的二进制字段，兼容带填充的编码
+func decodeBase64URL(s string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(strings.TrimRight(s, "="))
+}
+
+func toPasskeyDTO(passkey *entity.Passkey) *dto.PasskeyDTO {
+	return &dto.PasskeyDTO{
+		ID:         passkey.ID,
+		Name:       passkey.Name,
+		CreatedAt:  passkey.CreatedAt,
+		LastUsedAt: passkey.LastUsedAt,
+	}
+}
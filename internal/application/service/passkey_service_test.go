@@ -0,0 +1,319 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"sync"
+	"testing"
+	"time"
+	"yiwen/go-ddd/internal/application/command"
+	"yiwen/go-ddd/internal/domain/entity"
+	"yiwen/go-ddd/internal/domain/repository"
+	"yiwen/go-ddd/internal/domain/valueobject"
+	"yiwen/go-ddd/pkg/errors"
+	"yiwen/go-ddd/pkg/webauthn/webauthntest"
+
+	"github.com/google/uuid"
+
+	domainservice "yiwen/go-ddd/internal/domain/service"
+)
+
+const (
+	testRPID   = "example.com"
+	testOrigin = "https://example.com"
+)
+
+// memoryPasskeyRepository 测试用的内存通行密钥仓库
+type memoryPasskeyRepository struct {
+	mu       sync.Mutex
+	passkeys []*entity.Passkey
+	sessions map[string]*entity.WebAuthnSession
+}
+
+func newMemoryPasskeyRepository() *memoryPasskeyRepository {
+	return &memoryPasskeyRepository{sessions: make(map[string]*entity.WebAuthnSession)}
+}
+
+func (r *memoryPasskeyRepository) Save(ctx context.Context, passkey *entity.Passkey) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, p := range r.passkeys {
+		if bytes.Equal(p.CredentialID, passkey.CredentialID) {
+			return repository.ErrPasskeyExists
+		}
+	}
+	passkey.ID = uint64(len(r.passkeys) + 1)
+	r.passkeys = append(r.passkeys, passkey)
+	return nil
+}
+
+func (r *memoryPasskeyRepository) FindByCredentialID(ctx context.Context, credentialID []byte) (*entity.Passkey, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, p := range r.passkeys {
+		if bytes.Equal(p.CredentialID, credentialID) {
+			clone := *p
+			return &clone, nil
+		}
+	}
+	return nil, repository.ErrPasskeyNotFound
+}
+
+func (r *memoryPasskeyRepository) ListByUser(ctx context.Context, userID uint64) ([]*entity.Passkey, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var passkeys []*entity.Passkey
+	for _, p := range r.passkeys {
+		if p.UserID == userID {
+			passkeys = append(passkeys, p)
+		}
+	}
+	return passkeys, nil
+}
+
+func (r *memoryPasskeyRepository) Delete(ctx context.Context, userID, id uint64) error {
+	return repository.ErrPasskeyNotFound
+}
+
+func (r *memoryPasskeyRepository) UpdateSignCount(ctx context.Context, id uint64, previous, signCount uint32) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, p := range r.passkeys {
+		if p.ID == id {
+			if p.SignCount != previous {
+				return repository.ErrPasskeySignCountStale
+			}
+			p.SignCount = signCount
+			return nil
+		}
+	}
+	return repository.ErrPasskeyNotFound
+}
+
+func (r *memoryPasskeyRepository) SaveSession(ctx context.Context, session *entity.WebAuthnSession) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.sessions[session.ChallengeHash] = session
+	return nil
+}
+
+func (r *memoryPasskeyRepository) TakeSession(ctx context.Context, challengeHash string) (*entity.WebAuthnSession, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	session, ok := r.sessions[challengeHash]
+	if !ok {
+		return nil, repository.ErrWebAuthnSessionNotFound
+	}
+	delete(r.sessions, challengeHash)
+	return session, nil
+}
+
+// memoryUserRepository 测试用的内存用户仓库，只实现按 ID 查询
+type memoryUserRepository struct {
+	repository.UserRepository
+	users map[uint64]*entity.User
+}
+
+func (r *memoryUserRepository) FindByID(ctx context.Context, id uint64) (*entity.User, error) {
+	user, ok := r.users[id]
+	if !ok {
+		return nil, repository.ErrUserNotFound
+	}
+	return user, nil
+}
+
+type passkeyFixture struct {
+	service       *PasskeyApplicationService
+	passkeyRepo   *memoryPasskeyRepository
+	user          *entity.User
+	authenticator *webauthntest.Authenticator
+}
+
+func newPasskeyFixture(t *testing.T) *passkeyFixture {
+	t.Helper()
+	email, err := valueobject.NewEmail("alice@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	user := entity.NewUser(uuid.NewString(), "alice", email, valueobject.NewPasswordFromHash("hash"))
+	user.ID = 1
+
+	passkeyRepo := newMemoryPasskeyRepository()
+	userRepo := &memoryUserRepository{users: map[uint64]*entity.User{user.ID: user}}
+
+	authenticator := webauthntest.NewES256(testRPID, testOrigin)
+	handle, err := passkeyUserHandle(user)
+	if err != nil {
+		t.Fatal(err)
+	}
+	authenticator.UserHandle = handle
+
+	// 失败用例在签发令牌之前返回，不需要认证服务
+	service := NewPasskeyApplicationService(passkeyRepo, userRepo, nil, testRPID, "Example", []string{testOrigin}, time.Minute)
+	return &passkeyFixture{service: service, passkeyRepo: passkeyRepo, user: user, authenticator: authenticator}
+}
+
+func (f *passkeyFixture) register(t *testing.T) *command.FinishPasskeyRegistrationCommand {
+	t.Helper()
+	options, err := f.service.BeginRegistration(context.Background(), command.NewBeginPasskeyRegistrationCommand(f.user.ID))
+	if err != nil {
+		t.Fatal(err)
+	}
+	clientDataJSON, attestationObject := f.authenticator.Create(options.Challenge, webauthntest.Options{})
+	cmd := command.NewFinishPasskeyRegistrationCommand(f.user.ID, "", encodeBase64URL(clientDataJSON), encodeBase64URL(attestationObject))
+	if _, err := f.service.FinishRegistration(context.Background(), cmd); err != nil {
+		t.Fatalf("FinishRegistration: %v", err)
+	}
+	return cmd
+}
+
+func (f *passkeyFixture) loginCommand(t *testing.T, challenge string) *command.FinishPasskeyLoginCommand {
+	t.Helper()
+	clientDataJSON, authData, signature := f.authenticator.Get(challenge, webauthntest.Options{})
+	return command.NewFinishPasskeyLoginCommand(
+		encodeBase64URL(f.authenticator.CredentialID),
+		encodeBase64URL(clientDataJSON),
+		encodeBase64URL(authData),
+		encodeBase64URL(signature),
+		encodeBase64URL(f.authenticator.UserHandle),
+	)
+}
+
+func (f *passkeyFixture) beginLogin(t *testing.T) string {
+	t.Helper()
+	options, err := f.service.BeginLogin(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return options.Challenge
+}
+
+func encodeBase64URL(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func TestPasskeyRegistrationChallengeIsSingleUse(t *testing.T) {
+	f := newPasskeyFixture(t)
+	cmd := f.register(t)
+
+	if _, err := f.service.FinishRegistration(context.Background(), cmd); !errors.Is(err, ErrInvalidWebAuthnChallenge) {
+		t.Fatalf("replayed registration: err = %v, want ErrInvalidWebAuthnChallenge", err)
+	}
+}
+
+func TestPasskeyRegistrationRejectsExpiredChallenge(t *testing.T) {
+	f := newPasskeyFixture(t)
+	options, err := f.service.BeginRegistration(context.Background(), command.NewBeginPasskeyRegistrationCommand(f.user.ID))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, session := range f.passkeyRepo.sessions {
+		session.ExpiresAt = time.Now().Add(-time.Second)
+	}
+
+	clientDataJSON, attestationObject := f.authenticator.Create(options.Challenge, webauthntest.Options{})
+	cmd := command.NewFinishPasskeyRegistrationCommand(f.user.ID, "", encodeBase64URL(clientDataJSON), encodeBase64URL(attestationObject))
+	if _, err := f.service.FinishRegistration(context.Background(), cmd); !errors.Is(err, ErrInvalidWebAuthnChallenge) {
+		t.Fatalf("err = %v, want ErrInvalidWebAuthnChallenge", err)
+	}
+}
+
+func TestPasskeyRegistrationRejectsOtherUsersChallenge(t *testing.T) {
+	f := newPasskeyFixture(t)
+	options, err := f.service.BeginRegistration(context.Background(), command.NewBeginPasskeyRegistrationCommand(f.user.ID))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	clientDataJSON, attestationObject := f.authenticator.Create(options.Challenge, webauthntest.Options{})
+	cmd := command.NewFinishPasskeyRegistrationCommand(f.user.ID+1, "", encodeBase64URL(clientDataJSON), encodeBase64URL(attestationObject))
+	if _, err := f.service.FinishRegistration(context.Background(), cmd); !errors.Is(err, ErrInvalidWebAuthnChallenge) {
+		t.Fatalf("err = %v, want ErrInvalidWebAuthnChallenge", err)
+	}
+}
+
+func TestPasskeyRegistrationRejectsWrongOrigin(t *testing.T) {
+	f := newPasskeyFixture(t)
+	options, err := f.service.BeginRegistration(context.Background(), command.NewBeginPasskeyRegistrationCommand(f.user.ID))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	clientDataJSON, attestationObject := f.authenticator.Create(options.Challenge, webauthntest.Options{Origin: "https://evil.example"})
+	cmd := command.NewFinishPasskeyRegistrationCommand(f.user.ID, "", encodeBase64URL(clientDataJSON), encodeBase64URL(attestationObject))
+	if _, err := f.service.FinishRegistration(context.Background(), cmd); !errors.Is(err, ErrInvalidPasskey) {
+		t.Fatalf("err = %v, want ErrInvalidPasskey", err)
+	}
+}
+
+func TestPasskeyLoginRejectsRegistrationChallenge(t *testing.T) {
+	f := newPasskeyFixture(t)
+	f.register(t)
+	options, err := f.service.BeginRegistration(context.Background(), command.NewBeginPasskeyRegistrationCommand(f.user.ID))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := f.service.FinishLogin(context.Background(), f.loginCommand(t, options.Challenge)); !errors.Is(err, ErrInvalidWebAuthnChallenge) {
+		t.Fatalf("err = %v, want ErrInvalidWebAuthnChallenge", err)
+	}
+}
+
+func TestPasskeyLoginRejectsExpiredChallenge(t *testing.T) {
+	f := newPasskeyFixture(t)
+	f.register(t)
+	challenge := f.beginLogin(t)
+	for _, session := range f.passkeyRepo.sessions {
+		session.ExpiresAt = time.Now().Add(-time.Second)
+	}
+
+	if _, err := f.service.FinishLogin(context.Background(), f.loginCommand(t, challenge)); !errors.Is(err, ErrInvalidWebAuthnChallenge) {
+		t.Fatalf("err = %v, want ErrInvalidWebAuthnChallenge", err)
+	}
+}
+
+func TestPasskeyLoginRejectsUnknownChallenge(t *testing.T) {
+	f := newPasskeyFixture(t)
+	f.register(t)
+
+	if _, err := f.service.FinishLogin(context.Background(), f.loginCommand(t, "bm90LWlzc3VlZA")); !errors.Is(err, ErrInvalidWebAuthnChallenge) {
+		t.Fatalf("err = %v, want ErrInvalidWebAuthnChallenge", err)
+	}
+}
+
+func TestPasskeyLoginRejectsStaleSignCount(t *testing.T) {
+	f := newPasskeyFixture(t)
+	f.register(t)
+	f.passkeyRepo.passkeys[0].SignCount = 10
+
+	// 克隆的认证器签名计数落后于服务端保存的计数
+	f.authenticator.SignCount = 9
+	if _, err := f.service.FinishLogin(context.Background(), f.loginCommand(t, f.beginLogin(t))); !errors.Is(err, ErrPasskeyAuthenticationFailed) {
+		t.Fatalf("err = %v, want ErrPasskeyAuthenticationFailed", err)
+	}
+	if got := f.passkeyRepo.passkeys[0].SignCount; got != 10 {
+		t.Errorf("sign count = %d, want unchanged 10", got)
+	}
+}
+
+func TestPasskeyLoginRejectsWrongUserHandle(t *testing.T) {
+	f := newPasskeyFixture(t)
+	f.register(t)
+	cmd := f.loginCommand(t, f.beginLogin(t))
+	cmd.UserHandle = encodeBase64URL(make([]byte, 16))
+
+	if _, err := f.service.FinishLogin(context.Background(), cmd); !errors.Is(err, ErrPasskeyAuthenticationFailed) {
+		t.Fatalf("err = %v, want ErrPasskeyAuthenticationFailed", err)
+	}
+}
+
+func TestPasskeyLoginRejectsUserRequiringPasswordReset(t *testing.T) {
+	f := newPasskeyFixture(t)
+	f.register(t)
+	f.user.PasswordResetRequired = true
+
+	if _, err := f.service.FinishLogin(context.Background(), f.loginCommand(t, f.beginLogin(t))); !errors.Is(err, domainservice.ErrPasswordResetRequired) {
+		t.Fatalf("err = %v, want ErrPasswordResetRequired", err)
+	}
+}
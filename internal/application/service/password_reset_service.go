@@ -0,0 +1,131 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+	"yiwen/go-ddd/internal/application/command"
+	"yiwen/go-ddd/internal/application/notification"
+	"yiwen/go-ddd/internal/domain/entity"
+	"yiwen/go-ddd/internal/domain/repository"
+	"yiwen/go-ddd/internal/domain/valueobject"
+	"yiwen/go-ddd/pkg/errors"
+
+	"github.com/google/uuid"
+)
+
+var ErrInvalidResetToken = errors.New("invalid or expired password reset token")
+
+// PasswordResetApplicationService 找回密码服务
+// 1. 找回密码时向邮箱发送重置令牌，令牌经过签名并带有过期时间，服务端只登记令牌ID
+// 2. 邮箱是否注册、是否发送过于频繁都返回相同的结果，不能用来探测账户是否存在
+// 3. 重置时取出删除令牌保证只能使用一次，新密码通过 valueobject.NewPassword 校验
+// 4. 重置后产生 UserPasswordChangedEvent，之前签发的访问令牌、刷新令牌和会话全部失效
+type PasswordResetApplicationService struct {
+	userRepo        repository.UserRepository
+	actionTokenRepo repository.ActionTokenRepository
+	userService     *UserApplicationService
+	authService     *AuthApplicationService
+	tokenIssuer     ActionTokenIssuer
+	mailer          notification.Mailer
+	resetPageURL    string
+	tokenExpire     time.Duration
+	resendInterval  time.Duration
+}
+
+// NewPasswordResetApplicationService 创建找回密码服务，resetPageURL 为空时邮件中只包含重置令牌
+func NewPasswordResetApplicationService(userRepo repository.UserRepository, actionTokenRepo repository.ActionTokenRepository, userService *UserApplicationService, authService *AuthApplicationService, tokenIssuer ActionTokenIssuer, mailer notification.Mailer, resetPageURL string, tokenExpire, resendInterval time.Duration) *PasswordResetApplicationService {
+	return &PasswordResetApplicationService{
+		userRepo:        userRepo,
+		actionTokenRepo: actionTokenRepo,
+		userService:     userService,
+		authService:     authService,
+		tokenIssuer:     tokenIssuer,
+		mailer:          mailer,
+		resetPageURL:    resetPageURL,
+		tokenExpire:     tokenExpire,
+		resendInterval:  resendInterval,
+	}
+}
+
+// ForgotPassword 向邮箱发送重置令牌，邮箱未注册、用户被禁用或发送过于频繁时静默跳过
+func (s *PasswordResetApplicationService) ForgotPassword(ctx context.Context, cmd *command.ForgotPasswordCommand) error {
+	exists, err := s.userRepo.ExistsByEmail(ctx, cmd.Email)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return nil
+	}
+
+	user, err := s.userRepo.FindByEmail(ctx, cmd.Email)
+	if err != nil {
+		return err
+	}
+	if user.Status == entity.UserStatusBanned {
+		return nil
+	}
+
+	latest, err := s.actionTokenRepo.FindLatest(ctx, user.ID, entity.ActionTokenPasswordReset)
+	if err != nil && !errors.Is(err, repository.ErrActionTokenNotFound) {
+		return err
+	}
+	if latest != nil && time.Since(latest.CreatedAt) < s.resendInterval {
+		return nil
+	}
+
+	expiresAt := time.Now().Add(s.tokenExpire)
+	token := entity.NewActionToken(uuid.New().String(), user.ID, entity.ActionTokenPasswordReset, user.Email.String(), expiresAt)
+
+	signed, err := s.tokenIssuer.GenerateActionToken(token.Purpose, token.UserID, token.TokenID, expiresAt)
+	if err != nil {
+		return errors.Wrap(err, "failed to generate password reset token")
+	}
+	if err := s.actionTokenRepo.Save(ctx, token); err != nil {
+		return errors.Wrap(err, "failed to save password reset token")
+	}
+
+	return s.mailer.Send(ctx, notification.Message{
+		To:      token.Email,
+		Subject: "重置您的密码",
+		Body:    fmt.Sprintf("%s，您好：\n\n我们收到了重置密码的请求，请通过以下链接设置新密码：\n\n%s\n\n链接 %d 分钟内有效，只能使用一次。如果不是您本人操作，请忽略本邮件。", user.Username, actionTokenLink(s.resetPageURL, signed), int(s.tokenExpire.Minutes())),
+	})
+}
+
+// ResetPassword 校验重置令牌并设置新密码
+func (s *PasswordResetApplicationService) ResetPassword(ctx context.Context, cmd *command.ConfirmPasswordResetCommand) error {
+	tokenID, userID, err := s.tokenIssuer.ParseActionToken(entity.ActionTokenPasswordReset, cmd.Token)
+	if err != nil {
+		return ErrInvalidResetToken
+	}
+
+	// 先校验新密码，密码不符合要求时令牌仍然可用
+	password, err := valueobject.NewPassword(cmd.NewPassword)
+	if err != nil {
+		return errors.Wrapf(err, "invalid new password")
+	}
+
+	token, err := s.actionTokenRepo.Take(ctx, entity.ActionTokenPasswordReset, tokenID)
+	if err != nil {
+		if errors.Is(err, repository.ErrActionTokenNotFound) {
+			return ErrInvalidResetToken
+		}
+		return err
+	}
+	if token.IsExpired() || token.UserID != userID {
+		return ErrInvalidResetToken
+	}
+
+	user, err := s.userRepo.FindByID(ctx, token.UserID)
+	if err != nil {
+		return errors.Wrap(err, "user not found")
+	}
+	if user.Email.String() != token.Email || user.Status == entity.UserStatusBanned {
+		return ErrInvalidResetToken
+	}
+
+	if err := s.userService.ResetPassword(ctx, command.NewResetPasswordCommand(user.ID, password.Hash())); err != nil {
+		return err
+	}
+	return s.authService.RevokeUserSessions(ctx, command.NewRevokeUserSessionsCommand(user.ID))
+}
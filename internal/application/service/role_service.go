@@ -0,0 +1,207 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"yiwen/go-ddd/internal/application/command"
+	"yiwen/go-ddd/internal/application/dto"
+	"yiwen/go-ddd/internal/application/query"
+	"yiwen/go-ddd/internal/domain/entity"
+	"yiwen/go-ddd/internal/domain/repository"
+	"yiwen/go-ddd/pkg/errors"
+
+	domainservice "yiwen/go-ddd/internal/domain/service"
+)
+
+// RoleApplicationService 角色和权限服务
+// 管理角色及其权限和继承关系，为用户分配和收回角色；请求时的权限判断由 authz.Authorizer 负责
+type RoleApplicationService struct {
+	roleRepo          repository.RoleRepository
+	roleDomainService *domainservice.RoleDomainService
+	userService       *UserApplicationService
+	auditService      *AuditApplicationService
+}
+
+// NewRoleApplicationService 创建角色和权限服务
+func NewRoleApplicationService(roleRepo repository.RoleRepository, roleDomainService *domainservice.RoleDomainService, userService *UserApplicationService, auditService *AuditApplicationService) *RoleApplicationService {
+	return &RoleApplicationService{
+		roleRepo:          roleRepo,
+		roleDomainService: roleDomainService,
+		userService:       userService,
+		auditService:      auditService,
+	}
+}
+
+// EnsureBuiltinRoles 创建缺少的内置角色，admin 继承 user 并拥有全部权限
+func (s *RoleApplicationService) EnsureBuiltinRoles(ctx context.Context) error {
+	userRole, err := s.ensureRole(ctx, entity.NewRole(string(entity.UserRoleUser), "普通用户", 0, []string{
+		entity.PermissionProfileRead,
+		entity.PermissionProfileUpdate,
+		entity.PermissionPasswordChange,
+		entity.PermissionAccountDelete,
+	}))
+	if err != nil {
+		return err
+	}
+
+	_, err = s.ensureRole(ctx, entity.NewRole(string(entity.UserRoleAdmin), "管理员", userRole.ID, []string{entity.PermissionAll}))
+	return err
+}
+
+// ListPermissions 系统定义的全部权限
+func (s *RoleApplicationService) ListPermissions() []dto.PermissionDTO {
+	permissions := make([]dto.PermissionDTO, len(entity.Permissions))
+	for i, p := range entity.Permissions {
+		permissions[i] = dto.PermissionDTO{Name: p.Name, Description: p.Description}
+	}
+	return permissions
+}
+
+// CreateRole 创建角色，角色的管理不产生领域事件，由审计服务直接记录
+func (s *RoleApplicationService) CreateRole(ctx context.Context, cmd *command.CreateRoleCommand) (*dto.RoleDTO, error) {
+	if err := s.roleDomainService.ValidateNewRoleName(ctx, cmd.Name); err != nil {
+		return nil, err
+	}
+	permissions := uniquePermissions(cmd.Permissions)
+	if err := s.roleDomainService.ValidatePermissions(permissions); err != nil {
+		return nil, err
+	}
+	if err := s.roleDomainService.ValidateParent(ctx, 0, cmd.ParentID); err != nil {
+		return nil, err
+	}
+
+	role := entity.NewRole(cmd.Name, strings.TrimSpace(cmd.Description), cmd.ParentID, permissions)
+	if err := s.roleRepo.Save(ctx, role); err != nil {
+		return nil, errors.Wrap(err, "failed to save role")
+	}
+	if err := s.audit(ctx, entity.AuditActionRoleCreated, role); err != nil {
+		return nil, err
+	}
+
+	return s.toRoleDTO(ctx, role)
+}
+
+func (s *RoleApplicationService) GetRole(ctx context.Context, q *query.GetRoleQuery) (*dto.RoleDTO, error) {
+	role, err := s.roleRepo.FindByID(ctx, q.RoleID)
+	if err != nil {
+		return nil, err
+	}
+	return s.toRoleDTO(ctx, role)
+}
+
+func (s *RoleApplicationService) ListRoles(ctx context.Context) ([]dto.RoleDTO, error) {
+	roles, err := s.roleRepo.List(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list roles")
+	}
+
+	dtos := make([]dto.RoleDTO, len(roles))
+	for i, role := range roles {
+		result, err := s.toRoleDTO(ctx, role)
+		if err != nil {
+			return nil, err
+		}
+		dtos[i] = *result
+	}
+	return dtos, nil
+}
+
+// UpdateRole 更新角色的说明、父角色和权限，修改后拥有该角色的用户立即获得新的权限
+func (s *RoleApplicationService) UpdateRole(ctx context.Context, cmd *command.UpdateRoleCommand) (*dto.RoleDTO, error) {
+	role, err := s.roleRepo.FindByID(ctx, cmd.RoleID)
+	if err != nil {
+		return nil, err
+	}
+
+	permissions := uniquePermissions(cmd.Permissions)
+	if err := s.roleDomainService.ValidatePermissions(permissions); err != nil {
+		return nil, err
+	}
+	if err := s.roleDomainService.ValidateParent(ctx, role.ID, cmd.ParentID); err != nil {
+		return nil, err
+	}
+
+	role.Update(strings.TrimSpace(cmd.Description), cmd.ParentID, permissions)
+	if err := s.roleRepo.Save(ctx, role); err != nil {
+		return nil, errors.Wrap(err, "failed to save role")
+	}
+	if err := s.audit(ctx, entity.AuditActionRoleUpdated, role); err != nil {
+		return nil, err
+	}
+
+	return s.toRoleDTO(ctx, role)
+}
+
+// DeleteRole 删除角色，内置角色、已分配给用户或被其他角色继承的角色不能删除
+func (s *RoleApplicationService) DeleteRole(ctx context.Context, cmd *command.DeleteRoleCommand) error {
+	role, err := s.roleRepo.FindByID(ctx, cmd.RoleID)
+	if err != nil {
+		return err
+	}
+	if err := s.roleDomainService.ValidateDeletable(ctx, role); err != nil {
+		return err
+	}
+	if err := s.roleRepo.Delete(ctx, role.ID); err != nil {
+		return errors.Wrap(err, "failed to delete role")
+	}
+	return s.audit(ctx, entity.AuditActionRoleDeleted, role)
+}
+
+// AssignRole 为用户分配已存在的角色，由用户领域事件记录审计日志
+func (s *RoleApplicationService) AssignRole(ctx context.Context, cmd *command.AssignRoleCommand) (*dto.UserDTO, error) {
+	if _, err := s.roleRepo.FindByName(ctx, cmd.Role); err != nil {
+		return nil, err
+	}
+	return s.userService.AssignRole(ctx, cmd)
+}
+
+// RevokeRole 收回用户角色，角色被删除之前必须先从用户收回，因此不校验角色是否存在
+func (s *RoleApplicationService) RevokeRole(ctx context.Context, cmd *command.RevokeRoleCommand) (*dto.UserDTO, error) {
+	return s.userService.RevokeRole(ctx, cmd)
+}
+
+// audit 记录角色管理操作，说明中包含修改后的权限和父角色
+func (s *RoleApplicationService) audit(ctx context.Context, action string, role *entity.Role) error {
+	detail := fmt.Sprintf("name=%s parent_id=%d permissions=%s", role.Name, role.ParentID, strings.Join(role.Permissions, ","))
+	return s.auditService.Record(ctx, action, strconv.FormatUint(role.ID, 10), detail)
+}
+
+// ensureRole 按名称查询角色，不存在时创建
+func (s *RoleApplicationService) ensureRole(ctx context.Context, role *entity.Role) (*entity.Role, error) {
+	existing, err := s.roleRepo.FindByName(ctx, role.Name)
+	if err == nil {
+		return existing, nil
+	}
+	if !errors.Is(err, repository.ErrRoleNotFound) {
+		return nil, err
+	}
+
+	if err := s.roleRepo.Save(ctx, role); err != nil {
+		return nil, errors.Wrapf(err, "failed to create built-in role %s", role.Name)
+	}
+	return role, nil
+}
+
+func (s *RoleApplicationService) toRoleDTO(ctx context.Context, role *entity.Role) (*dto.RoleDTO, error) {
+	effective, err := s.roleDomainService.EffectivePermissions(ctx, []entity.UserRole{entity.UserRole(role.Name)})
+	if err != nil {
+		return nil, err
+	}
+
+	result := dto.ToRoleDTO(role, effective)
+	return &result, nil
+}
+
+// uniquePermissions 去掉重复的权限，保持提交的顺序
+func uniquePermissions(permissions []string) []string {
+	unique := make([]string, 0, len(permissions))
+	for _, p := range permissions {
+		if !slices.Contains(unique, p) {
+			unique = append(unique, p)
+		}
+	}
+	return unique
+}
@@ -0,0 +1,366 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base32"
+	"strings"
+	"time"
+	"yiwen/go-ddd/internal/application/command"
+	"yiwen/go-ddd/internal/application/dto"
+	"yiwen/go-ddd/internal/domain/entity"
+	"yiwen/go-ddd/internal/domain/repository"
+	domainservice "yiwen/go-ddd/internal/domain/service"
+	"yiwen/go-ddd/pkg/errors"
+	"yiwen/go-ddd/pkg/totp"
+)
+
+var (
+	ErrTwoFactorAlreadyEnabled = errors.New("two-factor authentication is already enabled")
+	ErrTwoFactorNotEnabled     = errors.New("two-factor authentication is not enabled")
+	ErrTwoFactorNotSetup       = errors.New("two-factor authentication has not been set up")
+	ErrInvalidTwoFactorCode    = errors.New("invalid two-factor code")
+	ErrInvalidMFAToken         = errors.New("invalid or expired mfa token")
+)
+
+const (
+	// recoveryCodeCount 每次生成的恢复码数量
+	recoveryCodeCount = 10
+	// maxTwoFactorAttempts 一个 MFA 令牌允许失败的次数，超过后需要重新输入密码
+	maxTwoFactorAttempts = 5
+	// totpSkew 允许前后各一个时间步的时钟偏差
+	totpSkew = 1
+)
+
+var recoveryCodeEncoding = base32.NewEncoding("abcdefghijklmnopqrstuvwxyz234567").WithPadding(base32.NoPadding)
+
+// TwoFactorApplicationService 两步验证服务
+// 1. 用户生成 TOTP 密钥后提交第一个验证码确认开启，同时获得一次性恢复码
+// 2. 开启后密码登录只返回短期 MFA 令牌，再提交验证码或恢复码换取访问令牌
+// 3. 验证码按时间步只能使用一次，恢复码只保存哈希，使用后作废
+// 4. 验证码错误次数按用户累计，超过阈值后临时锁定，换新的 MFA 令牌不会重置计数
+// 5. 管理员可以为丢失设备的用户重置两步验证，同时撤销用户已签发的令牌和会话
+type TwoFactorApplicationService struct {
+	twoFactorRepo   repository.TwoFactorRepository
+	userRepo        repository.UserRepository
+	userService     *UserApplicationService
+	authService     *AuthApplicationService
+	loginProtection *domainservice.LoginProtectionService
+	issuer          string
+	challengeExpire time.Duration
+}
+
+// NewTwoFactorApplicationService 创建两步验证服务
+func NewTwoFactorApplicationService(twoFactorRepo repository.TwoFactorRepository, userRepo repository.UserRepository, userService *UserApplicationService, authService *AuthApplicationService, loginProtection *domainservice.LoginProtectionService, issuer string, challengeExpire time.Duration) *TwoFactorApplicationService {
+	return &TwoFactorApplicationService{
+		twoFactorRepo:   twoFactorRepo,
+		userRepo:        userRepo,
+		userService:     userService,
+		authService:     authService,
+		loginProtection: loginProtection,
+		issuer:          issuer,
+		challengeExpire: challengeExpire,
+	}
+}
+
+// Status 查询两步验证状态
+func (s *TwoFactorApplicationService) Status(ctx context.Context, userID uint64) (*dto.TwoFactorStatusDTO, error) {
+	twoFactor, err := s.findEnabled(ctx, userID)
+	if errors.Is(err, ErrTwoFactorNotEnabled) {
+		return &dto.TwoFactorStatusDTO{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	remaining, err := s.twoFactorRepo.CountRecoveryCodes(ctx, twoFactor.UserID)
+	if err != nil {
+		return nil, err
+	}
+	return &dto.TwoFactorStatusDTO{Enabled: true, RecoveryCodesRemaining: remaining}, nil
+}
+
+// Setup 生成新的 TOTP 密钥，确认之前可以重复调用，之前未确认的密钥随之失效
+func (s *TwoFactorApplicationService) Setup(ctx context.Context, cmd *command.SetupTwoFactorCommand) (*dto.TwoFactorSetupDTO, error) {
+	user, err := s.userRepo.FindByID(ctx, cmd.UserID)
+	if err != nil {
+		return nil, errors.Wrap(err, "user not found")
+	}
+
+	twoFactor, err := s.twoFactorRepo.FindByUserID(ctx, cmd.UserID)
+	if err != nil && !errors.Is(err, repository.ErrTwoFactorNotFound) {
+		return nil, err
+	}
+	if twoFactor != nil && twoFactor.IsEnabled() {
+		return nil, ErrTwoFactorAlreadyEnabled
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to generate totp secret")
+	}
+	if twoFactor == nil {
+		twoFactor = entity.NewTwoFactor(cmd.UserID, secret)
+	} else {
+		twoFactor.Secret = secret
+	}
+
+	if err := s.twoFactorRepo.Save(ctx, twoFactor); err != nil {
+		return nil, errors.Wrap(err, "failed to save two-factor authentication")
+	}
+
+	return &dto.TwoFactorSetupDTO{
+		Secret:          secret,
+		ProvisioningURI: totp.ProvisioningURI(secret, s.issuer, user.Email.String()),
+	}, nil
+}
+
+// Confirm 使用认证器应用生成的第一个验证码确认开启，返回恢复码
+func (s *TwoFactorApplicationService) Confirm(ctx context.Context, cmd *command.ConfirmTwoFactorCommand) (*dto.RecoveryCodesDTO, error) {
+	twoFactor, err := s.twoFactorRepo.FindByUserID(ctx, cmd.UserID)
+	if err != nil {
+		if errors.Is(err, repository.ErrTwoFactorNotFound) {
+			return nil, ErrTwoFactorNotSetup
+		}
+		return nil, err
+	}
+	if twoFactor.IsEnabled() {
+		return nil, ErrTwoFactorAlreadyEnabled
+	}
+
+	step, ok := totp.Validate(twoFactor.Secret, normalizeTwoFactorCode(cmd.Code), time.Now(), totpSkew)
+	if !ok {
+		return nil, ErrInvalidTwoFactorCode
+	}
+
+	// 先保存恢复码再开启，避免开启后没有可用的恢复码
+	codes, err := s.replaceRecoveryCodes(ctx, cmd.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	twoFactor.Confirm(step)
+	if err := s.twoFactorRepo.Save(ctx, twoFactor); err != nil {
+		return nil, errors.Wrap(err, "failed to save two-factor authentication")
+	}
+	return codes, nil
+}
+
+// Disable 用户提交验证码或恢复码后关闭两步验证
+func (s *TwoFactorApplicationService) Disable(ctx context.Context, cmd *command.DisableTwoFactorCommand) error {
+	twoFactor, err := s.findEnabled(ctx, cmd.UserID)
+	if err != nil {
+		return err
+	}
+	if err := s.verifyCode(ctx, twoFactor, cmd.Code); err != nil {
+		return err
+	}
+	return s.twoFactorRepo.Delete(ctx, cmd.UserID)
+}
+
+// RegenerateRecoveryCodes 重新生成恢复码，之前的恢复码全部作废
+func (s *TwoFactorApplicationService) RegenerateRecoveryCodes(ctx context.Context, cmd *command.RegenerateRecoveryCodesCommand) (*dto.RecoveryCodesDTO, error) {
+	twoFactor, err := s.findEnabled(ctx, cmd.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.verifyCode(ctx, twoFactor, cmd.Code); err != nil {
+		return nil, err
+	}
+	return s.replaceRecoveryCodes(ctx, cmd.UserID)
+}
+
+// Reset 管理员重置用户的两步验证，用户之后可以只用密码登录并重新开启
+// 丢失的设备可能已落入他人之手，已签发的访问令牌、刷新令牌和会话全部撤销
+func (s *TwoFactorApplicationService) Reset(ctx context.Context, cmd *command.ResetTwoFactorCommand) error {
+	if _, err := s.twoFactorRepo.FindByUserID(ctx, cmd.UserID); err != nil {
+		if errors.Is(err, repository.ErrTwoFactorNotFound) {
+			return ErrTwoFactorNotEnabled
+		}
+		return err
+	}
+	if err := s.twoFactorRepo.Delete(ctx, cmd.UserID); err != nil {
+		return err
+	}
+	if _, err := s.loginProtection.UnlockSecondFactor(ctx, cmd.UserID); err != nil {
+		return errors.Wrap(err, "failed to reset two-factor attempts")
+	}
+
+	if err := s.userService.RevokeTokens(ctx, command.NewRevokeUserTokensCommand(cmd.UserID, "two_factor_reset")); err != nil {
+		return err
+	}
+	return s.authService.RevokeUserSessions(ctx, command.NewRevokeUserSessionsCommand(cmd.UserID))
+}
+
+// Challenge 第一步验证通过后调用，用户开启了两步验证时返回 MFA 令牌，否则返回 nil
+func (s *TwoFactorApplicationService) Challenge(ctx context.Context, userID uint64) (*dto.TwoFactorChallengeDTO, error) {
+	if _, err := s.findEnabled(ctx, userID); err != nil {
+		if errors.Is(err, ErrTwoFactorNotEnabled) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	token, err := generateRefreshToken()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to generate mfa token")
+	}
+
+	challenge := entity.NewTwoFactorChallenge(hashRefreshToken(token), userID, time.Now().Add(s.challengeExpire))
+	if err := s.twoFactorRepo.SaveChallenge(ctx, challenge); err != nil {
+		return nil, errors.Wrap(err, "failed to save two-factor challenge")
+	}
+
+	return &dto.TwoFactorChallengeDTO{
+		TwoFactorRequired: true,
+		MFAToken:          token,
+		ExpiresAt:         challenge.ExpiresAt.Unix(),
+	}, nil
+}
+
+// Verify 校验 MFA 令牌和验证码，通过后签发访问令牌和刷新令牌
+func (s *TwoFactorApplicationService) Verify(ctx context.Context, cmd *command.VerifyTwoFactorCommand) (*dto.LoginResponse, error) {
+	challenge, err := s.twoFactorRepo.FindChallengeByHash(ctx, hashRefreshToken(cmd.MFAToken))
+	if err != nil {
+		if errors.Is(err, repository.ErrTwoFactorChallengeNotFound) {
+			return nil, ErrInvalidMFAToken
+		}
+		return nil, err
+	}
+	if challenge.IsExpired() || challenge.Attempts >= maxTwoFactorAttempts {
+		if err := s.twoFactorRepo.DeleteChallenge(ctx, challenge.ID); err != nil && !errors.Is(err, repository.ErrTwoFactorChallengeNotFound) {
+			return nil, err
+		}
+		return nil, ErrInvalidMFAToken
+	}
+
+	twoFactor, err := s.findEnabled(ctx, challenge.UserID)
+	if err != nil {
+		if errors.Is(err, ErrTwoFactorNotEnabled) {
+			return nil, ErrInvalidMFAToken
+		}
+		return nil, err
+	}
+
+	if err := s.verifyCode(ctx, twoFactor, cmd.Code); err != nil {
+		if errors.Is(err, ErrInvalidTwoFactorCode) {
+			if err := s.twoFactorRepo.IncrementChallengeAttempts(ctx, challenge.ID); err != nil {
+				return nil, err
+			}
+		}
+		return nil, err
+	}
+
+	// 删除成功的请求才能登录，保证 MFA 令牌只能使用一次
+	if err := s.twoFactorRepo.DeleteChallenge(ctx, challenge.ID); err != nil {
+		if errors.Is(err, repository.ErrTwoFactorChallengeNotFound) {
+			return nil, ErrInvalidMFAToken
+		}
+		return nil, err
+	}
+
+	user, err := s.userRepo.FindByID(ctx, challenge.UserID)
+	if err != nil {
+		return nil, errors.Wrap(err, "user not found")
+	}
+	if !user.IsActive() {
+		return nil, domainservice.ErrUserNotActive
+	}
+	if user.PasswordResetRequired {
+		return nil, domainservice.ErrPasswordResetRequired
+	}
+
+	tokens, err := s.authService.IssueTokens(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	return &dto.LoginResponse{TokenDTO: *tokens, User: dto.ToUserDTO(user)}, nil
+}
+
+func (s *TwoFactorApplicationService) findEnabled(ctx context.Context, userID uint64) (*entity.TwoFactor, error) {
+	twoFactor, err := s.twoFactorRepo.FindByUserID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrTwoFactorNotFound) {
+			return nil, ErrTwoFactorNotEnabled
+		}
+		return nil, err
+	}
+	if !twoFactor.IsEnabled() {
+		return nil, ErrTwoFactorNotEnabled
+	}
+	return twoFactor, nil
+}
+
+// verifyCode 校验验证码或恢复码，用户因错误次数过多被锁定时直接拒绝
+// 错误时累计用户的失败次数，通过后清零
+func (s *TwoFactorApplicationService) verifyCode(ctx context.Context, twoFactor *entity.TwoFactor, code string) error {
+	if err := s.loginProtection.CheckSecondFactor(ctx, twoFactor.UserID); err != nil {
+		return err
+	}
+
+	if err := s.checkCode(ctx, twoFactor, code); err != nil {
+		if errors.Is(err, ErrInvalidTwoFactorCode) {
+			if _, err := s.loginProtection.RecordSecondFactorFailure(ctx, twoFactor.UserID); err != nil {
+				return errors.Wrap(err, "failed to record two-factor attempt")
+			}
+		}
+		return err
+	}
+
+	if err := s.loginProtection.RecordSecondFactorSuccess(ctx, twoFactor.UserID); err != nil {
+		return errors.Wrap(err, "failed to reset two-factor attempts")
+	}
+	return nil
+}
+
+// checkCode 6 位数字按 TOTP 验证码处理，其他按恢复码处理
+func (s *TwoFactorApplicationService) checkCode(ctx context.Context, twoFactor *entity.TwoFactor, code string) error {
+	code = normalizeTwoFactorCode(code)
+
+	if len(code) == totp.Digits && strings.Trim(code, "0123456789") == "" {
+		step, ok := totp.Validate(twoFactor.Secret, code, time.Now(), totpSkew)
+		if !ok || step <= twoFactor.LastUsedStep {
+			return ErrInvalidTwoFactorCode
+		}
+		if err := s.twoFactorRepo.MarkStepUsed(ctx, twoFactor.UserID, step); err != nil {
+			if errors.Is(err, repository.ErrTwoFactorStepUsed) {
+				return ErrInvalidTwoFactorCode
+			}
+			return err
+		}
+		return nil
+	}
+
+	if err := s.twoFactorRepo.UseRecoveryCode(ctx, twoFactor.UserID, hashRefreshToken(strings.ReplaceAll(code, "-", ""))); err != nil {
+		if errors.Is(err, repository.ErrRecoveryCodeNotFound) {
+			return ErrInvalidTwoFactorCode
+		}
+		return err
+	}
+	return nil
+}
+
+// replaceRecoveryCodes 生成新的恢复码，格式为 xxxx-xxxx，数据库只保存去掉分隔符后的哈希
+func (s *TwoFactorApplicationService) replaceRecoveryCodes(ctx context.Context, userID uint64) (*dto.RecoveryCodesDTO, error) {
+	codes := make([]string, recoveryCodeCount)
+	hashes := make([]string, recoveryCodeCount)
+	for i := range codes {
+		b := make([]byte, 5)
+		if _, err := rand.Read(b); err != nil {
+			return nil, errors.Wrap(err, "failed to generate recovery code")
+		}
+		code := recoveryCodeEncoding.EncodeToString(b)
+		codes[i] = code[:4] + "-" + code[4:]
+		hashes[i] = hashRefreshToken(code)
+	}
+
+	if err := s.twoFactorRepo.ReplaceRecoveryCodes(ctx, userID, hashes); err != nil {
+		return nil, errors.Wrap(err, "failed to save recovery codes")
+	}
+	return &dto.RecoveryCodesDTO{RecoveryCodes: codes}, nil
+}
+
+// normalizeTwoFactorCode 去掉用户输入中的空格，恢复码不区分大小写
+func normalizeTwoFactorCode(code string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(code), " ", ""))
+}
@@ -2,10 +2,15 @@ package service
 
 import (
 	"context"
+	"strings"
+	"unicode"
+	"yiwen/go-ddd/internal/application/authz"
 	"yiwen/go-ddd/internal/application/command"
 	"yiwen/go-ddd/internal/application/dto"
 	"yiwen/go-ddd/internal/application/query"
 	"yiwen/go-ddd/internal/domain/aggregate"
+	"yiwen/go-ddd/internal/domain/entity"
+	"yiwen/go-ddd/internal/domain/event"
 	"yiwen/go-ddd/internal/domain/repository"
 	"yiwen/go-ddd/internal/domain/service"
 	"yiwen/go-ddd/internal/domain/valueobject"
@@ -22,14 +27,32 @@ import (
 // 2. 处理事物
 // 3. 调用领域服务
 // 4. 不包含业务逻辑
+// 5. 按授权策略校验 context 中的主体，HTTP、gRPC、CLI 等入口使用相同的规则
 type UserApplicationService struct {
-	userRepo          repository.UserRepository
-	userDomainService service.UserDomainService
+	userRepo                 repository.UserRepository
+	userAggRepo              repository.UserAggregateRepository
+	userDomainService        service.UserDomainService
+	loginProtection          *service.LoginProtectionService
+	authorizer               *authz.Authorizer
+	requireEmailVerification bool
 }
 
-// NewUserApplicationService 创建用户应用服务
-func NewUserApplicationService(userRepo repository.UserRepository, userDomainService domainservice.UserDomainService) *UserApplicationService {
-	return &UserApplicationService{userRepo: userRepo, userDomainService: userDomainService}
+// 用户命令的授权策略
+// 注册、登录以及通过邮件令牌完成的操作由令牌本身授权，不在此列
+var (
+	getUserPolicy        = authz.OwnerOr(entity.PermissionProfileRead, entity.PermissionUsersRead)
+	listUsersPolicy      = authz.Require(entity.PermissionUsersRead)
+	updateProfilePolicy  = authz.OwnerOr(entity.PermissionProfileUpdate, entity.PermissionUsersUpdate)
+	changePasswordPolicy = authz.OwnerOnly(entity.PermissionPasswordChange) // 需要旧密码，只有本人可以修改
+	deleteUserPolicy     = authz.OwnerOr(entity.PermissionAccountDelete, entity.PermissionUsersDelete)
+	banUserPolicy        = authz.Require(entity.PermissionUsersBan)
+	unlockUserPolicy     = authz.Require(entity.PermissionUsersUnlock)
+	assignRolePolicy     = authz.Require(entity.PermissionRolesAssign)
+)
+
+// NewUserApplicationService 创建用户应用服务，requireEmailVerification 为 true 时注册的用户验证邮箱后才激活
+func NewUserApplicationService(userRepo repository.UserRepository, userAggRepo repository.UserAggregateRepository, userDomainService domainservice.UserDomainService, loginProtection *domainservice.LoginProtectionService, authorizer *authz.Authorizer, requireEmailVerification bool) *UserApplicationService {
+	return &UserApplicationService{userRepo: userRepo, userAggRepo: userAggRepo, userDomainService: userDomainService, loginProtection: loginProtection, authorizer: authorizer, requireEmailVerification: requireEmailVerification}
 }
 
 // Register 注册用户
@@ -54,30 +77,127 @@ func (s *UserApplicationService) Register(ctx context.Context, cmd *command.Regi
 		return nil, errors.Wrapf(err, "invalid password")
 	}
 
-	userAggregate := aggregate.Register(uuid.New().String(), cmd.Username, email, password)
-	userAggregate.User.Nickname = cmd.Nickname
+	userAggregate, err := aggregate.Register(uuid.New().String(), cmd.Username, email, password, cmd.Nickname, s.requireEmailVerification)
+	if err != nil {
+		return nil, err
+	}
 
-	if err := s.userRepo.Save(ctx, userAggregate.User); err != nil {
+	// 用户和领域事件在同一事务中保存，事件由 outbox relay 发布
+	if err := s.saveAggregate(ctx, userAggregate); err != nil {
 		return nil, errors.Wrapf(err, "failed to save user")
 	}
 
-	// 发布领域事件
+	result := dto.ToUserDTO(userAggregate.User)
+	return &result, nil
+}
+
+// RegisterExternalUser 外部身份首次登录时即时创建用户
+// 用户没有本地密码，只能通过外部身份登录
+// 提供方声明 email_verified 时信任该声明，标记邮箱已验证并直接激活
+// 否则与本地注册一样，要求邮箱验证时用户处于未激活状态，通过验证邮件确认后才能登录
+func (s *UserApplicationService) RegisterExternalUser(ctx context.Context, cmd *command.RegisterExternalUserCommand) (*dto.UserDTO, error) {
+	if err := s.userDomainService.ValidateUniqueEmail(ctx, cmd.Email); err != nil {
+		return nil, err
+	}
+
+	email, err := valueobject.NewEmail(cmd.Email)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid email")
+	}
+
+	username, err := s.availableUsername(ctx, cmd.Username)
+	if err != nil {
+		return nil, err
+	}
+
+	pendingVerification := s.requireEmailVerification && !cmd.EmailVerified
+	userAggregate, err := aggregate.Register(uuid.New().String(), username, email, valueobject.Password{}, cmd.Nickname, pendingVerification)
+	if err != nil {
+		return nil, err
+	}
+	if cmd.Avatar != "" {
+		if err := userAggregate.UpdateProfile(cmd.Nickname, cmd.Avatar); err != nil {
+			return nil, err
+		}
+	}
+	if cmd.EmailVerified {
+		if err := userAggregate.VerifyEmail(); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.saveAggregate(ctx, userAggregate); err != nil {
+		return nil, errors.Wrapf(err, "failed to save user")
+	}
 
 	result := dto.ToUserDTO(userAggregate.User)
 	return &result, nil
 }
 
+// Login 校验用户名和密码
+// 登录前检查账户和客户端IP是否被锁定，密码错误时记录失败次数，账户因此被锁定时产生 UserLockedOutEvent
 func (s *UserApplicationService) Login(ctx context.Context, q *query.LoginQuery) (*dto.UserDTO, error) {
+	ip := event.MetadataFromContext(ctx).IP
+	if err := s.loginProtection.Check(ctx, q.Username, ip); err != nil {
+		return nil, err
+	}
+
 	user, err := s.userDomainService.ValidateUserCredentials(ctx, q.Username, q.Password)
+	if errors.Is(err, domainservice.ErrInvalidCredentials) {
+		if err := s.recordLoginFailure(ctx, q.Username, ip); err != nil {
+			return nil, err
+		}
+		return nil, domainservice.ErrInvalidCredentials
+	}
 	if err != nil {
 		return nil, err
 	}
 
+	if err := s.loginProtection.RecordSuccess(ctx, q.Username); err != nil {
+		return nil, errors.Wrap(err, "failed to reset login attempts")
+	}
+
 	result := dto.ToUserDTO(user)
 	return &result, nil
 }
 
+// UnlockUser 管理员解除账户的登录锁定和两步验证锁定，账户之前处于锁定中时产生 UserUnlockedEvent
+func (s *UserApplicationService) UnlockUser(ctx context.Context, cmd *command.UnlockUserCommand) (*dto.UserDTO, error) {
+	if err := s.authorizer.Authorize(ctx, unlockUserPolicy, cmd.UserID); err != nil {
+		return nil, err
+	}
+
+	userAggregate, err := s.userAggRepo.Load(ctx, cmd.UserID)
+	if err != nil {
+		return nil, errors.Wrap(err, "user not found")
+	}
+
+	locked, err := s.loginProtection.Unlock(ctx, userAggregate.User.Username)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to unlock user")
+	}
+	secondFactorLocked, err := s.loginProtection.UnlockSecondFactor(ctx, cmd.UserID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to unlock user")
+	}
+	if locked || secondFactorLocked {
+		if err := userAggregate.Unlock(); err != nil {
+			return nil, err
+		}
+		if err := s.saveAggregate(ctx, userAggregate); err != nil {
+			return nil, errors.Wrap(err, "failed to save user")
+		}
+	}
+
+	result := dto.ToUserDTO(userAggregate.User)
+	return &result, nil
+}
+
 func (s *UserApplicationService) GetUserByID(ctx context.Context, q *query.GetUserByIDQuery) (*dto.UserDTO, error) {
+	if err := s.authorizer.Authorize(ctx, getUserPolicy, q.UserID); err != nil {
+		return nil, err
+	}
+
 	user, err := s.userRepo.FindByID(ctx, q.UserID)
 	if err != nil {
 		return nil, errors.Wrap(err, "user not found")
@@ -88,6 +208,10 @@ func (s *UserApplicationService) GetUserByID(ctx context.Context, q *query.GetUs
 }
 
 func (s *UserApplicationService) ListUsers(ctx context.Context, q *query.ListUsersQuery) (*dto.UserListDTO, error) {
+	if err := s.authorizer.Authorize(ctx, listUsersPolicy, 0); err != nil {
+		return nil, err
+	}
+
 	users, total, err := s.userRepo.List(ctx, q.Offset, q.Limit)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to list users")
@@ -101,15 +225,20 @@ func (s *UserApplicationService) ListUsers(ctx context.Context, q *query.ListUse
 }
 
 func (s *UserApplicationService) UpdateProfile(ctx context.Context, cmd *command.UpdateProfileCommand) (*dto.UserDTO, error) {
-	user, err := s.userRepo.FindByID(ctx, cmd.UserID)
+	if err := s.authorizer.Authorize(ctx, updateProfilePolicy, cmd.UserID); err != nil {
+		return nil, err
+	}
+
+	userAggregate, err := s.userAggRepo.Load(ctx, cmd.UserID)
 	if err != nil {
 		return nil, errors.Wrap(err, "user not found")
 	}
 
-	userAggregate := aggregate.NewUserAggregate(user)
-	userAggregate.UpdateProfile(cmd.Nickname, cmd.Avatar)
+	if err := userAggregate.UpdateProfile(cmd.Nickname, cmd.Avatar); err != nil {
+		return nil, err
+	}
 
-	if err := s.userRepo.Save(ctx, userAggregate.User); err != nil {
+	if err := s.saveAggregate(ctx, userAggregate); err != nil {
 		return nil, errors.Wrap(err, "failed to save user")
 	}
 
@@ -118,12 +247,16 @@ func (s *UserApplicationService) UpdateProfile(ctx context.Context, cmd *command
 }
 
 func (s *UserApplicationService) ChangePassword(ctx context.Context, cmd *command.ChangePasswordCommand) error {
-	user, err := s.userRepo.FindByID(ctx, cmd.UserID)
+	if err := s.authorizer.Authorize(ctx, changePasswordPolicy, cmd.UserID); err != nil {
+		return err
+	}
+
+	userAggregate, err := s.userAggRepo.Load(ctx, cmd.UserID)
 	if err != nil {
 		return errors.Wrap(err, "user not found")
 	}
 
-	if err := user.Password.Verify(cmd.OldPassword); err != nil {
+	if err := userAggregate.User.Password.Verify(cmd.OldPassword); err != nil {
 		return domainservice.ErrInvalidCredentials
 	}
 
@@ -132,20 +265,282 @@ func (s *UserApplicationService) ChangePassword(ctx context.Context, cmd *comman
 		return errors.Wrapf(err, "invalid new password")
 	}
 
-	userAggregate := aggregate.NewUserAggregate(user)
-	userAggregate.ChangePassword(newPassword)
+	if err := userAggregate.ChangePassword(newPassword); err != nil {
+		return err
+	}
+
+	if err := s.saveAggregate(ctx, userAggregate); err != nil {
+		return errors.Wrap(err, "failed to save user")
+	}
+
+	return nil
+}
+
+// ResetPassword 通过找回密码设置新密码，不需要旧密码
+// 与修改密码相同产生 UserPasswordChangedEvent 并递增令牌版本，之前签发的访问令牌全部失效
+func (s *UserApplicationService) ResetPassword(ctx context.Context, cmd *command.ResetPasswordCommand) error {
+	userAggregate, err := s.userAggRepo.Load(ctx, cmd.UserID)
+	if err != nil {
+		return errors.Wrap(err, "user not found")
+	}
+
+	if err := userAggregate.ChangePassword(valueobject.NewPasswordFromHash(cmd.PasswordHash)); err != nil {
+		return err
+	}
+
+	if err := s.saveAggregate(ctx, userAggregate); err != nil {
+		return errors.Wrap(err, "failed to save user")
+	}
+
+	return nil
+}
+
+// RevokeTokens 递增用户的令牌版本，使已签发的访问令牌全部失效
+// 刷新令牌和会话由 AuthApplicationService.RevokeUserSessions 撤销
+func (s *UserApplicationService) RevokeTokens(ctx context.Context, cmd *command.RevokeUserTokensCommand) error {
+	userAggregate, err := s.userAggRepo.Load(ctx, cmd.UserID)
+	if err != nil {
+		return errors.Wrap(err, "user not found")
+	}
+
+	if err := userAggregate.RevokeTokens(cmd.Reason); err != nil {
+		return err
+	}
 
-	if err := s.userRepo.Save(ctx, userAggregate.User); err != nil {
+	if err := s.saveAggregate(ctx, userAggregate); err != nil {
 		return errors.Wrap(err, "failed to save user")
 	}
 
 	return nil
 }
 
+// DeleteUser 删除用户，用户可以注销自己的账户，通过聚合产生删除事件，users 表中为软删除
 func (s *UserApplicationService) DeleteUser(ctx context.Context, cmd *command.DeleteUserCommand) error {
-	if err := s.userRepo.Delete(ctx, cmd.UserID); err != nil {
+	if err := s.authorizer.Authorize(ctx, deleteUserPolicy, cmd.UserID); err != nil {
+		return err
+	}
+
+	userAggregate, err := s.userAggRepo.Load(ctx, cmd.UserID)
+	if err != nil {
+		return errors.Wrap(err, "user not found")
+	}
+
+	if err := userAggregate.Delete(cmd.Reason); err != nil {
+		return err
+	}
+
+	if err := s.saveAggregate(ctx, userAggregate); err != nil {
 		return errors.Wrap(err, "failed to delete user")
 	}
 
 	return nil
 }
+
+// BanUser 禁用用户
+func (s *UserApplicationService) BanUser(ctx context.Context, cmd *command.BanUserCommand) (*dto.UserDTO, error) {
+	if err := s.authorizer.Authorize(ctx, banUserPolicy, cmd.UserID); err != nil {
+		return nil, err
+	}
+
+	userAggregate, err := s.userAggRepo.Load(ctx, cmd.UserID)
+	if err != nil {
+		return nil, errors.Wrap(err, "user not found")
+	}
+
+	if err := userAggregate.Ban(cmd.Reason); err != nil {
+		return nil, err
+	}
+
+	if err := s.saveAggregate(ctx, userAggregate); err != nil {
+		return nil, errors.Wrap(err, "failed to save user")
+	}
+
+	result := dto.ToUserDTO(userAggregate.User)
+	return &result, nil
+}
+
+// DeactivateUser 将用户设为未激活
+func (s *UserApplicationService) DeactivateUser(ctx context.Context, cmd *command.DeactivateUserCommand) error {
+	user, err := s.userRepo.FindByUUID(ctx, cmd.UserUUID)
+	if err != nil {
+		return errors.Wrap(err, "user not found")
+	}
+
+	userAggregate, err := s.userAggRepo.Load(ctx, user.ID)
+	if err != nil {
+		return errors.Wrap(err, "user not found")
+	}
+
+	if err := userAggregate.Deactivate(); err != nil {
+		return err
+	}
+
+	if err := s.saveAggregate(ctx, userAggregate); err != nil {
+		return errors.Wrap(err, "failed to save user")
+	}
+
+	return nil
+}
+
+// ConfirmEmail 用户通过验证邮件确认邮箱，未激活的用户同时被激活
+func (s *UserApplicationService) ConfirmEmail(ctx context.Context, cmd *command.ConfirmEmailCommand) (*dto.UserDTO, error) {
+	userAggregate, err := s.userAggRepo.Load(ctx, cmd.UserID)
+	if err != nil {
+		return nil, errors.Wrap(err, "user not found")
+	}
+
+	if err := userAggregate.ConfirmEmail(); err != nil {
+		return nil, err
+	}
+
+	if err := s.saveAggregate(ctx, userAggregate); err != nil {
+		return nil, errors.Wrap(err, "failed to save user")
+	}
+
+	result := dto.ToUserDTO(userAggregate.User)
+	return &result, nil
+}
+
+// PromoteToAdmin 提升为管理员
+func (s *UserApplicationService) PromoteToAdmin(ctx context.Context, cmd *command.PromoteToAdminCommand) (*dto.UserDTO, error) {
+	if err := s.authorizer.Authorize(ctx, assignRolePolicy, cmd.UserID); err != nil {
+		return nil, err
+	}
+
+	userAggregate, err := s.userAggRepo.Load(ctx, cmd.UserID)
+	if err != nil {
+		return nil, errors.Wrap(err, "user not found")
+	}
+
+	if err := userAggregate.PromoteToAdmin(); err != nil {
+		return nil, err
+	}
+
+	if err := s.saveAggregate(ctx, userAggregate); err != nil {
+		return nil, errors.Wrap(err, "failed to save user")
+	}
+
+	result := dto.ToUserDTO(userAggregate.User)
+	return &result, nil
+}
+
+// AssignRole 为用户分配角色，角色是否存在由调用方校验
+func (s *UserApplicationService) AssignRole(ctx context.Context, cmd *command.AssignRoleCommand) (*dto.UserDTO, error) {
+	if err := s.authorizer.Authorize(ctx, assignRolePolicy, cmd.UserID); err != nil {
+		return nil, err
+	}
+
+	userAggregate, err := s.userAggRepo.Load(ctx, cmd.UserID)
+	if err != nil {
+		return nil, errors.Wrap(err, "user not found")
+	}
+
+	if err := userAggregate.AssignRole(entity.UserRole(cmd.Role)); err != nil {
+		return nil, err
+	}
+
+	if err := s.saveAggregate(ctx, userAggregate); err != nil {
+		return nil, errors.Wrap(err, "failed to save user")
+	}
+
+	result := dto.ToUserDTO(userAggregate.User)
+	return &result, nil
+}
+
+// RevokeRole 收回用户角色
+func (s *UserApplicationService) RevokeRole(ctx context.Context, cmd *command.RevokeRoleCommand) (*dto.UserDTO, error) {
+	if err := s.authorizer.Authorize(ctx, assignRolePolicy, cmd.UserID); err != nil {
+		return nil, err
+	}
+
+	userAggregate, err := s.userAggRepo.Load(ctx, cmd.UserID)
+	if err != nil {
+		return nil, errors.Wrap(err, "user not found")
+	}
+
+	if err := userAggregate.RevokeRole(entity.UserRole(cmd.Role)); err != nil {
+		return nil, err
+	}
+
+	if err := s.saveAggregate(ctx, userAggregate); err != nil {
+		return nil, errors.Wrap(err, "failed to save user")
+	}
+
+	result := dto.ToUserDTO(userAggregate.User)
+	return &result, nil
+}
+
+// recordLoginFailure 记录一次密码错误，账户被锁定且用户存在时产生锁定事件
+func (s *UserApplicationService) recordLoginFailure(ctx context.Context, username, ip string) error {
+	lockout, err := s.loginProtection.RecordFailure(ctx, username, ip)
+	if err != nil {
+		return errors.Wrap(err, "failed to record login attempt")
+	}
+	if lockout == nil {
+		return nil
+	}
+
+	// 不存在的用户名同样会被锁定，但没有聚合可以产生事件
+	exists, err := s.userRepo.ExistsByUsername(ctx, username)
+	if err != nil || !exists {
+		return err
+	}
+	user, err := s.userRepo.FindByUsername(ctx, username)
+	if err != nil {
+		return err
+	}
+	userAggregate, err := s.userAggRepo.Load(ctx, user.ID)
+	if err != nil {
+		return errors.Wrap(err, "user not found")
+	}
+
+	if err := userAggregate.LockOut(lockout.Failures, lockout.LockedUntil); err != nil {
+		return err
+	}
+
+	if err := s.saveAggregate(ctx, userAggregate); err != nil {
+		return errors.Wrap(err, "failed to save user")
+	}
+	return nil
+}
+
+// availableUsername 根据候选用户名生成一个未被占用的用户名
+// 只保留字母、数字和 _ . -，被占用时追加随机后缀
+func (s *UserApplicationService) availableUsername(ctx context.Context, candidate string) (string, error) {
+	base := strings.Map(func(r rune) rune {
+		if r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r) || strings.ContainsRune("_.-", r)) {
+			return r
+		}
+		return -1
+	}, candidate)
+	if len(base) > 40 {
+		base = base[:40]
+	}
+	if len(base) < 3 {
+		base = "user"
+	}
+
+	username := base
+	for i := 0; i < 5; i++ {
+		exists, err := s.userRepo.ExistsByUsername(ctx, username)
+		if err != nil {
+			return "", err
+		}
+		if !exists {
+			return username, nil
+		}
+		username = base + "_" + strings.ReplaceAll(uuid.New().String(), "-", "")[:6]
+	}
+	return "", domainservice.ErrUsernameAlreadyExists
+}
+
+// saveAggregate 为新事件附加请求元数据，保存聚合并清空已持久化的领域事件
+func (s *UserApplicationService) saveAggregate(ctx context.Context, userAggregate *aggregate.UserAggregate) error {
+	event.AttachMetadata(userAggregate.GetEvents(), event.MetadataFromContext(ctx))
+
+	if err := s.userAggRepo.Save(ctx, userAggregate); err != nil {
+		return err
+	}
+	userAggregate.ClearEvents()
+	return nil
+}
@@ -0,0 +1,173 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"yiwen/go-ddd/internal/application/command"
+	"yiwen/go-ddd/internal/application/dto"
+	"yiwen/go-ddd/internal/application/query"
+	"yiwen/go-ddd/internal/domain/entity"
+	"yiwen/go-ddd/internal/domain/event"
+	"yiwen/go-ddd/internal/domain/repository"
+	"yiwen/go-ddd/pkg/errors"
+)
+
+var ErrUnknownEventType = errors.New("unknown event type")
+
+// WebhookApplicationService webhook 应用服务
+// 负责端点的管理和投递日志的查询、重放，实际投递由基础设施层完成
+// 端点的管理和投递重放不产生领域事件，由审计服务直接记录
+type WebhookApplicationService struct {
+	webhookRepo  repository.WebhookRepository
+	auditService *AuditApplicationService
+}
+
+// NewWebhookApplicationService 创建 webhook 应用服务
+func NewWebhookApplicationService(webhookRepo repository.WebhookRepository, auditService *AuditApplicationService) *WebhookApplicationService {
+	return &WebhookApplicationService{webhookRepo: webhookRepo, auditService: auditService}
+}
+
+// CreateEndpoint 注册 webhook 端点，返回结果中包含签名密钥
+func (s *WebhookApplicationService) CreateEndpoint(ctx context.Context, cmd *command.CreateWebhookCommand) (*dto.WebhookDTO, error) {
+	if err := validateEventTypes(cmd.EventTypes); err != nil {
+		return nil, err
+	}
+
+	secret := cmd.Secret
+	if secret == "" {
+		var err error
+		if secret, err = generateWebhookSecret(); err != nil {
+			return nil, errors.Wrap(err, "failed to generate secret")
+		}
+	}
+
+	endpoint := entity.NewWebhookEndpoint(cmd.URL, cmd.EventTypes, secret)
+	if err := s.webhookRepo.SaveEndpoint(ctx, endpoint); err != nil {
+		return nil, errors.Wrap(err, "failed to save webhook endpoint")
+	}
+	if err := s.auditEndpoint(ctx, entity.AuditActionWebhookEndpointCreated, endpoint); err != nil {
+		return nil, err
+	}
+
+	result := dto.ToWebhookDTO(endpoint)
+	result.Secret = endpoint.Secret
+	return &result, nil
+}
+
+func (s *WebhookApplicationService) GetEndpoint(ctx context.Context, q *query.GetWebhookQuery) (*dto.WebhookDTO, error) {
+	endpoint, err := s.webhookRepo.FindEndpointByID(ctx, q.EndpointID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := dto.ToWebhookDTO(endpoint)
+	return &result, nil
+}
+
+func (s *WebhookApplicationService) ListEndpoints(ctx context.Context) ([]dto.WebhookDTO, error) {
+	endpoints, err := s.webhookRepo.ListEndpoints(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list webhook endpoints")
+	}
+	return dto.ToWebhookDTOList(endpoints), nil
+}
+
+func (s *WebhookApplicationService) UpdateEndpoint(ctx context.Context, cmd *command.UpdateWebhookCommand) (*dto.WebhookDTO, error) {
+	if err := validateEventTypes(cmd.EventTypes); err != nil {
+		return nil, err
+	}
+
+	endpoint, err := s.webhookRepo.FindEndpointByID(ctx, cmd.EndpointID)
+	if err != nil {
+		return nil, err
+	}
+
+	endpoint.Update(cmd.URL, cmd.EventTypes, cmd.Active)
+	if err := s.webhookRepo.SaveEndpoint(ctx, endpoint); err != nil {
+		return nil, errors.Wrap(err, "failed to save webhook endpoint")
+	}
+	if err := s.auditEndpoint(ctx, entity.AuditActionWebhookEndpointUpdated, endpoint); err != nil {
+		return nil, err
+	}
+
+	result := dto.ToWebhookDTO(endpoint)
+	return &result, nil
+}
+
+func (s *WebhookApplicationService) DeleteEndpoint(ctx context.Context, cmd *command.DeleteWebhookCommand) error {
+	endpoint, err := s.webhookRepo.FindEndpointByID(ctx, cmd.EndpointID)
+	if err != nil {
+		return err
+	}
+	if err := s.webhookRepo.DeleteEndpoint(ctx, cmd.EndpointID); err != nil {
+		return errors.Wrap(err, "failed to delete webhook endpoint")
+	}
+	return s.auditEndpoint(ctx, entity.AuditActionWebhookEndpointDeleted, endpoint)
+}
+
+// ListDeliveries 查询投递日志
+func (s *WebhookApplicationService) ListDeliveries(ctx context.Context, q *query.ListWebhookDeliveriesQuery) (*dto.WebhookDeliveryListDTO, error) {
+	filter := repository.WebhookDeliveryFilter{
+		EndpointID: q.EndpointID,
+		EventType:  q.EventType,
+		Status:     entity.WebhookDeliveryStatus(q.Status),
+	}
+
+	deliveries, total, err := s.webhookRepo.ListDeliveries(ctx, filter, q.Offset, q.Limit)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list webhook deliveries")
+	}
+
+	return &dto.WebhookDeliveryListDTO{
+		Total: total,
+		Items: dto.ToWebhookDeliveryDTOList(deliveries),
+	}, nil
+}
+
+// ReplayDelivery 重新投递，通常用于处理进入死信的记录
+func (s *WebhookApplicationService) ReplayDelivery(ctx context.Context, cmd *command.ReplayWebhookDeliveryCommand) (*dto.WebhookDeliveryDTO, error) {
+	delivery, err := s.webhookRepo.FindDeliveryByID(ctx, cmd.DeliveryID)
+	if err != nil {
+		return nil, err
+	}
+
+	delivery.Replay()
+	if err := s.webhookRepo.SaveDelivery(ctx, delivery); err != nil {
+		return nil, errors.Wrap(err, "failed to save webhook delivery")
+	}
+	detail := fmt.Sprintf("endpoint_id=%d event_type=%s event_id=%s", delivery.EndpointID, delivery.EventType, delivery.EventID)
+	if err := s.auditService.Record(ctx, entity.AuditActionWebhookDeliveryReplayed, strconv.FormatUint(delivery.ID, 10), detail); err != nil {
+		return nil, err
+	}
+
+	result := dto.ToWebhookDeliveryDTO(delivery)
+	return &result, nil
+}
+
+// auditEndpoint 记录端点管理操作，说明中不包含签名密钥
+func (s *WebhookApplicationService) auditEndpoint(ctx context.Context, action string, endpoint *entity.WebhookEndpoint) error {
+	detail := fmt.Sprintf("url=%s event_types=%s active=%t", endpoint.URL, strings.Join(endpoint.EventTypes, ","), endpoint.Active)
+	return s.auditService.Record(ctx, action, strconv.FormatUint(endpoint.ID, 10), detail)
+}
+
+// validateEventTypes 订阅的事件类型必须是已注册的事件或通配符
+func validateEventTypes(eventTypes []string) error {
+	for _, t := range eventTypes {
+		if t != entity.WebhookEventAll && !event.DefaultRegistry.IsRegistered(t) {
+			return errors.Wrapf(ErrUnknownEventType, "%s", t)
+		}
+	}
+	return nil
+}
+
+func generateWebhookSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return "whsec_" + hex.EncodeToString(b), nil
+}
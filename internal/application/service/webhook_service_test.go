@@ -0,0 +1,128 @@
+package service
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"testing"
+	"yiwen/go-ddd/internal/application/command"
+	"yiwen/go-ddd/internal/domain/entity"
+	"yiwen/go-ddd/internal/domain/event"
+	"yiwen/go-ddd/internal/domain/repository"
+)
+
+// memoryWebhookRepository 只实现端点管理和投递重放用到的方法
+type memoryWebhookRepository struct {
+	repository.WebhookRepository
+	endpoints  map[uint64]*entity.WebhookEndpoint
+	deliveries map[uint64]*entity.WebhookDelivery
+	nextID     uint64
+}
+
+func newMemoryWebhookRepository() *memoryWebhookRepository {
+	return &memoryWebhookRepository{
+		endpoints:  make(map[uint64]*entity.WebhookEndpoint),
+		deliveries: make(map[uint64]*entity.WebhookDelivery),
+	}
+}
+
+func (r *memoryWebhookRepository) SaveEndpoint(ctx context.Context, endpoint *entity.WebhookEndpoint) error {
+	if endpoint.ID == 0 {
+		r.nextID++
+		endpoint.ID = r.nextID
+	}
+	r.endpoints[endpoint.ID] = endpoint
+	return nil
+}
+
+func (r *memoryWebhookRepository) FindEndpointByID(ctx context.Context, id uint64) (*entity.WebhookEndpoint, error) {
+	endpoint, ok := r.endpoints[id]
+	if !ok {
+		return nil, repository.ErrWebhookEndpointNotFound
+	}
+	return endpoint, nil
+}
+
+func (r *memoryWebhookRepository) DeleteEndpoint(ctx context.Context, id uint64) error {
+	delete(r.endpoints, id)
+	return nil
+}
+
+func (r *memoryWebhookRepository) SaveDelivery(ctx context.Context, delivery *entity.WebhookDelivery) error {
+	r.deliveries[delivery.ID] = delivery
+	return nil
+}
+
+func (r *memoryWebhookRepository) FindDeliveryByID(ctx context.Context, id uint64) (*entity.WebhookDelivery, error) {
+	delivery, ok := r.deliveries[id]
+	if !ok {
+		return nil, repository.ErrWebhookDeliveryNotFound
+	}
+	return delivery, nil
+}
+
+type memoryAuditLogRepository struct {
+	repository.AuditLogRepository
+	logs []*entity.AuditLog
+}
+
+func (r *memoryAuditLogRepository) Save(ctx context.Context, log *entity.AuditLog) error {
+	r.logs = append(r.logs, log)
+	return nil
+}
+
+func TestWebhookManagementIsAudited(t *testing.T) {
+	webhookRepo := newMemoryWebhookRepository()
+	auditRepo := &memoryAuditLogRepository{}
+	svc := NewWebhookApplicationService(webhookRepo, NewAuditApplicationService(auditRepo))
+
+	meta := event.Metadata{ActorID: 1, ActorName: "admin", IP: "10.0.0.1", UserAgent: "test"}
+	ctx := event.ContextWithMetadata(context.Background(), meta)
+
+	created, err := svc.CreateEndpoint(ctx, &command.CreateWebhookCommand{URL: "https://example.com/hook", EventTypes: []string{"*"}, Secret: "top-secret"})
+	if err != nil {
+		t.Fatalf("create endpoint: %v", err)
+	}
+	if _, err := svc.UpdateEndpoint(ctx, &command.UpdateWebhookCommand{EndpointID: created.ID, URL: "https://example.com/hook2", EventTypes: []string{"*"}, Active: false}); err != nil {
+		t.Fatalf("update endpoint: %v", err)
+	}
+	delivery := entity.NewWebhookDelivery(created.ID, "event-1", event.UserBanned, "{}")
+	delivery.ID = 7
+	delivery.Cancel("webhook endpoint is inactive")
+	webhookRepo.deliveries[delivery.ID] = delivery
+	if _, err := svc.ReplayDelivery(ctx, &command.ReplayWebhookDeliveryCommand{DeliveryID: delivery.ID}); err != nil {
+		t.Fatalf("replay delivery: %v", err)
+	}
+	if err := svc.DeleteEndpoint(ctx, &command.DeleteWebhookCommand{EndpointID: created.ID}); err != nil {
+		t.Fatalf("delete endpoint: %v", err)
+	}
+
+	endpointID := strconv.FormatUint(created.ID, 10)
+	want := []struct {
+		action string
+		target string
+	}{
+		{entity.AuditActionWebhookEndpointCreated, endpointID},
+		{entity.AuditActionWebhookEndpointUpdated, endpointID},
+		{entity.AuditActionWebhookDeliveryReplayed, "7"},
+		{entity.AuditActionWebhookEndpointDeleted, endpointID},
+	}
+	if len(auditRepo.logs) != len(want) {
+		t.Fatalf("got %d audit logs, want %d", len(auditRepo.logs), len(want))
+	}
+	for i, w := range want {
+		log := auditRepo.logs[i]
+		if log.Action != w.action || log.TargetUUID != w.target {
+			t.Errorf("log %d: got %s on %s, want %s on %s", i, log.Action, log.TargetUUID, w.action, w.target)
+		}
+		if log.Source != entity.AuditSourceCommand || log.EventID == "" {
+			t.Errorf("log %d: got source %q and event id %q, want a command entry with an id", i, log.Source, log.EventID)
+		}
+		if log.ActorID != meta.ActorID || log.ActorName != meta.ActorName || log.IP != meta.IP || log.UserAgent != meta.UserAgent {
+			t.Errorf("log %d: metadata not recorded: %+v", i, log)
+		}
+		if strings.Contains(log.Reason, "top-secret") {
+			t.Errorf("log %d: secret leaked into audit log: %s", i, log.Reason)
+		}
+	}
+}
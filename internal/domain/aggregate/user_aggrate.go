@@ -1,11 +1,23 @@
 package aggregate
 
 import (
+	"errors"
+	"fmt"
+	"time"
 	"yiwen/go-ddd/internal/domain/entity"
 	"yiwen/go-ddd/internal/domain/event"
 	"yiwen/go-ddd/internal/domain/valueobject"
 )
 
+// UserAggregateType 用户聚合类型，用于事件存储中区分不同聚合
+const UserAggregateType = "user"
+
+var (
+	ErrAggregateNotRegistered = errors.New("aggregate has no registered event")
+	// ErrUserBanned 用户已被禁用，不能再切换为其他状态
+	ErrUserBanned = errors.New("user is banned")
+)
+
 // UserAggregate 用户聚合根
 // 聚合根是DDD中的核心概念
 // 1. 聚合是一组相关对象的集合
@@ -13,8 +25,9 @@ import (
 // 3. 聚合根负责维护聚合内的一致性
 // 4. 聚合根可以发布领域事件
 type UserAggregate struct {
-	User   *entity.User
-	Events []event.Event
+	User    *entity.User
+	Events  []event.Event
+	Version int // 已持久化的事件版本号，仅事件溯源模式使用
 }
 
 func NewUserAggregate(user *entity.User) *UserAggregate {
@@ -24,66 +37,256 @@ func NewUserAggregate(user *entity.User) *UserAggregate {
 	}
 }
 
-// Register 注册用户
-func Register(uuid, username string, email valueobject.Email, password valueobject.Password) *UserAggregate {
-	user := entity.NewUser(uuid, username, email, password)
-	agg := NewUserAggregate(user)
-	agg.addEvent(event.NewUserRegisteredEvent(uuid, username, email.String()))
+// NewUserAggregateFromHistory 通过重放历史事件重建用户聚合
+// 事件溯源模式下聚合的状态完全由事件决定
+func NewUserAggregateFromHistory(history []event.Event) (*UserAggregate, error) {
+	agg := NewUserAggregate(nil)
+	for _, e := range history {
+		if err := agg.Apply(e); err != nil {
+			return nil, err
+		}
+	}
+	if agg.User == nil {
+		return nil, ErrAggregateNotRegistered
+	}
+	return agg, nil
+}
+
+// Register 注册用户，pendingVerification 为 true 时用户处于未激活状态，验证邮箱后激活
+func Register(uuid, username string, email valueobject.Email, password valueobject.Password, nickname string, pendingVerification bool) (*UserAggregate, error) {
+	agg := NewUserAggregate(nil)
+	if err := agg.raise(event.NewUserRegisteredEvent(uuid, username, email.String(), nickname, password.Hash(), pendingVerification)); err != nil {
+		return nil, err
+	}
 
-	return agg
+	return agg, nil
 }
 
-// UpdateProfile 更新用户资料
-func (a *UserAggregate) UpdateProfile(nickname, avatar string) {
-	oldNickname := a.User.Nickname
-	a.User.UpdateProfile(nickname, avatar)
+// Import 为切换到事件溯源之前已存在的用户生成起始事件，聚合状态与 user 相同
+func Import(user *entity.User) (*UserAggregate, error) {
+	var deletedAt *time.Time
+	if user.IsDeleted() {
+		deletedAt = &user.DeletedAt
+	}
+
+	agg := NewUserAggregate(nil)
+	if err := agg.raise(event.NewUserImportedEvent(user.UUID, user.Username, user.Email.String(), user.Nickname, user.Avatar, user.Password.Hash(), user.EmailVerified, int(user.Status), user.RoleNames(), user.TokenVersion, user.PasswordResetRequired, user.CreatedAt, deletedAt)); err != nil {
+		return nil, err
+	}
+	agg.User.ID = user.ID
 
-	a.addEvent(event.NewUserProfileUpdatedEvent(a.User.UUID, oldNickname, nickname))
+	return agg, nil
 }
 
-// ChangePassword 修改密码
-func (a *UserAggregate) ChangePassword(newPassword valueobject.Password) {
-	a.User.ChangePassword(newPassword)
+// UpdateProfile 更新用户资料
+func (a *UserAggregate) UpdateProfile(nickname, avatar string) error {
+	return a.raise(event.NewUserProfileUpdatedEvent(a.User.UUID, a.User.Nickname, nickname, avatar))
+}
 
-	a.addEvent(event.NewUserPasswordChangedEvent(a.User.UUID))
+// ChangePassword 修改密码
+func (a *UserAggregate) ChangePassword(newPassword valueobject.Password) error {
+	return a.raise(event.NewUserPasswordChangedEvent(a.User.UUID, newPassword.Hash()))
 }
 
 // Activate 激活用户
-func (a *UserAggregate) Activate() {
+func (a *UserAggregate) Activate() error {
 	if a.User.Status == entity.UserStatusActive {
-		return
+		return nil
 	}
 
-	a.User.Activate()
-	a.addEvent(event.NewUserActivatedEvent(a.User.UUID))
+	return a.raise(event.NewUserActivatedEvent(a.User.UUID))
 }
 
-// Deactivate 禁用用户
-func (a *UserAggregate) Deactivate() {
+// Deactivate 将用户设为未激活，被禁用的用户返回 ErrUserBanned，避免禁用状态被覆盖
+func (a *UserAggregate) Deactivate() error {
 	if a.User.Status == entity.UserStatusInactive {
-		return
+		return nil
+	}
+	if a.User.Status == entity.UserStatusBanned {
+		return ErrUserBanned
 	}
 
-	a.User.Deactivate()
-	a.addEvent(event.NewUserDeactivatedEvent(a.User.UUID))
+	return a.raise(event.NewUserDeactivatedEvent(a.User.UUID))
 }
 
-func (a *UserAggregate) Ban(reason string) {
+func (a *UserAggregate) Ban(reason string) error {
 	if a.User.Status == entity.UserStatusBanned {
-		return
+		return nil
 	}
 
-	a.User.Ban()
-	a.addEvent(event.NewUserBannedEvent(a.User.UUID, reason))
+	return a.raise(event.NewUserBannedEvent(a.User.UUID, reason))
 }
 
-func (a *UserAggregate) PromoteToAdmin() {
+func (a *UserAggregate) PromoteToAdmin() error {
 	if a.User.IsAdmin() {
-		return
+		return nil
+	}
+
+	return a.raise(event.NewUserPromotedEvent(a.User.UUID))
+}
+
+// AssignRole 为用户分配角色，已拥有时不产生事件
+func (a *UserAggregate) AssignRole(role entity.UserRole) error {
+	if a.User.HasRole(role) {
+		return nil
+	}
+
+	return a.raise(event.NewUserRoleAssignedEvent(a.User.UUID, string(role)))
+}
+
+// RevokeRole 收回用户角色，未拥有时不产生事件
+func (a *UserAggregate) RevokeRole(role entity.UserRole) error {
+	if !a.User.HasRole(role) {
+		return nil
+	}
+
+	return a.raise(event.NewUserRoleRevokedEvent(a.User.UUID, string(role)))
+}
+
+// VerifyEmail 验证邮箱
+func (a *UserAggregate) VerifyEmail() error {
+	if a.User.EmailVerified {
+		return nil
+	}
+
+	return a.raise(event.NewUserEmailVerifiedEvent(a.User.UUID, a.User.Email.String()))
+}
+
+// ConfirmEmail 用户通过验证邮件确认邮箱
+// 等待验证或验证超时而未激活的用户同时被激活，被禁用的用户不会因此解除禁用
+func (a *UserAggregate) ConfirmEmail() error {
+	if err := a.VerifyEmail(); err != nil {
+		return err
+	}
+	if a.User.Status == entity.UserStatusInactive {
+		return a.Activate()
+	}
+	return nil
+}
+
+// LockOut 连续登录失败次数过多，临时锁定账户
+// 锁定状态保存在登录失败记录中，事件只用于审计和通知
+func (a *UserAggregate) LockOut(failures int, lockedUntil time.Time) error {
+	return a.raise(event.NewUserLockedOutEvent(a.User.UUID, failures, lockedUntil))
+}
+
+// Unlock 管理员解除账户锁定
+func (a *UserAggregate) Unlock() error {
+	return a.raise(event.NewUserUnlockedEvent(a.User.UUID))
+}
+
+// RevokeTokens 撤销用户已签发的全部令牌，例如管理员重置两步验证后
+func (a *UserAggregate) RevokeTokens(reason string) error {
+	return a.raise(event.NewUserTokensRevokedEvent(a.User.UUID, reason))
+}
+
+// Delete 删除用户
+func (a *UserAggregate) Delete(reason string) error {
+	if a.User.IsDeleted() {
+		return nil
+	}
+
+	return a.raise(event.NewUserDeletedEvent(a.User.UUID, reason))
+}
+
+// Apply 应用一个已持久化的历史事件
+// 只改变聚合状态并递增版本号，不会记录为新事件
+func (a *UserAggregate) Apply(e event.Event) error {
+	if err := a.apply(e); err != nil {
+		return err
+	}
+	a.Version++
+	return nil
+}
+
+// raise 应用并记录聚合新产生的事件
+// 命令方法统一通过事件修改状态，保证直接执行和事件重放得到相同的结果
+// 事件无法应用时不记录事件，错误返回给命令的调用方
+func (a *UserAggregate) raise(e event.Event) error {
+	if err := a.apply(e); err != nil {
+		return err
+	}
+	a.addEvent(e)
+	return nil
+}
+
+// apply 根据事件类型修改用户状态
+func (a *UserAggregate) apply(e event.Event) error {
+	switch e.(type) {
+	case *event.UserRegisteredEvent, *event.UserImportedEvent:
+	default:
+		if a.User == nil {
+			return ErrAggregateNotRegistered
+		}
+	}
+
+	switch ev := e.(type) {
+	case *event.UserRegisteredEvent:
+		email, err := valueobject.NewEmail(ev.Email)
+		if err != nil {
+			return err
+		}
+		a.User = entity.NewUser(ev.AggregateID(), ev.UserName, email, valueobject.NewPasswordFromHash(ev.PasswordHash))
+		a.User.Nickname = ev.Nickname
+		a.User.CreatedAt = ev.OccurredAt()
+		if ev.PendingVerification {
+			a.User.Status = entity.UserStatusInactive
+		}
+		a.User.PasswordResetRequired = ev.PasswordResetRequired
+	case *event.UserImportedEvent:
+		email, err := valueobject.NewEmail(ev.Email)
+		if err != nil {
+			return err
+		}
+		a.User = &entity.User{
+			UUID:                  ev.AggregateID(),
+			Username:              ev.UserName,
+			Email:                 email,
+			Password:              valueobject.NewPasswordFromHash(ev.PasswordHash),
+			Nickname:              ev.Nickname,
+			Avatar:                ev.Avatar,
+			EmailVerified:         ev.EmailVerified,
+			Status:                entity.UserStatus(ev.Status),
+			Roles:                 entity.UserRolesFromNames(ev.Roles),
+			TokenVersion:          ev.TokenVersion,
+			PasswordResetRequired: ev.PasswordResetRequired,
+			CreatedAt:             ev.CreatedAt,
+			UpdatedAt:             ev.OccurredAt(),
+		}
+		if ev.DeletedAt != nil {
+			a.User.DeletedAt = *ev.DeletedAt
+		}
+	case *event.UserProfileUpdatedEvent:
+		a.User.UpdateProfile(ev.NewNickname, ev.Avatar)
+	case *event.UserPasswordChangedEvent:
+		a.User.ChangePassword(valueobject.NewPasswordFromHash(ev.PasswordHash))
+		a.User.PasswordResetRequired = ev.PasswordResetRequired
+	case *event.UserActivatedEvent:
+		a.User.Activate()
+	case *event.UserDeactivatedEvent:
+		a.User.Deactivate()
+	case *event.UserBannedEvent:
+		a.User.Ban()
+	case *event.UserPromotedEvent:
+		a.User.PromoteToAdmin()
+	case *event.UserRoleAssignedEvent:
+		a.User.AssignRole(entity.UserRole(ev.Role))
+	case *event.UserRoleRevokedEvent:
+		a.User.RevokeRole(entity.UserRole(ev.Role))
+	case *event.UserEmailVerifiedEvent:
+		a.User.VerifyEmail()
+	case *event.UserDeletedEvent:
+		a.User.Delete(ev.OccurredAt())
+	case *event.UserTokensRevokedEvent:
+		a.User.RevokeTokens()
+	case *event.UserLockedOutEvent, *event.UserUnlockedEvent:
+		// 锁定状态不属于用户实体，由登录失败记录维护
+	default:
+		return fmt.Errorf("unknown event for user aggregate: %s", e.EventName())
 	}
 
-	a.User.PromoteToAdmin()
-	a.addEvent(event.NewUserPromotedEvent(a.User.UUID))
+	a.User.UpdatedAt = e.OccurredAt()
+	return nil
 }
 
 func (a *UserAggregate) addEvent(e event.Event) {
@@ -0,0 +1,127 @@
+package aggregate
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+	"yiwen/go-ddd/internal/domain/entity"
+	"yiwen/go-ddd/internal/domain/event"
+	"yiwen/go-ddd/internal/domain/valueobject"
+
+	"github.com/google/uuid"
+)
+
+// existingUser 切换到事件溯源之前保存在 users 表中的用户
+func existingUser(t *testing.T) *entity.User {
+	t.Helper()
+	email, err := valueobject.NewEmail("admin@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	createdAt := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	return &entity.User{
+		ID:            7,
+		UUID:          uuid.NewString(),
+		Username:      "admin",
+		Email:         email,
+		Password:      valueobject.NewPasswordFromHash("$2a$10$hash"),
+		Nickname:      "Admin",
+		Avatar:        "https://example.com/a.png",
+		EmailVerified: true,
+		Status:        entity.UserStatusBanned,
+		Roles:         []entity.UserRole{entity.UserRoleUser, entity.UserRoleAdmin},
+		TokenVersion:  3,
+		CreatedAt:     createdAt,
+		UpdatedAt:     createdAt.Add(time.Hour),
+	}
+}
+
+// replay 像事件存储一样序列化事件，再从历史重建聚合
+func replay(t *testing.T, events []event.Event) *UserAggregate {
+	t.Helper()
+	history := make([]event.Event, len(events))
+	for i, e := range events {
+		payload, err := event.Encode(e)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if history[i], err = event.Decode(e.EventName(), payload); err != nil {
+			t.Fatal(err)
+		}
+	}
+	agg, err := NewUserAggregateFromHistory(history)
+	if err != nil {
+		t.Fatalf("NewUserAggregateFromHistory: %v", err)
+	}
+	return agg
+}
+
+func TestImportedUserRehydratesToExistingState(t *testing.T) {
+	tests := []struct {
+		name    string
+		prepare func(u *entity.User)
+	}{
+		{name: "banned admin"},
+		{name: "active user without password reset", prepare: func(u *entity.User) {
+			u.Status = entity.UserStatusActive
+			u.Roles = []entity.UserRole{entity.UserRoleUser}
+		}},
+		{name: "user requiring password reset", prepare: func(u *entity.User) {
+			u.Password = valueobject.NewPasswordFromHash("")
+			u.PasswordResetRequired = true
+		}},
+		{name: "deleted user", prepare: func(u *entity.User) {
+			u.Status = entity.UserStatusInactive
+			u.DeletedAt = u.CreatedAt.Add(48 * time.Hour)
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user := existingUser(t)
+			if tt.prepare != nil {
+				tt.prepare(user)
+			}
+
+			imported, err := Import(user)
+			if err != nil {
+				t.Fatalf("Import: %v", err)
+			}
+			if len(imported.GetEvents()) != 1 || imported.GetEvents()[0].EventName() != event.UserImported {
+				t.Fatalf("events = %v, want a single %s event", imported.GetEvents(), event.UserImported)
+			}
+
+			agg := replay(t, imported.GetEvents())
+			if agg.Version != 1 {
+				t.Errorf("Version = %d, want 1", agg.Version)
+			}
+
+			got := *agg.User
+			want := *user
+			// ID 来自 users 表，更新时间为补写时间
+			got.ID, got.UpdatedAt, want.UpdatedAt = want.ID, time.Time{}, time.Time{}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("rehydrated user = %+v\nwant %+v", got, want)
+			}
+		})
+	}
+}
+
+func TestImportedUserAcceptsCommands(t *testing.T) {
+	imported, err := Import(existingUser(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	agg := replay(t, imported.GetEvents())
+
+	if err := agg.RevokeTokens("test"); err != nil {
+		t.Fatalf("RevokeTokens: %v", err)
+	}
+	if agg.User.TokenVersion != 4 {
+		t.Errorf("TokenVersion = %d, want 4", agg.User.TokenVersion)
+	}
+	if err := agg.Deactivate(); !errors.Is(err, ErrUserBanned) {
+		t.Errorf("Deactivate: err = %v, want ErrUserBanned", err)
+	}
+}
@@ -0,0 +1,93 @@
+package aggregate
+
+import (
+	"fmt"
+	"time"
+	"yiwen/go-ddd/internal/domain/entity"
+	"yiwen/go-ddd/internal/domain/event"
+	"yiwen/go-ddd/internal/domain/valueobject"
+)
+
+// UserSnapshotSchemaVersion 快照结构版本
+// 聚合状态结构发生变化时需要递增，旧版本的快照会被忽略并重新生成
+const UserSnapshotSchemaVersion = 6
+
+// UserSnapshot 用户聚合快照
+// 保存聚合在某个版本时的完整状态，重建聚合时只需重放之后的事件
+type UserSnapshot struct {
+	SchemaVersion         int       `json:"schema_version"`
+	Version               int       `json:"version"`
+	UUID                  string    `json:"uuid"`
+	Username              string    `json:"username"`
+	Email                 string    `json:"email"`
+	PasswordHash          string    `json:"password_hash"`
+	Nickname              string    `json:"nickname"`
+	Avatar                string    `json:"avatar"`
+	EmailVerified         bool      `json:"email_verified"`
+	Status                int       `json:"status"`
+	Roles                 []string  `json:"roles"`
+	TokenVersion          int       `json:"token_version"`
+	PasswordResetRequired bool      `json:"password_reset_required"`
+	CreatedAt             time.Time `json:"created_at"`
+	UpdatedAt             time.Time `json:"updated_at"`
+	DeletedAt             time.Time `json:"deleted_at"`
+}
+
+// Snapshot 生成聚合当前已持久化状态的快照
+func (a *UserAggregate) Snapshot() *UserSnapshot {
+	return &UserSnapshot{
+		SchemaVersion:         UserSnapshotSchemaVersion,
+		Version:               a.Version,
+		UUID:                  a.User.UUID,
+		Username:              a.User.Username,
+		Email:                 a.User.Email.String(),
+		PasswordHash:          a.User.Password.Hash(),
+		Nickname:              a.User.Nickname,
+		Avatar:                a.User.Avatar,
+		EmailVerified:         a.User.EmailVerified,
+		Status:                int(a.User.Status),
+		Roles:                 a.User.RoleNames(),
+		TokenVersion:          a.User.TokenVersion,
+		PasswordResetRequired: a.User.PasswordResetRequired,
+		CreatedAt:             a.User.CreatedAt,
+		UpdatedAt:             a.User.UpdatedAt,
+		DeletedAt:             a.User.DeletedAt,
+	}
+}
+
+// NewUserAggregateFromSnapshot 从快照恢复聚合，再重放快照之后的事件
+func NewUserAggregateFromSnapshot(snapshot *UserSnapshot, history []event.Event) (*UserAggregate, error) {
+	if snapshot.SchemaVersion != UserSnapshotSchemaVersion {
+		return nil, fmt.Errorf("unsupported snapshot schema version: %d", snapshot.SchemaVersion)
+	}
+
+	email, err := valueobject.NewEmail(snapshot.Email)
+	if err != nil {
+		return nil, err
+	}
+
+	agg := NewUserAggregate(&entity.User{
+		UUID:                  snapshot.UUID,
+		Username:              snapshot.Username,
+		Email:                 email,
+		Password:              valueobject.NewPasswordFromHash(snapshot.PasswordHash),
+		Nickname:              snapshot.Nickname,
+		Avatar:                snapshot.Avatar,
+		EmailVerified:         snapshot.EmailVerified,
+		Status:                entity.UserStatus(snapshot.Status),
+		Roles:                 entity.UserRolesFromNames(snapshot.Roles),
+		TokenVersion:          snapshot.TokenVersion,
+		PasswordResetRequired: snapshot.PasswordResetRequired,
+		CreatedAt:             snapshot.CreatedAt,
+		UpdatedAt:             snapshot.UpdatedAt,
+		DeletedAt:             snapshot.DeletedAt,
+	})
+	agg.Version = snapshot.Version
+
+	for _, e := range history {
+		if err := agg.Apply(e); err != nil {
+			return nil, err
+		}
+	}
+	return agg, nil
+}
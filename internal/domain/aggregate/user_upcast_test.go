@@ -0,0 +1,61 @@
+package aggregate
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+	"yiwen/go-ddd/internal/domain/event"
+	"yiwen/go-ddd/internal/domain/valueobject"
+)
+
+// v1Registered 引入信封和密码哈希之前写入的注册事件
+func v1Registered(t *testing.T, aggregateID string) event.Event {
+	t.Helper()
+	raw, err := json.Marshal(map[string]interface{}{
+		"event_id":     "event-1",
+		"name":         "UserRegistered",
+		"occurred_at":  time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		"aggregate_id": aggregateID,
+		"user_name":    "alice",
+		"email":        "alice@example.com",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	e, err := event.Decode("UserRegistered", raw)
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	return e
+}
+
+func TestV1RegisteredUserRequiresPasswordReset(t *testing.T) {
+	registered := v1Registered(t, "user-1")
+
+	agg, err := NewUserAggregateFromHistory([]event.Event{registered})
+	if err != nil {
+		t.Fatalf("NewUserAggregateFromHistory: %v", err)
+	}
+	if !agg.User.PasswordResetRequired {
+		t.Fatal("user rehydrated from a v1 registration without a hash does not require a password reset")
+	}
+	if agg.User.Password.Hash() != "" {
+		t.Errorf("got password hash %q, want empty", agg.User.Password.Hash())
+	}
+
+	// 通过找回密码设置新密码后清除标记，重建后同样如此
+	if err := agg.ChangePassword(valueobject.NewPasswordFromHash("$2a$10$hash")); err != nil {
+		t.Fatalf("ChangePassword: %v", err)
+	}
+	if agg.User.PasswordResetRequired {
+		t.Error("password reset still required after changing the password")
+	}
+
+	rehydrated := replay(t, append([]event.Event{registered}, agg.GetEvents()...))
+	if rehydrated.User.PasswordResetRequired {
+		t.Error("password reset still required after replaying the password change")
+	}
+	if rehydrated.User.Password.Hash() != "$2a$10$hash" {
+		t.Errorf("got password hash %q, want the new hash", rehydrated.User.Password.Hash())
+	}
+}
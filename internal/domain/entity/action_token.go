@@ -0,0 +1,37 @@
+package entity
+
+import "time"
+
+// 一次性操作令牌的用途
+const (
+	ActionTokenEmailVerification = "email_verification" // 验证邮箱
+	ActionTokenPasswordReset     = "password_reset"     // 重置密码
+)
+
+// ActionToken 通过邮件发给用户的一次性操作令牌
+// 令牌本身经过签名并带有过期时间，服务端只登记令牌ID，使用时取出删除，保证只能使用一次
+// 同一用户同一用途只保留最新的一个令牌，重新发送后之前的令牌失效
+type ActionToken struct {
+	ID        uint64    // 数据库自增ID
+	TokenID   string    // 令牌ID，即签名令牌中的 jti
+	UserID    uint64    // 所属用户
+	Purpose   string    // 用途
+	Email     string    // 发送令牌时用户的邮箱
+	ExpiresAt time.Time // 过期时间
+	CreatedAt time.Time // 创建时间，即发送时间
+}
+
+func NewActionToken(tokenID string, userID uint64, purpose, email string, expiresAt time.Time) *ActionToken {
+	return &ActionToken{
+		TokenID:   tokenID,
+		UserID:    userID,
+		Purpose:   purpose,
+		Email:     email,
+		ExpiresAt: expiresAt,
+		CreatedAt: time.Now(),
+	}
+}
+
+func (t *ActionToken) IsExpired() bool {
+	return time.Now().After(t.ExpiresAt)
+}
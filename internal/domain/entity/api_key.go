@@ -0,0 +1,40 @@
+package entity
+
+import "time"
+
+// APIKeyPrefix API 密钥的固定前缀，认证中间件据此区分 API 密钥和 JWT
+const APIKeyPrefix = "gdk_"
+
+// APIKeyDisplayLength 创建之后展示的密钥前缀长度，便于用户辨认是哪一个密钥
+const APIKeyDisplayLength = 12
+
+// APIKey 用户创建的个人访问令牌，供脚本和集成代替密码登录
+// 1. 密钥只在创建时返回一次，数据库保存哈希和用于展示的前缀
+// 2. 密钥只拥有创建时选择的权限范围，可以设置过期时间
+type APIKey struct {
+	ID         uint64     // 数据库自增ID
+	UserID     uint64     // 所属用户
+	Name       string     // 用户为密钥起的名称
+	Prefix     string     // 密钥前缀，仅用于展示
+	KeyHash    string     // 密钥的 SHA-256 哈希
+	Scopes     []string   // 权限范围
+	ExpiresAt  *time.Time // 过期时间，为空表示不过期
+	LastUsedAt *time.Time // 最近一次使用时间
+	CreatedAt  time.Time  // 创建时间
+}
+
+func NewAPIKey(userID uint64, name, prefix, keyHash string, scopes []string, expiresAt *time.Time) *APIKey {
+	return &APIKey{
+		UserID:    userID,
+		Name:      name,
+		Prefix:    prefix,
+		KeyHash:   keyHash,
+		Scopes:    scopes,
+		ExpiresAt: expiresAt,
+		CreatedAt: time.Now(),
+	}
+}
+
+func (k *APIKey) IsExpired() bool {
+	return k.ExpiresAt != nil && time.Now().After(*k.ExpiresAt)
+}
@@ -0,0 +1,38 @@
+package entity
+
+import "time"
+
+// AuditSource 审计日志来源
+type AuditSource string
+
+const (
+	AuditSourceEvent   AuditSource = "event"   // 由领域事件投影生成，可从事件存储重建
+	AuditSourceCommand AuditSource = "command" // 由管理命令直接写入，没有对应的事件，重建投影时保留
+)
+
+// 不产生领域事件的管理命令
+const (
+	AuditActionRoleCreated             = "role.created"
+	AuditActionRoleUpdated             = "role.updated"
+	AuditActionRoleDeleted             = "role.deleted"
+	AuditActionWebhookEndpointCreated  = "webhook.endpoint_created"
+	AuditActionWebhookEndpointUpdated  = "webhook.endpoint_updated"
+	AuditActionWebhookEndpointDeleted  = "webhook.endpoint_deleted"
+	AuditActionWebhookDeliveryReplayed = "webhook.delivery_replayed"
+)
+
+// AuditLog 审计日志
+// 记录谁在什么时候对哪个对象做了什么操作，用户相关的操作由领域事件投影而来，角色和 webhook 的管理操作由应用服务直接写入
+type AuditLog struct {
+	ID         uint64
+	EventID    string      // 来源事件ID，用于保证投影幂等；管理命令为随机生成的ID
+	Source     AuditSource // 来源
+	ActorID    uint64      // 操作人ID，匿名操作为 0
+	ActorName  string      // 操作人用户名
+	TargetUUID string      // 被操作的对象，用户为UUID，角色、webhook 端点和投递记录为ID
+	Action     string      // 操作，即事件类型或管理命令
+	Reason     string      // 操作原因，例如禁用原因；管理命令为操作对象的说明
+	IP         string      // 请求来源IP
+	UserAgent  string      // 请求来源 User-Agent
+	OccurredAt time.Time   // 操作时间
+}
@@ -0,0 +1,60 @@
+package entity
+
+import "time"
+
+// ExternalIdentity 外部身份，把外部身份提供方中的用户关联到本地用户
+// 同一提供方中的同一个 subject 只能关联一个本地用户，一个本地用户可以关联多个外部身份
+type ExternalIdentity struct {
+	ID          uint64    // 数据库自增ID
+	UserID      uint64    // 关联的本地用户
+	Provider    string    // 提供方标识
+	Subject     string    // 提供方内的用户唯一标识
+	Email       string    // 最近一次登录时提供方返回的邮箱，仅作记录
+	CreatedAt   time.Time // 关联时间
+	LastLoginAt time.Time // 最近一次通过该身份登录的时间
+}
+
+func NewExternalIdentity(userID uint64, provider, subject, email string) *ExternalIdentity {
+	now := time.Now()
+	return &ExternalIdentity{
+		UserID:      userID,
+		Provider:    provider,
+		Subject:     subject,
+		Email:       email,
+		CreatedAt:   now,
+		LastLoginAt: now,
+	}
+}
+
+// RecordLogin 记录一次登录
+func (i *ExternalIdentity) RecordLogin(email string) {
+	i.Email = email
+	i.LastLoginAt = time.Now()
+}
+
+// ExternalLoginState 外部登录的流程状态
+// 跳转到提供方之前生成，回调时根据 state 取出并删除，保证每个 state 只能使用一次
+type ExternalLoginState struct {
+	ID           uint64    // 数据库自增ID
+	StateHash    string    // state 参数的 SHA-256 哈希
+	Provider     string    // 发起登录的提供方
+	Nonce        string    // 写入 ID Token 的 nonce，回调时校验
+	CodeVerifier string    // PKCE code_verifier
+	ExpiresAt    time.Time // 过期时间
+	CreatedAt    time.Time // 创建时间
+}
+
+func NewExternalLoginState(stateHash, provider, nonce, codeVerifier string, expiresAt time.Time) *ExternalLoginState {
+	return &ExternalLoginState{
+		StateHash:    stateHash,
+		Provider:     provider,
+		Nonce:        nonce,
+		CodeVerifier: codeVerifier,
+		ExpiresAt:    expiresAt,
+		CreatedAt:    time.Now(),
+	}
+}
+
+func (s *ExternalLoginState) IsExpired() bool {
+	return time.Now().After(s.ExpiresAt)
+}
@@ -0,0 +1,25 @@
+package entity
+
+import "time"
+
+// LoginAttempt 一个账户或一个IP的连续登录失败记录
+// 失败次数在 LastFailedAt 之后的统计窗口内有效，登录成功、锁定或管理员解锁后重新计数
+type LoginAttempt struct {
+	Key          string     // 统计对象，账户为 account:用户名，IP 为 ip:地址
+	Failures     int        // 连续失败次数
+	LastFailedAt time.Time  // 最近一次失败时间
+	LockedUntil  *time.Time // 锁定到期时间，为空表示未锁定
+}
+
+// IsLocked 是否处于锁定中
+func (a *LoginAttempt) IsLocked(now time.Time) bool {
+	return a.LockedUntil != nil && now.Before(*a.LockedUntil)
+}
+
+// FailuresWithin 统计窗口内的失败次数，距离最近一次失败超过窗口时视为 0
+func (a *LoginAttempt) FailuresWithin(window time.Duration, now time.Time) int {
+	if now.Sub(a.LastFailedAt) > window {
+		return 0
+	}
+	return a.Failures
+}
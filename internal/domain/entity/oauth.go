@@ -0,0 +1,143 @@
+package entity
+
+import (
+	"slices"
+	"time"
+)
+
+// OAuth2 授权类型
+const (
+	GrantTypeAuthorizationCode = "authorization_code"
+	GrantTypeRefreshToken      = "refresh_token"
+	GrantTypeClientCredentials = "client_credentials"
+)
+
+// OAuthClient OAuth2 客户端
+// 1. 机密客户端（服务端应用）持有客户端密钥，公共客户端（移动端、单页应用）没有密钥，必须使用 PKCE
+// 2. 回调地址必须与注册的地址完全一致
+// 3. 客户端只能申请注册时允许的权限范围和授权类型
+type OAuthClient struct {
+	ID           uint64    // 数据库自增ID
+	ClientID     string    // 客户端标识
+	SecretHash   string    // 客户端密钥的 SHA-256 哈希，公共客户端为空
+	Name         string    // 展示给用户的名称
+	RedirectURIs []string  // 允许的回调地址
+	Scopes       []string  // 允许申请的权限范围
+	GrantTypes   []string  // 允许的授权类型
+	CreatedAt    time.Time // 创建时间
+	UpdatedAt    time.Time // 更新时间
+}
+
+func NewOAuthClient(clientID, secretHash, name string, redirectURIs, scopes, grantTypes []string) *OAuthClient {
+	return &OAuthClient{
+		ClientID:     clientID,
+		SecretHash:   secretHash,
+		Name:         name,
+		RedirectURIs: redirectURIs,
+		Scopes:       scopes,
+		GrantTypes:   grantTypes,
+		CreatedAt:    time.Now(),
+		UpdatedAt:    time.Now(),
+	}
+}
+
+// IsConfidential 是否为机密客户端
+func (c *OAuthClient) IsConfidential() bool {
+	return c.SecretHash != ""
+}
+
+func (c *OAuthClient) AllowsRedirectURI(uri string) bool {
+	return slices.Contains(c.RedirectURIs, uri)
+}
+
+func (c *OAuthClient) AllowsGrantType(grantType string) bool {
+	return slices.Contains(c.GrantTypes, grantType)
+}
+
+// AllowsScopes 判断申请的权限范围是否全部在允许范围内
+func (c *OAuthClient) AllowsScopes(scopes []string) bool {
+	for _, scope := range scopes {
+		if !slices.Contains(c.Scopes, scope) {
+			return false
+		}
+	}
+	return true
+}
+
+// AuthorizationCode 授权码
+// 授权码只能使用一次，有效期很短；只保存哈希，PKCE 的 code_challenge 随授权码保存，换取令牌时校验
+type AuthorizationCode struct {
+	ID            uint64     // 数据库自增ID
+	CodeHash      string     // 授权码的 SHA-256 哈希
+	ClientID      string     // 客户端标识
+	UserID        uint64     // 授权用户
+	RedirectURI   string     // 授权请求中的 redirect_uri 参数，换取令牌时必须一致
+	Scopes        []string   // 授权的权限范围
+	CodeChallenge string     // PKCE S256 code_challenge，为空表示未使用 PKCE
+	Nonce         string     // OIDC nonce
+	ExpiresAt     time.Time  // 过期时间
+	UsedAt        *time.Time // 使用时间
+	CreatedAt     time.Time  // 创建时间
+}
+
+func NewAuthorizationCode(codeHash, clientID string, userID uint64, redirectURI string, scopes []string, codeChallenge, nonce string, expiresAt time.Time) *AuthorizationCode {
+	return &AuthorizationCode{
+		CodeHash:      codeHash,
+		ClientID:      clientID,
+		UserID:        userID,
+		RedirectURI:   redirectURI,
+		Scopes:        scopes,
+		CodeChallenge: codeChallenge,
+		Nonce:         nonce,
+		ExpiresAt:     expiresAt,
+		CreatedAt:     time.Now(),
+	}
+}
+
+func (c *AuthorizationCode) IsUsed() bool {
+	return c.UsedAt != nil
+}
+
+func (c *AuthorizationCode) IsExpired() bool {
+	return time.Now().After(c.ExpiresAt)
+}
+
+// OAuthConsent 用户对客户端的授权同意记录
+// 用户同意过的权限范围再次申请时不再询问
+type OAuthConsent struct {
+	ID        uint64    // 数据库自增ID
+	UserID    uint64    // 用户
+	ClientID  string    // 客户端标识
+	Scopes    []string  // 已同意的权限范围
+	CreatedAt time.Time // 创建时间
+	UpdatedAt time.Time // 更新时间
+}
+
+func NewOAuthConsent(userID uint64, clientID string) *OAuthConsent {
+	return &OAuthConsent{
+		UserID:    userID,
+		ClientID:  clientID,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+}
+
+// Covers 判断是否已经同意了全部权限范围
+func (c *OAuthConsent) Covers(scopes []string) bool {
+	for _, scope := range scopes {
+		if !slices.Contains(c.Scopes, scope) {
+			return false
+		}
+	}
+	return true
+}
+
+// Grant 追加同意的权限范围
+func (c *OAuthConsent) Grant(scopes []string) {
+	for _, scope := range scopes {
+		if !slices.Contains(c.Scopes, scope) {
+			c.Scopes = append(c.Scopes, scope)
+		}
+	}
+	c.UpdatedAt = time.Now()
+}
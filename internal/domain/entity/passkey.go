@@ -0,0 +1,67 @@
+package entity
+
+import "time"
+
+// Passkey 用户注册的通行密钥（WebAuthn 凭证）
+// 私钥保存在用户的认证器中，服务端只保存公钥和签名计数
+type Passkey struct {
+	ID           uint64     // 数据库自增ID
+	UserID       uint64     // 所属用户
+	CredentialID []byte     // 认证器生成的凭证ID
+	PublicKey    []byte     // COSE 编码的凭证公钥
+	SignCount    uint32     // 最近一次登录时认证器返回的签名计数
+	Name         string     // 用户为通行密钥起的名称
+	CreatedAt    time.Time  // 创建时间
+	LastUsedAt   *time.Time // 最近一次登录时间
+}
+
+func NewPasskey(userID uint64, credentialID, publicKey []byte, signCount uint32, name string) *Passkey {
+	return &Passkey{
+		UserID:       userID,
+		CredentialID: credentialID,
+		PublicKey:    publicKey,
+		SignCount:    signCount,
+		Name:         name,
+		CreatedAt:    time.Now(),
+	}
+}
+
+// IsCloned 判断认证器返回的签名计数是否说明凭证被克隆
+// 计数必须严格递增，不支持计数的认证器始终返回 0
+func (p *Passkey) IsCloned(signCount uint32) bool {
+	if signCount == 0 && p.SignCount == 0 {
+		return false
+	}
+	return signCount <= p.SignCount
+}
+
+// WebAuthn 仪式类型
+const (
+	WebAuthnCeremonyRegistration   = "registration"
+	WebAuthnCeremonyAuthentication = "authentication"
+)
+
+// WebAuthnSession 进行中的注册或登录仪式
+// 挑战值本身返回给浏览器，服务端只保存哈希，浏览器签名的客户端数据中带有挑战值
+type WebAuthnSession struct {
+	ID            uint64    // 数据库自增ID
+	ChallengeHash string    // 挑战值的 SHA-256 哈希
+	UserID        uint64    // 注册时为当前用户，登录时为 0，由通行密钥确定用户
+	Ceremony      string    // 仪式类型
+	ExpiresAt     time.Time // 过期时间
+	CreatedAt     time.Time // 创建时间
+}
+
+func NewWebAuthnSession(challengeHash string, userID uint64, ceremony string, expiresAt time.Time) *WebAuthnSession {
+	return &WebAuthnSession{
+		ChallengeHash: challengeHash,
+		UserID:        userID,
+		Ceremony:      ceremony,
+		ExpiresAt:     expiresAt,
+		CreatedAt:     time.Now(),
+	}
+}
+
+func (s *WebAuthnSession) IsExpired() bool {
+	return time.Now().After(s.ExpiresAt)
+}
@@ -0,0 +1,70 @@
+package entity
+
+// PermissionAll 拥有全部权限
+const PermissionAll = "*"
+
+// 权限名称，统一使用 "资源:操作" 的格式
+const (
+	PermissionProfileRead    = "profile:read"    // 查看自己的资料
+	PermissionProfileUpdate  = "profile:update"  // 修改自己的资料
+	PermissionPasswordChange = "password:change" // 修改自己的密码
+	PermissionAccountDelete  = "account:delete"  // 注销自己的账户
+
+	PermissionUsersRead           = "users:read"            // 查看任意用户
+	PermissionUsersUpdate         = "users:update"          // 修改任意用户的资料
+	PermissionUsersDelete         = "users:delete"          // 删除用户
+	PermissionUsersBan            = "users:ban"             // 禁用用户
+	PermissionUsersUnlock         = "users:unlock"          // 解除登录锁定
+	PermissionUsersReset2FA       = "users:reset_2fa"       // 重置两步验证
+	PermissionUsersRevokeSessions = "users:revoke_sessions" // 结束用户的全部会话
+
+	PermissionRolesRead   = "roles:read"   // 查看角色
+	PermissionRolesManage = "roles:manage" // 创建、修改、删除角色
+	PermissionRolesAssign = "roles:assign" // 为用户分配和收回角色
+
+	PermissionWebhooksManage     = "webhooks:manage"      // 管理 webhook
+	PermissionEventsStream       = "events:stream"        // 订阅事件流
+	PermissionAuditRead          = "audit:read"           // 查看和导出审计日志
+	PermissionOAuthClientsManage = "oauth_clients:manage" // 管理 OAuth2 客户端
+)
+
+// Permission 系统定义的权限
+type Permission struct {
+	Name        string
+	Description string
+}
+
+// Permissions 系统定义的全部权限，角色只能包含这些权限或 PermissionAll
+var Permissions = []Permission{
+	{PermissionProfileRead, "查看自己的资料"},
+	{PermissionProfileUpdate, "修改自己的资料"},
+	{PermissionPasswordChange, "修改自己的密码"},
+	{PermissionAccountDelete, "注销自己的账户"},
+	{PermissionUsersRead, "查看任意用户"},
+	{PermissionUsersUpdate, "修改任意用户的资料"},
+	{PermissionUsersDelete, "删除用户"},
+	{PermissionUsersBan, "禁用用户"},
+	{PermissionUsersUnlock, "解除登录锁定"},
+	{PermissionUsersReset2FA, "重置两步验证"},
+	{PermissionUsersRevokeSessions, "结束用户的全部会话"},
+	{PermissionRolesRead, "查看角色"},
+	{PermissionRolesManage, "创建、修改、删除角色"},
+	{PermissionRolesAssign, "为用户分配和收回角色"},
+	{PermissionWebhooksManage, "管理 webhook"},
+	{PermissionEventsStream, "订阅事件流"},
+	{PermissionAuditRead, "查看和导出审计日志"},
+	{PermissionOAuthClientsManage, "管理 OAuth2 客户端"},
+}
+
+// IsKnownPermission 判断是否为系统定义的权限
+func IsKnownPermission(name string) bool {
+	if name == PermissionAll {
+		return true
+	}
+	for _, p := range Permissions {
+		if p.Name == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,46 @@
+package entity
+
+import "time"
+
+// RefreshToken 刷新令牌实体
+// 1. 令牌本身不落库，只保存哈希
+// 2. 每次刷新都会使用旧令牌换取同一家族（FamilyID）中的新令牌
+// 3. 已使用过的令牌再次出现说明令牌可能被盗用，整个家族都会被撤销
+type RefreshToken struct {
+	ID           uint64     // 数据库自增ID
+	UserID       uint64     // 所属用户
+	ClientID     string     // 通过 OAuth2 签发时的客户端标识，自身登录签发时为空
+	Scope        string     // 通过 OAuth2 签发时授权的权限范围，刷新后保持不变
+	FamilyID     string     // 令牌家族，同一次登录轮换出的令牌属于同一家族
+	TokenHash    string     // 令牌的 SHA-256 哈希
+	TokenVersion int        // 签发时用户的令牌版本，与用户当前版本不一致时令牌失效
+	ExpiresAt    time.Time  // 过期时间
+	UsedAt       *time.Time // 轮换时间，不为空表示已经换取过新令牌
+	RevokedAt    *time.Time // 撤销时间
+	CreatedAt    time.Time  // 创建时间
+}
+
+func NewRefreshToken(userID uint64, clientID, scope, familyID, tokenHash string, tokenVersion int, expiresAt time.Time) *RefreshToken {
+	return &RefreshToken{
+		UserID:       userID,
+		ClientID:     clientID,
+		Scope:        scope,
+		FamilyID:     familyID,
+		TokenHash:    tokenHash,
+		TokenVersion: tokenVersion,
+		ExpiresAt:    expiresAt,
+		CreatedAt:    time.Now(),
+	}
+}
+
+func (t *RefreshToken) IsUsed() bool {
+	return t.UsedAt != nil
+}
+
+func (t *RefreshToken) IsRevoked() bool {
+	return t.RevokedAt != nil
+}
+
+func (t *RefreshToken) IsExpired() bool {
+	return time.Now().After(t.ExpiresAt)
+}
@@ -0,0 +1,48 @@
+package entity
+
+import (
+	"slices"
+	"time"
+)
+
+// Role 角色实体
+// 1. 角色包含一组权限，用户可以拥有多个角色，权限为全部角色权限的并集
+// 2. 角色可以指定父角色，继承父角色（及其祖先角色）的全部权限
+// 3. 角色名称分配给用户后作为标识使用，创建后不能修改
+type Role struct {
+	ID          uint64    // 数据库自增ID
+	Name        string    // 角色名称
+	Description string    // 说明
+	ParentID    uint64    // 父角色，0 表示不继承
+	Permissions []string  // 角色自身的权限，不包括继承的权限
+	CreatedAt   time.Time // 创建时间
+	UpdatedAt   time.Time // 更新时间
+}
+
+func NewRole(name, description string, parentID uint64, permissions []string) *Role {
+	return &Role{
+		Name:        name,
+		Description: description,
+		ParentID:    parentID,
+		Permissions: permissions,
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+	}
+}
+
+// IsBuiltin 内置角色，不能删除
+func (r *Role) IsBuiltin() bool {
+	return r.Name == string(UserRoleUser) || r.Name == string(UserRoleAdmin)
+}
+
+// HasPermission 判断角色自身是否包含指定权限
+func (r *Role) HasPermission(permission string) bool {
+	return slices.Contains(r.Permissions, PermissionAll) || slices.Contains(r.Permissions, permission)
+}
+
+func (r *Role) Update(description string, parentID uint64, permissions []string) {
+	r.Description = description
+	r.ParentID = parentID
+	r.Permissions = permissions
+	r.UpdatedAt = time.Now()
+}
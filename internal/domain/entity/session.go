@@ -0,0 +1,37 @@
+package entity
+
+import "time"
+
+// Session 用户的一次登录
+// 1. 每次登录开始一个新的刷新令牌家族，会话ID与令牌家族ID相同
+// 2. 访问令牌通过 sid 声明关联会话，会话结束后该会话的访问令牌和刷新令牌立即失效
+// 3. 刷新令牌时更新会话的过期时间，访问令牌的使用会更新最近活跃时间
+type Session struct {
+	ID         uint64    // 数据库自增ID
+	SessionID  string    // 会话ID，即刷新令牌家族ID
+	UserID     uint64    // 所属用户
+	Device     string    // 根据 User-Agent 识别的设备描述
+	UserAgent  string    // 登录时的 User-Agent
+	IP         string    // 最近一次刷新令牌时的客户端IP
+	ExpiresAt  time.Time // 过期时间，与最新的刷新令牌一致
+	LastSeenAt time.Time // 最近活跃时间
+	CreatedAt  time.Time // 登录时间
+}
+
+func NewSession(sessionID string, userID uint64, device, userAgent, ip string, expiresAt time.Time) *Session {
+	now := time.Now()
+	return &Session{
+		SessionID:  sessionID,
+		UserID:     userID,
+		Device:     device,
+		UserAgent:  userAgent,
+		IP:         ip,
+		ExpiresAt:  expiresAt,
+		LastSeenAt: now,
+		CreatedAt:  now,
+	}
+}
+
+func (s *Session) IsExpired() bool {
+	return time.Now().After(s.ExpiresAt)
+}
@@ -0,0 +1,63 @@
+package entity
+
+import "time"
+
+// TwoFactor 用户的 TOTP 两步验证
+// 1. 设置时生成密钥，用户用认证器应用扫描后提交第一个验证码确认，确认之前不生效
+// 2. 每个时间步的验证码只能使用一次，LastUsedStep 记录最近使用的时间步
+type TwoFactor struct {
+	ID           uint64     // 数据库自增ID
+	UserID       uint64     // 所属用户
+	Secret       string     // base32 编码的 TOTP 密钥
+	ConfirmedAt  *time.Time // 确认时间，不为空表示已开启
+	LastUsedStep int64      // 最近一次使用的验证码时间步
+	CreatedAt    time.Time  // 创建时间
+	UpdatedAt    time.Time  // 更新时间
+}
+
+func NewTwoFactor(userID uint64, secret string) *TwoFactor {
+	now := time.Now()
+	return &TwoFactor{
+		UserID:    userID,
+		Secret:    secret,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
+
+// IsEnabled 是否已经确认开启
+func (t *TwoFactor) IsEnabled() bool {
+	return t.ConfirmedAt != nil
+}
+
+// Confirm 用户提交第一个正确的验证码后开启两步验证
+func (t *TwoFactor) Confirm(step int64) {
+	now := time.Now()
+	t.ConfirmedAt = &now
+	t.LastUsedStep = step
+	t.UpdatedAt = now
+}
+
+// TwoFactorChallenge 密码验证通过、等待两步验证的登录
+// 客户端持有的 MFA 令牌不落库，只保存哈希；验证失败次数过多后作废，需要重新输入密码
+type TwoFactorChallenge struct {
+	ID        uint64    // 数据库自增ID
+	TokenHash string    // MFA 令牌的 SHA-256 哈希
+	UserID    uint64    // 待登录的用户
+	Attempts  int       // 已失败的验证次数
+	ExpiresAt time.Time // 过期时间
+	CreatedAt time.Time // 创建时间
+}
+
+func NewTwoFactorChallenge(tokenHash string, userID uint64, expiresAt time.Time) *TwoFactorChallenge {
+	return &TwoFactorChallenge{
+		TokenHash: tokenHash,
+		UserID:    userID,
+		ExpiresAt: expiresAt,
+		CreatedAt: time.Now(),
+	}
+}
+
+func (c *TwoFactorChallenge) IsExpired() bool {
+	return time.Now().After(c.ExpiresAt)
+}
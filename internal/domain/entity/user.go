@@ -1,6 +1,7 @@
 package entity
 
 import (
+	"slices"
 	"time"
 	"yiwen/go-ddd/internal/domain/valueobject"
 )
@@ -13,29 +14,44 @@ const (
 	UserStatusBanned   UserStatus = 3 // 禁用
 )
 
+// UserRole 角色名称，对应 roles 表中的角色
 type UserRole string
 
+// 内置角色，新注册的用户默认拥有 UserRoleUser
 const (
 	UserRoleUser  UserRole = "user"
 	UserRoleAdmin UserRole = "admin"
 )
 
+// UserRolesFromNames 将角色名称列表转换为角色
+func UserRolesFromNames(names []string) []UserRole {
+	roles := make([]UserRole, len(names))
+	for i, name := range names {
+		roles[i] = UserRole(name)
+	}
+	return roles
+}
+
 // user 用户实体
 // 实体是ddd中的核心概念 具有唯一标识 id
 // 实体的相等性由id决定 而不是属性
 type User struct {
-	ID        uint64               // 数据库自增ID
-	UUID      string               // 业务唯一标识
-	Username  string               // 用户名
-	Email     valueobject.Email    // 邮箱
-	Password  valueobject.Password //密码
-	Nickname  string               // 昵称
-	Avatar    string               // 头像
-	Status    UserStatus           // 状态
-	Role      UserRole             // 角色
-	CreatedAt time.Time            // 创建时间
-	UpdatedAt time.Time            // 更新时间
-	DeletedAt time.Time            // 删除时间
+	ID            uint64               // 数据库自增ID
+	UUID          string               // 业务唯一标识
+	Username      string               // 用户名
+	Email         valueobject.Email    // 邮箱
+	Password      valueobject.Password //密码
+	Nickname      string               // 昵称
+	Avatar        string               // 头像
+	EmailVerified bool                 // 邮箱是否已验证
+	Status        UserStatus           // 状态
+	Roles         []UserRole           // 角色
+	TokenVersion  int                  // 令牌版本，递增后之前签发的令牌全部失效
+	// PasswordResetRequired 密码哈希在旧版本事件中丢失，用户需要通过找回密码重新设置，设置新密码后清除
+	PasswordResetRequired bool
+	CreatedAt             time.Time // 创建时间
+	UpdatedAt             time.Time // 更新时间
+	DeletedAt             time.Time // 删除时间
 }
 
 func NewUser(uuid string, username string, email valueobject.Email, password valueobject.Password) *User {
@@ -45,7 +61,7 @@ func NewUser(uuid string, username string, email valueobject.Email, password val
 		Email:     email,
 		Password:  password,
 		Status:    UserStatusActive,
-		Role:      UserRoleUser,
+		Roles:     []UserRole{UserRoleUser},
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
 	}
@@ -56,7 +72,20 @@ func (u *User) IsActive() bool {
 }
 
 func (u *User) IsAdmin() bool {
-	return u.Role == UserRoleAdmin
+	return u.HasRole(UserRoleAdmin)
+}
+
+func (u *User) HasRole(role UserRole) bool {
+	return slices.Contains(u.Roles, role)
+}
+
+// RoleNames 角色名称列表
+func (u *User) RoleNames() []string {
+	names := make([]string, len(u.Roles))
+	for i, role := range u.Roles {
+		names[i] = string(role)
+	}
+	return names
 }
 
 func (u *User) UpdateProfile(nickname, avatar string) {
@@ -67,6 +96,8 @@ func (u *User) UpdateProfile(nickname, avatar string) {
 
 func (u *User) ChangePassword(newPassword valueobject.Password) {
 	u.Password = newPassword
+	u.PasswordResetRequired = false
+	u.RevokeTokens()
 	u.UpdatedAt = time.Now()
 }
 
@@ -77,15 +108,51 @@ func (u *User) Activate() {
 
 func (u *User) Deactivate() {
 	u.Status = UserStatusInactive
+	u.RevokeTokens()
 	u.UpdatedAt = time.Now()
 }
 
 func (u *User) Ban() {
 	u.Status = UserStatusBanned
+	u.RevokeTokens()
 	u.UpdatedAt = time.Now()
 }
 
+// RevokeTokens 递增令牌版本，使之前签发的所有令牌失效
+func (u *User) RevokeTokens() {
+	u.TokenVersion++
+}
+
 func (u *User) PromoteToAdmin() {
-	u.Role = UserRoleAdmin
+	u.AssignRole(UserRoleAdmin)
+}
+
+// AssignRole 分配角色，已拥有时不变
+func (u *User) AssignRole(role UserRole) {
+	if !u.HasRole(role) {
+		u.Roles = append(u.Roles, role)
+	}
+	u.UpdatedAt = time.Now()
+}
+
+// RevokeRole 收回角色
+func (u *User) RevokeRole(role UserRole) {
+	u.Roles = slices.DeleteFunc(u.Roles, func(r UserRole) bool { return r == role })
+	u.UpdatedAt = time.Now()
+}
+
+// VerifyEmail 标记邮箱已验证
+func (u *User) VerifyEmail() {
+	u.EmailVerified = true
+	u.UpdatedAt = time.Now()
+}
+
+func (u *User) IsDeleted() bool {
+	return !u.DeletedAt.IsZero()
+}
+
+// Delete 软删除用户
+func (u *User) Delete(deletedAt time.Time) {
+	u.DeletedAt = deletedAt
 	u.UpdatedAt = time.Now()
 }
@@ -0,0 +1,131 @@
+package entity
+
+import (
+	"time"
+)
+
+// WebhookEventAll 订阅全部事件
+const WebhookEventAll = "*"
+
+// WebhookEndpoint webhook 端点实体
+// 下游系统注册的回调地址，按事件类型订阅领域事件
+type WebhookEndpoint struct {
+	ID         uint64    // 数据库自增ID
+	URL        string    // 回调地址
+	EventTypes []string  // 订阅的事件类型
+	Secret     string    // HMAC-SHA256 签名密钥
+	Active     bool      // 是否启用
+	CreatedAt  time.Time // 创建时间
+	UpdatedAt  time.Time // 更新时间
+}
+
+func NewWebhookEndpoint(url string, eventTypes []string, secret string) *WebhookEndpoint {
+	return &WebhookEndpoint{
+		URL:        url,
+		EventTypes: eventTypes,
+		Secret:     secret,
+		Active:     true,
+		CreatedAt:  time.Now(),
+		UpdatedAt:  time.Now(),
+	}
+}
+
+// Subscribes 判断端点是否订阅了指定事件
+func (w *WebhookEndpoint) Subscribes(eventType string) bool {
+	if !w.Active {
+		return false
+	}
+	for _, t := range w.EventTypes {
+		if t == WebhookEventAll || t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+func (w *WebhookEndpoint) Update(url string, eventTypes []string, active bool) {
+	w.URL = url
+	w.EventTypes = eventTypes
+	w.Active = active
+	w.UpdatedAt = time.Now()
+}
+
+type WebhookDeliveryStatus int
+
+const (
+	WebhookDeliveryPending   WebhookDeliveryStatus = 1 // 待投递或等待重试
+	WebhookDeliverySucceeded WebhookDeliveryStatus = 2 // 投递成功
+	WebhookDeliveryDead      WebhookDeliveryStatus = 3 // 超过最大重试次数，进入死信
+)
+
+// WebhookDelivery webhook 投递记录
+// 每个事件对每个订阅端点生成一条投递记录，同时作为投递日志
+type WebhookDelivery struct {
+	ID             uint64
+	EndpointID     uint64
+	EventID        string
+	EventType      string
+	Payload        string // 发送的请求体
+	Status         WebhookDeliveryStatus
+	Attempts       int
+	NextAttemptAt  time.Time
+	ResponseStatus int    // 最近一次响应状态码
+	ResponseBody   string // 最近一次响应内容（截断）
+	LastError      string
+	DeliveredAt    *time.Time
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+func NewWebhookDelivery(endpointID uint64, eventID, eventType, payload string) *WebhookDelivery {
+	return &WebhookDelivery{
+		EndpointID:    endpointID,
+		EventID:       eventID,
+		EventType:     eventType,
+		Payload:       payload,
+		Status:        WebhookDeliveryPending,
+		NextAttemptAt: time.Now(),
+		CreatedAt:     time.Now(),
+		UpdatedAt:     time.Now(),
+	}
+}
+
+// MarkSucceeded 记录投递成功
+func (d *WebhookDelivery) MarkSucceeded(responseStatus int, responseBody string) {
+	now := time.Now()
+	d.Attempts++
+	d.Status = WebhookDeliverySucceeded
+	d.ResponseStatus = responseStatus
+	d.ResponseBody = responseBody
+	d.LastError = ""
+	d.DeliveredAt = &now
+	d.UpdatedAt = now
+}
+
+// MarkFailed 记录投递失败，dead 为 true 时进入死信不再重试
+func (d *WebhookDelivery) MarkFailed(responseStatus int, responseBody, lastError string, nextAttemptAt time.Time, dead bool) {
+	d.Attempts++
+	d.ResponseStatus = responseStatus
+	d.ResponseBody = responseBody
+	d.LastError = lastError
+	d.NextAttemptAt = nextAttemptAt
+	if dead {
+		d.Status = WebhookDeliveryDead
+	}
+	d.UpdatedAt = time.Now()
+}
+
+// Cancel 端点已删除或已停用，不发送请求直接进入死信，不计入重试次数
+func (d *WebhookDelivery) Cancel(reason string) {
+	d.Status = WebhookDeliveryDead
+	d.LastError = reason
+	d.UpdatedAt = time.Now()
+}
+
+// Replay 重新投递，重置重试次数
+func (d *WebhookDelivery) Replay() {
+	d.Status = WebhookDeliveryPending
+	d.Attempts = 0
+	d.NextAttemptAt = time.Now()
+	d.UpdatedAt = time.Now()
+}
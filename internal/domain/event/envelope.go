@@ -0,0 +1,66 @@
+package event
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Envelope 事件信封
+// 事件在持久化（事件存储、outbox）和跨进程传递时统一使用信封格式：
+// 1. 公共字段放在信封上，payload 只包含具体事件自己的字段
+// 2. schema_version 记录 payload 的结构版本，结构变化后通过 upcaster 兼容旧数据
+// 3. metadata 记录操作人和请求来源，对外发送时去除
+type Envelope struct {
+	EventID       string          `json:"event_id"`
+	EventType     string          `json:"event_type"`
+	SchemaVersion int             `json:"schema_version"`
+	AggregateID   string          `json:"aggregate_id"`
+	OccurredAt    time.Time       `json:"occurred_at"`
+	Metadata      *Metadata       `json:"metadata,omitempty"`
+	Payload       json.RawMessage `json:"payload"`
+}
+
+// Encode 使用默认注册表将领域事件序列化为信封 JSON
+func Encode(e Event) ([]byte, error) {
+	env, err := DefaultRegistry.Wrap(e)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(env)
+}
+
+// EncodePublic 使用默认注册表将领域事件序列化为去除敏感字段的信封 JSON
+func EncodePublic(e Event) ([]byte, error) {
+	env, err := DefaultRegistry.WrapPublic(e)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(env)
+}
+
+// Decode 使用默认注册表将信封 JSON 反序列化为领域事件
+// 兼容引入信封之前写入的数据：这类数据是事件结构体的原始 JSON，
+// 按 name 解析类型，并视为结构版本 1 进行升级
+func Decode(name string, data []byte) (Event, error) {
+	var env Envelope
+	if err := json.Unmarshal(data, &env); err != nil {
+		return nil, err
+	}
+
+	if env.EventType == "" {
+		var base BaseEvent
+		if err := json.Unmarshal(data, &base); err != nil {
+			return nil, err
+		}
+		env = Envelope{
+			EventID:       base.ID,
+			EventType:     name,
+			SchemaVersion: 1,
+			AggregateID:   base.AggregateId,
+			OccurredAt:    base.OccurredOn,
+			Payload:       data,
+		}
+	}
+
+	return DefaultRegistry.Unwrap(&env)
+}
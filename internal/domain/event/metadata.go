@@ -0,0 +1,39 @@
+package event
+
+import "context"
+
+// Metadata 事件元数据
+// 记录触发事件的操作人和请求来源，随事件一起持久化，用于审计
+type Metadata struct {
+	ActorID   uint64 `json:"actor_id,omitempty"`   // 操作人ID，匿名操作（例如注册）为 0
+	ActorName string `json:"actor_name,omitempty"` // 操作人用户名
+	IP        string `json:"ip,omitempty"`
+	UserAgent string `json:"user_agent,omitempty"`
+}
+
+// IsZero 判断元数据是否为空
+func (m Metadata) IsZero() bool {
+	return m == Metadata{}
+}
+
+type metadataKey struct{}
+
+// ContextWithMetadata 把请求的元数据放入 context，由应用服务在保存聚合时附加到事件上
+func ContextWithMetadata(ctx context.Context, m Metadata) context.Context {
+	return context.WithValue(ctx, metadataKey{}, m)
+}
+
+// MetadataFromContext 从 context 中读取元数据，不存在时返回零值
+func MetadataFromContext(ctx context.Context) Metadata {
+	m, _ := ctx.Value(metadataKey{}).(Metadata)
+	return m
+}
+
+// AttachMetadata 为尚未持久化的事件附加元数据
+func AttachMetadata(events []Event, m Metadata) {
+	for _, e := range events {
+		if setter, ok := e.(baseSetter); ok {
+			setter.setMetadata(m)
+		}
+	}
+}
@@ -0,0 +1,264 @@
+package event
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+)
+
+// Upcaster 将某个结构版本的 payload 升级到下一个版本
+type Upcaster func(payload map[string]interface{}) (map[string]interface{}, error)
+
+// Registry 事件类型注册表
+// 1. 维护事件类型名称到具体结构体的映射，用于反序列化
+// 2. 记录每种事件当前的结构版本
+// 3. 维护旧名称到规范名称的别名，兼容历史数据
+// 4. 维护 upcaster 链，把旧版本 payload 逐级升级到当前版本
+// 5. 记录敏感字段，对外发送事件时去除
+type Registry struct {
+	mu        sync.RWMutex
+	types     map[string]registeredType
+	aliases   map[string]string
+	upcasters map[string]map[int]Upcaster
+	sensitive map[string][]string
+}
+
+type registeredType struct {
+	schemaVersion int
+	factory       func() Event
+}
+
+// NewRegistry 创建空的事件注册表
+func NewRegistry() *Registry {
+	return &Registry{
+		types:     make(map[string]registeredType),
+		aliases:   make(map[string]string),
+		upcasters: make(map[string]map[int]Upcaster),
+		sensitive: make(map[string][]string),
+	}
+}
+
+// Register 注册事件类型及其当前结构版本，factory 返回该事件的空指针实例
+func (r *Registry) Register(eventType string, schemaVersion int, factory func() Event) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.types[eventType] = registeredType{schemaVersion: schemaVersion, factory: factory}
+}
+
+// RegisterAlias 注册事件的历史名称
+func (r *Registry) RegisterAlias(alias, eventType string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.aliases[alias] = eventType
+}
+
+// RegisterUpcaster 注册把 fromVersion 版本升级到 fromVersion+1 的 upcaster
+func (r *Registry) RegisterUpcaster(eventType string, fromVersion int, upcaster Upcaster) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.upcasters[eventType] == nil {
+		r.upcasters[eventType] = make(map[int]Upcaster)
+	}
+	r.upcasters[eventType][fromVersion] = upcaster
+}
+
+// RegisterSensitive 注册事件中不允许发送到系统外部的字段，例如密码哈希
+func (r *Registry) RegisterSensitive(eventType string, fields ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.sensitive[eventType] = append(r.sensitive[eventType], fields...)
+}
+
+// IsRegistered 判断是否为已注册的规范事件类型（不包括别名）
+func (r *Registry) IsRegistered(eventType string) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	_, ok := r.types[eventType]
+	return ok
+}
+
+// SchemaVersion 返回事件类型当前的结构版本
+func (r *Registry) SchemaVersion(eventType string) (int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	t, ok := r.types[r.resolve(eventType)]
+	if !ok {
+		return 0, fmt.Errorf("unknown event type: %s", eventType)
+	}
+	return t.schemaVersion, nil
+}
+
+// Wrap 将领域事件封装为当前版本的信封
+func (r *Registry) Wrap(e Event) (*Envelope, error) {
+	version, err := r.SchemaVersion(e.EventName())
+	if err != nil {
+		return nil, err
+	}
+
+	payload, err := toPayload(e)
+	if err != nil {
+		return nil, err
+	}
+
+	env := &Envelope{
+		EventID:       e.EventID(),
+		EventType:     r.canonicalName(e.EventName()),
+		SchemaVersion: version,
+		AggregateID:   e.AggregateID(),
+		OccurredAt:    e.OccurredAt(),
+		Payload:       payload,
+	}
+	if meta := e.Metadata(); !meta.IsZero() {
+		env.Metadata = &meta
+	}
+	return env, nil
+}
+
+// WrapPublic 将领域事件封装为对外发送的信封，去除元数据和敏感字段
+// webhook、SSE 等把事件发送到系统外部时使用
+func (r *Registry) WrapPublic(e Event) (*Envelope, error) {
+	env, err := r.Wrap(e)
+	if err != nil {
+		return nil, err
+	}
+	env.Metadata = nil
+
+	r.mu.RLock()
+	fields := r.sensitive[env.EventType]
+	r.mu.RUnlock()
+	if len(fields) == 0 {
+		return env, nil
+	}
+
+	var data map[string]interface{}
+	if err := json.Unmarshal(env.Payload, &data); err != nil {
+		return nil, err
+	}
+	for _, field := range fields {
+		delete(data, field)
+	}
+	if env.Payload, err = json.Marshal(data); err != nil {
+		return nil, err
+	}
+	return env, nil
+}
+
+// Unwrap 将信封还原为具体的领域事件，必要时先升级 payload
+func (r *Registry) Unwrap(env *Envelope) (Event, error) {
+	r.mu.RLock()
+	eventType := r.resolve(env.EventType)
+	t, ok := r.types[eventType]
+	r.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown event type: %s", env.EventType)
+	}
+
+	payload, err := r.upcast(eventType, env.SchemaVersion, t.schemaVersion, env.Payload)
+	if err != nil {
+		return nil, err
+	}
+
+	e := t.factory()
+	if err := json.Unmarshal(payload, e); err != nil {
+		return nil, fmt.Errorf("failed to decode event %s: %w", eventType, err)
+	}
+
+	setter, ok := e.(baseSetter)
+	if !ok {
+		return nil, fmt.Errorf("event %s does not embed BaseEvent", eventType)
+	}
+	base := BaseEvent{
+		ID:          env.EventID,
+		Name:        eventType,
+		OccurredOn:  env.OccurredAt,
+		AggregateId: env.AggregateID,
+	}
+	if env.Metadata != nil {
+		base.Meta = *env.Metadata
+	}
+	setter.setBase(base)
+	return e, nil
+}
+
+// upcast 逐级执行 upcaster，把 payload 从 from 版本升级到 to 版本
+func (r *Registry) upcast(eventType string, from, to int, payload json.RawMessage) (json.RawMessage, error) {
+	if from == to {
+		return payload, nil
+	}
+	if from > to {
+		return nil, fmt.Errorf("event %s schema version %d is newer than supported version %d", eventType, from, to)
+	}
+
+	var data map[string]interface{}
+	if err := json.Unmarshal(payload, &data); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	chain := r.upcasters[eventType]
+	r.mu.RUnlock()
+
+	for v := from; v < to; v++ {
+		upcaster, ok := chain[v]
+		if !ok {
+			return nil, fmt.Errorf("missing upcaster for event %s version %d", eventType, v)
+		}
+		var err error
+		if data, err = upcaster(data); err != nil {
+			return nil, fmt.Errorf("failed to upcast event %s from version %d: %w", eventType, v, err)
+		}
+	}
+	return json.Marshal(data)
+}
+
+func (r *Registry) canonicalName(name string) string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.resolve(name)
+}
+
+// resolve 将别名解析为规范名称，调用方需持有读锁
+func (r *Registry) resolve(name string) string {
+	if canonical, ok := r.aliases[name]; ok {
+		return canonical
+	}
+	return name
+}
+
+// toPayload 序列化事件并去掉信封上已有的公共字段
+func toPayload(e Event) (json.RawMessage, error) {
+	raw, err := json.Marshal(e)
+	if err != nil {
+		return nil, err
+	}
+
+	var data map[string]interface{}
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return nil, err
+	}
+	for _, key := range []string{"event_id", "name", "occurred_at", "aggregate_id", "metadata"} {
+		delete(data, key)
+	}
+	return json.Marshal(data)
+}
+
+// baseSetter 由嵌入 BaseEvent 的事件指针实现，用于反序列化时回填公共字段
+type baseSetter interface {
+	setBase(base BaseEvent)
+	setMetadata(m Metadata)
+}
+
+func (e *BaseEvent) setBase(base BaseEvent) {
+	*e = base
+}
+
+func (e *BaseEvent) setMetadata(m Metadata) {
+	e.Meta = m
+}
@@ -1,6 +1,29 @@
 package event
 
-import "time"
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// 事件类型名称，统一使用 "聚合.动作" 的格式
+const (
+	UserRegistered      = "user.registered"
+	UserProfileUpdated  = "user.profile_updated"
+	UserPasswordChanged = "user.password_changed"
+	UserActivated       = "user.activated"
+	UserDeactivated     = "user.deactivated"
+	UserBanned          = "user.banned"
+	UserPromoted        = "user.promoted"
+	UserDeleted         = "user.deleted"
+	UserEmailVerified   = "user.email_verified"
+	UserLockedOut       = "user.locked_out"
+	UserUnlocked        = "user.unlocked"
+	UserTokensRevoked   = "user.tokens_revoked"
+	UserRoleAssigned    = "user.role_assigned"
+	UserRoleRevoked     = "user.role_revoked"
+	UserImported        = "user.imported"
+)
 
 // Event 领域事件
 // 领域事件是DDD中的重要概念
@@ -9,15 +32,33 @@ import "time"
 // 3. 可以用于解耦不同的领域/服务
 // 4. 支持事件溯源 event sourcing
 type Event interface {
+	EventID() string
 	EventName() string
 	OccurredAt() time.Time
 	AggregateID() string
+	Metadata() Metadata
 }
 
 type BaseEvent struct {
+	ID          string    `json:"event_id"`
 	Name        string    `json:"name"`
 	OccurredOn  time.Time `json:"occurred_at"`
 	AggregateId string    `json:"aggregate_id"`
+	Meta        Metadata  `json:"metadata"`
+}
+
+// NewBaseEvent 创建事件公共字段，每个事件都有唯一的事件ID
+func NewBaseEvent(name, aggregateID string) BaseEvent {
+	return BaseEvent{
+		ID:          uuid.New().String(),
+		Name:        name,
+		OccurredOn:  time.Now(),
+		AggregateId: aggregateID,
+	}
+}
+
+func (e BaseEvent) EventID() string {
+	return e.ID
 }
 
 func (e BaseEvent) EventName() string {
@@ -32,22 +73,31 @@ func (e BaseEvent) AggregateID() string {
 	return e.AggregateId
 }
 
+func (e BaseEvent) Metadata() Metadata {
+	return e.Meta
+}
+
 // UserRegisteredEvent 用户注册事件
 type UserRegisteredEvent struct {
 	BaseEvent
-	UserName string `json:"user_name"`
-	Email    string `json:"email"`
+	UserName     string `json:"user_name"`
+	Email        string `json:"email"`
+	Nickname     string `json:"nickname"`
+	PasswordHash string `json:"password_hash"`
+	// PendingVerification 为 true 时用户注册后处于未激活状态，验证邮箱后激活
+	PendingVerification bool `json:"pending_verification,omitempty"`
+	// PasswordResetRequired 由没有密码哈希的 v1 事件升级而来，用户需要通过找回密码重新设置密码
+	PasswordResetRequired bool `json:"password_reset_required,omitempty"`
 }
 
-func NewUserRegisteredEvent(uuid, username, email string) *UserRegisteredEvent {
+func NewUserRegisteredEvent(uuid, username, email, nickname, passwordHash string, pendingVerification bool) *UserRegisteredEvent {
 	return &UserRegisteredEvent{
-		BaseEvent: BaseEvent{
-			Name:        "UserRegistered",
-			OccurredOn:  time.Now(),
-			AggregateId: uuid,
-		},
-		UserName: username,
-		Email:    email,
+		BaseEvent:           NewBaseEvent(UserRegistered, uuid),
+		UserName:            username,
+		Email:               email,
+		Nickname:            nickname,
+		PasswordHash:        passwordHash,
+		PendingVerification: pendingVerification,
 	}
 }
 
@@ -55,31 +105,29 @@ type UserProfileUpdatedEvent struct {
 	BaseEvent
 	OldNickname string `json:"old_nickname"`
 	NewNickname string `json:"new_nickname"`
+	Avatar      string `json:"avatar"`
 }
 
-func NewUserProfileUpdatedEvent(uuid, oldNickname, newNickname string) *UserProfileUpdatedEvent {
+func NewUserProfileUpdatedEvent(uuid, oldNickname, newNickname, avatar string) *UserProfileUpdatedEvent {
 	return &UserProfileUpdatedEvent{
-		BaseEvent: BaseEvent{
-			Name:        "UserProfileUpdated",
-			OccurredOn:  time.Now(),
-			AggregateId: uuid,
-		},
+		BaseEvent:   NewBaseEvent(UserProfileUpdated, uuid),
 		OldNickname: oldNickname,
 		NewNickname: newNickname,
+		Avatar:      avatar,
 	}
 }
 
 type UserPasswordChangedEvent struct {
 	BaseEvent
+	PasswordHash string `json:"password_hash"`
+	// PasswordResetRequired 由没有密码哈希的 v1 事件升级而来，用户需要通过找回密码重新设置密码
+	PasswordResetRequired bool `json:"password_reset_required,omitempty"`
 }
 
-func NewUserPasswordChangedEvent(uuid string) *UserPasswordChangedEvent {
+func NewUserPasswordChangedEvent(uuid, passwordHash string) *UserPasswordChangedEvent {
 	return &UserPasswordChangedEvent{
-		BaseEvent: BaseEvent{
-			Name:        "UserPasswordChanged",
-			OccurredOn:  time.Now(),
-			AggregateId: uuid,
-		},
+		BaseEvent:    NewBaseEvent(UserPasswordChanged, uuid),
+		PasswordHash: passwordHash,
 	}
 }
 
@@ -89,11 +137,7 @@ type UserActivatedEvent struct {
 
 func NewUserActivatedEvent(uuid string) *UserActivatedEvent {
 	return &UserActivatedEvent{
-		BaseEvent: BaseEvent{
-			Name:        "UserActivated",
-			OccurredOn:  time.Now(),
-			AggregateId: uuid,
-		},
+		BaseEvent: NewBaseEvent(UserActivated, uuid),
 	}
 }
 
@@ -103,11 +147,7 @@ type UserDeactivatedEvent struct {
 
 func NewUserDeactivatedEvent(uuid string) *UserDeactivatedEvent {
 	return &UserDeactivatedEvent{
-		BaseEvent: BaseEvent{
-			Name:        "UserDeactivated",
-			OccurredOn:  time.Now(),
-			AggregateId: uuid,
-		},
+		BaseEvent: NewBaseEvent(UserDeactivated, uuid),
 	}
 }
 
@@ -119,12 +159,8 @@ type UserBannedEvent struct {
 
 func NewUserBannedEvent(uuid, reason string) *UserBannedEvent {
 	return &UserBannedEvent{
-		BaseEvent: BaseEvent{
-			Name:        "user.banned",
-			OccurredOn:  time.Now(),
-			AggregateId: uuid,
-		},
-		Reason: reason,
+		BaseEvent: NewBaseEvent(UserBanned, uuid),
+		Reason:    reason,
 	}
 }
 
@@ -135,11 +171,134 @@ type UserPromotedEvent struct {
 
 func NewUserPromotedEvent(uuid string) *UserPromotedEvent {
 	return &UserPromotedEvent{
-		BaseEvent: BaseEvent{
-			Name:        "user.promoted",
-			OccurredOn:  time.Now(),
-			AggregateId: uuid,
-		},
+		BaseEvent: NewBaseEvent(UserPromoted, uuid),
+	}
+}
+
+// UserDeletedEvent 用户删除事件
+type UserDeletedEvent struct {
+	BaseEvent
+	Reason string `json:"reason"`
+}
+
+func NewUserDeletedEvent(uuid, reason string) *UserDeletedEvent {
+	return &UserDeletedEvent{
+		BaseEvent: NewBaseEvent(UserDeleted, uuid),
+		Reason:    reason,
+	}
+}
+
+// UserEmailVerifiedEvent 用户邮箱验证通过事件
+type UserEmailVerifiedEvent struct {
+	BaseEvent
+	Email string `json:"email"`
+}
+
+func NewUserEmailVerifiedEvent(uuid, email string) *UserEmailVerifiedEvent {
+	return &UserEmailVerifiedEvent{
+		BaseEvent: NewBaseEvent(UserEmailVerified, uuid),
+		Email:     email,
+	}
+}
+
+// UserLockedOutEvent 连续登录失败次数过多，账户被临时锁定事件
+type UserLockedOutEvent struct {
+	BaseEvent
+	Failures    int       `json:"failures"`
+	LockedUntil time.Time `json:"locked_until"`
+}
+
+func NewUserLockedOutEvent(uuid string, failures int, lockedUntil time.Time) *UserLockedOutEvent {
+	return &UserLockedOutEvent{
+		BaseEvent:   NewBaseEvent(UserLockedOut, uuid),
+		Failures:    failures,
+		LockedUntil: lockedUntil,
+	}
+}
+
+// UserUnlockedEvent 管理员解除账户锁定事件
+type UserUnlockedEvent struct {
+	BaseEvent
+}
+
+func NewUserUnlockedEvent(uuid string) *UserUnlockedEvent {
+	return &UserUnlockedEvent{
+		BaseEvent: NewBaseEvent(UserUnlocked, uuid),
+	}
+}
+
+// UserTokensRevokedEvent 撤销用户已签发的全部令牌事件
+type UserTokensRevokedEvent struct {
+	BaseEvent
+	Reason string `json:"reason"`
+}
+
+func NewUserTokensRevokedEvent(uuid, reason string) *UserTokensRevokedEvent {
+	return &UserTokensRevokedEvent{
+		BaseEvent: NewBaseEvent(UserTokensRevoked, uuid),
+		Reason:    reason,
+	}
+}
+
+// UserRoleAssignedEvent 为用户分配角色事件
+type UserRoleAssignedEvent struct {
+	BaseEvent
+	Role string `json:"role"`
+}
+
+func NewUserRoleAssignedEvent(uuid, role string) *UserRoleAssignedEvent {
+	return &UserRoleAssignedEvent{
+		BaseEvent: NewBaseEvent(UserRoleAssigned, uuid),
+		Role:      role,
+	}
+}
+
+// UserRoleRevokedEvent 收回用户角色事件
+type UserRoleRevokedEvent struct {
+	BaseEvent
+	Role string `json:"role"`
+}
+
+func NewUserRoleRevokedEvent(uuid, role string) *UserRoleRevokedEvent {
+	return &UserRoleRevokedEvent{
+		BaseEvent: NewBaseEvent(UserRoleRevoked, uuid),
+		Role:      role,
+	}
+}
+
+// UserImportedEvent 为切换到事件溯源之前已存在的用户补写的起始事件
+// 携带用户在 users 表中的完整状态，聚合从该事件开始重建
+type UserImportedEvent struct {
+	BaseEvent
+	UserName              string     `json:"user_name"`
+	Email                 string     `json:"email"`
+	Nickname              string     `json:"nickname"`
+	Avatar                string     `json:"avatar"`
+	PasswordHash          string     `json:"password_hash"`
+	EmailVerified         bool       `json:"email_verified"`
+	Status                int        `json:"status"`
+	Roles                 []string   `json:"roles"`
+	TokenVersion          int        `json:"token_version"`
+	PasswordResetRequired bool       `json:"password_reset_required,omitempty"`
+	CreatedAt             time.Time  `json:"created_at"`
+	DeletedAt             *time.Time `json:"deleted_at,omitempty"`
+}
+
+func NewUserImportedEvent(uuid, username, email, nickname, avatar, passwordHash string, emailVerified bool, status int, roles []string, tokenVersion int, passwordResetRequired bool, createdAt time.Time, deletedAt *time.Time) *UserImportedEvent {
+	return &UserImportedEvent{
+		BaseEvent:             NewBaseEvent(UserImported, uuid),
+		UserName:              username,
+		Email:                 email,
+		Nickname:              nickname,
+		Avatar:                avatar,
+		PasswordHash:          passwordHash,
+		EmailVerified:         emailVerified,
+		Status:                status,
+		Roles:                 roles,
+		TokenVersion:          tokenVersion,
+		PasswordResetRequired: passwordResetRequired,
+		CreatedAt:             createdAt,
+		DeletedAt:             deletedAt,
 	}
 }
 
@@ -147,6 +306,13 @@ type EventHandler interface {
 	Handle(event Event) error
 }
 
+// EventHandlerFunc 函数适配器，允许普通函数作为事件处理器
+type EventHandlerFunc func(event Event) error
+
+func (f EventHandlerFunc) Handle(event Event) error {
+	return f(event)
+}
+
 type EventPublisher interface {
 	Publish(event Event) error
 }
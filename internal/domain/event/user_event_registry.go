@@ -0,0 +1,83 @@
+package event
+
+// DefaultRegistry 默认事件注册表，包含所有用户领域事件
+var DefaultRegistry = newUserEventRegistry()
+
+func newUserEventRegistry() *Registry {
+	r := NewRegistry()
+
+	r.Register(UserRegistered, 2, func() Event { return &UserRegisteredEvent{} })
+	r.Register(UserProfileUpdated, 2, func() Event { return &UserProfileUpdatedEvent{} })
+	r.Register(UserPasswordChanged, 2, func() Event { return &UserPasswordChangedEvent{} })
+	r.Register(UserActivated, 1, func() Event { return &UserActivatedEvent{} })
+	r.Register(UserDeactivated, 1, func() Event { return &UserDeactivatedEvent{} })
+	r.Register(UserBanned, 1, func() Event { return &UserBannedEvent{} })
+	r.Register(UserPromoted, 1, func() Event { return &UserPromotedEvent{} })
+	r.Register(UserDeleted, 1, func() Event { return &UserDeletedEvent{} })
+	r.Register(UserEmailVerified, 1, func() Event { return &UserEmailVerifiedEvent{} })
+	r.Register(UserLockedOut, 1, func() Event { return &UserLockedOutEvent{} })
+	r.Register(UserUnlocked, 1, func() Event { return &UserUnlockedEvent{} })
+	r.Register(UserTokensRevoked, 1, func() Event { return &UserTokensRevokedEvent{} })
+	r.Register(UserRoleAssigned, 1, func() Event { return &UserRoleAssignedEvent{} })
+	r.Register(UserRoleRevoked, 1, func() Event { return &UserRoleRevokedEvent{} })
+	r.Register(UserImported, 1, func() Event { return &UserImportedEvent{} })
+
+	// 统一命名之前使用的事件名称
+	r.RegisterAlias("UserRegistered", UserRegistered)
+	r.RegisterAlias("UserProfileUpdated", UserProfileUpdated)
+	r.RegisterAlias("UserPasswordChanged", UserPasswordChanged)
+	r.RegisterAlias("UserActivated", UserActivated)
+	r.RegisterAlias("UserDeactivated", UserDeactivated)
+
+	// v1 -> v2: 注册事件增加昵称和密码哈希，v1 事件没有密码哈希，用户需要重新设置密码
+	r.RegisterUpcaster(UserRegistered, 1, chain(withDefaults(map[string]interface{}{
+		"nickname": "",
+	}), requirePasswordReset))
+	// v1 -> v2: 资料更新事件增加头像
+	r.RegisterUpcaster(UserProfileUpdated, 1, withDefaults(map[string]interface{}{
+		"avatar": "",
+	}))
+	// v1 -> v2: 修改密码事件增加新的密码哈希，v1 事件没有密码哈希，用户需要重新设置密码
+	r.RegisterUpcaster(UserPasswordChanged, 1, requirePasswordReset)
+
+	// 密码哈希只用于事件溯源重建聚合，不能发送到系统外部
+	r.RegisterSensitive(UserRegistered, "password_hash")
+	r.RegisterSensitive(UserPasswordChanged, "password_hash")
+	r.RegisterSensitive(UserImported, "password_hash")
+
+	return r
+}
+
+// withDefaults 返回为缺失字段补充默认值的 upcaster
+func withDefaults(defaults map[string]interface{}) Upcaster {
+	return func(payload map[string]interface{}) (map[string]interface{}, error) {
+		for key, value := range defaults {
+			if _, ok := payload[key]; !ok {
+				payload[key] = value
+			}
+		}
+		return payload, nil
+	}
+}
+
+// requirePasswordReset 缺少密码哈希时不能用空哈希冒充密码，标记为需要通过找回密码重新设置
+func requirePasswordReset(payload map[string]interface{}) (map[string]interface{}, error) {
+	if hash, _ := payload["password_hash"].(string); hash == "" {
+		payload["password_hash"] = ""
+		payload["password_reset_required"] = true
+	}
+	return payload, nil
+}
+
+// chain 依次执行多个 upcaster
+func chain(upcasters ...Upcaster) Upcaster {
+	return func(payload map[string]interface{}) (map[string]interface{}, error) {
+		for _, upcaster := range upcasters {
+			var err error
+			if payload, err = upcaster(payload); err != nil {
+				return nil, err
+			}
+		}
+		return payload, nil
+	}
+}
@@ -0,0 +1,266 @@
+package event
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+// v1Envelope 构造结构版本 1 的信封 JSON
+func v1Envelope(t *testing.T, eventType string, payload map[string]interface{}) []byte {
+	t.Helper()
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		t.Fatalf("marshal payload: %v", err)
+	}
+	data, err := json.Marshal(Envelope{
+		EventID:       "event-1",
+		EventType:     eventType,
+		SchemaVersion: 1,
+		AggregateID:   "user-1",
+		OccurredAt:    time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		Payload:       raw,
+	})
+	if err != nil {
+		t.Fatalf("marshal envelope: %v", err)
+	}
+	return data
+}
+
+// legacyJSON 构造引入信封之前直接序列化事件结构体的 JSON
+func legacyJSON(t *testing.T, name string, fields map[string]interface{}) []byte {
+	t.Helper()
+	data := map[string]interface{}{
+		"event_id":     "event-1",
+		"name":         name,
+		"occurred_at":  time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		"aggregate_id": "user-1",
+	}
+	for k, v := range fields {
+		data[k] = v
+	}
+	raw, err := json.Marshal(data)
+	if err != nil {
+		t.Fatalf("marshal legacy event: %v", err)
+	}
+	return raw
+}
+
+func TestDecodeUpcastsV1UserRegistered(t *testing.T) {
+	tests := []struct {
+		name          string
+		eventType     string
+		legacy        bool
+		payload       map[string]interface{}
+		wantHash      string
+		wantNickname  string
+		wantResetFlag bool
+	}{
+		{
+			name:          "old name without hash",
+			eventType:     "UserRegistered",
+			payload:       map[string]interface{}{"user_name": "alice", "email": "alice@example.com"},
+			wantResetFlag: true,
+		},
+		{
+			name:          "canonical name without hash",
+			eventType:     UserRegistered,
+			payload:       map[string]interface{}{"user_name": "alice", "email": "alice@example.com"},
+			wantResetFlag: true,
+		},
+		{
+			name:          "old name with empty hash",
+			eventType:     "UserRegistered",
+			payload:       map[string]interface{}{"user_name": "alice", "email": "alice@example.com", "password_hash": ""},
+			wantResetFlag: true,
+		},
+		{
+			name:         "canonical name with hash and nickname",
+			eventType:    UserRegistered,
+			payload:      map[string]interface{}{"user_name": "alice", "email": "alice@example.com", "password_hash": "$2a$10$hash", "nickname": "Alice"},
+			wantHash:     "$2a$10$hash",
+			wantNickname: "Alice",
+		},
+		{
+			name:          "legacy struct JSON under old name",
+			eventType:     "UserRegistered",
+			legacy:        true,
+			payload:       map[string]interface{}{"user_name": "alice", "email": "alice@example.com"},
+			wantResetFlag: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := v1Envelope(t, tt.eventType, tt.payload)
+			if tt.legacy {
+				data = legacyJSON(t, tt.eventType, tt.payload)
+			}
+
+			e, err := Decode(tt.eventType, data)
+			if err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+			ev, ok := e.(*UserRegisteredEvent)
+			if !ok {
+				t.Fatalf("got %T, want *UserRegisteredEvent", e)
+			}
+			if ev.EventName() != UserRegistered {
+				t.Errorf("got name %q, want %q", ev.EventName(), UserRegistered)
+			}
+			if ev.EventID() != "event-1" || ev.AggregateID() != "user-1" {
+				t.Errorf("got event %q on %q, want event-1 on user-1", ev.EventID(), ev.AggregateID())
+			}
+			if ev.UserName != "alice" || ev.Email != "alice@example.com" {
+				t.Errorf("got user %q <%s>, want alice <alice@example.com>", ev.UserName, ev.Email)
+			}
+			if ev.Nickname != tt.wantNickname {
+				t.Errorf("got nickname %q, want %q", ev.Nickname, tt.wantNickname)
+			}
+			if ev.PasswordHash != tt.wantHash {
+				t.Errorf("got hash %q, want %q", ev.PasswordHash, tt.wantHash)
+			}
+			if ev.PasswordResetRequired != tt.wantResetFlag {
+				t.Errorf("got password_reset_required %v, want %v", ev.PasswordResetRequired, tt.wantResetFlag)
+			}
+		})
+	}
+}
+
+func TestDecodeUpcastsV1UserPasswordChanged(t *testing.T) {
+	tests := []struct {
+		name          string
+		eventType     string
+		payload       map[string]interface{}
+		wantHash      string
+		wantResetFlag bool
+	}{
+		{name: "old name without hash", eventType: "UserPasswordChanged", payload: map[string]interface{}{}, wantResetFlag: true},
+		{name: "canonical name without hash", eventType: UserPasswordChanged, payload: map[string]interface{}{}, wantResetFlag: true},
+		{name: "old name with hash", eventType: "UserPasswordChanged", payload: map[string]interface{}{"password_hash": "$2a$10$hash"}, wantHash: "$2a$10$hash"},
+		{name: "canonical name with hash", eventType: UserPasswordChanged, payload: map[string]interface{}{"password_hash": "$2a$10$hash"}, wantHash: "$2a$10$hash"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := Decode(tt.eventType, v1Envelope(t, tt.eventType, tt.payload))
+			if err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+			ev, ok := e.(*UserPasswordChangedEvent)
+			if !ok {
+				t.Fatalf("got %T, want *UserPasswordChangedEvent", e)
+			}
+			if ev.EventName() != UserPasswordChanged {
+				t.Errorf("got name %q, want %q", ev.EventName(), UserPasswordChanged)
+			}
+			if ev.PasswordHash != tt.wantHash {
+				t.Errorf("got hash %q, want %q", ev.PasswordHash, tt.wantHash)
+			}
+			if ev.PasswordResetRequired != tt.wantResetFlag {
+				t.Errorf("got password_reset_required %v, want %v", ev.PasswordResetRequired, tt.wantResetFlag)
+			}
+		})
+	}
+}
+
+func TestDecodeUpcastsV1UserProfileUpdated(t *testing.T) {
+	tests := []struct {
+		name       string
+		eventType  string
+		payload    map[string]interface{}
+		wantAvatar string
+	}{
+		{name: "old name without avatar", eventType: "UserProfileUpdated", payload: map[string]interface{}{"old_nickname": "a", "new_nickname": "b"}},
+		{name: "canonical name without avatar", eventType: UserProfileUpdated, payload: map[string]interface{}{"old_nickname": "a", "new_nickname": "b"}},
+		{name: "avatar kept", eventType: "UserProfileUpdated", payload: map[string]interface{}{"old_nickname": "a", "new_nickname": "b", "avatar": "https://example.com/a.png"}, wantAvatar: "https://example.com/a.png"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := Decode(tt.eventType, v1Envelope(t, tt.eventType, tt.payload))
+			if err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+			ev, ok := e.(*UserProfileUpdatedEvent)
+			if !ok {
+				t.Fatalf("got %T, want *UserProfileUpdatedEvent", e)
+			}
+			if ev.EventName() != UserProfileUpdated {
+				t.Errorf("got name %q, want %q", ev.EventName(), UserProfileUpdated)
+			}
+			if ev.OldNickname != "a" || ev.NewNickname != "b" {
+				t.Errorf("got nicknames %q -> %q, want a -> b", ev.OldNickname, ev.NewNickname)
+			}
+			if ev.Avatar != tt.wantAvatar {
+				t.Errorf("got avatar %q, want %q", ev.Avatar, tt.wantAvatar)
+			}
+		})
+	}
+}
+
+func TestDecodeResolvesRenamedEvents(t *testing.T) {
+	tests := []struct {
+		oldName string
+		want    string
+	}{
+		{"UserRegistered", UserRegistered},
+		{"UserProfileUpdated", UserProfileUpdated},
+		{"UserPasswordChanged", UserPasswordChanged},
+		{"UserActivated", UserActivated},
+		{"UserDeactivated", UserDeactivated},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.oldName, func(t *testing.T) {
+			// 旧名称只用于读取历史数据，不能作为事件类型订阅
+			if DefaultRegistry.IsRegistered(tt.oldName) {
+				t.Errorf("%s is registered as an event type", tt.oldName)
+			}
+			if _, err := DefaultRegistry.SchemaVersion(tt.oldName); err != nil {
+				t.Fatalf("schema version: %v", err)
+			}
+			e, err := Decode(tt.oldName, legacyJSON(t, tt.oldName, nil))
+			if err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+			if e.EventName() != tt.want {
+				t.Errorf("got name %q, want %q", e.EventName(), tt.want)
+			}
+		})
+	}
+}
+
+func TestDecodeKeepsCurrentVersionUnchanged(t *testing.T) {
+	original := NewUserPasswordChangedEvent("user-1", "")
+	data, err := Encode(original)
+	if err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+
+	e, err := Decode(UserPasswordChanged, data)
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	// 当前版本的事件不经过 upcaster，空哈希不会被标记为需要重置密码
+	if ev := e.(*UserPasswordChangedEvent); ev.PasswordResetRequired {
+		t.Error("current version event was upcast")
+	}
+}
+
+func TestDecodeRejectsNewerSchemaVersion(t *testing.T) {
+	data, err := json.Marshal(Envelope{
+		EventID:       "event-1",
+		EventType:     UserRegistered,
+		SchemaVersion: 3,
+		AggregateID:   "user-1",
+		Payload:       json.RawMessage(`{}`),
+	})
+	if err != nil {
+		t.Fatalf("marshal envelope: %v", err)
+	}
+
+	if _, err := Decode(UserRegistered, data); err == nil {
+		t.Fatal("expected an error for a schema version newer than supported")
+	}
+}
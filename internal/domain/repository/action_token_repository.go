@@ -0,0 +1,21 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"yiwen/go-ddd/internal/domain/entity"
+)
+
+var ErrActionTokenNotFound = errors.New("action token not found")
+
+// ActionTokenRepository 一次性操作令牌仓库接口
+type ActionTokenRepository interface {
+	// Save 保存令牌，同时删除该用户同一用途的旧令牌并清理已过期的记录
+	Save(ctx context.Context, token *entity.ActionToken) error
+
+	// FindLatest 查询用户某一用途最近发送的令牌
+	FindLatest(ctx context.Context, userID uint64, purpose string) (*entity.ActionToken, error)
+
+	// Take 取出并删除令牌，保证令牌只能使用一次，不存在或已被使用时返回 ErrActionTokenNotFound
+	Take(ctx context.Context, purpose, tokenID string) (*entity.ActionToken, error)
+}
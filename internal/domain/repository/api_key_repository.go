@@ -0,0 +1,28 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+	"yiwen/go-ddd/internal/domain/entity"
+)
+
+var ErrAPIKeyNotFound = errors.New("api key not found")
+
+// APIKeyRepository API 密钥仓库接口
+type APIKeyRepository interface {
+	// Save 保存新创建的密钥
+	Save(ctx context.Context, key *entity.APIKey) error
+
+	// FindByHash 根据密钥哈希查询
+	FindByHash(ctx context.Context, keyHash string) (*entity.APIKey, error)
+
+	// ListByUser 查询用户的全部密钥
+	ListByUser(ctx context.Context, userID uint64) ([]*entity.APIKey, error)
+
+	// Delete 删除用户的密钥，不存在或不属于该用户时返回 ErrAPIKeyNotFound
+	Delete(ctx context.Context, userID, id uint64) error
+
+	// UpdateLastUsed 记录密钥的使用时间
+	UpdateLastUsed(ctx context.Context, id uint64, usedAt time.Time) error
+}
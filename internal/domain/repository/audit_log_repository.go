@@ -0,0 +1,37 @@
+package repository
+
+import (
+	"context"
+	"time"
+	"yiwen/go-ddd/internal/domain/entity"
+)
+
+// AuditLogFilter 审计日志查询条件，零值表示不过滤
+type AuditLogFilter struct {
+	ActorID    uint64
+	TargetUUID string
+	Action     string
+	From       time.Time
+	To         time.Time
+}
+
+// AuditLogCursor 审计日志的游标位置，按 (occurred_at, id) 倒序排列时位于该记录之后的记录才会返回
+type AuditLogCursor struct {
+	OccurredAt time.Time
+	ID         uint64
+}
+
+// AuditLogRepository 审计日志仓库接口
+type AuditLogRepository interface {
+	// Save 保存审计日志，同一事件重复保存时忽略
+	Save(ctx context.Context, log *entity.AuditLog) error
+
+	// List 按时间倒序分页查询审计日志
+	List(ctx context.Context, filter AuditLogFilter, offset, limit int) ([]*entity.AuditLog, int64, error)
+
+	// ListAfter 按时间倒序查询游标之后的审计日志，after 为空时从最新的记录开始，用于导出全部记录
+	ListAfter(ctx context.Context, filter AuditLogFilter, after *AuditLogCursor, limit int) ([]*entity.AuditLog, error)
+
+	// DeleteProjected 删除由事件投影生成的审计日志，仅用于从事件重建，管理命令直接写入的记录保留
+	DeleteProjected(ctx context.Context) error
+}
@@ -0,0 +1,32 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"yiwen/go-ddd/internal/domain/entity"
+)
+
+var (
+	ErrExternalIdentityNotFound = errors.New("external identity not found")
+	// ErrExternalIdentityExists 外部身份已经关联了其他用户，并发首次登录时只有一个请求能关联成功
+	ErrExternalIdentityExists     = errors.New("external identity already linked")
+	ErrExternalLoginStateNotFound = errors.New("external login state not found")
+)
+
+// ExternalIdentityRepository 外部身份仓库接口
+type ExternalIdentityRepository interface {
+	// SaveIdentity 保存外部身份，同一提供方的 subject 已被关联时返回 ErrExternalIdentityExists
+	SaveIdentity(ctx context.Context, identity *entity.ExternalIdentity) error
+
+	// FindIdentity 根据提供方和 subject 查询
+	FindIdentity(ctx context.Context, provider, subject string) (*entity.ExternalIdentity, error)
+
+	// ListIdentitiesByUser 查询用户关联的全部外部身份
+	ListIdentitiesByUser(ctx context.Context, userID uint64) ([]*entity.ExternalIdentity, error)
+
+	// SaveLoginState 保存登录流程状态，同时清理已过期的状态
+	SaveLoginState(ctx context.Context, state *entity.ExternalLoginState) error
+
+	// TakeLoginState 原子地取出并删除登录流程状态，并发回调时只有一个请求能拿到
+	TakeLoginState(ctx context.Context, stateHash string) (*entity.ExternalLoginState, error)
+}
@@ -0,0 +1,26 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+	"yiwen/go-ddd/internal/domain/entity"
+)
+
+var ErrLoginAttemptNotFound = errors.New("login attempt not found")
+
+// LoginAttemptRepository 登录失败记录仓库接口
+// 有内存和数据库两种实现：内存实现只适用于单实例部署，多实例部署时使用数据库实现共享计数
+type LoginAttemptRepository interface {
+	// Find 查询登录失败记录，没有记录时返回 ErrLoginAttemptNotFound
+	Find(ctx context.Context, key string) (*entity.LoginAttempt, error)
+
+	// RecordFailure 原子地累加失败次数并返回累加后的记录，距离上次失败超过 window 时从 1 重新计数
+	RecordFailure(ctx context.Context, key string, window time.Duration) (*entity.LoginAttempt, error)
+
+	// Lock 锁定到 until 并清零失败次数，解锁后重新计数
+	Lock(ctx context.Context, key string, until time.Time) error
+
+	// Reset 删除登录失败记录，同时解除锁定
+	Reset(ctx context.Context, key string) error
+}
@@ -0,0 +1,45 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"yiwen/go-ddd/internal/domain/entity"
+)
+
+var (
+	ErrOAuthClientNotFound       = errors.New("oauth client not found")
+	ErrAuthorizationCodeNotFound = errors.New("authorization code not found")
+	// ErrAuthorizationCodeUsed 授权码已经被使用，并发换取令牌时只有一个请求能成功
+	ErrAuthorizationCodeUsed = errors.New("authorization code already used")
+	ErrOAuthConsentNotFound  = errors.New("oauth consent not found")
+)
+
+// OAuthRepository OAuth2 仓库接口
+type OAuthRepository interface {
+	// SaveClient 保存客户端
+	SaveClient(ctx context.Context, client *entity.OAuthClient) error
+
+	// FindClientByClientID 根据客户端标识查询
+	FindClientByClientID(ctx context.Context, clientID string) (*entity.OAuthClient, error)
+
+	// ListClients 查询所有客户端
+	ListClients(ctx context.Context) ([]*entity.OAuthClient, error)
+
+	// DeleteClient 删除客户端
+	DeleteClient(ctx context.Context, clientID string) error
+
+	// SaveAuthorizationCode 保存授权码
+	SaveAuthorizationCode(ctx context.Context, code *entity.AuthorizationCode) error
+
+	// FindAuthorizationCodeByHash 根据授权码哈希查询
+	FindAuthorizationCodeByHash(ctx context.Context, codeHash string) (*entity.AuthorizationCode, error)
+
+	// MarkAuthorizationCodeUsed 原子地把未使用的授权码标记为已使用，否则返回 ErrAuthorizationCodeUsed
+	MarkAuthorizationCodeUsed(ctx context.Context, id uint64) error
+
+	// FindConsent 查询用户对客户端的授权同意记录
+	FindConsent(ctx context.Context, userID uint64, clientID string) (*entity.OAuthConsent, error)
+
+	// SaveConsent 保存授权同意记录
+	SaveConsent(ctx context.Context, consent *entity.OAuthConsent) error
+}
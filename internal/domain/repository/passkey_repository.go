@@ -0,0 +1,41 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"yiwen/go-ddd/internal/domain/entity"
+)
+
+var (
+	ErrPasskeyNotFound = errors.New("passkey not found")
+	// ErrPasskeyExists 凭证ID已经注册过
+	ErrPasskeyExists = errors.New("passkey already registered")
+	// ErrPasskeySignCountStale 签名计数没有增加，并发使用同一个断言登录时只有一个请求能成功
+	ErrPasskeySignCountStale   = errors.New("passkey sign count is stale")
+	ErrWebAuthnSessionNotFound = errors.New("webauthn session not found")
+)
+
+// PasskeyRepository 通行密钥仓库接口
+type PasskeyRepository interface {
+	// Save 保存新注册的通行密钥，凭证ID已存在时返回 ErrPasskeyExists
+	Save(ctx context.Context, passkey *entity.Passkey) error
+
+	// FindByCredentialID 根据凭证ID查询
+	FindByCredentialID(ctx context.Context, credentialID []byte) (*entity.Passkey, error)
+
+	// ListByUser 查询用户的全部通行密钥
+	ListByUser(ctx context.Context, userID uint64) ([]*entity.Passkey, error)
+
+	// Delete 删除用户的通行密钥，不存在或不属于该用户时返回 ErrPasskeyNotFound
+	Delete(ctx context.Context, userID, id uint64) error
+
+	// UpdateSignCount 原子地把签名计数从 previous 更新为 signCount 并记录使用时间
+	// 计数已被其他请求修改时返回 ErrPasskeySignCountStale
+	UpdateSignCount(ctx context.Context, id uint64, previous, signCount uint32) error
+
+	// SaveSession 保存进行中的仪式，同时清理已过期的记录
+	SaveSession(ctx context.Context, session *entity.WebAuthnSession) error
+
+	// TakeSession 根据挑战值哈希取出并删除仪式，保证每个挑战值只能使用一次
+	TakeSession(ctx context.Context, challengeHash string) (*entity.WebAuthnSession, error)
+}
@@ -0,0 +1,31 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"yiwen/go-ddd/internal/domain/entity"
+)
+
+var (
+	ErrRefreshTokenNotFound = errors.New("refresh token not found")
+	// ErrRefreshTokenUsed 令牌已经被使用或撤销，并发刷新时只有一个请求能成功
+	ErrRefreshTokenUsed = errors.New("refresh token already used")
+)
+
+// RefreshTokenRepository 刷新令牌仓库接口
+type RefreshTokenRepository interface {
+	// Save 保存新令牌
+	Save(ctx context.Context, token *entity.RefreshToken) error
+
+	// FindByHash 根据令牌哈希查询
+	FindByHash(ctx context.Context, tokenHash string) (*entity.RefreshToken, error)
+
+	// MarkUsed 原子地把未使用、未撤销的令牌标记为已使用，否则返回 ErrRefreshTokenUsed
+	MarkUsed(ctx context.Context, id uint64) error
+
+	// RevokeFamily 撤销家族中的全部令牌
+	RevokeFamily(ctx context.Context, familyID string) error
+
+	// RevokeByUser 撤销用户的全部令牌
+	RevokeByUser(ctx context.Context, userID uint64) error
+}
@@ -0,0 +1,16 @@
+package repository
+
+import (
+	"context"
+	"time"
+)
+
+// RevokedTokenRepository 已注销访问令牌仓库接口
+// 访问令牌本身无状态，注销后需要记录其 jti，直到令牌过期为止
+type RevokedTokenRepository interface {
+	// Revoke 撤销令牌，重复撤销不会报错
+	Revoke(ctx context.Context, tokenID string, userID uint64, expiresAt time.Time) error
+
+	// IsRevoked 判断令牌是否已被撤销
+	IsRevoked(ctx context.Context, tokenID string) (bool, error)
+}
@@ -0,0 +1,27 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"yiwen/go-ddd/internal/domain/entity"
+)
+
+var ErrRoleNotFound = errors.New("role not found")
+
+// RoleRepository 角色仓库接口
+type RoleRepository interface {
+	// Save 保存角色
+	Save(ctx context.Context, role *entity.Role) error
+
+	// FindByID 根据id查询角色
+	FindByID(ctx context.Context, id uint64) (*entity.Role, error)
+
+	// FindByName 根据名称查询角色
+	FindByName(ctx context.Context, name string) (*entity.Role, error)
+
+	// List 查询全部角色
+	List(ctx context.Context) ([]*entity.Role, error)
+
+	// Delete 删除角色
+	Delete(ctx context.Context, id uint64) error
+}
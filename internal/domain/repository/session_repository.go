@@ -0,0 +1,38 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+	"yiwen/go-ddd/internal/domain/entity"
+)
+
+var ErrSessionNotFound = errors.New("session not found")
+
+// SessionRepository 登录会话仓库接口
+// 结束会话直接删除记录，访问令牌关联的会话不存在即视为已失效
+type SessionRepository interface {
+	// Save 保存新会话，同时清理该用户已过期的会话
+	Save(ctx context.Context, session *entity.Session) error
+
+	// FindByID 根据数据库ID查询
+	FindByID(ctx context.Context, id uint64) (*entity.Session, error)
+
+	// FindBySessionID 根据会话ID查询
+	FindBySessionID(ctx context.Context, sessionID string) (*entity.Session, error)
+
+	// ListByUser 查询用户未过期的会话，最近活跃的在前
+	ListByUser(ctx context.Context, userID uint64) ([]*entity.Session, error)
+
+	// UpdateActivity 刷新令牌时更新客户端IP、活跃时间和过期时间
+	UpdateActivity(ctx context.Context, id uint64, ip string, lastSeenAt, expiresAt time.Time) error
+
+	// Touch 更新最近活跃时间
+	Touch(ctx context.Context, id uint64, lastSeenAt time.Time) error
+
+	// Delete 删除会话
+	Delete(ctx context.Context, id uint64) error
+
+	// DeleteByUser 删除用户的全部会话
+	DeleteByUser(ctx context.Context, userID uint64) error
+}
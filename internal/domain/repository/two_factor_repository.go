@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"yiwen/go-ddd/internal/domain/entity"
+)
+
+var (
+	ErrTwoFactorNotFound          = errors.New("two-factor authentication not found")
+	ErrTwoFactorChallengeNotFound = errors.New("two-factor challenge not found")
+	// ErrTwoFactorStepUsed 该时间步的验证码已经使用过，并发提交同一个验证码时只有一个请求能成功
+	ErrTwoFactorStepUsed    = errors.New("two-factor code already used")
+	ErrRecoveryCodeNotFound = errors.New("recovery code not found")
+)
+
+// TwoFactorRepository 两步验证仓库接口
+type TwoFactorRepository interface {
+	// FindByUserID 查询用户的两步验证
+	FindByUserID(ctx context.Context, userID uint64) (*entity.TwoFactor, error)
+
+	// Save 保存两步验证，每个用户只有一条记录
+	Save(ctx context.Context, twoFactor *entity.TwoFactor) error
+
+	// Delete 删除用户的两步验证、恢复码和等待中的登录
+	Delete(ctx context.Context, userID uint64) error
+
+	// MarkStepUsed 原子地把最近使用的时间步推进到 step，step 不大于已使用的时间步时返回 ErrTwoFactorStepUsed
+	MarkStepUsed(ctx context.Context, userID uint64, step int64) error
+
+	// ReplaceRecoveryCodes 用新的恢复码哈希替换用户全部恢复码
+	ReplaceRecoveryCodes(ctx context.Context, userID uint64, codeHashes []string) error
+
+	// UseRecoveryCode 原子地把未使用的恢复码标记为已使用，不存在或已使用时返回 ErrRecoveryCodeNotFound
+	UseRecoveryCode(ctx context.Context, userID uint64, codeHash string) error
+
+	// CountRecoveryCodes 统计剩余可用的恢复码
+	CountRecoveryCodes(ctx context.Context, userID uint64) (int64, error)
+
+	// SaveChallenge 保存等待两步验证的登录，同时清理已过期的记录
+	SaveChallenge(ctx context.Context, challenge *entity.TwoFactorChallenge) error
+
+	// FindChallengeByHash 根据 MFA 令牌哈希查询
+	FindChallengeByHash(ctx context.Context, tokenHash string) (*entity.TwoFactorChallenge, error)
+
+	// IncrementChallengeAttempts 记录一次验证失败
+	IncrementChallengeAttempts(ctx context.Context, id uint64) error
+
+	// DeleteChallenge 删除等待中的登录，已被删除时返回 ErrTwoFactorChallengeNotFound
+	DeleteChallenge(ctx context.Context, id uint64) error
+}
@@ -0,0 +1,22 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"yiwen/go-ddd/internal/domain/aggregate"
+)
+
+// ErrConcurrencyConflict 聚合版本冲突，说明聚合在加载后已被其他请求修改
+var ErrConcurrencyConflict = errors.New("aggregate version conflict")
+
+// UserAggregateRepository 用户聚合仓库接口
+// 与 UserRepository 不同，它以聚合为单位进行加载和保存：
+// 1. Save 会在同一个事务中持久化聚合状态和聚合产生的领域事件
+// 2. 应用服务的写操作都应该通过它完成，保证事件不会丢失
+type UserAggregateRepository interface {
+	// Load 根据id加载用户聚合
+	Load(ctx context.Context, id uint64) (*aggregate.UserAggregate, error)
+
+	// Save 保存用户聚合及其未提交的领域事件
+	Save(ctx context.Context, agg *aggregate.UserAggregate) error
+}
@@ -2,9 +2,12 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"yiwen/go-ddd/internal/domain/entity"
 )
 
+var ErrUserNotFound = errors.New("user not found")
+
 // UserRepository 用户仓库接口
 // 仓储模式是DDD中的重要模式：
 // 1. 领域层只定义接口，不关心具体实现
@@ -38,4 +41,7 @@ type UserRepository interface {
 
 	// ExistsByEmail 检查邮箱是否存在
 	ExistsByEmail(ctx context.Context, email string) (bool, error)
+
+	// ExistsByRole 检查是否有用户拥有指定角色
+	ExistsByRole(ctx context.Context, role string) (bool, error)
 }
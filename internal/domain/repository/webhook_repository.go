@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+	"yiwen/go-ddd/internal/domain/entity"
+)
+
+var (
+	ErrWebhookEndpointNotFound = errors.New("webhook endpoint not found")
+	ErrWebhookDeliveryNotFound = errors.New("webhook delivery not found")
+)
+
+// WebhookDeliveryFilter 投递记录查询条件，零值表示不过滤
+type WebhookDeliveryFilter struct {
+	EndpointID uint64
+	EventType  string
+	Status     entity.WebhookDeliveryStatus
+}
+
+// WebhookRepository webhook 仓库接口
+type WebhookRepository interface {
+	// SaveEndpoint 保存端点
+	SaveEndpoint(ctx context.Context, endpoint *entity.WebhookEndpoint) error
+
+	// FindEndpointByID 根据id查询端点
+	FindEndpointByID(ctx context.Context, id uint64) (*entity.WebhookEndpoint, error)
+
+	// ListEndpoints 查询所有端点
+	ListEndpoints(ctx context.Context) ([]*entity.WebhookEndpoint, error)
+
+	// DeleteEndpoint 删除端点
+	DeleteEndpoint(ctx context.Context, id uint64) error
+
+	// SaveDelivery 保存投递记录
+	SaveDelivery(ctx context.Context, delivery *entity.WebhookDelivery) error
+
+	// EnqueueDelivery 创建投递记录，同一端点已有同一事件的投递记录时忽略，事件重复投递时不会重复发送
+	EnqueueDelivery(ctx context.Context, delivery *entity.WebhookDelivery) error
+
+	// FindDeliveryByID 根据id查询投递记录
+	FindDeliveryByID(ctx context.Context, id uint64) (*entity.WebhookDelivery, error)
+
+	// ListDeliveries 分页查询投递记录
+	ListDeliveries(ctx context.Context, filter WebhookDeliveryFilter, offset, limit int) ([]*entity.WebhookDelivery, int64, error)
+
+	// ClaimDueDeliveries 领取到期需要投递的记录，并把下次投递时间推迟 lease
+	// 租期内其他实例不会领取到同一条记录，实例在租期内没有保存结果时记录到期后被重新领取
+	ClaimDueDeliveries(ctx context.Context, limit int, lease time.Duration) ([]*entity.WebhookDelivery, error)
+}
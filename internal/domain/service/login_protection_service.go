@@ -0,0 +1,192 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+	"yiwen/go-ddd/internal/domain/repository"
+)
+
+var (
+	ErrAccountLocked  = errors.New("account temporarily locked due to too many failed login attempts")
+	ErrLoginThrottled = errors.New("too many failed login attempts, please try again later")
+)
+
+// LoginBlockedError 登录被拒绝及需要等待的时间，可以通过 errors.Is 与 ErrAccountLocked、ErrLoginThrottled 比较
+type LoginBlockedError struct {
+	Reason     error
+	RetryAfter time.Duration
+}
+
+func (e *LoginBlockedError) Error() string {
+	return e.Reason.Error()
+}
+
+func (e *LoginBlockedError) Unwrap() error {
+	return e.Reason
+}
+
+// LockoutPolicy 登录保护策略
+type LockoutPolicy struct {
+	MaxAccountFailures int           // 账户连续失败多少次后锁定，<= 0 表示不锁定
+	MaxIPFailures      int           // 同一IP连续失败多少次后锁定该IP，<= 0 表示不锁定
+	FailureWindow      time.Duration // 失败次数的统计窗口，距离上次失败超过窗口后重新计数
+	LockoutDuration    time.Duration // 锁定时长
+	BaseDelay          time.Duration // 失败后到下一次尝试的最短间隔，之后每失败一次翻倍
+	MaxDelay           time.Duration // 间隔的上限
+}
+
+// Lockout 一次登录失败导致账户被锁定
+type Lockout struct {
+	Failures    int
+	LockedUntil time.Time
+}
+
+// LoginProtectionService 登录保护领域服务
+// 1. 按账户和IP分别统计连续失败次数，不存在的用户名同样计数，避免通过锁定行为探测账户
+// 2. 每次失败后下一次尝试需要等待的时间逐次翻倍
+// 3. 失败次数达到阈值后临时锁定账户或IP，锁定期间即使密码正确也不能登录
+// 4. 登录成功后清零账户的失败次数；IP 的失败次数不清零，防止攻击者用自己的账户登录来重置计数
+// 5. 两步验证的失败次数按用户单独统计，知道密码的攻击者不能通过重新登录来重置计数
+type LoginProtectionService struct {
+	attemptRepo repository.LoginAttemptRepository
+	policy      LockoutPolicy
+}
+
+// NewLoginProtectionService 创建登录保护领域服务
+func NewLoginProtectionService(attemptRepo repository.LoginAttemptRepository, policy LockoutPolicy) *LoginProtectionService {
+	return &LoginProtectionService{attemptRepo: attemptRepo, policy: policy}
+}
+
+// Check 登录前检查账户和IP是否被锁定或者需要等待
+func (s *LoginProtectionService) Check(ctx context.Context, username, ip string) error {
+	if err := s.check(ctx, accountKey(username), ErrAccountLocked); err != nil {
+		return err
+	}
+	if ip == "" {
+		return nil
+	}
+	return s.check(ctx, ipKey(ip), ErrLoginThrottled)
+}
+
+// RecordFailure 记录一次密码错误，账户因此被锁定时返回锁定信息
+func (s *LoginProtectionService) RecordFailure(ctx context.Context, username, ip string) (*Lockout, error) {
+	if ip != "" {
+		if _, err := s.recordFailure(ctx, ipKey(ip), s.policy.MaxIPFailures); err != nil {
+			return nil, err
+		}
+	}
+	return s.recordFailure(ctx, accountKey(username), s.policy.MaxAccountFailures)
+}
+
+// RecordSuccess 登录成功后清零账户的失败次数
+func (s *LoginProtectionService) RecordSuccess(ctx context.Context, username string) error {
+	return s.attemptRepo.Reset(ctx, accountKey(username))
+}
+
+// Unlock 解除账户锁定并清零失败次数，返回账户之前是否处于锁定中
+func (s *LoginProtectionService) Unlock(ctx context.Context, username string) (bool, error) {
+	return s.unlock(ctx, accountKey(username))
+}
+
+// CheckSecondFactor 校验两步验证码前检查用户是否因验证码错误次数过多被锁定或者需要等待
+func (s *LoginProtectionService) CheckSecondFactor(ctx context.Context, userID uint64) error {
+	return s.check(ctx, secondFactorKey(userID), ErrAccountLocked)
+}
+
+// RecordSecondFactorFailure 记录一次两步验证码错误，用户因此被锁定时返回锁定信息
+func (s *LoginProtectionService) RecordSecondFactorFailure(ctx context.Context, userID uint64) (*Lockout, error) {
+	return s.recordFailure(ctx, secondFactorKey(userID), s.policy.MaxAccountFailures)
+}
+
+// RecordSecondFactorSuccess 两步验证通过后清零失败次数
+func (s *LoginProtectionService) RecordSecondFactorSuccess(ctx context.Context, userID uint64) error {
+	return s.attemptRepo.Reset(ctx, secondFactorKey(userID))
+}
+
+// UnlockSecondFactor 解除两步验证锁定并清零失败次数，返回之前是否处于锁定中
+func (s *LoginProtectionService) UnlockSecondFactor(ctx context.Context, userID uint64) (bool, error) {
+	return s.unlock(ctx, secondFactorKey(userID))
+}
+
+func (s *LoginProtectionService) unlock(ctx context.Context, key string) (bool, error) {
+	attempt, err := s.attemptRepo.Find(ctx, key)
+	if errors.Is(err, repository.ErrLoginAttemptNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	if err := s.attemptRepo.Reset(ctx, key); err != nil {
+		return false, err
+	}
+	return attempt.IsLocked(time.Now()), nil
+}
+
+func (s *LoginProtectionService) check(ctx context.Context, key string, lockedErr error) error {
+	attempt, err := s.attemptRepo.Find(ctx, key)
+	if errors.Is(err, repository.ErrLoginAttemptNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	if attempt.IsLocked(now) {
+		return &LoginBlockedError{Reason: lockedErr, RetryAfter: attempt.LockedUntil.Sub(now)}
+	}
+
+	failures := attempt.FailuresWithin(s.policy.FailureWindow, now)
+	if failures == 0 {
+		return nil
+	}
+	if next := attempt.LastFailedAt.Add(s.delay(failures)); now.Before(next) {
+		return &LoginBlockedError{Reason: ErrLoginThrottled, RetryAfter: next.Sub(now)}
+	}
+	return nil
+}
+
+func (s *LoginProtectionService) recordFailure(ctx context.Context, key string, maxFailures int) (*Lockout, error) {
+	attempt, err := s.attemptRepo.RecordFailure(ctx, key, s.policy.FailureWindow)
+	if err != nil {
+		return nil, err
+	}
+	if maxFailures <= 0 || attempt.Failures < maxFailures {
+		return nil, nil
+	}
+
+	lockedUntil := time.Now().Add(s.policy.LockoutDuration)
+	if err := s.attemptRepo.Lock(ctx, key, lockedUntil); err != nil {
+		return nil, err
+	}
+	return &Lockout{Failures: attempt.Failures, LockedUntil: lockedUntil}, nil
+}
+
+// delay 连续失败 failures 次后到下一次尝试的最短间隔
+func (s *LoginProtectionService) delay(failures int) time.Duration {
+	delay := s.policy.BaseDelay
+	for i := 1; i < failures && delay < s.policy.MaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > s.policy.MaxDelay {
+		delay = s.policy.MaxDelay
+	}
+	return delay
+}
+
+// accountKey 用户名不区分大小写，与 users 表的排序规则一致
+func accountKey(username string) string {
+	return "account:" + strings.ToLower(username)
+}
+
+func ipKey(ip string) string {
+	return "ip:" + ip
+}
+
+func secondFactorKey(userID uint64) string {
+	return "2fa:" + strconv.FormatUint(userID, 10)
+}
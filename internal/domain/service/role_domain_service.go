@@ -0,0 +1,191 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"yiwen/go-ddd/internal/domain/entity"
+	"yiwen/go-ddd/internal/domain/repository"
+)
+
+var (
+	ErrRoleAlreadyExists  = errors.New("role already exists")
+	ErrInvalidRoleName    = errors.New("role name must be 2-50 lowercase letters, digits, '_' or '-', starting with a letter")
+	ErrUnknownPermission  = errors.New("unknown permission")
+	ErrRoleHierarchyCycle = errors.New("role cannot inherit from itself or its descendants")
+	ErrBuiltinRole        = errors.New("built-in role cannot be deleted")
+	ErrRoleInUse          = errors.New("role is assigned to users or inherited by other roles")
+)
+
+// roleNamePattern 角色名称以逗号分隔保存在用户中，因此只允许小写字母、数字、_ 和 -
+var roleNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_-]{1,49}$`)
+
+// RoleDomainService 角色领域服务
+// 1. 校验角色名称、权限和继承关系，继承关系不能形成环
+// 2. 根据用户的角色及其祖先角色计算用户拥有的权限
+type RoleDomainService struct {
+	roleRepo repository.RoleRepository
+	userRepo repository.UserRepository
+}
+
+// NewRoleDomainService 创建角色领域服务
+func NewRoleDomainService(roleRepo repository.RoleRepository, userRepo repository.UserRepository) *RoleDomainService {
+	return &RoleDomainService{roleRepo: roleRepo, userRepo: userRepo}
+}
+
+// ValidateNewRoleName 校验新角色的名称格式，并且名称未被使用
+func (s *RoleDomainService) ValidateNewRoleName(ctx context.Context, name string) error {
+	if !roleNamePattern.MatchString(name) {
+		return ErrInvalidRoleName
+	}
+
+	_, err := s.roleRepo.FindByName(ctx, name)
+	if err == nil {
+		return ErrRoleAlreadyExists
+	}
+	if !errors.Is(err, repository.ErrRoleNotFound) {
+		return err
+	}
+	return nil
+}
+
+// ValidatePermissions 校验权限都是系统定义的权限
+func (s *RoleDomainService) ValidatePermissions(permissions []string) error {
+	for _, p := range permissions {
+		if !entity.IsKnownPermission(p) {
+			return fmt.Errorf("%w: %s", ErrUnknownPermission, p)
+		}
+	}
+	return nil
+}
+
+// ValidateParent 校验父角色存在，并且 roleID 不是父角色自身或其祖先，新角色的 roleID 为 0
+func (s *RoleDomainService) ValidateParent(ctx context.Context, roleID, parentID uint64) error {
+	if parentID == 0 {
+		return nil
+	}
+
+	roles, err := s.rolesByID(ctx)
+	if err != nil {
+		return err
+	}
+	if _, ok := roles[parentID]; !ok {
+		return fmt.Errorf("parent %w", repository.ErrRoleNotFound)
+	}
+
+	visited := map[uint64]bool{}
+	for id := parentID; id != 0 && !visited[id]; {
+		if id == roleID {
+			return ErrRoleHierarchyCycle
+		}
+		visited[id] = true
+		role, ok := roles[id]
+		if !ok {
+			break
+		}
+		id = role.ParentID
+	}
+	return nil
+}
+
+// ValidateDeletable 内置角色、已分配给用户或被其他角色继承的角色不能删除
+func (s *RoleDomainService) ValidateDeletable(ctx context.Context, role *entity.Role) error {
+	if role.IsBuiltin() {
+		return ErrBuiltinRole
+	}
+
+	roles, err := s.roleRepo.List(ctx)
+	if err != nil {
+		return err
+	}
+	for _, r := range roles {
+		if r.ParentID == role.ID {
+			return ErrRoleInUse
+		}
+	}
+
+	assigned, err := s.userRepo.ExistsByRole(ctx, role.Name)
+	if err != nil {
+		return err
+	}
+	if assigned {
+		return ErrRoleInUse
+	}
+	return nil
+}
+
+// HasPermission 判断用户的角色或其祖先角色是否包含指定权限，不存在的角色不提供任何权限
+func (s *RoleDomainService) HasPermission(ctx context.Context, user *entity.User, permission string) (bool, error) {
+	roles, err := s.resolveRoles(ctx, user.Roles)
+	if err != nil {
+		return false, err
+	}
+	for _, role := range roles {
+		if role.HasPermission(permission) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// EffectivePermissions 角色及其祖先角色的全部权限，包含 PermissionAll 时只返回 PermissionAll
+func (s *RoleDomainService) EffectivePermissions(ctx context.Context, roleNames []entity.UserRole) ([]string, error) {
+	roles, err := s.resolveRoles(ctx, roleNames)
+	if err != nil {
+		return nil, err
+	}
+
+	permissions := []string{}
+	for _, role := range roles {
+		for _, p := range role.Permissions {
+			if p == entity.PermissionAll {
+				return []string{entity.PermissionAll}, nil
+			}
+			if !slices.Contains(permissions, p) {
+				permissions = append(permissions, p)
+			}
+		}
+	}
+	slices.Sort(permissions)
+	return permissions, nil
+}
+
+// resolveRoles 查询角色及其全部祖先角色
+func (s *RoleDomainService) resolveRoles(ctx context.Context, roleNames []entity.UserRole) ([]*entity.Role, error) {
+	roles, err := s.rolesByID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	byName := make(map[string]*entity.Role, len(roles))
+	for _, role := range roles {
+		byName[role.Name] = role
+	}
+
+	var resolved []*entity.Role
+	visited := map[uint64]bool{}
+	for _, name := range roleNames {
+		role := byName[string(name)]
+		// 沿继承链向上，visited 同时防止数据被直接修改后出现环
+		for role != nil && !visited[role.ID] {
+			visited[role.ID] = true
+			resolved = append(resolved, role)
+			role = roles[role.ParentID]
+		}
+	}
+	return resolved, nil
+}
+
+func (s *RoleDomainService) rolesByID(ctx context.Context) (map[uint64]*entity.Role, error) {
+	roles, err := s.roleRepo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[uint64]*entity.Role, len(roles))
+	for _, role := range roles {
+		byID[role.ID] = role
+	}
+	return byID, nil
+}
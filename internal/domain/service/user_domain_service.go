@@ -14,6 +14,8 @@ var (
 	ErrUserNotActive         = errors.New("user not active")
 	ErrInvalidCredentials    = errors.New("invalid credentials")
 	ErrUserNotAdmin          = errors.New("user is not an admin")
+	// ErrPasswordResetRequired 用户的密码哈希在旧版本事件中丢失，需要通过找回密码重新设置后才能登录
+	ErrPasswordResetRequired = errors.New("password reset required")
 )
 
 // UserDomainService 用户领域服务
@@ -51,11 +53,25 @@ func (s *UserDomainService) ValidateUniqueEmail(ctx context.Context, email strin
 	return nil
 }
 
+// ValidateUserCredentials 校验用户名和密码，用户不存在与密码错误返回相同的 ErrInvalidCredentials
+// 需要重新设置密码的用户返回 ErrPasswordResetRequired
 func (s *UserDomainService) ValidateUserCredentials(ctx context.Context, username, password string) (*entity.User, error) {
+	exists, err := s.userRepo.ExistsByUsername(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, ErrInvalidCredentials
+	}
+
 	user, err := s.userRepo.FindByUsername(ctx, username)
 	if err != nil {
 		return nil, err
 	}
+	// 没有密码哈希可供校验
+	if user.PasswordResetRequired {
+		return nil, ErrPasswordResetRequired
+	}
 	if err := user.Password.Verify(password); err != nil {
 		return nil, ErrInvalidCredentials
 	}
@@ -65,20 +81,6 @@ func (s *UserDomainService) ValidateUserCredentials(ctx context.Context, usernam
 	return user, nil
 }
 
-func (s *UserDomainService) CanUserPerformAction(user *entity.User, action string) bool {
-	if user.IsAdmin() {
-		return true
-	}
-
-	alowedActions := map[string]bool{
-		"view_profile":    true,
-		"update_profile":  true,
-		"change_password": true,
-	}
-
-	return alowedActions[action]
-}
-
 func (s *UserDomainService) TransferAdmin(ctx context.Context, userID uint64) error {
 	user, err := s.userRepo.FindByID(ctx, userID)
 	if err != nil {
@@ -0,0 +1,67 @@
+package audit
+
+import (
+	"context"
+	"yiwen/go-ddd/internal/domain/entity"
+	"yiwen/go-ddd/internal/domain/event"
+	"yiwen/go-ddd/internal/domain/repository"
+)
+
+// Projector 审计日志投影
+// 作为事件处理器订阅事件总线，把用户领域事件及其元数据转换为审计日志。
+// outbox 的投递语义为至少一次，重复的事件按事件ID去重
+type Projector struct {
+	auditRepo repository.AuditLogRepository
+}
+
+func NewProjector(auditRepo repository.AuditLogRepository) *Projector {
+	return &Projector{auditRepo: auditRepo}
+}
+
+// ProjectionName 审计日志投影名称
+const ProjectionName = "audit"
+
+// Name 实现 projection.Projection
+func (p *Projector) Name() string {
+	return ProjectionName
+}
+
+// Reset 实现 projection.Projection，清空由事件投影生成的审计日志
+func (p *Projector) Reset(ctx context.Context) error {
+	return p.auditRepo.DeleteProjected(ctx)
+}
+
+// Handle 实现 event.EventHandler
+func (p *Projector) Handle(e event.Event) error {
+	return p.auditRepo.Save(context.Background(), toAuditLog(e))
+}
+
+// toAuditLog 把领域事件转换为审计日志
+func toAuditLog(e event.Event) *entity.AuditLog {
+	meta := e.Metadata()
+
+	return &entity.AuditLog{
+		EventID:    e.EventID(),
+		Source:     entity.AuditSourceEvent,
+		ActorID:    meta.ActorID,
+		ActorName:  meta.ActorName,
+		TargetUUID: e.AggregateID(),
+		Action:     e.EventName(),
+		Reason:     reasonOf(e),
+		IP:         meta.IP,
+		UserAgent:  meta.UserAgent,
+		OccurredAt: e.OccurredAt(),
+	}
+}
+
+// reasonOf 返回事件中携带的操作原因
+func reasonOf(e event.Event) string {
+	switch ev := e.(type) {
+	case *event.UserBannedEvent:
+		return ev.Reason
+	case *event.UserDeletedEvent:
+		return ev.Reason
+	default:
+		return ""
+	}
+}
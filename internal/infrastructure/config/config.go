@@ -9,9 +9,21 @@ import (
 )
 
 type Config struct {
-	App      AppConfig      `mapstructure:"app"`
-	Database DatabaseConfig `mapstructure:"databse"`
-	JWT      JWTConfig      `mapstructure:"jwt"`
+	App               AppConfig               `mapstructure:"app"`
+	Database          DatabaseConfig          `mapstructure:"databse"`
+	Persistence       PersistenceConfig       `mapstructure:"persistence"`
+	JWT               JWTConfig               `mapstructure:"jwt"`
+	Outbox            OutboxConfig            `mapstructure:"outbox"`
+	Webhook           WebhookConfig           `mapstructure:"webhook"`
+	EventStream       EventStreamConfig       `mapstructure:"event_stream"`
+	Saga              SagaConfig              `mapstructure:"saga"`
+	OAuth             OAuthConfig             `mapstructure:"oauth"`
+	SSO               SSOConfig               `mapstructure:"sso"`
+	TwoFactor         TwoFactorConfig         `mapstructure:"two_factor"`
+	WebAuthn          WebAuthnConfig          `mapstructure:"webauthn"`
+	EmailVerification EmailVerificationConfig `mapstructure:"email_verification"`
+	PasswordReset     PasswordResetConfig     `mapstructure:"password_reset"`
+	LoginProtection   LoginProtectionConfig   `mapstructure:"login_protection"`
 }
 
 type AppConfig struct {
@@ -34,10 +46,139 @@ func (c *DatabaseConfig) DSN() string {
 	return fmt.Sprintf("%s:%s@tcp(%s:%d)/%s?charset=utf8mb4&parseTime=True&loc=Local", c.Username, c.Password, c.Host, c.Port, c.Database)
 }
 
+// 用户聚合的持久化方式
+const (
+	PersistenceModeState        = "state"         // 状态存储，users 表是事实来源
+	PersistenceModeEventSourced = "event_sourced" // 事件溯源，事件存储是事实来源
+)
+
+type PersistenceConfig struct {
+	Mode          string `mapstructure:"mode"`
+	SnapshotEvery int    `mapstructure:"snapshot_every"` // 每追加多少个事件生成一次快照，0 表示不生成
+}
+
 type JWTConfig struct {
-	Secret     string `mapstructure:"secret"`
-	ExpireHour int    `mapstructure:"expire_hour"`
-	Issuer     string `mapstructure:"issuer"`
+	Secret             string         `mapstructure:"secret"`               // HS256 密钥，未配置 signing_key_id 时使用
+	AccessExpireMinute int            `mapstructure:"access_expire_minute"` // 访问令牌有效期
+	RefreshExpireDay   int            `mapstructure:"refresh_expire_day"`   // 刷新令牌有效期
+	Issuer             string         `mapstructure:"issuer"`
+	SigningKeyID       string         `mapstructure:"signing_key_id"`      // 当前签名密钥，必须是 keys 中带私钥的一项
+	Keys               []JWTKeyConfig `mapstructure:"keys"`                // 全部可用于验证的密钥
+	LegacySecretUntil  string         `mapstructure:"legacy_secret_until"` // 配置 signing_key_id 后继续接受 secret 签发的旧令牌的截止时间，RFC3339 格式，为空时不再接受
+}
+
+// JWTKeyConfig 非对称签名密钥配置
+// 只配置 public_key_file 的密钥仅用于验证，通常是轮换下来的旧密钥
+type JWTKeyConfig struct {
+	ID             string `mapstructure:"id"`
+	Algorithm      string `mapstructure:"algorithm"` // RS256、ES256、EdDSA 等
+	PrivateKeyFile string `mapstructure:"private_key_file"`
+	PublicKeyFile  string `mapstructure:"public_key_file"`
+}
+
+// OutboxConfig outbox 投递配置
+type OutboxConfig struct {
+	PollIntervalSecond int `mapstructure:"poll_interval_second"` // 轮询间隔
+	BatchSize          int `mapstructure:"batch_size"`           // 每批读取的事件数
+	MaxAttempts        int `mapstructure:"max_attempts"`         // 最大投递次数
+	BaseBackoffSecond  int `mapstructure:"base_backoff_second"`  // 首次重试等待时间
+	MaxBackoffSecond   int `mapstructure:"max_backoff_second"`   // 最长重试等待时间
+	LeaseSecond        int `mapstructure:"lease_second"`         // 领取事件的租期，需要大于投递一批事件的耗时
+}
+
+// WebhookConfig webhook 投递配置
+type WebhookConfig struct {
+	PollIntervalSecond int `mapstructure:"poll_interval_second"` // 轮询间隔
+	BatchSize          int `mapstructure:"batch_size"`           // 每批投递数量
+	TimeoutSecond      int `mapstructure:"timeout_second"`       // 单次请求超时
+	MaxAttempts        int `mapstructure:"max_attempts"`         // 超过后进入死信
+	BaseBackoffSecond  int `mapstructure:"base_backoff_second"`  // 首次重试等待时间
+	MaxBackoffSecond   int `mapstructure:"max_backoff_second"`   // 最长重试等待时间
+	LeaseSecond        int `mapstructure:"lease_second"`         // 领取投递记录的租期，需要大于投递一批记录的耗时
+}
+
+// EventStreamConfig 管理后台事件流配置
+type EventStreamConfig struct {
+	BufferSize         int `mapstructure:"buffer_size"`          // 保留最近多少条事件用于断线续传
+	HeartbeatSecond    int `mapstructure:"heartbeat_second"`     // 心跳间隔
+	PollIntervalSecond int `mapstructure:"poll_interval_second"` // 读取新事件的轮询间隔
+}
+
+// SagaConfig 流程管理器配置
+type SagaConfig struct {
+	PollIntervalSecond     int  `mapstructure:"poll_interval_second"`     // 超时调度轮询间隔
+	BatchSize              int  `mapstructure:"batch_size"`               // 每批处理的超时数
+	DeactivateUnverified   bool `mapstructure:"deactivate_unverified"`    // 是否将注册后超时未验证邮箱的用户设为未激活
+	VerificationTimeoutDay int  `mapstructure:"verification_timeout_day"` // 注册后未验证邮箱多少天设为未激活，0 表示不限制
+}
+
+// OAuthConfig OAuth2 授权服务配置
+type OAuthConfig struct {
+	AuthorizationCodeExpireSecond int    `mapstructure:"authorization_code_expire_second"` // 授权码有效期
+	AuthorizePageURL              string `mapstructure:"authorize_page_url"`               // 前端授权页面，浏览器在该页面登录并确认授权
+}
+
+// SSOConfig 外部身份提供方登录配置
+type SSOConfig struct {
+	StateExpireSecond int                  `mapstructure:"state_expire_second"` // 跳转到提供方后多长时间内必须完成回调
+	Providers         []OIDCProviderConfig `mapstructure:"providers"`
+}
+
+// OIDCProviderConfig OIDC 身份提供方配置，端点通过 issuer 的发现文档获取
+type OIDCProviderConfig struct {
+	Name         string   `mapstructure:"name"` // 提供方标识，出现在登录和回调地址中
+	DisplayName  string   `mapstructure:"display_name"`
+	Issuer       string   `mapstructure:"issuer"`
+	ClientID     string   `mapstructure:"client_id"`
+	ClientSecret string   `mapstructure:"client_secret"`
+	RedirectURL  string   `mapstructure:"redirect_url"` // 在提供方登记的回调地址，指向 /api/v1/auth/sso/:provider/callback
+	Scopes       []string `mapstructure:"scopes"`       // 默认 openid profile email
+}
+
+// TwoFactorConfig 两步验证配置
+type TwoFactorConfig struct {
+	Issuer                string `mapstructure:"issuer"`                  // 认证器应用中显示的服务名称，默认使用 app.name
+	ChallengeExpireSecond int    `mapstructure:"challenge_expire_second"` // 密码验证通过后多长时间内必须完成两步验证
+}
+
+// WebAuthnConfig 通行密钥配置
+type WebAuthnConfig struct {
+	RPID          string   `mapstructure:"rp_id"`          // 依赖方ID，必须是前端页面的域名或其上级域名
+	RPName        string   `mapstructure:"rp_name"`        // 创建通行密钥时显示的服务名称，默认使用 app.name
+	Origins       []string `mapstructure:"origins"`        // 允许发起注册和登录的前端页面来源
+	TimeoutSecond int      `mapstructure:"timeout_second"` // 获取选项后多长时间内必须完成注册或登录
+}
+
+// EmailVerificationConfig 邮箱验证配置
+type EmailVerificationConfig struct {
+	Required             bool   `mapstructure:"required"`               // 开启后注册的用户处于未激活状态，验证邮箱后才能登录
+	VerifyPageURL        string `mapstructure:"verify_page_url"`        // 前端验证页面，邮件中的链接为 {verify_page_url}?token=...
+	TokenExpireHour      int    `mapstructure:"token_expire_hour"`      // 验证令牌有效期
+	ResendIntervalSecond int    `mapstructure:"resend_interval_second"` // 同一用户两次发送验证邮件的最小间隔
+}
+
+// PasswordResetConfig 找回密码配置
+type PasswordResetConfig struct {
+	ResetPageURL         string `mapstructure:"reset_page_url"`         // 前端重置密码页面，邮件中的链接为 {reset_page_url}?token=...
+	TokenExpireMinute    int    `mapstructure:"token_expire_minute"`    // 重置令牌有效期
+	ResendIntervalSecond int    `mapstructure:"resend_interval_second"` // 同一用户两次发送重置邮件的最小间隔
+}
+
+// 登录失败记录的存储方式
+const (
+	LoginAttemptStoreMemory   = "memory"   // 进程内存，只适用于单实例部署
+	LoginAttemptStoreDatabase = "database" // 数据库，多个实例共享计数
+)
+
+// LoginProtectionConfig 登录保护配置
+type LoginProtectionConfig struct {
+	Store               string `mapstructure:"store"`                 // 登录失败记录的存储方式
+	MaxAccountFailures  int    `mapstructure:"max_account_failures"`  // 账户连续失败多少次后锁定
+	MaxIPFailures       int    `mapstructure:"max_ip_failures"`       // 同一IP连续失败多少次后锁定该IP
+	FailureWindowMinute int    `mapstructure:"failure_window_minute"` // 距离上次失败超过该时间后重新计数
+	LockoutMinute       int    `mapstructure:"lockout_minute"`        // 锁定时长
+	BaseDelaySecond     int    `mapstructure:"base_delay_second"`     // 失败后到下一次尝试的最短间隔，之后每失败一次翻倍
+	MaxDelaySecond      int    `mapstructure:"max_delay_second"`      // 间隔的上限
 }
 
 func Load(configPath string) (*Config, error) {
@@ -64,6 +205,10 @@ func Load(configPath string) (*Config, error) {
 		config.App.Mode = "debug"
 	}
 
+	if config.Persistence.Mode == "" {
+		config.Persistence.Mode = PersistenceModeState
+	}
+
 	if config.Database.MaxIdleConns == 0 {
 		config.Database.MaxIdleConns = 10
 	}
@@ -71,8 +216,134 @@ func Load(configPath string) (*Config, error) {
 		config.Database.MaxOpenConns = 100
 	}
 
-	if config.JWT.ExpireHour == 0 {
-		config.JWT.ExpireHour = 24
+	if config.JWT.AccessExpireMinute == 0 {
+		config.JWT.AccessExpireMinute = 15
+	}
+	if config.JWT.RefreshExpireDay == 0 {
+		config.JWT.RefreshExpireDay = 30
+	}
+
+	if config.Outbox.PollIntervalSecond == 0 {
+		config.Outbox.PollIntervalSecond = 1
+	}
+	if config.Outbox.BatchSize == 0 {
+		config.Outbox.BatchSize = 100
+	}
+	if config.Outbox.MaxAttempts == 0 {
+		config.Outbox.MaxAttempts = 10
+	}
+	if config.Outbox.BaseBackoffSecond == 0 {
+		config.Outbox.BaseBackoffSecond = 1
+	}
+	if config.Outbox.MaxBackoffSecond == 0 {
+		config.Outbox.MaxBackoffSecond = 300
+	}
+	if config.Outbox.LeaseSecond == 0 {
+		config.Outbox.LeaseSecond = 60
+	}
+
+	if config.Webhook.PollIntervalSecond == 0 {
+		config.Webhook.PollIntervalSecond = 5
+	}
+	if config.Webhook.BatchSize == 0 {
+		config.Webhook.BatchSize = 50
+	}
+	if config.Webhook.TimeoutSecond == 0 {
+		config.Webhook.TimeoutSecond = 10
+	}
+	if config.Webhook.MaxAttempts == 0 {
+		config.Webhook.MaxAttempts = 8
+	}
+	if config.Webhook.BaseBackoffSecond == 0 {
+		config.Webhook.BaseBackoffSecond = 30
+	}
+	if config.Webhook.MaxBackoffSecond == 0 {
+		config.Webhook.MaxBackoffSecond = 3600
+	}
+	if config.Webhook.LeaseSecond == 0 {
+		// 一批记录依次发送，最坏情况下每条都等到超时
+		config.Webhook.LeaseSecond = config.Webhook.BatchSize*config.Webhook.TimeoutSecond + 60
+	}
+
+	if config.EventStream.BufferSize == 0 {
+		config.EventStream.BufferSize = 1000
+	}
+	if config.EventStream.HeartbeatSecond == 0 {
+		config.EventStream.HeartbeatSecond = 15
+	}
+	if config.EventStream.PollIntervalSecond == 0 {
+		config.EventStream.PollIntervalSecond = 1
+	}
+
+	if config.Saga.PollIntervalSecond == 0 {
+		config.Saga.PollIntervalSecond = 60
+	}
+	if config.Saga.BatchSize == 0 {
+		config.Saga.BatchSize = 100
+	}
+
+	if config.OAuth.AuthorizationCodeExpireSecond == 0 {
+		config.OAuth.AuthorizationCodeExpireSecond = 600
+	}
+
+	if config.SSO.StateExpireSecond == 0 {
+		config.SSO.StateExpireSecond = 600
+	}
+
+	if config.TwoFactor.Issuer == "" {
+		config.TwoFactor.Issuer = config.App.Name
+	}
+	if config.TwoFactor.ChallengeExpireSecond == 0 {
+		config.TwoFactor.ChallengeExpireSecond = 300
+	}
+
+	if config.WebAuthn.RPID == "" {
+		config.WebAuthn.RPID = "localhost"
+	}
+	if config.WebAuthn.RPName == "" {
+		config.WebAuthn.RPName = config.App.Name
+	}
+	if len(config.WebAuthn.Origins) == 0 {
+		config.WebAuthn.Origins = []string{fmt.Sprintf("http://localhost:%d", config.App.Port)}
+	}
+	if config.WebAuthn.TimeoutSecond == 0 {
+		config.WebAuthn.TimeoutSecond = 300
+	}
+
+	if config.EmailVerification.TokenExpireHour == 0 {
+		config.EmailVerification.TokenExpireHour = 24
+	}
+	if config.EmailVerification.ResendIntervalSecond == 0 {
+		config.EmailVerification.ResendIntervalSecond = 60
+	}
+
+	if config.PasswordReset.TokenExpireMinute == 0 {
+		config.PasswordReset.TokenExpireMinute = 30
+	}
+	if config.PasswordReset.ResendIntervalSecond == 0 {
+		config.PasswordReset.ResendIntervalSecond = 60
+	}
+
+	if config.LoginProtection.Store == "" {
+		config.LoginProtection.Store = LoginAttemptStoreDatabase
+	}
+	if config.LoginProtection.MaxAccountFailures == 0 {
+		config.LoginProtection.MaxAccountFailures = 5
+	}
+	if config.LoginProtection.MaxIPFailures == 0 {
+		config.LoginProtection.MaxIPFailures = 20
+	}
+	if config.LoginProtection.FailureWindowMinute == 0 {
+		config.LoginProtection.FailureWindowMinute = 15
+	}
+	if config.LoginProtection.LockoutMinute == 0 {
+		config.LoginProtection.LockoutMinute = 15
+	}
+	if config.LoginProtection.BaseDelaySecond == 0 {
+		config.LoginProtection.BaseDelaySecond = 1
+	}
+	if config.LoginProtection.MaxDelaySecond == 0 {
+		config.LoginProtection.MaxDelaySecond = 30
 	}
 
 	return &config, nil
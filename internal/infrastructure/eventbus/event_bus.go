@@ -0,0 +1,102 @@
+package eventbus
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"yiwen/go-ddd/internal/domain/event"
+)
+
+// Wildcard 通配符，订阅该名称的处理器会收到所有事件
+const Wildcard = "*"
+
+// EventBus 进程内事件总线
+// 实现领域层定义的 event.EventPublisher 接口
+// 1. 按 EventName() 订阅事件，也可以使用通配符订阅全部事件
+// 2. 同步模式下在 Publish 中依次调用处理器，并返回处理器的错误
+// 3. 异步模式下每个处理器在独立的 goroutine 中执行，错误只记录日志
+type EventBus struct {
+	mu       sync.RWMutex
+	handlers map[string][]event.EventHandler
+	async    bool
+	wg       sync.WaitGroup
+}
+
+// NewEventBus 创建事件总线
+func NewEventBus(async bool) *EventBus {
+	return &EventBus{
+		handlers: make(map[string][]event.EventHandler),
+		async:    async,
+	}
+}
+
+// Subscribe 订阅指定名称的事件
+func (b *EventBus) Subscribe(eventName string, handler event.EventHandler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.handlers[eventName] = append(b.handlers[eventName], handler)
+}
+
+// SubscribeAll 订阅所有事件
+func (b *EventBus) SubscribeAll(handler event.EventHandler) {
+	b.Subscribe(Wildcard, handler)
+}
+
+// Publish 发布事件
+func (b *EventBus) Publish(e event.Event) error {
+	handlers := b.handlersFor(e.EventName())
+	if len(handlers) == 0 {
+		return nil
+	}
+
+	if b.async {
+		for _, h := range handlers {
+			b.wg.Add(1)
+			go func(h event.EventHandler) {
+				defer b.wg.Done()
+				if err := dispatch(h, e); err != nil {
+					log.Printf("eventbus: handle %s failed: %v", e.EventName(), err)
+				}
+			}(h)
+		}
+		return nil
+	}
+
+	var errs []error
+	for _, h := range handlers {
+		if err := dispatch(h, e); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Wait 等待所有异步处理器执行完毕，通常在服务关闭时调用
+func (b *EventBus) Wait() {
+	b.wg.Wait()
+}
+
+// handlersFor 返回精确订阅和通配符订阅的处理器
+func (b *EventBus) handlersFor(eventName string) []event.EventHandler {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	handlers := make([]event.EventHandler, 0, len(b.handlers[eventName])+len(b.handlers[Wildcard]))
+	handlers = append(handlers, b.handlers[eventName]...)
+	if eventName != Wildcard {
+		handlers = append(handlers, b.handlers[Wildcard]...)
+	}
+	return handlers
+}
+
+// dispatch 调用处理器，并把处理器中的 panic 转换为错误，避免影响发布方
+func dispatch(h event.EventHandler, e event.Event) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("handler panic: %v", r)
+		}
+	}()
+	return h.Handle(e)
+}
@@ -0,0 +1,229 @@
+package eventstream
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"strconv"
+	"sync"
+	"time"
+	"yiwen/go-ddd/internal/domain/event"
+	"yiwen/go-ddd/internal/infrastructure/persistence/model"
+)
+
+// subscriberBuffer 每个订阅者的缓冲区大小
+const subscriberBuffer = 64
+
+// tailBatchSize 每次读取的事件数量
+const tailBatchSize = 500
+
+// settleDelay 只读取写入超过该时间的事件
+// 自增 id 在事务开始写入时分配，提交顺序可能与 id 顺序不同，等待较早的事务提交后再读取，避免跳过事件
+const settleDelay = 2 * time.Second
+
+// ResetType 重连时 Last-Event-ID 之后的事件已不在缓冲区中，客户端需要重新加载数据
+const ResetType = "stream.reset"
+
+// Source 按写入顺序读取所有实例共享的事件日志
+type Source interface {
+	LatestID(ctx context.Context) (uint64, error)
+	Tail(ctx context.Context, afterID uint64, settledBefore time.Time, limit int) ([]*model.OutboxEventModel, error)
+}
+
+// Message 推送给订阅者的事件
+type Message struct {
+	ID   string // 事件在 outbox 中的 id，作为 SSE 的 id 字段，客户端重连时通过 Last-Event-ID 带回
+	Type string // 事件类型
+	Data []byte // 去除敏感字段的事件信封 JSON
+	pos  uint64
+}
+
+// resetData ResetType 消息的内容
+type resetData struct {
+	LastEventID string `json:"last_event_id"`
+}
+
+// Subscription 事件订阅
+type Subscription struct {
+	C     chan Message
+	types map[string]bool
+	after uint64 // 只推送位置在它之后的事件
+}
+
+// accepts 判断订阅是否关心该事件，未指定类型时接收全部事件
+func (s *Subscription) accepts(msg Message) bool {
+	return msg.pos > s.after && (len(s.types) == 0 || s.types[msg.Type])
+}
+
+// Broker 事件流广播器
+// 每个实例各自按 id 顺序读取 outbox_events 表，把事件广播给本实例在线的订阅者：
+// 1. outbox 由所有实例共享，不受 relay 由哪个实例投递的影响，每个实例都能收到全部事件
+// 2. 事件的位置即 outbox 的 id，各实例相同，客户端断线后连到任一实例都可以从 Last-Event-ID 之后继续接收
+// 3. 保留最近 bufferSize 条事件，Last-Event-ID 之后的事件已被挤出缓冲区时，先推送 ResetType 消息，由客户端重新加载
+// 4. 订阅者处理过慢导致缓冲区满时会被断开，由客户端重连补齐
+type Broker struct {
+	mu          sync.RWMutex
+	ready       bool   // 已确定起始位置
+	floor       uint64 // 缓冲区覆盖 floor 之后的全部事件
+	latest      uint64 // 最近一条事件的位置
+	history     []Message
+	bufferSize  int
+	subscribers map[*Subscription]struct{}
+}
+
+func NewBroker(bufferSize int) *Broker {
+	return &Broker{
+		history:     make([]Message, 0, bufferSize),
+		bufferSize:  bufferSize,
+		subscribers: make(map[*Subscription]struct{}),
+	}
+}
+
+// Run 从当前最新的事件之后开始轮询事件日志，直到 ctx 被取消
+func (b *Broker) Run(ctx context.Context, source Source, pollInterval time.Duration) {
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := b.poll(ctx, source); err != nil {
+			log.Printf("eventstream: poll failed: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// poll 读取并广播新写入的事件
+func (b *Broker) poll(ctx context.Context, source Source) error {
+	b.mu.RLock()
+	ready, cursor := b.ready, b.latest
+	b.mu.RUnlock()
+
+	if !ready {
+		latest, err := source.LatestID(ctx)
+		if err != nil {
+			return err
+		}
+		b.mu.Lock()
+		b.ready, b.floor, b.latest = true, latest, latest
+		b.mu.Unlock()
+		return nil
+	}
+
+	for {
+		records, err := source.Tail(ctx, cursor, time.Now().Add(-settleDelay), tailBatchSize)
+		if err != nil {
+			return err
+		}
+		for _, record := range records {
+			if err := b.publish(record); err != nil {
+				log.Printf("eventstream: skip event %d: %v", record.ID, err)
+			}
+			cursor = record.ID
+		}
+		if len(records) < tailBatchSize {
+			return nil
+		}
+	}
+}
+
+// publish 把一条事件加入缓冲区并推送给订阅者
+func (b *Broker) publish(record *model.OutboxEventModel) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.latest = record.ID
+
+	e, err := record.ToEvent()
+	if err != nil {
+		return err
+	}
+	data, err := event.EncodePublic(e)
+	if err != nil {
+		return err
+	}
+	msg := Message{ID: strconv.FormatUint(record.ID, 10), Type: e.EventName(), Data: data, pos: record.ID}
+
+	if len(b.history) == b.bufferSize {
+		b.floor = b.history[0].pos
+		b.history = append(b.history[:0], b.history[1:]...)
+	}
+	b.history = append(b.history, msg)
+
+	for sub := range b.subscribers {
+		if !sub.accepts(msg) {
+			continue
+		}
+		select {
+		case sub.C <- msg:
+		default:
+			b.remove(sub)
+		}
+	}
+	return nil
+}
+
+// Subscribe 订阅事件流，types 为空表示订阅全部类型
+// lastEventID 不为空时返回该位置之后错过的事件，错过的事件已不在缓冲区中时只返回一条 ResetType 消息
+func (b *Broker) Subscribe(types []string, lastEventID string) (*Subscription, []Message) {
+	sub := &Subscription{
+		C:     make(chan Message, subscriberBuffer),
+		types: make(map[string]bool, len(types)),
+	}
+	for _, t := range types {
+		sub.types[t] = true
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var missed []Message
+	if lastEventID != "" {
+		missed = b.missed(sub, lastEventID)
+	}
+
+	b.subscribers[sub] = struct{}{}
+	return sub, missed
+}
+
+// missed 返回 lastEventID 之后订阅者关心的事件，调用方需持有锁
+// lastEventID 来自读取进度更快的其他实例时，本实例稍后读到的事件中位置不超过它的不会重复推送
+func (b *Broker) missed(sub *Subscription, lastEventID string) []Message {
+	pos, err := strconv.ParseUint(lastEventID, 10, 64)
+	if err != nil || !b.ready || pos < b.floor {
+		sub.after = b.latest
+		// 只含字符串字段，序列化不会失败
+		data, _ := json.Marshal(resetData{LastEventID: lastEventID})
+		return []Message{{ID: strconv.FormatUint(b.latest, 10), Type: ResetType, Data: data, pos: b.latest}}
+	}
+
+	sub.after = pos
+	var missed []Message
+	for _, msg := range b.history {
+		if sub.accepts(msg) {
+			missed = append(missed, msg)
+		}
+	}
+	return missed
+}
+
+// Unsubscribe 取消订阅
+func (b *Broker) Unsubscribe(sub *Subscription) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.remove(sub)
+}
+
+// remove 移除订阅者并关闭通道，调用方需持有写锁
+func (b *Broker) remove(sub *Subscription) {
+	if _, ok := b.subscribers[sub]; !ok {
+		return
+	}
+	delete(b.subscribers, sub)
+	close(sub.C)
+}
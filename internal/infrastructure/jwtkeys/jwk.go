@@ -0,0 +1,198 @@
+package jwtkeys
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// JSONWebKeySet JWKS 文档
+type JSONWebKeySet struct {
+	Keys []JSONWebKey `json:"keys"`
+}
+
+// JSONWebKey 单个公钥，字段含义见 RFC 7517/7518/8037
+type JSONWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Alg string `json:"alg"`
+	Use string `json:"use"`
+	// RSA
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+	// EC / OKP
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+}
+
+func toJWK(key *Key) JSONWebKey {
+	jwk := JSONWebKey{
+		Kid: key.ID,
+		Alg: key.Method.Alg(),
+		Use: "sig",
+	}
+
+	switch public := key.verifyKey.(type) {
+	case *rsa.PublicKey:
+		jwk.Kty = "RSA"
+		jwk.N = encode(public.N.Bytes())
+		jwk.E = encode(big.NewInt(int64(public.E)).Bytes())
+	case *ecdsa.PublicKey:
+		// 坐标按曲线长度左侧补零
+		size := (public.Curve.Params().BitSize + 7) / 8
+		jwk.Kty = "EC"
+		jwk.Crv = public.Curve.Params().Name
+		jwk.X = encode(public.X.FillBytes(make([]byte, size)))
+		jwk.Y = encode(public.Y.FillBytes(make([]byte, size)))
+	case ed25519.PublicKey:
+		jwk.Kty = "OKP"
+		jwk.Crv = "Ed25519"
+		jwk.X = encode(public)
+	}
+	return jwk
+}
+
+// ParseJWKS 把外部发布的 JWKS 解析为只能验证的密钥集合，用于验证外部身份提供方签发的令牌
+// 用途为加密的密钥和不支持的密钥类型会被跳过
+// 只有一个密钥时不带 kid 的令牌使用该密钥验证，有多个密钥时令牌必须带有 kid
+func ParseJWKS(set *JSONWebKeySet) (*KeySet, error) {
+	keys := &KeySet{keys: make(map[string]*Key)}
+	for _, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		key, err := fromJWK(jwk)
+		if err != nil {
+			if errors.Is(err, errUnsupportedJWK) {
+				continue
+			}
+			return nil, fmt.Errorf("invalid jwk %q: %w", jwk.Kid, err)
+		}
+		keys.keys[key.ID] = key
+		keys.ordered = append(keys.ordered, key)
+	}
+	if len(keys.ordered) == 1 {
+		keys.fallback = keys.ordered[0]
+	}
+	return keys, nil
+}
+
+var errUnsupportedJWK = errors.New("unsupported jwk")
+
+func fromJWK(jwk JSONWebKey) (*Key, error) {
+	alg := jwk.Alg
+	var public interface{}
+
+	switch jwk.Kty {
+	case "RSA":
+		n, err := decode(jwk.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decode(jwk.E)
+		if err != nil {
+			return nil, err
+		}
+		public = &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+		if alg == "" {
+			alg = "RS256"
+		}
+	case "EC":
+		curve, curveAlg := curveByName(jwk.Crv)
+		if curve == nil {
+			return nil, errUnsupportedJWK
+		}
+		x, err := decode(jwk.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decode(jwk.Y)
+		if err != nil {
+			return nil, err
+		}
+		// 通过非压缩格式解析，顺带校验点是否在曲线上
+		size := (curve.Params().BitSize + 7) / 8
+		if len(x) != size || len(y) != size {
+			return nil, errors.New("invalid ec coordinates")
+		}
+		key, err := ecdsa.ParseUncompressedPublicKey(curve, append(append([]byte{4}, x...), y...))
+		if err != nil {
+			return nil, err
+		}
+		public = key
+		if alg == "" {
+			alg = curveAlg
+		}
+	case "OKP":
+		if jwk.Crv != "Ed25519" {
+			return nil, errUnsupportedJWK
+		}
+		x, err := decode(jwk.X)
+		if err != nil {
+			return nil, err
+		}
+		if len(x) != ed25519.PublicKeySize {
+			return nil, errors.New("invalid ed25519 key")
+		}
+		public = ed25519.PublicKey(x)
+		if alg == "" {
+			alg = "EdDSA"
+		}
+	default:
+		return nil, errUnsupportedJWK
+	}
+
+	method := jwt.GetSigningMethod(alg)
+	if method == nil {
+		return nil, errUnsupportedJWK
+	}
+	if !methodMatchesKey(method, public) {
+		return nil, fmt.Errorf("algorithm %s does not match key type %s", alg, jwk.Kty)
+	}
+	return &Key{ID: jwk.Kid, Method: method, verifyKey: public}, checkCurve(method, public)
+}
+
+func methodMatchesKey(method jwt.SigningMethod, public interface{}) bool {
+	switch method.(type) {
+	case *jwt.SigningMethodRSA, *jwt.SigningMethodRSAPSS:
+		_, ok := public.(*rsa.PublicKey)
+		return ok
+	case *jwt.SigningMethodECDSA:
+		_, ok := public.(*ecdsa.PublicKey)
+		return ok
+	case *jwt.SigningMethodEd25519:
+		_, ok := public.(ed25519.PublicKey)
+		return ok
+	default:
+		return false
+	}
+}
+
+func curveByName(name string) (elliptic.Curve, string) {
+	switch name {
+	case "P-256":
+		return elliptic.P256(), "ES256"
+	case "P-384":
+		return elliptic.P384(), "ES384"
+	case "P-521":
+		return elliptic.P521(), "ES512"
+	default:
+		return nil, ""
+	}
+}
+
+func decode(s string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(s)
+}
+
+func encode(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
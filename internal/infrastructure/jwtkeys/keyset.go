@@ -0,0 +1,262 @@
+package jwtkeys
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+	"yiwen/go-ddd/internal/infrastructure/config"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var (
+	ErrNoSigningKey = errors.New("no jwt signing key configured")
+	ErrUnknownKey   = errors.New("unknown jwt key id")
+)
+
+// Key 一个签名或验证密钥
+type Key struct {
+	ID         string
+	Method     jwt.SigningMethod
+	signingKey interface{} // 私钥，只用于验证的密钥为空
+	verifyKey  interface{} // 公钥，HS256 时与 signingKey 相同
+}
+
+// CanSign 是否持有私钥
+func (k *Key) CanSign() bool {
+	return k.signingKey != nil
+}
+
+// Symmetric 是否为对称密钥，对称密钥不会出现在 JWKS 中
+func (k *Key) Symmetric() bool {
+	_, ok := k.Method.(*jwt.SigningMethodHMAC)
+	return ok
+}
+
+// KeySet JWT 密钥集合
+// 1. 使用 signing_key_id 指定的密钥签发令牌，并在头部写入 kid
+// 2. 配置中的所有密钥都可以用来验证令牌，轮换时新旧密钥同时存在，直到旧令牌全部过期
+// 3. 未配置非对称密钥时退回到使用 secret 的 HS256，不带 kid 的令牌按 HS256 验证
+// 4. 配置了 signing_key_id 后不再接受 HS256 令牌，除非通过 legacy_secret_until 显式设置过渡期的截止时间
+type KeySet struct {
+	signing     *Key
+	keys        map[string]*Key
+	ordered     []*Key // 配置顺序，JWKS 按此顺序输出
+	legacy      *Key
+	legacyUntil time.Time // 零值表示 legacy 是当前签名密钥，一直有效
+	fallback    *Key      // 外部 JWKS 只有一个密钥时，不带 kid 的令牌使用该密钥验证
+}
+
+// Load 根据配置加载密钥集合
+func Load(cfg config.JWTConfig) (*KeySet, error) {
+	set := &KeySet{keys: make(map[string]*Key)}
+
+	if cfg.Secret != "" {
+		set.legacy = &Key{
+			Method:     jwt.SigningMethodHS256,
+			signingKey: []byte(cfg.Secret),
+			verifyKey:  []byte(cfg.Secret),
+		}
+	}
+
+	for _, kc := range cfg.Keys {
+		key, err := loadKey(kc)
+		if err != nil {
+			return nil, fmt.Errorf("load jwt key %q: %w", kc.ID, err)
+		}
+		if _, exists := set.keys[key.ID]; exists {
+			return nil, fmt.Errorf("duplicate jwt key id %q", key.ID)
+		}
+		set.keys[key.ID] = key
+		set.ordered = append(set.ordered, key)
+	}
+
+	switch {
+	case cfg.SigningKeyID != "":
+		key, ok := set.keys[cfg.SigningKeyID]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownKey, cfg.SigningKeyID)
+		}
+		if !key.CanSign() {
+			return nil, fmt.Errorf("jwt key %q has no private key", key.ID)
+		}
+		set.signing = key
+		if err := set.limitLegacy(cfg.LegacySecretUntil); err != nil {
+			return nil, err
+		}
+	case set.legacy != nil:
+		set.signing = set.legacy
+	default:
+		return nil, ErrNoSigningKey
+	}
+
+	return set, nil
+}
+
+// limitLegacy 已配置非对称签名密钥时，HS256 旧令牌只在 legacy_secret_until 之前接受
+func (s *KeySet) limitLegacy(until string) error {
+	if s.legacy == nil {
+		return nil
+	}
+	if until == "" {
+		s.legacy = nil
+		return nil
+	}
+
+	t, err := time.Parse(time.RFC3339, until)
+	if err != nil {
+		return fmt.Errorf("invalid jwt legacy_secret_until: %w", err)
+	}
+	if !time.Now().Before(t) {
+		s.legacy = nil
+		return nil
+	}
+	s.legacyUntil = t
+	return nil
+}
+
+// SigningAsymmetric 当前签名密钥是否为非对称密钥，下游应用只能通过 JWKS 验证非对称密钥签发的令牌
+func (s *KeySet) SigningAsymmetric() bool {
+	return !s.signing.Symmetric()
+}
+
+// SigningAlgorithm 当前签名密钥的算法
+func (s *KeySet) SigningAlgorithm() string {
+	return s.signing.Method.Alg()
+}
+
+// Sign 使用当前签名密钥签发令牌
+func (s *KeySet) Sign(claims jwt.Claims) (string, error) {
+	return s.SignWithType(claims, "")
+}
+
+// SignWithType 使用当前签名密钥签发令牌，typ 不为空时写入头部的 typ，用于区分令牌类型
+func (s *KeySet) SignWithType(claims jwt.Claims, typ string) (string, error) {
+	token := jwt.NewWithClaims(s.signing.Method, claims)
+	if s.signing.ID != "" {
+		token.Header["kid"] = s.signing.ID
+	}
+	if typ != "" {
+		token.Header["typ"] = typ
+	}
+	return token.SignedString(s.signing.signingKey)
+}
+
+// Keyfunc 根据令牌头部的 kid 选择验证密钥，并要求算法与密钥一致，防止算法混淆攻击
+func (s *KeySet) Keyfunc(token *jwt.Token) (interface{}, error) {
+	key := s.legacy
+	if key == nil {
+		key = s.fallback
+	}
+	if kid, ok := token.Header["kid"].(string); ok && kid != "" {
+		key = s.keys[kid]
+	}
+	if key == nil {
+		return nil, ErrUnknownKey
+	}
+	if key == s.legacy && !s.legacyUntil.IsZero() && !time.Now().Before(s.legacyUntil) {
+		return nil, ErrUnknownKey
+	}
+	if token.Method.Alg() != key.Method.Alg() {
+		return nil, fmt.Errorf("unexpected signing method %s for key %q", token.Method.Alg(), key.ID)
+	}
+	return key.verifyKey, nil
+}
+
+// JWKS 返回所有非对称密钥的公钥，按 RFC 7517 格式
+func (s *KeySet) JWKS() *JSONWebKeySet {
+	set := &JSONWebKeySet{Keys: make([]JSONWebKey, 0, len(s.ordered))}
+	for _, key := range s.ordered {
+		if key.Symmetric() {
+			continue
+		}
+		set.Keys = append(set.Keys, toJWK(key))
+	}
+	return set
+}
+
+func loadKey(kc config.JWTKeyConfig) (*Key, error) {
+	if kc.ID == "" {
+		return nil, errors.New("key id is required")
+	}
+
+	method := jwt.GetSigningMethod(kc.Algorithm)
+	if method == nil {
+		return nil, fmt.Errorf("unsupported algorithm %q", kc.Algorithm)
+	}
+	key := &Key{ID: kc.ID, Method: method}
+
+	switch {
+	case kc.PrivateKeyFile != "":
+		pem, err := os.ReadFile(kc.PrivateKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		signer, err := parsePrivateKey(method, pem)
+		if err != nil {
+			return nil, err
+		}
+		key.signingKey = signer
+		key.verifyKey = signer.Public()
+	case kc.PublicKeyFile != "":
+		pem, err := os.ReadFile(kc.PublicKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		public, err := parsePublicKey(method, pem)
+		if err != nil {
+			return nil, err
+		}
+		key.verifyKey = public
+	default:
+		return nil, errors.New("private_key_file or public_key_file is required")
+	}
+
+	return key, checkCurve(method, key.verifyKey)
+}
+
+func parsePrivateKey(method jwt.SigningMethod, pem []byte) (crypto.Signer, error) {
+	switch method.(type) {
+	case *jwt.SigningMethodRSA, *jwt.SigningMethodRSAPSS:
+		return jwt.ParseRSAPrivateKeyFromPEM(pem)
+	case *jwt.SigningMethodECDSA:
+		return jwt.ParseECPrivateKeyFromPEM(pem)
+	case *jwt.SigningMethodEd25519:
+		key, err := jwt.ParseEdPrivateKeyFromPEM(pem)
+		if err != nil {
+			return nil, err
+		}
+		return key.(crypto.Signer), nil
+	default:
+		return nil, fmt.Errorf("algorithm %s is not asymmetric", method.Alg())
+	}
+}
+
+func parsePublicKey(method jwt.SigningMethod, pem []byte) (crypto.PublicKey, error) {
+	switch method.(type) {
+	case *jwt.SigningMethodRSA, *jwt.SigningMethodRSAPSS:
+		return jwt.ParseRSAPublicKeyFromPEM(pem)
+	case *jwt.SigningMethodECDSA:
+		return jwt.ParseECPublicKeyFromPEM(pem)
+	case *jwt.SigningMethodEd25519:
+		return jwt.ParseEdPublicKeyFromPEM(pem)
+	default:
+		return nil, fmt.Errorf("algorithm %s is not asymmetric", method.Alg())
+	}
+}
+
+// checkCurve ES256/ES384/ES512 分别要求 P-256/P-384/P-521 曲线
+func checkCurve(method jwt.SigningMethod, public crypto.PublicKey) error {
+	m, ok := method.(*jwt.SigningMethodECDSA)
+	if !ok {
+		return nil
+	}
+	key := public.(*ecdsa.PublicKey)
+	if key.Curve.Params().BitSize != m.CurveBits {
+		return fmt.Errorf("algorithm %s requires a %d-bit curve", m.Alg(), m.CurveBits)
+	}
+	return nil
+}
@@ -0,0 +1,21 @@
+package mailer
+
+import (
+	"context"
+	"log"
+	"yiwen/go-ddd/internal/application/notification"
+)
+
+// LogMailer 只把邮件内容写入日志，用于开发环境和未配置邮件服务时
+type LogMailer struct{}
+
+func NewLogMailer() *LogMailer {
+	return &LogMailer{}
+}
+
+var _ notification.Mailer = (*LogMailer)(nil)
+
+func (m *LogMailer) Send(ctx context.Context, msg notification.Message) error {
+	log.Printf("mailer: to=%s subject=%q\n%s", msg.To, msg.Subject, msg.Body)
+	return nil
+}
@@ -0,0 +1,343 @@
+package oidc
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+	"yiwen/go-ddd/internal/application/identity"
+	"yiwen/go-ddd/internal/infrastructure/config"
+	"yiwen/go-ddd/internal/infrastructure/jwtkeys"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Provider 基于 OpenID Connect 的外部身份提供方
+// 1. 端点通过 {issuer}/.well-known/openid-configuration 发现，首次使用时获取并缓存
+// 2. 使用授权码模式和 PKCE，令牌端点通过 client_secret_basic 认证
+// 3. ID Token 使用提供方的 JWKS 验证签名，并校验 iss、aud、exp 和 nonce；遇到未知的 kid 时重新获取 JWKS，以支持提供方轮换密钥
+// 4. ID Token 中没有邮箱时再从 userinfo 端点补充
+type Provider struct {
+	cfg    config.OIDCProviderConfig
+	client *http.Client
+
+	mu        sync.Mutex
+	discovery *discoveryDocument
+	keys      *jwtkeys.KeySet
+}
+
+type discoveryDocument struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	UserinfoEndpoint      string `json:"userinfo_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+type tokenResponse struct {
+	AccessToken      string `json:"access_token"`
+	IDToken          string `json:"id_token"`
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+// profileClaims ID Token 和 userinfo 中共同的用户信息
+// email_verified 有的提供方返回字符串，因此不直接解析为 bool
+type profileClaims struct {
+	Email             string      `json:"email"`
+	EmailVerified     interface{} `json:"email_verified"`
+	PreferredUsername string      `json:"preferred_username"`
+	Name              string      `json:"name"`
+	Picture           string      `json:"picture"`
+}
+
+type userinfoResponse struct {
+	Subject string `json:"sub"`
+	profileClaims
+}
+
+type idTokenClaims struct {
+	profileClaims
+	Nonce           string `json:"nonce"`
+	AuthorizedParty string `json:"azp"`
+	jwt.RegisteredClaims
+}
+
+// NewProviders 根据配置创建全部提供方
+func NewProviders(cfg config.SSOConfig) ([]identity.Provider, error) {
+	names := make(map[string]bool)
+	providers := make([]identity.Provider, 0, len(cfg.Providers))
+	for _, pc := range cfg.Providers {
+		if pc.Name == "" || pc.Issuer == "" || pc.ClientID == "" || pc.RedirectURL == "" {
+			return nil, fmt.Errorf("sso provider %q: name, issuer, client_id and redirect_url are required", pc.Name)
+		}
+		if names[pc.Name] {
+			return nil, fmt.Errorf("duplicate sso provider %q", pc.Name)
+		}
+		names[pc.Name] = true
+		providers = append(providers, NewProvider(pc))
+	}
+	return providers, nil
+}
+
+func NewProvider(cfg config.OIDCProviderConfig) *Provider {
+	if len(cfg.Scopes) == 0 {
+		cfg.Scopes = []string{"openid", "profile", "email"}
+	}
+	return &Provider{
+		cfg:    cfg,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (p *Provider) Name() string {
+	return p.cfg.Name
+}
+
+func (p *Provider) DisplayName() string {
+	if p.cfg.DisplayName == "" {
+		return p.cfg.Name
+	}
+	return p.cfg.DisplayName
+}
+
+func (p *Provider) AuthCodeURL(ctx context.Context, state, nonce, codeChallenge string) (string, error) {
+	doc, err := p.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	u, err := url.Parse(doc.AuthorizationEndpoint)
+	if err != nil {
+		return "", fmt.Errorf("invalid authorization endpoint: %w", err)
+	}
+	params := u.Query()
+	params.Set("response_type", "code")
+	params.Set("client_id", p.cfg.ClientID)
+	params.Set("redirect_uri", p.cfg.RedirectURL)
+	params.Set("scope", strings.Join(p.cfg.Scopes, " "))
+	params.Set("state", state)
+	params.Set("nonce", nonce)
+	params.Set("code_challenge", codeChallenge)
+	params.Set("code_challenge_method", "S256")
+	u.RawQuery = params.Encode()
+	return u.String(), nil
+}
+
+func (p *Provider) Exchange(ctx context.Context, code, codeVerifier, nonce string) (*identity.Identity, error) {
+	doc, err := p.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	tokens, err := p.requestToken(ctx, doc, code, codeVerifier)
+	if err != nil {
+		return nil, err
+	}
+
+	claims, err := p.verifyIDToken(ctx, doc, tokens.IDToken, nonce)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", identity.ErrAuthenticationFailed, err)
+	}
+
+	profile := claims.profileClaims
+	if profile.Email == "" && doc.UserinfoEndpoint != "" && tokens.AccessToken != "" {
+		info, err := p.userinfo(ctx, doc, tokens.AccessToken)
+		if err != nil {
+			return nil, err
+		}
+		// userinfo 的 sub 必须与 ID Token 一致，见 OIDC Core 5.3.2
+		if info.Subject != claims.Subject {
+			return nil, fmt.Errorf("%w: userinfo subject mismatch", identity.ErrAuthenticationFailed)
+		}
+		profile = info.profileClaims
+	}
+
+	return &identity.Identity{
+		Subject:       claims.Subject,
+		Email:         profile.Email,
+		EmailVerified: profile.EmailVerified == true || profile.EmailVerified == "true",
+		Username:      profile.PreferredUsername,
+		Name:          profile.Name,
+		Picture:       profile.Picture,
+	}, nil
+}
+
+func (p *Provider) requestToken(ctx context.Context, doc *discoveryDocument, code, codeVerifier string) (*tokenResponse, error) {
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {p.cfg.RedirectURL},
+		"code_verifier": {codeVerifier},
+	}
+	if p.cfg.ClientSecret == "" {
+		form.Set("client_id", p.cfg.ClientID)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, doc.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if p.cfg.ClientSecret != "" {
+		// client_secret_basic 要求先对标识和密钥做表单编码，见 RFC 6749 2.3.1
+		req.SetBasicAuth(url.QueryEscape(p.cfg.ClientID), url.QueryEscape(p.cfg.ClientSecret))
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("token request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var tokens tokenResponse
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&tokens); err != nil {
+		return nil, fmt.Errorf("invalid token response: %w", err)
+	}
+	if tokens.Error == "invalid_grant" {
+		// 授权码无效、过期或已被使用
+		return nil, fmt.Errorf("%w: %s %s", identity.ErrAuthenticationFailed, tokens.Error, tokens.ErrorDescription)
+	}
+	if tokens.Error != "" {
+		// 其他错误通常是客户端配置有误
+		return nil, fmt.Errorf("token endpoint returned %s: %s", tokens.Error, tokens.ErrorDescription)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("token endpoint returned status %d", resp.StatusCode)
+	}
+	if tokens.IDToken == "" {
+		return nil, fmt.Errorf("%w: token response has no id_token", identity.ErrAuthenticationFailed)
+	}
+	return &tokens, nil
+}
+
+func (p *Provider) verifyIDToken(ctx context.Context, doc *discoveryDocument, rawIDToken, nonce string) (*idTokenClaims, error) {
+	parser := jwt.NewParser(
+		jwt.WithIssuer(doc.Issuer),
+		jwt.WithAudience(p.cfg.ClientID),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(time.Minute),
+	)
+
+	claims := &idTokenClaims{}
+	if _, err := parser.ParseWithClaims(rawIDToken, claims, func(token *jwt.Token) (interface{}, error) {
+		keys, err := p.jwks(ctx, false)
+		if err != nil {
+			return nil, err
+		}
+		key, err := keys.Keyfunc(token)
+		if errors.Is(err, jwtkeys.ErrUnknownKey) {
+			if keys, err = p.jwks(ctx, true); err != nil {
+				return nil, err
+			}
+			return keys.Keyfunc(token)
+		}
+		return key, err
+	}); err != nil {
+		return nil, err
+	}
+
+	if claims.Subject == "" {
+		return nil, errors.New("id token has no subject")
+	}
+	if claims.AuthorizedParty != "" && claims.AuthorizedParty != p.cfg.ClientID {
+		return nil, errors.New("id token azp mismatch")
+	}
+	if subtle.ConstantTimeCompare([]byte(claims.Nonce), []byte(nonce)) != 1 {
+		return nil, errors.New("id token nonce mismatch")
+	}
+	return claims, nil
+}
+
+func (p *Provider) userinfo(ctx context.Context, doc *discoveryDocument, accessToken string) (*userinfoResponse, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, doc.UserinfoEndpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	req.Header.Set("Accept", "application/json")
+
+	var info userinfoResponse
+	if err := p.getJSON(req, &info); err != nil {
+		return nil, fmt.Errorf("userinfo request failed: %w", err)
+	}
+	return &info, nil
+}
+
+// discover 获取并缓存发现文档，失败时不缓存，下次登录时重试
+func (p *Provider) discover(ctx context.Context) (*discoveryDocument, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.discovery != nil {
+		return p.discovery, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(p.cfg.Issuer, "/")+"/.well-known/openid-configuration", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var doc discoveryDocument
+	if err := p.getJSON(req, &doc); err != nil {
+		return nil, fmt.Errorf("oidc discovery for %s failed: %w", p.cfg.Name, err)
+	}
+	// 发现文档中的 issuer 必须与配置一致，见 OIDC Discovery 4.3
+	if doc.Issuer != p.cfg.Issuer {
+		return nil, fmt.Errorf("oidc discovery for %s: issuer %q does not match %q", p.cfg.Name, doc.Issuer, p.cfg.Issuer)
+	}
+	if doc.AuthorizationEndpoint == "" || doc.TokenEndpoint == "" || doc.JWKSURI == "" {
+		return nil, fmt.Errorf("oidc discovery for %s: missing endpoints", p.cfg.Name)
+	}
+
+	p.discovery = &doc
+	return p.discovery, nil
+}
+
+// jwks 返回缓存的提供方公钥，refresh 为 true 时重新获取
+func (p *Provider) jwks(ctx context.Context, refresh bool) (*jwtkeys.KeySet, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.keys != nil && !refresh {
+		return p.keys, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.discovery.JWKSURI, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var set jwtkeys.JSONWebKeySet
+	if err := p.getJSON(req, &set); err != nil {
+		return nil, fmt.Errorf("fetch jwks for %s failed: %w", p.cfg.Name, err)
+	}
+	keys, err := jwtkeys.ParseJWKS(&set)
+	if err != nil {
+		return nil, err
+	}
+
+	p.keys = keys
+	return p.keys, nil
+}
+
+func (p *Provider) getJSON(req *http.Request, v interface{}) error {
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(v)
+}
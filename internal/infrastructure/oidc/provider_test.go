@@ -0,0 +1,458 @@
+package oidc
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+	"yiwen/go-ddd/internal/application/identity"
+	"yiwen/go-ddd/internal/infrastructure/config"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	testClientID     = "client-1"
+	testClientSecret = "secret/1"
+	testRedirectURL  = "https://app.example.com/api/v1/auth/sso/mock/callback"
+	testSubject      = "user-42"
+)
+
+// authRequest 授权端点收到的请求，换取令牌时校验
+type authRequest struct {
+	nonce         string
+	codeChallenge string
+	redirectURI   string
+}
+
+// mockIdP 本地模拟的 OpenID Connect 提供方
+type mockIdP struct {
+	server *httptest.Server
+
+	mu       sync.Mutex
+	key      *ecdsa.PrivateKey
+	kid      string // 为空时 JWKS 和 ID Token 都不带 kid
+	codes    map[string]authRequest
+	tamper   func(claims jwt.MapClaims) // 签发前修改 ID Token 的声明
+	signer   *ecdsa.PrivateKey          // 不为空时使用该密钥签名，模拟未发布的密钥
+	userinfo map[string]interface{}
+	jwksHits int
+}
+
+func newMockIdP(t *testing.T) *mockIdP {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	idp := &mockIdP{key: key, kid: "key-1", codes: make(map[string]authRequest)}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", idp.discovery)
+	mux.HandleFunc("/authorize", idp.authorize)
+	mux.HandleFunc("/token", idp.token)
+	mux.HandleFunc("/jwks", idp.jwks)
+	mux.HandleFunc("/userinfo", idp.userinfoHandler)
+	idp.server = httptest.NewServer(mux)
+	t.Cleanup(idp.server.Close)
+	return idp
+}
+
+func (idp *mockIdP) issuer() string {
+	return idp.server.URL
+}
+
+func (idp *mockIdP) discovery(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{
+		"issuer":                 idp.issuer(),
+		"authorization_endpoint": idp.issuer() + "/authorize",
+		"token_endpoint":         idp.issuer() + "/token",
+		"userinfo_endpoint":      idp.issuer() + "/userinfo",
+		"jwks_uri":               idp.issuer() + "/jwks",
+	})
+}
+
+// authorize 用户同意后重定向回客户端，带上授权码和原样返回的 state
+func (idp *mockIdP) authorize(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	if q.Get("response_type") != "code" || q.Get("client_id") != testClientID || q.Get("code_challenge_method") != "S256" || q.Get("code_challenge") == "" {
+		http.Error(w, "invalid request", http.StatusBadRequest)
+		return
+	}
+
+	code := rand.Text()
+	idp.mu.Lock()
+	idp.codes[code] = authRequest{nonce: q.Get("nonce"), codeChallenge: q.Get("code_challenge"), redirectURI: q.Get("redirect_uri")}
+	idp.mu.Unlock()
+
+	redirect, _ := url.Parse(q.Get("redirect_uri"))
+	redirect.RawQuery = url.Values{"code": {code}, "state": {q.Get("state")}}.Encode()
+	http.Redirect(w, r, redirect.String(), http.StatusFound)
+}
+
+func (idp *mockIdP) token(w http.ResponseWriter, r *http.Request) {
+	clientID, secret, ok := r.BasicAuth()
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid_client"})
+		return
+	}
+	clientID, _ = url.QueryUnescape(clientID)
+	secret, _ = url.QueryUnescape(secret)
+	if clientID != testClientID || secret != testClientSecret {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid_client"})
+		return
+	}
+
+	idp.mu.Lock()
+	req, ok := idp.codes[r.PostFormValue("code")]
+	delete(idp.codes, r.PostFormValue("code"))
+	idp.mu.Unlock()
+
+	sum := sha256.Sum256([]byte(r.PostFormValue("code_verifier")))
+	switch {
+	case r.PostFormValue("grant_type") != "authorization_code", !ok:
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+		return
+	case r.PostFormValue("redirect_uri") != req.redirectURI:
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant", "error_description": "redirect_uri mismatch"})
+		return
+	case base64.RawURLEncoding.EncodeToString(sum[:]) != req.codeChallenge:
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant", "error_description": "pkce verification failed"})
+		return
+	}
+
+	claims := jwt.MapClaims{
+		"iss":            idp.issuer(),
+		"sub":            testSubject,
+		"aud":            testClientID,
+		"exp":            time.Now().Add(5 * time.Minute).Unix(),
+		"iat":            time.Now().Unix(),
+		"nonce":          req.nonce,
+		"email":          "alice@example.com",
+		"email_verified": "true",
+		"name":           "Alice",
+	}
+	idp.mu.Lock()
+	tamper, signer, kid := idp.tamper, idp.signer, idp.kid
+	if signer == nil {
+		signer = idp.key
+	}
+	idp.mu.Unlock()
+	if tamper != nil {
+		tamper(claims)
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodES256, claims)
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+	idToken, err := token.SignedString(signer)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"access_token": "access-" + testSubject, "id_token": idToken, "token_type": "Bearer"})
+}
+
+func (idp *mockIdP) jwks(w http.ResponseWriter, r *http.Request) {
+	idp.mu.Lock()
+	idp.jwksHits++
+	key, kid := idp.key, idp.kid
+	idp.mu.Unlock()
+
+	jwk := map[string]string{
+		"kty": "EC",
+		"crv": "P-256",
+		"use": "sig",
+		"x":   base64.RawURLEncoding.EncodeToString(key.X.FillBytes(make([]byte, 32))),
+		"y":   base64.RawURLEncoding.EncodeToString(key.Y.FillBytes(make([]byte, 32))),
+	}
+	if kid != "" {
+		jwk["kid"] = kid
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"keys": []interface{}{jwk}})
+}
+
+func (idp *mockIdP) userinfoHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("Authorization") != "Bearer access-"+testSubject {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	idp.mu.Lock()
+	info := idp.userinfo
+	idp.mu.Unlock()
+	writeJSON(w, http.StatusOK, info)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func newTestProvider(idp *mockIdP) *Provider {
+	return NewProvider(config.OIDCProviderConfig{
+		Name:         "mock",
+		Issuer:       idp.issuer(),
+		ClientID:     testClientID,
+		ClientSecret: testClientSecret,
+		RedirectURL:  testRedirectURL,
+	})
+}
+
+// pkce 生成 code_verifier 和对应的 S256 code_challenge
+func pkce() (verifier, challenge string) {
+	verifier = rand.Text()
+	sum := sha256.Sum256([]byte(verifier))
+	return verifier, base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// authorize 跳转到提供方登录，返回回调中的授权码和 state
+func authorize(t *testing.T, p *Provider, state, nonce, codeChallenge string) (code, returnedState string) {
+	t.Helper()
+	authURL, err := p.AuthCodeURL(context.Background(), state, nonce, codeChallenge)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }}
+	resp, err := client.Get(authURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusFound {
+		t.Fatalf("authorize returned status %d", resp.StatusCode)
+	}
+
+	callback, err := url.Parse(resp.Header.Get("Location"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(callback.String(), testRedirectURL+"?") {
+		t.Fatalf("redirected to %s", callback)
+	}
+	return callback.Query().Get("code"), callback.Query().Get("state")
+}
+
+// login 完成一次正常的登录流程
+func login(t *testing.T, p *Provider) (*identity.Identity, error) {
+	t.Helper()
+	verifier, challenge := pkce()
+	code, _ := authorize(t, p, "state-1", "nonce-1", challenge)
+	return p.Exchange(context.Background(), code, verifier, "nonce-1")
+}
+
+func TestAuthCodeURL(t *testing.T) {
+	idp := newMockIdP(t)
+	p := newTestProvider(idp)
+
+	authURL, err := p.AuthCodeURL(context.Background(), "state-1", "nonce-1", "challenge-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	u, err := url.Parse(authURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := u.Scheme + "://" + u.Host + u.Path; got != idp.issuer()+"/authorize" {
+		t.Errorf("authorization endpoint = %s", got)
+	}
+
+	want := map[string]string{
+		"response_type":         "code",
+		"client_id":             testClientID,
+		"redirect_uri":          testRedirectURL,
+		"scope":                 "openid profile email",
+		"state":                 "state-1",
+		"nonce":                 "nonce-1",
+		"code_challenge":        "challenge-1",
+		"code_challenge_method": "S256",
+	}
+	for name, value := range want {
+		if got := u.Query().Get(name); got != value {
+			t.Errorf("%s = %q, want %q", name, got, value)
+		}
+	}
+}
+
+func TestExchange(t *testing.T) {
+	idp := newMockIdP(t)
+	p := newTestProvider(idp)
+
+	verifier, challenge := pkce()
+	code, state := authorize(t, p, "state-1", "nonce-1", challenge)
+	if state != "state-1" {
+		t.Errorf("state = %q, want it returned unchanged", state)
+	}
+
+	got, err := p.Exchange(context.Background(), code, verifier, "nonce-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := identity.Identity{Subject: testSubject, Email: "alice@example.com", EmailVerified: true, Name: "Alice"}
+	if *got != want {
+		t.Errorf("identity = %+v, want %+v", *got, want)
+	}
+
+	// 授权码只能使用一次
+	if _, err := p.Exchange(context.Background(), code, verifier, "nonce-1"); !errors.Is(err, identity.ErrAuthenticationFailed) {
+		t.Errorf("reused code: err = %v, want ErrAuthenticationFailed", err)
+	}
+}
+
+func TestExchangeRejectsWrongCodeVerifier(t *testing.T) {
+	idp := newMockIdP(t)
+	p := newTestProvider(idp)
+
+	_, challenge := pkce()
+	code, _ := authorize(t, p, "state-1", "nonce-1", challenge)
+	otherVerifier, _ := pkce()
+
+	if _, err := p.Exchange(context.Background(), code, otherVerifier, "nonce-1"); !errors.Is(err, identity.ErrAuthenticationFailed) {
+		t.Fatalf("err = %v, want ErrAuthenticationFailed", err)
+	}
+}
+
+func TestExchangeRejectsNonceMismatch(t *testing.T) {
+	idp := newMockIdP(t)
+	p := newTestProvider(idp)
+
+	verifier, challenge := pkce()
+	code, _ := authorize(t, p, "state-1", "nonce-1", challenge)
+
+	if _, err := p.Exchange(context.Background(), code, verifier, "nonce-2"); !errors.Is(err, identity.ErrAuthenticationFailed) {
+		t.Fatalf("err = %v, want ErrAuthenticationFailed", err)
+	}
+}
+
+func TestExchangeRejectsInvalidIDToken(t *testing.T) {
+	otherKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		tamper func(claims jwt.MapClaims)
+		signer *ecdsa.PrivateKey
+	}{
+		{name: "wrong issuer", tamper: func(c jwt.MapClaims) { c["iss"] = "https://evil.example" }},
+		{name: "wrong audience", tamper: func(c jwt.MapClaims) { c["aud"] = "other-client" }},
+		{name: "audience list without client", tamper: func(c jwt.MapClaims) { c["aud"] = []string{"a", "b"} }},
+		{name: "expired", tamper: func(c jwt.MapClaims) { c["exp"] = time.Now().Add(-5 * time.Minute).Unix() }},
+		{name: "no expiry", tamper: func(c jwt.MapClaims) { delete(c, "exp") }},
+		{name: "wrong authorized party", tamper: func(c jwt.MapClaims) {
+			c["aud"] = []string{testClientID, "other-client"}
+			c["azp"] = "other-client"
+		}},
+		{name: "no subject", tamper: func(c jwt.MapClaims) { delete(c, "sub") }},
+		{name: "no nonce", tamper: func(c jwt.MapClaims) { delete(c, "nonce") }},
+		{name: "unpublished signing key", signer: otherKey},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			idp := newMockIdP(t)
+			idp.tamper, idp.signer = tt.tamper, tt.signer
+			if _, err := login(t, newTestProvider(idp)); !errors.Is(err, identity.ErrAuthenticationFailed) {
+				t.Fatalf("err = %v, want ErrAuthenticationFailed", err)
+			}
+		})
+	}
+}
+
+func TestExchangeAcceptsMatchingAuthorizedParty(t *testing.T) {
+	idp := newMockIdP(t)
+	idp.tamper = func(c jwt.MapClaims) {
+		c["aud"] = []string{testClientID, "other-client"}
+		c["azp"] = testClientID
+	}
+	if _, err := login(t, newTestProvider(idp)); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestExchangeWithoutKeyID(t *testing.T) {
+	idp := newMockIdP(t)
+	idp.kid = ""
+
+	if _, err := login(t, newTestProvider(idp)); err != nil {
+		t.Fatalf("single-key jwks without kid: %v", err)
+	}
+}
+
+func TestExchangeRefetchesJWKSOnKeyRotation(t *testing.T) {
+	idp := newMockIdP(t)
+	p := newTestProvider(idp)
+	if _, err := login(t, p); err != nil {
+		t.Fatal(err)
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	idp.mu.Lock()
+	idp.key, idp.kid = key, "key-2"
+	idp.mu.Unlock()
+
+	if _, err := login(t, p); err != nil {
+		t.Fatalf("after rotation: %v", err)
+	}
+	if idp.jwksHits != 2 {
+		t.Errorf("jwks fetched %d times, want 2", idp.jwksHits)
+	}
+}
+
+func TestExchangeFallsBackToUserinfo(t *testing.T) {
+	idp := newMockIdP(t)
+	idp.tamper = func(c jwt.MapClaims) {
+		delete(c, "email")
+		delete(c, "email_verified")
+	}
+	idp.userinfo = map[string]interface{}{"sub": testSubject, "email": "alice@example.com", "email_verified": true, "preferred_username": "alice"}
+
+	got, err := login(t, newTestProvider(idp))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Email != "alice@example.com" || !got.EmailVerified || got.Username != "alice" {
+		t.Errorf("identity = %+v", got)
+	}
+}
+
+func TestExchangeRejectsUserinfoSubjectMismatch(t *testing.T) {
+	idp := newMockIdP(t)
+	idp.tamper = func(c jwt.MapClaims) { delete(c, "email") }
+	idp.userinfo = map[string]interface{}{"sub": "someone-else", "email": "mallory@example.com", "email_verified": true}
+
+	if _, err := login(t, newTestProvider(idp)); !errors.Is(err, identity.ErrAuthenticationFailed) {
+		t.Fatalf("err = %v, want ErrAuthenticationFailed", err)
+	}
+}
+
+func TestDiscoveryRejectsIssuerMismatch(t *testing.T) {
+	idp := newMockIdP(t)
+	p := NewProvider(config.OIDCProviderConfig{
+		Name:        "mock",
+		Issuer:      idp.issuer() + "/",
+		ClientID:    testClientID,
+		RedirectURL: testRedirectURL,
+	})
+
+	if _, err := p.AuthCodeURL(context.Background(), "state-1", "nonce-1", "challenge-1"); err == nil {
+		t.Fatal("discovery accepted an issuer that does not match the configuration")
+	}
+}
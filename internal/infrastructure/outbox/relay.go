@@ -0,0 +1,105 @@
+package outbox
+
+import (
+	"context"
+	"log"
+	"time"
+	"yiwen/go-ddd/internal/domain/event"
+	"yiwen/go-ddd/internal/infrastructure/config"
+	"yiwen/go-ddd/internal/infrastructure/persistence/model"
+	"yiwen/go-ddd/pkg/backoff"
+)
+
+// Store relay 依赖的 outbox 存储
+type Store interface {
+	FetchPending(ctx context.Context, limit int, lease time.Duration) ([]*model.OutboxEventModel, error)
+	MarkPublished(ctx context.Context, id uint64) error
+	MarkFailed(ctx context.Context, id uint64, attempts int, nextAttemptAt time.Time, lastError string, dead bool) error
+}
+
+// Relay outbox 投递器
+// 定时读取待投递的事件并通过 EventPublisher 发布：
+// 1. 发布成功后标记为已投递，publisher 需要同步调用处理器并返回处理器的错误，否则失败无法重试
+// 2. 发布失败按指数退避重试，超过最大次数后标记为失败
+// 3. 投递语义为至少一次，事件处理器需要自行保证幂等
+// 4. 领取事件时带有租期，多个实例可以同时运行，租期内事件只会被一个实例投递
+type Relay struct {
+	store        Store
+	publisher    event.EventPublisher
+	pollInterval time.Duration
+	lease        time.Duration
+	batchSize    int
+	maxAttempts  int
+	baseBackoff  time.Duration
+	maxBackoff   time.Duration
+}
+
+// NewRelay 创建 outbox 投递器
+func NewRelay(store Store, publisher event.EventPublisher, cfg config.OutboxConfig) *Relay {
+	return &Relay{
+		store:        store,
+		publisher:    publisher,
+		pollInterval: time.Duration(cfg.PollIntervalSecond) * time.Second,
+		lease:        time.Duration(cfg.LeaseSecond) * time.Second,
+		batchSize:    cfg.BatchSize,
+		maxAttempts:  cfg.MaxAttempts,
+		baseBackoff:  time.Duration(cfg.BaseBackoffSecond) * time.Second,
+		maxBackoff:   time.Duration(cfg.MaxBackoffSecond) * time.Second,
+	}
+}
+
+// Run 启动投递循环，直到 ctx 被取消
+func (r *Relay) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := r.ProcessBatch(ctx); err != nil {
+			log.Printf("outbox: process batch failed: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// ProcessBatch 投递一批到期的事件
+func (r *Relay) ProcessBatch(ctx context.Context) error {
+	records, err := r.store.FetchPending(ctx, r.batchSize, r.lease)
+	if err != nil {
+		return err
+	}
+
+	for _, record := range records {
+		if err := r.publish(record); err != nil {
+			r.fail(ctx, record, err)
+			continue
+		}
+		if err := r.store.MarkPublished(ctx, record.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *Relay) publish(record *model.OutboxEventModel) error {
+	e, err := record.ToEvent()
+	if err != nil {
+		return err
+	}
+	return r.publisher.Publish(e)
+}
+
+func (r *Relay) fail(ctx context.Context, record *model.OutboxEventModel, cause error) {
+	attempts := record.Attempts + 1
+	dead := attempts >= r.maxAttempts
+
+	log.Printf("outbox: publish event %d (%s) failed, attempt %d: %v", record.ID, record.EventName, attempts, cause)
+
+	if err := r.store.MarkFailed(ctx, record.ID, attempts, time.Now().Add(backoff.Exponential(r.baseBackoff, r.maxBackoff, attempts)), cause.Error(), dead); err != nil {
+		log.Printf("outbox: mark event %d failed: %v", record.ID, err)
+	}
+}
@@ -0,0 +1,98 @@
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+	"yiwen/go-ddd/internal/domain/entity"
+	"yiwen/go-ddd/internal/domain/repository"
+)
+
+// purgeInterval 清理过期记录的间隔
+const purgeInterval = 10 * time.Minute
+
+// LoginAttemptRepository 内存登录失败记录仓库实现
+// 计数只存在于当前进程，重启后清零，适用于单实例部署和开发环境
+type LoginAttemptRepository struct {
+	mu        sync.Mutex
+	attempts  map[string]*entity.LoginAttempt
+	lastPurge time.Time
+}
+
+func NewLoginAttemptRepository() repository.LoginAttemptRepository {
+	return &LoginAttemptRepository{
+		attempts:  make(map[string]*entity.LoginAttempt),
+		lastPurge: time.Now(),
+	}
+}
+
+func (r *LoginAttemptRepository) Find(ctx context.Context, key string) (*entity.LoginAttempt, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	attempt, ok := r.attempts[key]
+	if !ok {
+		return nil, repository.ErrLoginAttemptNotFound
+	}
+	return copyAttempt(attempt), nil
+}
+
+func (r *LoginAttemptRepository) RecordFailure(ctx context.Context, key string, window time.Duration) (*entity.LoginAttempt, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	r.purge(now, window)
+
+	attempt, ok := r.attempts[key]
+	if !ok {
+		attempt = &entity.LoginAttempt{Key: key}
+		r.attempts[key] = attempt
+	}
+	attempt.Failures = attempt.FailuresWithin(window, now) + 1
+	attempt.LastFailedAt = now
+
+	return copyAttempt(attempt), nil
+}
+
+func (r *LoginAttemptRepository) Lock(ctx context.Context, key string, until time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if attempt, ok := r.attempts[key]; ok {
+		attempt.Failures = 0
+		attempt.LockedUntil = &until
+	}
+	return nil
+}
+
+func (r *LoginAttemptRepository) Reset(ctx context.Context, key string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.attempts, key)
+	return nil
+}
+
+// purge 定期删除已经超出统计窗口且未锁定的记录，避免大量不同的用户名和IP占用内存
+func (r *LoginAttemptRepository) purge(now time.Time, window time.Duration) {
+	if now.Sub(r.lastPurge) < purgeInterval {
+		return
+	}
+	r.lastPurge = now
+
+	for key, attempt := range r.attempts {
+		if !attempt.IsLocked(now) && now.Sub(attempt.LastFailedAt) > window {
+			delete(r.attempts, key)
+		}
+	}
+}
+
+func copyAttempt(attempt *entity.LoginAttempt) *entity.LoginAttempt {
+	c := *attempt
+	if attempt.LockedUntil != nil {
+		until := *attempt.LockedUntil
+		c.LockedUntil = &until
+	}
+	return &c
+}
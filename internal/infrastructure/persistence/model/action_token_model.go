@@ -0,0 +1,45 @@
+package model
+
+import (
+	"time"
+	"yiwen/go-ddd/internal/domain/entity"
+)
+
+// ActionTokenModel 一次性操作令牌数据库模型，只登记令牌ID，不保存令牌本身
+type ActionTokenModel struct {
+	ID        uint64    `gorm:"primaryKey;autoIncrement"`
+	TokenID   string    `gorm:"type:varchar(36);not null;uniqueIndex"`
+	UserID    uint64    `gorm:"not null;index:idx_user_purpose"`
+	Purpose   string    `gorm:"type:varchar(32);not null;index:idx_user_purpose"`
+	Email     string    `gorm:"type:varchar(100);not null"`
+	ExpiresAt time.Time `gorm:"not null;index"`
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+}
+
+func (ActionTokenModel) TableName() string {
+	return "action_tokens"
+}
+
+func (m *ActionTokenModel) ToEntity() *entity.ActionToken {
+	return &entity.ActionToken{
+		ID:        m.ID,
+		TokenID:   m.TokenID,
+		UserID:    m.UserID,
+		Purpose:   m.Purpose,
+		Email:     m.Email,
+		ExpiresAt: m.ExpiresAt,
+		CreatedAt: m.CreatedAt,
+	}
+}
+
+func FromActionToken(token *entity.ActionToken) *ActionTokenModel {
+	return &ActionTokenModel{
+		ID:        token.ID,
+		TokenID:   token.TokenID,
+		UserID:    token.UserID,
+		Purpose:   token.Purpose,
+		Email:     token.Email,
+		ExpiresAt: token.ExpiresAt,
+		CreatedAt: token.CreatedAt,
+	}
+}
@@ -0,0 +1,52 @@
+package model
+
+import (
+	"strings"
+	"time"
+	"yiwen/go-ddd/internal/domain/entity"
+)
+
+// APIKeyModel API 密钥数据库模型
+type APIKeyModel struct {
+	ID         uint64 `gorm:"primaryKey;autoIncrement"`
+	UserID     uint64 `gorm:"not null;index"`
+	Name       string `gorm:"type:varchar(100);not null"`
+	Prefix     string `gorm:"type:varchar(20);not null"`
+	KeyHash    string `gorm:"type:char(64);not null;uniqueIndex"`
+	Scopes     string `gorm:"type:varchar(1000);not null"`
+	ExpiresAt  *time.Time
+	LastUsedAt *time.Time
+	CreatedAt  time.Time `gorm:"autoCreateTime"`
+}
+
+func (APIKeyModel) TableName() string {
+	return "api_keys"
+}
+
+func (m *APIKeyModel) ToEntity() *entity.APIKey {
+	return &entity.APIKey{
+		ID:         m.ID,
+		UserID:     m.UserID,
+		Name:       m.Name,
+		Prefix:     m.Prefix,
+		KeyHash:    m.KeyHash,
+		Scopes:     strings.Fields(m.Scopes),
+		ExpiresAt:  m.ExpiresAt,
+		LastUsedAt: m.LastUsedAt,
+		CreatedAt:  m.CreatedAt,
+	}
+}
+
+func FromAPIKey(key *entity.APIKey) *APIKeyModel {
+	return &APIKeyModel{
+		ID:         key.ID,
+		UserID:     key.UserID,
+		Name:       key.Name,
+		Prefix:     key.Prefix,
+		KeyHash:    key.KeyHash,
+		Scopes:     strings.Join(key.Scopes, " "),
+		ExpiresAt:  key.ExpiresAt,
+		LastUsedAt: key.LastUsedAt,
+		CreatedAt:  key.CreatedAt,
+	}
+}
@@ -0,0 +1,58 @@
+package model
+
+import (
+	"time"
+	"yiwen/go-ddd/internal/domain/entity"
+)
+
+// AuditLogModel 审计日志数据库模型
+type AuditLogModel struct {
+	ID         uint64    `gorm:"primaryKey;autoIncrement"`
+	EventID    string    `gorm:"type:varchar(36);not null;uniqueIndex"`
+	Source     string    `gorm:"type:varchar(20);not null;default:event"`
+	ActorID    uint64    `gorm:"not null;default:0;index"`
+	ActorName  string    `gorm:"type:varchar(50)"`
+	TargetUUID string    `gorm:"type:varchar(36);not null;index"`
+	Action     string    `gorm:"type:varchar(100);not null;index"`
+	Reason     string    `gorm:"type:varchar(500)"`
+	IP         string    `gorm:"type:varchar(45)"`
+	UserAgent  string    `gorm:"type:varchar(255)"`
+	OccurredAt time.Time `gorm:"not null;index"`
+	CreatedAt  time.Time `gorm:"autoCreateTime"`
+}
+
+func (AuditLogModel) TableName() string {
+	return "audit_logs"
+}
+
+func (m *AuditLogModel) ToEntity() *entity.AuditLog {
+	return &entity.AuditLog{
+		ID:         m.ID,
+		EventID:    m.EventID,
+		Source:     entity.AuditSource(m.Source),
+		ActorID:    m.ActorID,
+		ActorName:  m.ActorName,
+		TargetUUID: m.TargetUUID,
+		Action:     m.Action,
+		Reason:     m.Reason,
+		IP:         m.IP,
+		UserAgent:  m.UserAgent,
+		OccurredAt: m.OccurredAt,
+	}
+}
+
+func FromAuditLog(log *entity.AuditLog) *AuditLogModel {
+	return &AuditLogModel{
+		ID:         log.ID,
+		EventID:    log.EventID,
+		Source:     string(log.Source),
+		ActorID:    log.ActorID,
+		ActorName:  log.ActorName,
+		TargetUUID: log.TargetUUID,
+		Action:     log.Action,
+		Reason:     truncate(log.Reason, 500),
+		IP:         log.IP,
+		UserAgent:  truncate(log.UserAgent, 255),
+		OccurredAt: log.OccurredAt,
+	}
+}
@@ -0,0 +1,45 @@
+package model
+
+import (
+	"time"
+	"yiwen/go-ddd/internal/domain/event"
+)
+
+// EventStoreModel 事件存储数据库模型
+// 事件只追加不修改，(aggregate_id, version) 唯一，用于乐观并发控制
+type EventStoreModel struct {
+	ID            uint64    `gorm:"primaryKey;autoIncrement"`
+	AggregateID   string    `gorm:"type:varchar(36);not null;uniqueIndex:uk_aggregate_version"`
+	AggregateType string    `gorm:"type:varchar(50);not null"`
+	Version       int       `gorm:"not null;uniqueIndex:uk_aggregate_version"`
+	EventName     string    `gorm:"type:varchar(100);not null"`
+	Payload       string    `gorm:"type:text;not null"`
+	OccurredAt    time.Time `gorm:"not null"`
+	CreatedAt     time.Time `gorm:"autoCreateTime"`
+}
+
+func (EventStoreModel) TableName() string {
+	return "event_store"
+}
+
+// NewEventStoreModel 将领域事件序列化为指定版本的事件记录
+func NewEventStoreModel(aggregateType string, version int, e event.Event) (*EventStoreModel, error) {
+	payload, err := event.Encode(e)
+	if err != nil {
+		return nil, err
+	}
+
+	return &EventStoreModel{
+		AggregateID:   e.AggregateID(),
+		AggregateType: aggregateType,
+		Version:       version,
+		EventName:     e.EventName(),
+		Payload:       string(payload),
+		OccurredAt:    e.OccurredAt(),
+	}, nil
+}
+
+// ToEvent 将事件记录反序列化为领域事件
+func (m *EventStoreModel) ToEvent() (event.Event, error) {
+	return event.Decode(m.EventName, []byte(m.Payload))
+}
@@ -0,0 +1,84 @@
+package model
+
+import (
+	"time"
+	"yiwen/go-ddd/internal/domain/entity"
+)
+
+// ExternalIdentityModel 外部身份数据库模型
+type ExternalIdentityModel struct {
+	ID          uint64    `gorm:"primaryKey;autoIncrement"`
+	UserID      uint64    `gorm:"not null;index"`
+	Provider    string    `gorm:"type:varchar(50);not null;uniqueIndex:uk_provider_subject"`
+	Subject     string    `gorm:"type:varchar(255);not null;uniqueIndex:uk_provider_subject"`
+	Email       string    `gorm:"type:varchar(100);not null;default:''"`
+	CreatedAt   time.Time `gorm:"autoCreateTime"`
+	LastLoginAt time.Time `gorm:"not null"`
+}
+
+func (ExternalIdentityModel) TableName() string {
+	return "external_identities"
+}
+
+func (m *ExternalIdentityModel) ToEntity() *entity.ExternalIdentity {
+	return &entity.ExternalIdentity{
+		ID:          m.ID,
+		UserID:      m.UserID,
+		Provider:    m.Provider,
+		Subject:     m.Subject,
+		Email:       m.Email,
+		CreatedAt:   m.CreatedAt,
+		LastLoginAt: m.LastLoginAt,
+	}
+}
+
+func FromExternalIdentity(identity *entity.ExternalIdentity) *ExternalIdentityModel {
+	return &ExternalIdentityModel{
+		ID:          identity.ID,
+		UserID:      identity.UserID,
+		Provider:    identity.Provider,
+		Subject:     identity.Subject,
+		Email:       identity.Email,
+		CreatedAt:   identity.CreatedAt,
+		LastLoginAt: identity.LastLoginAt,
+	}
+}
+
+// ExternalLoginStateModel 外部登录流程状态数据库模型
+type ExternalLoginStateModel struct {
+	ID           uint64    `gorm:"primaryKey;autoIncrement"`
+	StateHash    string    `gorm:"type:char(64);not null;uniqueIndex"`
+	Provider     string    `gorm:"type:varchar(50);not null"`
+	Nonce        string    `gorm:"type:varchar(64);not null"`
+	CodeVerifier string    `gorm:"type:varchar(128);not null"`
+	ExpiresAt    time.Time `gorm:"not null;index"`
+	CreatedAt    time.Time `gorm:"autoCreateTime"`
+}
+
+func (ExternalLoginStateModel) TableName() string {
+	return "external_login_states"
+}
+
+func (m *ExternalLoginStateModel) ToEntity() *entity.ExternalLoginState {
+	return &entity.ExternalLoginState{
+		ID:           m.ID,
+		StateHash:    m.StateHash,
+		Provider:     m.Provider,
+		Nonce:        m.Nonce,
+		CodeVerifier: m.CodeVerifier,
+		ExpiresAt:    m.ExpiresAt,
+		CreatedAt:    m.CreatedAt,
+	}
+}
+
+func FromExternalLoginState(state *entity.ExternalLoginState) *ExternalLoginStateModel {
+	return &ExternalLoginStateModel{
+		ID:           state.ID,
+		StateHash:    state.StateHash,
+		Provider:     state.Provider,
+		Nonce:        state.Nonce,
+		CodeVerifier: state.CodeVerifier,
+		ExpiresAt:    state.ExpiresAt,
+		CreatedAt:    state.CreatedAt,
+	}
+}
@@ -0,0 +1,27 @@
+package model
+
+import (
+	"time"
+	"yiwen/go-ddd/internal/domain/entity"
+)
+
+// LoginAttemptModel 登录失败记录数据库模型
+type LoginAttemptModel struct {
+	AttemptKey   string    `gorm:"type:varchar(128);primaryKey"`
+	Failures     int       `gorm:"not null;default:0"`
+	LastFailedAt time.Time `gorm:"not null;index"`
+	LockedUntil  *time.Time
+}
+
+func (LoginAttemptModel) TableName() string {
+	return "login_attempts"
+}
+
+func (m *LoginAttemptModel) ToEntity() *entity.LoginAttempt {
+	return &entity.LoginAttempt{
+		Key:          m.AttemptKey,
+		Failures:     m.Failures,
+		LastFailedAt: m.LastFailedAt,
+		LockedUntil:  m.LockedUntil,
+	}
+}
@@ -0,0 +1,140 @@
+package model
+
+import (
+	"strings"
+	"time"
+	"yiwen/go-ddd/internal/domain/entity"
+)
+
+// OAuthClientModel OAuth2 客户端数据库模型
+// 回调地址、权限范围、授权类型都不会包含空格，按 OAuth2 的习惯以空格分隔保存
+type OAuthClientModel struct {
+	ID           uint64    `gorm:"primaryKey;autoIncrement"`
+	ClientID     string    `gorm:"type:varchar(64);not null;uniqueIndex"`
+	SecretHash   string    `gorm:"type:char(64);not null;default:''"`
+	Name         string    `gorm:"type:varchar(100);not null"`
+	RedirectURIs string    `gorm:"type:varchar(2000);not null"`
+	Scopes       string    `gorm:"type:varchar(1000);not null"`
+	GrantTypes   string    `gorm:"type:varchar(200);not null"`
+	CreatedAt    time.Time `gorm:"autoCreateTime"`
+	UpdatedAt    time.Time `gorm:"autoUpdateTime"`
+}
+
+func (OAuthClientModel) TableName() string {
+	return "oauth_clients"
+}
+
+func (m *OAuthClientModel) ToEntity() *entity.OAuthClient {
+	return &entity.OAuthClient{
+		ID:           m.ID,
+		ClientID:     m.ClientID,
+		SecretHash:   m.SecretHash,
+		Name:         m.Name,
+		RedirectURIs: strings.Fields(m.RedirectURIs),
+		Scopes:       strings.Fields(m.Scopes),
+		GrantTypes:   strings.Fields(m.GrantTypes),
+		CreatedAt:    m.CreatedAt,
+		UpdatedAt:    m.UpdatedAt,
+	}
+}
+
+func FromOAuthClient(client *entity.OAuthClient) *OAuthClientModel {
+	return &OAuthClientModel{
+		ID:           client.ID,
+		ClientID:     client.ClientID,
+		SecretHash:   client.SecretHash,
+		Name:         client.Name,
+		RedirectURIs: strings.Join(client.RedirectURIs, " "),
+		Scopes:       strings.Join(client.Scopes, " "),
+		GrantTypes:   strings.Join(client.GrantTypes, " "),
+		CreatedAt:    client.CreatedAt,
+		UpdatedAt:    client.UpdatedAt,
+	}
+}
+
+// AuthorizationCodeModel 授权码数据库模型
+type AuthorizationCodeModel struct {
+	ID            uint64    `gorm:"primaryKey;autoIncrement"`
+	CodeHash      string    `gorm:"type:char(64);not null;uniqueIndex"`
+	ClientID      string    `gorm:"type:varchar(64);not null;index"`
+	UserID        uint64    `gorm:"not null;index"`
+	RedirectURI   string    `gorm:"type:varchar(500);not null;default:''"`
+	Scopes        string    `gorm:"type:varchar(1000);not null"`
+	CodeChallenge string    `gorm:"type:varchar(128);not null;default:''"`
+	Nonce         string    `gorm:"type:varchar(255);not null;default:''"`
+	ExpiresAt     time.Time `gorm:"not null"`
+	UsedAt        *time.Time
+	CreatedAt     time.Time `gorm:"autoCreateTime"`
+}
+
+func (AuthorizationCodeModel) TableName() string {
+	return "oauth_authorization_codes"
+}
+
+func (m *AuthorizationCodeModel) ToEntity() *entity.AuthorizationCode {
+	return &entity.AuthorizationCode{
+		ID:            m.ID,
+		CodeHash:      m.CodeHash,
+		ClientID:      m.ClientID,
+		UserID:        m.UserID,
+		RedirectURI:   m.RedirectURI,
+		Scopes:        strings.Fields(m.Scopes),
+		CodeChallenge: m.CodeChallenge,
+		Nonce:         m.Nonce,
+		ExpiresAt:     m.ExpiresAt,
+		UsedAt:        m.UsedAt,
+		CreatedAt:     m.CreatedAt,
+	}
+}
+
+func FromAuthorizationCode(code *entity.AuthorizationCode) *AuthorizationCodeModel {
+	return &AuthorizationCodeModel{
+		ID:            code.ID,
+		CodeHash:      code.CodeHash,
+		ClientID:      code.ClientID,
+		UserID:        code.UserID,
+		RedirectURI:   code.RedirectURI,
+		Scopes:        strings.Join(code.Scopes, " "),
+		CodeChallenge: code.CodeChallenge,
+		Nonce:         code.Nonce,
+		ExpiresAt:     code.ExpiresAt,
+		UsedAt:        code.UsedAt,
+		CreatedAt:     code.CreatedAt,
+	}
+}
+
+// OAuthConsentModel 授权同意记录数据库模型，每个用户和客户端只有一条
+type OAuthConsentModel struct {
+	ID        uint64    `gorm:"primaryKey;autoIncrement"`
+	UserID    uint64    `gorm:"not null;uniqueIndex:uk_oauth_consent_user_client"`
+	ClientID  string    `gorm:"type:varchar(64);not null;uniqueIndex:uk_oauth_consent_user_client"`
+	Scopes    string    `gorm:"type:varchar(1000);not null"`
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime"`
+}
+
+func (OAuthConsentModel) TableName() string {
+	return "oauth_consents"
+}
+
+func (m *OAuthConsentModel) ToEntity() *entity.OAuthConsent {
+	return &entity.OAuthConsent{
+		ID:        m.ID,
+		UserID:    m.UserID,
+		ClientID:  m.ClientID,
+		Scopes:    strings.Fields(m.Scopes),
+		CreatedAt: m.CreatedAt,
+		UpdatedAt: m.UpdatedAt,
+	}
+}
+
+func FromOAuthConsent(consent *entity.OAuthConsent) *OAuthConsentModel {
+	return &OAuthConsentModel{
+		ID:        consent.ID,
+		UserID:    consent.UserID,
+		ClientID:  consent.ClientID,
+		Scopes:    strings.Join(consent.Scopes, " "),
+		CreatedAt: consent.CreatedAt,
+		UpdatedAt: consent.UpdatedAt,
+	}
+}
@@ -0,0 +1,56 @@
+package model
+
+import (
+	"time"
+	"yiwen/go-ddd/internal/domain/event"
+)
+
+// outbox 事件状态
+const (
+	OutboxStatusPending   = 1 // 待投递
+	OutboxStatusPublished = 2 // 已投递
+	OutboxStatusFailed    = 3 // 超过最大重试次数，不再投递
+)
+
+// OutboxEventModel outbox 事件数据库模型
+// 事务性发件箱模式：领域事件与聚合状态在同一个事务中写入，
+// 再由 relay 异步读取并投递，保证事件至少投递一次
+type OutboxEventModel struct {
+	ID            uint64    `gorm:"primaryKey;autoIncrement"`
+	EventName     string    `gorm:"type:varchar(100);not null"`
+	AggregateID   string    `gorm:"type:varchar(36);index;not null"`
+	Payload       string    `gorm:"type:text;not null"`
+	Status        int       `gorm:"type:tinyint;not null;default:1;index:idx_outbox_status_next"`
+	Attempts      int       `gorm:"not null;default:0"`
+	NextAttemptAt time.Time `gorm:"not null;index:idx_outbox_status_next"`
+	LastError     string    `gorm:"type:varchar(1000)"`
+	OccurredAt    time.Time `gorm:"not null"`
+	CreatedAt     time.Time `gorm:"autoCreateTime"`
+	PublishedAt   *time.Time
+}
+
+func (OutboxEventModel) TableName() string {
+	return "outbox_events"
+}
+
+// NewOutboxEventModel 将领域事件序列化为待投递的 outbox 记录
+func NewOutboxEventModel(e event.Event) (*OutboxEventModel, error) {
+	payload, err := event.Encode(e)
+	if err != nil {
+		return nil, err
+	}
+
+	return &OutboxEventModel{
+		EventName:     e.EventName(),
+		AggregateID:   e.AggregateID(),
+		Payload:       string(payload),
+		Status:        OutboxStatusPending,
+		NextAttemptAt: time.Now(),
+		OccurredAt:    e.OccurredAt(),
+	}, nil
+}
+
+// ToEvent 将 outbox 记录反序列化为领域事件
+func (m *OutboxEventModel) ToEvent() (event.Event, error) {
+	return event.Decode(m.EventName, []byte(m.Payload))
+}
@@ -0,0 +1,84 @@
+package model
+
+import (
+	"time"
+	"yiwen/go-ddd/internal/domain/entity"
+)
+
+// PasskeyModel 通行密钥数据库模型
+type PasskeyModel struct {
+	ID           uint64 `gorm:"primaryKey;autoIncrement"`
+	UserID       uint64 `gorm:"not null;index"`
+	CredentialID []byte `gorm:"type:varbinary(1023);not null;uniqueIndex"`
+	PublicKey    []byte `gorm:"type:blob;not null"`
+	SignCount    uint32 `gorm:"not null;default:0"`
+	Name         string `gorm:"type:varchar(100);not null;default:''"`
+	LastUsedAt   *time.Time
+	CreatedAt    time.Time `gorm:"autoCreateTime"`
+}
+
+func (PasskeyModel) TableName() string {
+	return "user_passkeys"
+}
+
+func (m *PasskeyModel) ToEntity() *entity.Passkey {
+	return &entity.Passkey{
+		ID:           m.ID,
+		UserID:       m.UserID,
+		CredentialID: m.CredentialID,
+		PublicKey:    m.PublicKey,
+		SignCount:    m.SignCount,
+		Name:         m.Name,
+		CreatedAt:    m.CreatedAt,
+		LastUsedAt:   m.LastUsedAt,
+	}
+}
+
+func FromPasskey(passkey *entity.Passkey) *PasskeyModel {
+	return &PasskeyModel{
+		ID:           passkey.ID,
+		UserID:       passkey.UserID,
+		CredentialID: passkey.CredentialID,
+		PublicKey:    passkey.PublicKey,
+		SignCount:    passkey.SignCount,
+		Name:         passkey.Name,
+		LastUsedAt:   passkey.LastUsedAt,
+		CreatedAt:    passkey.CreatedAt,
+	}
+}
+
+// WebAuthnSessionModel 进行中的通行密钥仪式数据库模型
+type WebAuthnSessionModel struct {
+	ID            uint64    `gorm:"primaryKey;autoIncrement"`
+	ChallengeHash string    `gorm:"type:char(64);not null;uniqueIndex"`
+	UserID        uint64    `gorm:"not null;default:0"`
+	Ceremony      string    `gorm:"type:varchar(20);not null"`
+	ExpiresAt     time.Time `gorm:"not null;index"`
+	CreatedAt     time.Time `gorm:"autoCreateTime"`
+}
+
+func (WebAuthnSessionModel) TableName() string {
+	return "webauthn_sessions"
+}
+
+func (m *WebAuthnSessionModel) ToEntity() *entity.WebAuthnSession {
+	return &entity.WebAuthnSession{
+		ID:            m.ID,
+		ChallengeHash: m.ChallengeHash,
+		UserID:        m.UserID,
+		Ceremony:      m.Ceremony,
+		ExpiresAt:     m.ExpiresAt,
+		CreatedAt:     m.CreatedAt,
+	}
+}
+
+func FromWebAuthnSession(session *entity.WebAuthnSession) *WebAuthnSessionModel {
+	return &WebAuthnSessionModel{
+		ID:            session.ID,
+		ChallengeHash: session.ChallengeHash,
+		UserID:        session.UserID,
+		Ceremony:      session.Ceremony,
+		ExpiresAt:     session.ExpiresAt,
+		CreatedAt:     session.CreatedAt,
+	}
+}
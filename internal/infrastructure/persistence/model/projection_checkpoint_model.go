@@ -0,0 +1,14 @@
+package model
+
+import "time"
+
+// ProjectionCheckpointModel 投影重建检查点数据库模型
+type ProjectionCheckpointModel struct {
+	Name      string    `gorm:"type:varchar(100);primaryKey"`
+	Position  uint64    `gorm:"not null;default:0"` // 已处理到的 outbox 事件ID
+	UpdatedAt time.Time `gorm:"autoUpdateTime"`
+}
+
+func (ProjectionCheckpointModel) TableName() string {
+	return "projection_checkpoints"
+}
@@ -0,0 +1,57 @@
+package model
+
+import (
+	"time"
+	"yiwen/go-ddd/internal/domain/entity"
+)
+
+// RefreshTokenModel 刷新令牌数据库模型
+type RefreshTokenModel struct {
+	ID           uint64    `gorm:"primaryKey;autoIncrement"`
+	UserID       uint64    `gorm:"not null;index"`
+	ClientID     string    `gorm:"type:varchar(64);not null;default:''"`
+	Scope        string    `gorm:"type:varchar(1000);not null;default:''"`
+	FamilyID     string    `gorm:"type:varchar(36);not null;index"`
+	TokenHash    string    `gorm:"type:char(64);not null;uniqueIndex"`
+	TokenVersion int       `gorm:"not null;default:0"`
+	ExpiresAt    time.Time `gorm:"not null"`
+	UsedAt       *time.Time
+	RevokedAt    *time.Time
+	CreatedAt    time.Time `gorm:"autoCreateTime"`
+}
+
+func (RefreshTokenModel) TableName() string {
+	return "refresh_tokens"
+}
+
+func (m *RefreshTokenModel) ToEntity() *entity.RefreshToken {
+	return &entity.RefreshToken{
+		ID:           m.ID,
+		UserID:       m.UserID,
+		ClientID:     m.ClientID,
+		Scope:        m.Scope,
+		FamilyID:     m.FamilyID,
+		TokenHash:    m.TokenHash,
+		TokenVersion: m.TokenVersion,
+		ExpiresAt:    m.ExpiresAt,
+		UsedAt:       m.UsedAt,
+		RevokedAt:    m.RevokedAt,
+		CreatedAt:    m.CreatedAt,
+	}
+}
+
+func FromRefreshToken(token *entity.RefreshToken) *RefreshTokenModel {
+	return &RefreshTokenModel{
+		ID:           token.ID,
+		UserID:       token.UserID,
+		ClientID:     token.ClientID,
+		Scope:        token.Scope,
+		FamilyID:     token.FamilyID,
+		TokenHash:    token.TokenHash,
+		TokenVersion: token.TokenVersion,
+		ExpiresAt:    token.ExpiresAt,
+		UsedAt:       token.UsedAt,
+		RevokedAt:    token.RevokedAt,
+		CreatedAt:    token.CreatedAt,
+	}
+}
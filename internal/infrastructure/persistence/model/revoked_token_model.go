@@ -0,0 +1,15 @@
+package model
+
+import "time"
+
+// RevokedTokenModel 已注销访问令牌数据库模型
+type RevokedTokenModel struct {
+	TokenID   string    `gorm:"type:varchar(36);primaryKey"`
+	UserID    uint64    `gorm:"not null;index"`
+	ExpiresAt time.Time `gorm:"not null;index"`
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+}
+
+func (RevokedTokenModel) TableName() string {
+	return "revoked_tokens"
+}
@@ -0,0 +1,46 @@
+package model
+
+import (
+	"strings"
+	"time"
+	"yiwen/go-ddd/internal/domain/entity"
+)
+
+// RoleModel 角色数据库模型
+type RoleModel struct {
+	ID          uint64    `gorm:"primaryKey;autoIncrement"`
+	Name        string    `gorm:"type:varchar(50);not null;uniqueIndex"`
+	Description string    `gorm:"type:varchar(255)"`
+	ParentID    uint64    `gorm:"not null;default:0;index"`
+	Permissions string    `gorm:"type:varchar(1000);not null"` // 空格分隔的权限名称
+	CreatedAt   time.Time `gorm:"autoCreateTime"`
+	UpdatedAt   time.Time `gorm:"autoUpdateTime"`
+}
+
+func (RoleModel) TableName() string {
+	return "roles"
+}
+
+func (m *RoleModel) ToEntity() *entity.Role {
+	return &entity.Role{
+		ID:          m.ID,
+		Name:        m.Name,
+		Description: m.Description,
+		ParentID:    m.ParentID,
+		Permissions: strings.Fields(m.Permissions),
+		CreatedAt:   m.CreatedAt,
+		UpdatedAt:   m.UpdatedAt,
+	}
+}
+
+func FromRole(role *entity.Role) *RoleModel {
+	return &RoleModel{
+		ID:          role.ID,
+		Name:        role.Name,
+		Description: role.Description,
+		ParentID:    role.ParentID,
+		Permissions: strings.Join(role.Permissions, " "),
+		CreatedAt:   role.CreatedAt,
+		UpdatedAt:   role.UpdatedAt,
+	}
+}
@@ -0,0 +1,78 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+	"yiwen/go-ddd/internal/application/saga"
+)
+
+// SagaInstanceModel 流程实例数据库模型
+type SagaInstanceModel struct {
+	ID            uint64     `gorm:"primaryKey;autoIncrement"`
+	SagaName      string     `gorm:"type:varchar(50);not null;uniqueIndex:uk_saga_correlation"`
+	CorrelationID string     `gorm:"type:varchar(64);not null;uniqueIndex:uk_saga_correlation"`
+	State         string     `gorm:"type:varchar(50);not null"`
+	Data          string     `gorm:"type:text"`
+	DeadlineAt    *time.Time `gorm:"index:idx_saga_completed_deadline"`
+	Completed     bool       `gorm:"not null;default:false;index:idx_saga_completed_deadline"`
+	Version       int        `gorm:"not null;default:0"`
+	CreatedAt     time.Time  `gorm:"autoCreateTime"`
+	UpdatedAt     time.Time  `gorm:"autoUpdateTime"`
+}
+
+func (SagaInstanceModel) TableName() string {
+	return "saga_instances"
+}
+
+func (m *SagaInstanceModel) ToInstance() (*saga.Instance, error) {
+	data := make(map[string]string)
+	if m.Data != "" {
+		if err := json.Unmarshal([]byte(m.Data), &data); err != nil {
+			return nil, err
+		}
+	}
+
+	return &saga.Instance{
+		ID:            m.ID,
+		SagaName:      m.SagaName,
+		CorrelationID: m.CorrelationID,
+		State:         m.State,
+		Data:          data,
+		DeadlineAt:    m.DeadlineAt,
+		Completed:     m.Completed,
+		Version:       m.Version,
+		CreatedAt:     m.CreatedAt,
+		UpdatedAt:     m.UpdatedAt,
+	}, nil
+}
+
+func FromSagaInstance(instance *saga.Instance) (*SagaInstanceModel, error) {
+	data, err := json.Marshal(instance.Data)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SagaInstanceModel{
+		ID:            instance.ID,
+		SagaName:      instance.SagaName,
+		CorrelationID: instance.CorrelationID,
+		State:         instance.State,
+		Data:          string(data),
+		DeadlineAt:    instance.DeadlineAt,
+		Completed:     instance.Completed,
+		Version:       instance.Version,
+		CreatedAt:     instance.CreatedAt,
+		UpdatedAt:     instance.UpdatedAt,
+	}, nil
+}
+
+// SagaProcessedEventModel 流程已处理的事件，用于事件去重
+type SagaProcessedEventModel struct {
+	SagaName  string    `gorm:"type:varchar(50);primaryKey"`
+	EventID   string    `gorm:"type:varchar(36);primaryKey"`
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+}
+
+func (SagaProcessedEventModel) TableName() string {
+	return "saga_processed_events"
+}
@@ -0,0 +1,51 @@
+package model
+
+import (
+	"time"
+	"yiwen/go-ddd/internal/domain/entity"
+)
+
+// SessionModel 登录会话数据库模型
+type SessionModel struct {
+	ID         uint64    `gorm:"primaryKey;autoIncrement"`
+	SessionID  string    `gorm:"type:varchar(36);not null;uniqueIndex"`
+	UserID     uint64    `gorm:"not null;index"`
+	Device     string    `gorm:"type:varchar(100);not null;default:''"`
+	UserAgent  string    `gorm:"type:varchar(512);not null;default:''"`
+	IP         string    `gorm:"type:varchar(45);not null;default:''"`
+	ExpiresAt  time.Time `gorm:"not null"`
+	LastSeenAt time.Time `gorm:"not null"`
+	CreatedAt  time.Time `gorm:"autoCreateTime"`
+}
+
+func (SessionModel) TableName() string {
+	return "user_sessions"
+}
+
+func (m *SessionModel) ToEntity() *entity.Session {
+	return &entity.Session{
+		ID:         m.ID,
+		SessionID:  m.SessionID,
+		UserID:     m.UserID,
+		Device:     m.Device,
+		UserAgent:  m.UserAgent,
+		IP:         m.IP,
+		ExpiresAt:  m.ExpiresAt,
+		LastSeenAt: m.LastSeenAt,
+		CreatedAt:  m.CreatedAt,
+	}
+}
+
+func FromSession(session *entity.Session) *SessionModel {
+	return &SessionModel{
+		ID:         session.ID,
+		SessionID:  session.SessionID,
+		UserID:     session.UserID,
+		Device:     session.Device,
+		UserAgent:  session.UserAgent,
+		IP:         session.IP,
+		ExpiresAt:  session.ExpiresAt,
+		LastSeenAt: session.LastSeenAt,
+		CreatedAt:  session.CreatedAt,
+	}
+}
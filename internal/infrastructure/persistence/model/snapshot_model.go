@@ -0,0 +1,22 @@
+package model
+
+import (
+	"time"
+)
+
+// AggregateSnapshotModel 聚合快照数据库模型
+// 每个聚合只保留最新的一份快照
+type AggregateSnapshotModel struct {
+	ID            uint64    `gorm:"primaryKey;autoIncrement"`
+	AggregateID   string    `gorm:"type:varchar(36);uniqueIndex;not null"`
+	AggregateType string    `gorm:"type:varchar(50);not null"`
+	Version       int       `gorm:"not null"`
+	SchemaVersion int       `gorm:"not null"`
+	State         string    `gorm:"type:text;not null"`
+	CreatedAt     time.Time `gorm:"autoCreateTime"`
+	UpdatedAt     time.Time `gorm:"autoUpdateTime"`
+}
+
+func (AggregateSnapshotModel) TableName() string {
+	return "aggregate_snapshots"
+}
@@ -0,0 +1,94 @@
+package model
+
+import (
+	"time"
+	"yiwen/go-ddd/internal/domain/entity"
+)
+
+// TwoFactorModel 两步验证数据库模型
+type TwoFactorModel struct {
+	ID           uint64 `gorm:"primaryKey;autoIncrement"`
+	UserID       uint64 `gorm:"not null;uniqueIndex"`
+	Secret       string `gorm:"type:varchar(64);not null"`
+	ConfirmedAt  *time.Time
+	LastUsedStep int64     `gorm:"not null;default:0"`
+	CreatedAt    time.Time `gorm:"autoCreateTime"`
+	UpdatedAt    time.Time `gorm:"autoUpdateTime"`
+}
+
+func (TwoFactorModel) TableName() string {
+	return "user_two_factors"
+}
+
+func (m *TwoFactorModel) ToEntity() *entity.TwoFactor {
+	return &entity.TwoFactor{
+		ID:           m.ID,
+		UserID:       m.UserID,
+		Secret:       m.Secret,
+		ConfirmedAt:  m.ConfirmedAt,
+		LastUsedStep: m.LastUsedStep,
+		CreatedAt:    m.CreatedAt,
+		UpdatedAt:    m.UpdatedAt,
+	}
+}
+
+func FromTwoFactor(twoFactor *entity.TwoFactor) *TwoFactorModel {
+	return &TwoFactorModel{
+		ID:           twoFactor.ID,
+		UserID:       twoFactor.UserID,
+		Secret:       twoFactor.Secret,
+		ConfirmedAt:  twoFactor.ConfirmedAt,
+		LastUsedStep: twoFactor.LastUsedStep,
+		CreatedAt:    twoFactor.CreatedAt,
+		UpdatedAt:    twoFactor.UpdatedAt,
+	}
+}
+
+// RecoveryCodeModel 两步验证恢复码数据库模型，只保存哈希
+type RecoveryCodeModel struct {
+	ID        uint64 `gorm:"primaryKey;autoIncrement"`
+	UserID    uint64 `gorm:"not null;uniqueIndex:uk_user_code"`
+	CodeHash  string `gorm:"type:char(64);not null;uniqueIndex:uk_user_code"`
+	UsedAt    *time.Time
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+}
+
+func (RecoveryCodeModel) TableName() string {
+	return "two_factor_recovery_codes"
+}
+
+// TwoFactorChallengeModel 等待两步验证的登录数据库模型
+type TwoFactorChallengeModel struct {
+	ID        uint64    `gorm:"primaryKey;autoIncrement"`
+	TokenHash string    `gorm:"type:char(64);not null;uniqueIndex"`
+	UserID    uint64    `gorm:"not null;index"`
+	Attempts  int       `gorm:"not null;default:0"`
+	ExpiresAt time.Time `gorm:"not null;index"`
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+}
+
+func (TwoFactorChallengeModel) TableName() string {
+	return "two_factor_challenges"
+}
+
+func (m *TwoFactorChallengeModel) ToEntity() *entity.TwoFactorChallenge {
+	return &entity.TwoFactorChallenge{
+		ID:        m.ID,
+		TokenHash: m.TokenHash,
+		UserID:    m.UserID,
+		Attempts:  m.Attempts,
+		ExpiresAt: m.ExpiresAt,
+		CreatedAt: m.CreatedAt,
+	}
+}
+
+func FromTwoFactorChallenge(challenge *entity.TwoFactorChallenge) *TwoFactorChallengeModel {
+	return &TwoFactorChallengeModel{
+		ID:        challenge.ID,
+		TokenHash: challenge.TokenHash,
+		UserID:    challenge.UserID,
+		Attempts:  challenge.Attempts,
+		ExpiresAt: challenge.ExpiresAt,
+		CreatedAt: challenge.CreatedAt,
+	}
+}
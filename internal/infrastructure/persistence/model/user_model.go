@@ -1,6 +1,7 @@
 package model
 
 import (
+	"strings"
 	"time"
 	"yiwen/go-ddd/internal/domain/entity"
 	"yiwen/go-ddd/internal/domain/valueobject"
@@ -14,17 +15,20 @@ import (
 // 2. 可以自由添加数据库特有字段 - 例如软删除
 // 3. 便于处理ORM特有的变迁和钩子
 type UserModel struct {
-	ID           uint64    `gorm:"primayKey;autoIncrement"`
-	UUID         string    `gorm:"type:varchar(36);uniqueIndex;not null"`
-	Username     string    `gorm:"type:varchar(50);uniqueIndex;not null"`
-	Email        string    `gorm:"type:varchar(100);uniqueIndex;not null"`
-	PasswordHash string    `gorm:"type:varchar(255);not null"`
-	Nickname     string    `gorm:"type:varchar(50)"`
-	Avatar       string    `gorm:"type:varchar(255)"`
-	Status       int       `gorm:"type:tinyint(1);not null;default:1"` // tinyint(1) 是 MySQL 的字段类型，适合用于布尔值或者较小的整型状态字段
-	Role         string    `gorm:"type:varchar(20);not null;default:user"`
-	CreatedAt    time.Time `gorm:"autoCreateTime"`
-	UpdatedAt    time.Time `gorm:"autoUpdateTime"`
+	ID                    uint64    `gorm:"primayKey;autoIncrement"`
+	UUID                  string    `gorm:"type:varchar(36);uniqueIndex;not null"`
+	Username              string    `gorm:"type:varchar(50);uniqueIndex;not null"`
+	Email                 string    `gorm:"type:varchar(100);uniqueIndex;not null"`
+	PasswordHash          string    `gorm:"type:varchar(255);not null"`
+	Nickname              string    `gorm:"type:varchar(50)"`
+	Avatar                string    `gorm:"type:varchar(255)"`
+	EmailVerified         bool      `gorm:"not null;default:false"`
+	Status                int       `gorm:"type:tinyint(1);not null;default:1"`                  // tinyint(1) 是 MySQL 的字段类型，适合用于布尔值或者较小的整型状态字段
+	Roles                 string    `gorm:"column:role;type:varchar(255);not null;default:user"` // 逗号分隔的角色名称，沿用原来的 role 列
+	TokenVersion          int       `gorm:"not null;default:0"`
+	PasswordResetRequired bool      `gorm:"not null;default:false"`
+	CreatedAt             time.Time `gorm:"autoCreateTime"`
+	UpdatedAt             time.Time `gorm:"autoUpdateTime"`
 	// 软删除字段，gorm内置类型，表示删除时间。被删除不会真正移除，只是设置删除时间。
 	DeletedAt gorm.DeletedAt `gorm:"index"`
 }
@@ -37,29 +41,49 @@ func (m *UserModel) ToEnitity() *entity.User {
 	email, _ := valueobject.NewEmail(m.Email)
 	password := valueobject.NewPasswordFromHash(m.PasswordHash)
 
-	return &entity.User{
-		ID:       m.ID,
-		UUID:     m.UUID,
-		Username: m.Username,
-		Email:    email,
-		Password: password,
-		Nickname: m.Nickname,
-		Avatar:   m.Avatar,
-		Status:   entity.UserStatus(m.Status),
-		Role:     entity.UserRole(m.Role),
+	user := &entity.User{
+		ID:                    m.ID,
+		UUID:                  m.UUID,
+		Username:              m.Username,
+		Email:                 email,
+		Password:              password,
+		Nickname:              m.Nickname,
+		Avatar:                m.Avatar,
+		EmailVerified:         m.EmailVerified,
+		Status:                entity.UserStatus(m.Status),
+		Roles:                 entity.UserRolesFromNames(splitRoles(m.Roles)),
+		TokenVersion:          m.TokenVersion,
+		PasswordResetRequired: m.PasswordResetRequired,
+		CreatedAt:             m.CreatedAt,
+		UpdatedAt:             m.UpdatedAt,
 	}
+	if m.DeletedAt.Valid {
+		user.DeletedAt = m.DeletedAt.Time
+	}
+	return user
 }
 
 func FromEntity(user *entity.User) *UserModel {
 	return &UserModel{
-		ID:           user.ID,
-		UUID:         user.UUID,
-		Username:     user.Username,
-		Email:        user.Email.String(),
-		PasswordHash: user.Password.Hash(),
-		Nickname:     user.Nickname,
-		Avatar:       user.Avatar,
-		Status:       int(user.Status),
-		Role:         string(user.Role),
+		ID:                    user.ID,
+		UUID:                  user.UUID,
+		Username:              user.Username,
+		Email:                 user.Email.String(),
+		PasswordHash:          user.Password.Hash(),
+		Nickname:              user.Nickname,
+		Avatar:                user.Avatar,
+		EmailVerified:         user.EmailVerified,
+		Status:                int(user.Status),
+		Roles:                 strings.Join(user.RoleNames(), ","),
+		TokenVersion:          user.TokenVersion,
+		PasswordResetRequired: user.PasswordResetRequired,
+		DeletedAt:             gorm.DeletedAt{Time: user.DeletedAt, Valid: user.IsDeleted()},
+	}
+}
+
+func splitRoles(roles string) []string {
+	if roles == "" {
+		return nil
 	}
+	return strings.Split(roles, ",")
 }
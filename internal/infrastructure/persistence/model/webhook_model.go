@@ -0,0 +1,119 @@
+package model
+
+import (
+	"strings"
+	"time"
+	"yiwen/go-ddd/internal/domain/entity"
+)
+
+// WebhookEndpointModel webhook 端点数据库模型
+type WebhookEndpointModel struct {
+	ID         uint64    `gorm:"primaryKey;autoIncrement"`
+	URL        string    `gorm:"type:varchar(500);not null"`
+	EventTypes string    `gorm:"type:varchar(1000);not null"` // 逗号分隔的事件类型
+	Secret     string    `gorm:"type:varchar(100);not null"`
+	Active     bool      `gorm:"not null;default:true"`
+	CreatedAt  time.Time `gorm:"autoCreateTime"`
+	UpdatedAt  time.Time `gorm:"autoUpdateTime"`
+}
+
+func (WebhookEndpointModel) TableName() string {
+	return "webhook_endpoints"
+}
+
+func (m *WebhookEndpointModel) ToEntity() *entity.WebhookEndpoint {
+	var eventTypes []string
+	if m.EventTypes != "" {
+		eventTypes = strings.Split(m.EventTypes, ",")
+	}
+
+	return &entity.WebhookEndpoint{
+		ID:         m.ID,
+		URL:        m.URL,
+		EventTypes: eventTypes,
+		Secret:     m.Secret,
+		Active:     m.Active,
+		CreatedAt:  m.CreatedAt,
+		UpdatedAt:  m.UpdatedAt,
+	}
+}
+
+func FromWebhookEndpoint(endpoint *entity.WebhookEndpoint) *WebhookEndpointModel {
+	return &WebhookEndpointModel{
+		ID:         endpoint.ID,
+		URL:        endpoint.URL,
+		EventTypes: strings.Join(endpoint.EventTypes, ","),
+		Secret:     endpoint.Secret,
+		Active:     endpoint.Active,
+		CreatedAt:  endpoint.CreatedAt,
+		UpdatedAt:  endpoint.UpdatedAt,
+	}
+}
+
+// WebhookDeliveryModel webhook 投递记录数据库模型
+type WebhookDeliveryModel struct {
+	ID             uint64    `gorm:"primaryKey;autoIncrement"`
+	EndpointID     uint64    `gorm:"not null;uniqueIndex:uk_webhook_endpoint_event,priority:1"`
+	EventID        string    `gorm:"type:varchar(36);not null;index;uniqueIndex:uk_webhook_endpoint_event,priority:2"`
+	EventType      string    `gorm:"type:varchar(100);not null;index"`
+	Payload        string    `gorm:"type:text;not null"`
+	Status         int       `gorm:"type:tinyint;not null;default:1;index:idx_webhook_status_next"`
+	Attempts       int       `gorm:"not null;default:0"`
+	NextAttemptAt  time.Time `gorm:"not null;index:idx_webhook_status_next"`
+	ResponseStatus int       `gorm:"not null;default:0"`
+	ResponseBody   string    `gorm:"type:varchar(1000)"`
+	LastError      string    `gorm:"type:varchar(1000)"`
+	DeliveredAt    *time.Time
+	CreatedAt      time.Time `gorm:"autoCreateTime"`
+	UpdatedAt      time.Time `gorm:"autoUpdateTime"`
+}
+
+func (WebhookDeliveryModel) TableName() string {
+	return "webhook_deliveries"
+}
+
+func (m *WebhookDeliveryModel) ToEntity() *entity.WebhookDelivery {
+	return &entity.WebhookDelivery{
+		ID:             m.ID,
+		EndpointID:     m.EndpointID,
+		EventID:        m.EventID,
+		EventType:      m.EventType,
+		Payload:        m.Payload,
+		Status:         entity.WebhookDeliveryStatus(m.Status),
+		Attempts:       m.Attempts,
+		NextAttemptAt:  m.NextAttemptAt,
+		ResponseStatus: m.ResponseStatus,
+		ResponseBody:   m.ResponseBody,
+		LastError:      m.LastError,
+		DeliveredAt:    m.DeliveredAt,
+		CreatedAt:      m.CreatedAt,
+		UpdatedAt:      m.UpdatedAt,
+	}
+}
+
+func FromWebhookDelivery(delivery *entity.WebhookDelivery) *WebhookDeliveryModel {
+	return &WebhookDeliveryModel{
+		ID:             delivery.ID,
+		EndpointID:     delivery.EndpointID,
+		EventID:        delivery.EventID,
+		EventType:      delivery.EventType,
+		Payload:        delivery.Payload,
+		Status:         int(delivery.Status),
+		Attempts:       delivery.Attempts,
+		NextAttemptAt:  delivery.NextAttemptAt,
+		ResponseStatus: delivery.ResponseStatus,
+		ResponseBody:   truncate(delivery.ResponseBody, 1000),
+		LastError:      truncate(delivery.LastError, 1000),
+		DeliveredAt:    delivery.DeliveredAt,
+		CreatedAt:      delivery.CreatedAt,
+		UpdatedAt:      delivery.UpdatedAt,
+	}
+}
+
+// truncate 按字节截断字符串，避免超过字段长度
+func truncate(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+	return s[:max]
+}
@@ -0,0 +1,72 @@
+package mysql
+
+import (
+	"context"
+	"errors"
+	"time"
+	"yiwen/go-ddd/internal/domain/entity"
+	"yiwen/go-ddd/internal/domain/repository"
+	"yiwen/go-ddd/internal/infrastructure/persistence/model"
+
+	"gorm.io/gorm"
+)
+
+// ActionTokenRepository Mysql 一次性操作令牌仓库实现
+type ActionTokenRepository struct {
+	db *gorm.DB
+}
+
+func NewActionTokenRepository(db *gorm.DB) repository.ActionTokenRepository {
+	return &ActionTokenRepository{db: db}
+}
+
+// Save 写入新令牌，同一事务中作废旧令牌并顺带清理已经过期的记录
+func (r *ActionTokenRepository) Save(ctx context.Context, token *entity.ActionToken) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("(user_id = ? AND purpose = ?) OR expires_at < ?", token.UserID, token.Purpose, time.Now()).Delete(&model.ActionTokenModel{}).Error; err != nil {
+			return err
+		}
+
+		tokenModel := model.FromActionToken(token)
+		if err := tx.Create(tokenModel).Error; err != nil {
+			return err
+		}
+		token.ID = tokenModel.ID
+		return nil
+	})
+}
+
+func (r *ActionTokenRepository) FindLatest(ctx context.Context, userID uint64, purpose string) (*entity.ActionToken, error) {
+	var tokenModel model.ActionTokenModel
+
+	if err := r.db.WithContext(ctx).Where("user_id = ? AND purpose = ?", userID, purpose).Order("created_at DESC").First(&tokenModel).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, repository.ErrActionTokenNotFound
+		}
+		return nil, err
+	}
+
+	return tokenModel.ToEntity(), nil
+}
+
+// Take 先查询再按主键删除，删除影响行数为 0 说明已被并发的请求取走
+func (r *ActionTokenRepository) Take(ctx context.Context, purpose, tokenID string) (*entity.ActionToken, error) {
+	var tokenModel model.ActionTokenModel
+
+	if err := r.db.WithContext(ctx).Where("token_id = ? AND purpose = ?", tokenID, purpose).First(&tokenModel).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, repository.ErrActionTokenNotFound
+		}
+		return nil, err
+	}
+
+	result := r.db.WithContext(ctx).Delete(&model.ActionTokenModel{}, tokenModel.ID)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, repository.ErrActionTokenNotFound
+	}
+
+	return tokenModel.ToEntity(), nil
+}
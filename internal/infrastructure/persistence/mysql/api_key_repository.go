@@ -0,0 +1,76 @@
+package mysql
+
+import (
+	"context"
+	"errors"
+	"time"
+	"yiwen/go-ddd/internal/domain/entity"
+	"yiwen/go-ddd/internal/domain/repository"
+	"yiwen/go-ddd/internal/infrastructure/persistence/model"
+
+	"gorm.io/gorm"
+)
+
+// APIKeyRepository Mysql API 密钥仓库实现
+type APIKeyRepository struct {
+	db *gorm.DB
+}
+
+func NewAPIKeyRepository(db *gorm.DB) repository.APIKeyRepository {
+	return &APIKeyRepository{db: db}
+}
+
+func (r *APIKeyRepository) Save(ctx context.Context, key *entity.APIKey) error {
+	keyModel := model.FromAPIKey(key)
+
+	if err := r.db.WithContext(ctx).Create(keyModel).Error; err != nil {
+		return err
+	}
+	key.ID = keyModel.ID
+	return nil
+}
+
+func (r *APIKeyRepository) FindByHash(ctx context.Context, keyHash string) (*entity.APIKey, error) {
+	var keyModel model.APIKeyModel
+
+	if err := r.db.WithContext(ctx).Where("key_hash = ?", keyHash).First(&keyModel).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, repository.ErrAPIKeyNotFound
+		}
+		return nil, err
+	}
+
+	return keyModel.ToEntity(), nil
+}
+
+func (r *APIKeyRepository) ListByUser(ctx context.Context, userID uint64) ([]*entity.APIKey, error) {
+	var keyModels []model.APIKeyModel
+
+	if err := r.db.WithContext(ctx).Where("user_id = ?", userID).Order("id ASC").Find(&keyModels).Error; err != nil {
+		return nil, err
+	}
+
+	keys := make([]*entity.APIKey, len(keyModels))
+	for i := range keyModels {
+		keys[i] = keyModels[i].ToEntity()
+	}
+	return keys, nil
+}
+
+func (r *APIKeyRepository) Delete(ctx context.Context, userID, id uint64) error {
+	result := r.db.WithContext(ctx).Where("user_id = ?", userID).Delete(&model.APIKeyModel{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return repository.ErrAPIKeyNotFound
+	}
+	return nil
+}
+
+func (r *APIKeyRepository) UpdateLastUsed(ctx context.Context, id uint64, usedAt time.Time) error {
+	return r.db.WithContext(ctx).
+		Model(&model.APIKeyModel{}).
+		Where("id = ?", id).
+		Update("last_used_at", usedAt).Error
+}
@@ -0,0 +1,97 @@
+package mysql
+
+import (
+	"context"
+	"errors"
+	"yiwen/go-ddd/internal/domain/entity"
+	"yiwen/go-ddd/internal/domain/repository"
+	"yiwen/go-ddd/internal/infrastructure/persistence/model"
+
+	"gorm.io/gorm"
+)
+
+// AuditLogRepository Mysql 审计日志仓库实现
+type AuditLogRepository struct {
+	db *gorm.DB
+}
+
+func NewAuditLogRepository(db *gorm.DB) repository.AuditLogRepository {
+	return &AuditLogRepository{db: db}
+}
+
+// Save 插入审计日志，event_id 唯一索引冲突说明事件已经投影过，直接忽略
+func (r *AuditLogRepository) Save(ctx context.Context, log *entity.AuditLog) error {
+	logModel := model.FromAuditLog(log)
+
+	if err := r.db.WithContext(ctx).Create(logModel).Error; err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return nil
+		}
+		return err
+	}
+	log.ID = logModel.ID
+	return nil
+}
+
+func (r *AuditLogRepository) List(ctx context.Context, filter repository.AuditLogFilter, offset, limit int) ([]*entity.AuditLog, int64, error) {
+	query := r.filter(ctx, filter)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var logModels []model.AuditLogModel
+	if err := query.Order("occurred_at DESC, id DESC").Offset(offset).Limit(limit).Find(&logModels).Error; err != nil {
+		return nil, 0, err
+	}
+	return toAuditLogs(logModels), total, nil
+}
+
+// ListAfter 按 (occurred_at, id) 做游标分页，翻页时不需要跳过前面的记录，也不会因为并发写入而重复或遗漏
+func (r *AuditLogRepository) ListAfter(ctx context.Context, filter repository.AuditLogFilter, after *repository.AuditLogCursor, limit int) ([]*entity.AuditLog, error) {
+	query := r.filter(ctx, filter)
+	if after != nil {
+		query = query.Where("occurred_at < ? OR (occurred_at = ? AND id < ?)", after.OccurredAt, after.OccurredAt, after.ID)
+	}
+
+	var logModels []model.AuditLogModel
+	if err := query.Order("occurred_at DESC, id DESC").Limit(limit).Find(&logModels).Error; err != nil {
+		return nil, err
+	}
+	return toAuditLogs(logModels), nil
+}
+
+func (r *AuditLogRepository) filter(ctx context.Context, filter repository.AuditLogFilter) *gorm.DB {
+	query := r.db.WithContext(ctx).Model(&model.AuditLogModel{})
+	if filter.ActorID != 0 {
+		query = query.Where("actor_id = ?", filter.ActorID)
+	}
+	if filter.TargetUUID != "" {
+		query = query.Where("target_uuid = ?", filter.TargetUUID)
+	}
+	if filter.Action != "" {
+		query = query.Where("action = ?", filter.Action)
+	}
+	if !filter.From.IsZero() {
+		query = query.Where("occurred_at >= ?", filter.From)
+	}
+	if !filter.To.IsZero() {
+		query = query.Where("occurred_at < ?", filter.To)
+	}
+	return query
+}
+
+func toAuditLogs(logModels []model.AuditLogModel) []*entity.AuditLog {
+	logs := make([]*entity.AuditLog, len(logModels))
+	for i := range logModels {
+		logs[i] = logModels[i].ToEntity()
+	}
+	return logs
+}
+
+func (r *AuditLogRepository) DeleteProjected(ctx context.Context) error {
+	return r.db.WithContext(ctx).
+		Where("source = ?", entity.AuditSourceEvent).
+		Delete(&model.AuditLogModel{}).Error
+}
@@ -0,0 +1,41 @@
+package mysql
+
+import (
+	"context"
+	"errors"
+	"yiwen/go-ddd/internal/infrastructure/persistence/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// CheckpointStore 投影重建检查点存储
+type CheckpointStore struct {
+	db *gorm.DB
+}
+
+func NewCheckpointStore(db *gorm.DB) *CheckpointStore {
+	return &CheckpointStore{db: db}
+}
+
+// Load 读取投影的检查点，不存在时返回 0
+func (s *CheckpointStore) Load(ctx context.Context, name string) (uint64, error) {
+	var record model.ProjectionCheckpointModel
+	if err := s.db.WithContext(ctx).Where("name = ?", name).First(&record).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return 0, nil
+		}
+		return 0, err
+	}
+	return record.Position, nil
+}
+
+// Save 保存投影的检查点
+func (s *CheckpointStore) Save(ctx context.Context, name string, position uint64) error {
+	record := &model.ProjectionCheckpointModel{Name: name, Position: position}
+
+	return s.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "name"}},
+		DoUpdates: clause.AssignmentColumns([]string{"position", "updated_at"}),
+	}).Create(record).Error
+}
@@ -0,0 +1,83 @@
+package mysql
+
+import (
+	"yiwen/go-ddd/internal/infrastructure/config"
+	"yiwen/go-ddd/internal/infrastructure/persistence/model"
+
+	mysqldriver "gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// NewDB 根据配置创建数据库连接
+func NewDB(cfg *config.Config) (*gorm.DB, error) {
+	var logLevel logger.LogLevel
+	switch cfg.App.Mode {
+	case "debug":
+		logLevel = logger.Info
+	case "test":
+		logLevel = logger.Warn
+	case "production":
+		logLevel = logger.Error
+	default:
+		logLevel = logger.Info
+	}
+
+	db, err := gorm.Open(mysqldriver.Open(cfg.Database.DSN()), &gorm.Config{
+		Logger: logger.Default.LogMode(logLevel),
+		// 将驱动错误转换为 gorm 错误，例如唯一索引冲突转换为 gorm.ErrDuplicatedKey
+		TranslateError: true,
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+
+	sqlDB.SetMaxIdleConns(cfg.Database.MaxIdleConns)
+	sqlDB.SetMaxOpenConns(cfg.Database.MaxOpenConns)
+
+	// 自动迁移（AutoMigrate）是 GORM 提供的一种功能，用于自动根据模型（如 UserModel）的结构变更数据库中的表结构。
+	// 它会自动创建、修改（字段类型默认兼容）、删除表结构，以确保数据库结构和 Go 代码中的模型结构保持一致。
+	// 但是，自动迁移不会删除已有字段的内容，也不会更改已有字段的类型，它主要用于保持字段的增加或表的创建同步。
+	// 这里示例只在生产环境（production）下才自动迁移，以避免开发或测试时误操作数据库结构。
+	if cfg.App.Mode == "production" {
+		if err := db.AutoMigrate(
+			&model.UserModel{},
+			&model.OutboxEventModel{},
+			&model.EventStoreModel{},
+			&model.AggregateSnapshotModel{},
+			&model.WebhookEndpointModel{},
+			&model.WebhookDeliveryModel{},
+			&model.AuditLogModel{},
+			&model.ProjectionCheckpointModel{},
+			&model.SagaInstanceModel{},
+			&model.SagaProcessedEventModel{},
+			&model.RefreshTokenModel{},
+			&model.RevokedTokenModel{},
+			&model.OAuthClientModel{},
+			&model.AuthorizationCodeModel{},
+			&model.OAuthConsentModel{},
+			&model.ExternalIdentityModel{},
+			&model.ExternalLoginStateModel{},
+			&model.TwoFactorModel{},
+			&model.RecoveryCodeModel{},
+			&model.TwoFactorChallengeModel{},
+			&model.PasskeyModel{},
+			&model.WebAuthnSessionModel{},
+			&model.APIKeyModel{},
+			&model.SessionModel{},
+			&model.ActionTokenModel{},
+			&model.LoginAttemptModel{},
+			&model.RoleModel{},
+		); err != nil {
+			return nil, err
+		}
+	}
+
+	return db, nil
+}
@@ -0,0 +1,172 @@
+package mysql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"yiwen/go-ddd/internal/domain/aggregate"
+	"yiwen/go-ddd/internal/domain/repository"
+	"yiwen/go-ddd/internal/infrastructure/persistence/model"
+
+	"gorm.io/gorm"
+)
+
+// EventSourcedUserRepository 基于事件溯源的用户聚合仓库
+// 1. 事件存储是唯一的事实来源，聚合通过重放事件重建
+// 2. users 表作为查询投影，在同一事务中同步更新，供列表、唯一性校验等查询使用
+// 3. 领域事件同时写入 outbox，由 relay 投递
+// 4. 每追加 snapshotEvery 个事件保存一次快照，加载时从最新快照开始重放
+type EventSourcedUserRepository struct {
+	db            *gorm.DB
+	eventStore    *EventStore
+	snapshotStore *SnapshotStore
+	snapshotEvery int
+}
+
+// NewEventSourcedUserRepository 创建事件溯源用户仓库，snapshotEvery <= 0 表示不生成快照
+func NewEventSourcedUserRepository(db *gorm.DB, eventStore *EventStore, snapshotStore *SnapshotStore, snapshotEvery int) *EventSourcedUserRepository {
+	return &EventSourcedUserRepository{
+		db:            db,
+		eventStore:    eventStore,
+		snapshotStore: snapshotStore,
+		snapshotEvery: snapshotEvery,
+	}
+}
+
+var _ repository.UserAggregateRepository = (*EventSourcedUserRepository)(nil)
+
+// Load 通过 users 投影找到聚合 UUID，再从快照和之后的事件重建聚合
+func (r *EventSourcedUserRepository) Load(ctx context.Context, id uint64) (*aggregate.UserAggregate, error) {
+	user, err := (&UserRepository{db: r.db}).FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	agg, err := r.rehydrate(ctx, user.UUID, true)
+	if err != nil {
+		return nil, err
+	}
+	agg.User.ID = user.ID
+	return agg, nil
+}
+
+// Save 乐观追加事件并更新 users 投影和 outbox
+func (r *EventSourcedUserRepository) Save(ctx context.Context, agg *aggregate.UserAggregate) error {
+	events := agg.GetEvents()
+	oldVersion := agg.Version
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := r.eventStore.append(tx, aggregate.UserAggregateType, agg.User.UUID, agg.Version, events); err != nil {
+			return err
+		}
+		if err := (&UserRepository{db: tx}).Save(ctx, agg.User); err != nil {
+			return err
+		}
+		return appendOutboxEvents(tx, events)
+	})
+	if err != nil {
+		return err
+	}
+
+	agg.Version += len(events)
+
+	// 跨过快照间隔时保存快照；快照只是加速手段，失败不影响本次保存
+	if r.snapshotEvery > 0 && oldVersion/r.snapshotEvery != agg.Version/r.snapshotEvery {
+		if err := r.snapshotStore.Save(ctx, agg.Snapshot()); err != nil {
+			log.Printf("failed to save snapshot for %s: %v", agg.User.UUID, err)
+		}
+	}
+	return nil
+}
+
+// RebuildSnapshot 忽略已有快照，重放全部事件后重新生成快照
+// 用于聚合状态结构变化（UserSnapshotSchemaVersion 递增）之后
+func (r *EventSourcedUserRepository) RebuildSnapshot(ctx context.Context, aggregateID string) error {
+	agg, err := r.rehydrate(ctx, aggregateID, false)
+	if err != nil {
+		return err
+	}
+	return r.snapshotStore.Save(ctx, agg.Snapshot())
+}
+
+// RebuildAllSnapshots 为所有用户聚合重新生成快照
+func (r *EventSourcedUserRepository) RebuildAllSnapshots(ctx context.Context) (int, error) {
+	ids, err := r.eventStore.AggregateIDs(ctx, aggregate.UserAggregateType)
+	if err != nil {
+		return 0, err
+	}
+
+	for i, id := range ids {
+		if err := r.RebuildSnapshot(ctx, id); err != nil {
+			return i, err
+		}
+	}
+	return len(ids), nil
+}
+
+// Backfill 为切换到事件溯源之前已存在、还没有事件流的用户补写 user.imported 起始事件
+// 1. 事件内容取自 users 表中的当前状态，已删除的用户同样补写
+// 2. 补写的事件不写入 outbox，不会触发通知、webhook 等后续处理
+// 3. 已有事件流的用户跳过，可以重复执行
+func (r *EventSourcedUserRepository) Backfill(ctx context.Context, batchSize int) (int, error) {
+	count := 0
+	var lastID uint64
+	for {
+		var models []*model.UserModel
+		if err := r.db.WithContext(ctx).Unscoped().
+			Where("id > ?", lastID).
+			Where("NOT EXISTS (?)", r.db.Model(&model.EventStoreModel{}).Select("1").Where("event_store.aggregate_id = users.uuid")).
+			Order("id ASC").
+			Limit(batchSize).
+			Find(&models).Error; err != nil {
+			return count, err
+		}
+		if len(models) == 0 {
+			return count, nil
+		}
+
+		for _, m := range models {
+			lastID = m.ID
+
+			agg, err := aggregate.Import(m.ToEnitity())
+			if err != nil {
+				return count, fmt.Errorf("failed to import user %s: %w", m.UUID, err)
+			}
+			err = r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+				return r.eventStore.append(tx, aggregate.UserAggregateType, agg.User.UUID, 0, agg.GetEvents())
+			})
+			if errors.Is(err, repository.ErrConcurrencyConflict) {
+				// 补写期间用户已经有了事件流
+				continue
+			}
+			if err != nil {
+				return count, err
+			}
+			count++
+		}
+	}
+}
+
+// rehydrate 重建聚合，useSnapshot 为 false 时从第一个事件开始重放
+func (r *EventSourcedUserRepository) rehydrate(ctx context.Context, aggregateID string, useSnapshot bool) (*aggregate.UserAggregate, error) {
+	if useSnapshot {
+		snapshot, err := r.snapshotStore.Load(ctx, aggregateID)
+		if err != nil {
+			return nil, err
+		}
+		if snapshot != nil {
+			history, err := r.eventStore.Load(ctx, aggregateID, snapshot.Version)
+			if err != nil {
+				return nil, err
+			}
+			return aggregate.NewUserAggregateFromSnapshot(snapshot, history)
+		}
+	}
+
+	history, err := r.eventStore.Load(ctx, aggregateID, 0)
+	if err != nil {
+		return nil, err
+	}
+	return aggregate.NewUserAggregateFromHistory(history)
+}
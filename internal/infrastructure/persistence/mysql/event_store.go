@@ -0,0 +1,141 @@
+package mysql
+
+import (
+	"context"
+	"errors"
+	"yiwen/go-ddd/internal/domain/event"
+	"yiwen/go-ddd/internal/domain/repository"
+	"yiwen/go-ddd/internal/infrastructure/persistence/model"
+
+	"gorm.io/gorm"
+)
+
+// EventStore Mysql 事件存储
+// 事件按聚合分流保存，每个聚合的版本号从 1 开始连续递增
+type EventStore struct {
+	db *gorm.DB
+}
+
+func NewEventStore(db *gorm.DB) *EventStore {
+	return &EventStore{db: db}
+}
+
+// Load 读取聚合在 afterVersion 之后的全部事件，按版本号排序
+func (s *EventStore) Load(ctx context.Context, aggregateID string, afterVersion int) ([]event.Event, error) {
+	var records []*model.EventStoreModel
+	if err := s.db.WithContext(ctx).
+		Where("aggregate_id = ? AND version > ?", aggregateID, afterVersion).
+		Order("version ASC").
+		Find(&records).Error; err != nil {
+		return nil, err
+	}
+
+	events := make([]event.Event, len(records))
+	for i, record := range records {
+		e, err := record.ToEvent()
+		if err != nil {
+			return nil, err
+		}
+		events[i] = e
+	}
+	return events, nil
+}
+
+// AggregateIDs 返回指定类型的所有聚合ID
+func (s *EventStore) AggregateIDs(ctx context.Context, aggregateType string) ([]string, error) {
+	var ids []string
+	if err := s.db.WithContext(ctx).
+		Model(&model.EventStoreModel{}).
+		Where("aggregate_type = ?", aggregateType).
+		Distinct().
+		Pluck("aggregate_id", &ids).Error; err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// FetchAfter 按全局顺序读取 id 大于 position 的事件
+func (s *EventStore) FetchAfter(ctx context.Context, position uint64, limit int) ([]*model.EventStoreModel, error) {
+	var records []*model.EventStoreModel
+	if err := s.db.WithContext(ctx).
+		Where("id > ?", position).
+		Order("id ASC").
+		Limit(limit).
+		Find(&records).Error; err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+// CountAfter 统计 id 大于 position 的事件数量
+func (s *EventStore) CountAfter(ctx context.Context, position uint64) (int64, error) {
+	var count int64
+	if err := s.db.WithContext(ctx).
+		Model(&model.EventStoreModel{}).
+		Where("id > ?", position).
+		Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// CountMissing 统计不在事件存储中的历史
+// 1. users 表中没有事件流的用户，包括已删除的用户
+// 2. outbox 中早于所属用户事件流的事件，即状态存储模式下产生、事件存储中没有的事件
+func (s *EventStore) CountMissing(ctx context.Context) (int64, error) {
+	var users int64
+	if err := s.db.WithContext(ctx).
+		Model(&model.UserModel{}).
+		Unscoped().
+		Where("NOT EXISTS (?)", s.db.Model(&model.EventStoreModel{}).Select("1").Where("event_store.aggregate_id = users.uuid")).
+		Count(&users).Error; err != nil {
+		return 0, err
+	}
+
+	// 事件溯源模式下事件存储与 outbox 在同一事务中先后写入，事件流的第一个事件不会晚于 outbox 中的事件
+	var events int64
+	if err := s.db.WithContext(ctx).
+		Model(&model.OutboxEventModel{}).
+		Where("NOT EXISTS (?)", s.db.Model(&model.EventStoreModel{}).Select("1").Where("event_store.aggregate_id = outbox_events.aggregate_id AND event_store.version = 1 AND event_store.created_at <= outbox_events.created_at")).
+		Count(&events).Error; err != nil {
+		return 0, err
+	}
+	return users + events, nil
+}
+
+// append 在给定事务中乐观追加事件
+// expectedVersion 是调用方加载聚合时看到的版本，若当前版本不一致则返回 ErrConcurrencyConflict；
+// 并发写入时由 (aggregate_id, version) 唯一索引兜底
+func (s *EventStore) append(tx *gorm.DB, aggregateType, aggregateID string, expectedVersion int, events []event.Event) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	var current int
+	if err := tx.Model(&model.EventStoreModel{}).
+		Select("COALESCE(MAX(version), 0)").
+		Where("aggregate_id = ?", aggregateID).
+		Scan(&current).Error; err != nil {
+		return err
+	}
+	if current != expectedVersion {
+		return repository.ErrConcurrencyConflict
+	}
+
+	records := make([]*model.EventStoreModel, len(events))
+	for i, e := range events {
+		record, err := model.NewEventStoreModel(aggregateType, expectedVersion+i+1, e)
+		if err != nil {
+			return err
+		}
+		records[i] = record
+	}
+
+	if err := tx.Create(&records).Error; err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return repository.ErrConcurrencyConflict
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+package mysql
+
+import (
+	"context"
+	"errors"
+	"time"
+	"yiwen/go-ddd/internal/domain/entity"
+	"yiwen/go-ddd/internal/domain/repository"
+	"yiwen/go-ddd/internal/infrastructure/persistence/model"
+
+	"gorm.io/gorm"
+)
+
+// ExternalIdentityRepository Mysql 外部身份仓库实现
+type ExternalIdentityRepository struct {
+	db *gorm.DB
+}
+
+func NewExternalIdentityRepository(db *gorm.DB) repository.ExternalIdentityRepository {
+	return &ExternalIdentityRepository{db: db}
+}
+
+func (r *ExternalIdentityRepository) SaveIdentity(ctx context.Context, identity *entity.ExternalIdentity) error {
+	identityModel := model.FromExternalIdentity(identity)
+
+	if identity.ID == 0 {
+		if err := r.db.WithContext(ctx).Create(identityModel).Error; err != nil {
+			if errors.Is(err, gorm.ErrDuplicatedKey) {
+				return repository.ErrExternalIdentityExists
+			}
+			return err
+		}
+		identity.ID = identityModel.ID
+		return nil
+	}
+	return r.db.WithContext(ctx).Save(identityModel).Error
+}
+
+func (r *ExternalIdentityRepository) FindIdentity(ctx context.Context, provider, subject string) (*entity.ExternalIdentity, error) {
+	var identityModel model.ExternalIdentityModel
+
+	if err := r.db.WithContext(ctx).Where("provider = ? AND subject = ?", provider, subject).First(&identityModel).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, repository.ErrExternalIdentityNotFound
+		}
+		return nil, err
+	}
+
+	return identityModel.ToEntity(), nil
+}
+
+func (r *ExternalIdentityRepository) ListIdentitiesByUser(ctx context.Context, userID uint64) ([]*entity.ExternalIdentity, error) {
+	var identityModels []model.ExternalIdentityModel
+
+	if err := r.db.WithContext(ctx).Where("user_id = ?", userID).Order("id ASC").Find(&identityModels).Error; err != nil {
+		return nil, err
+	}
+
+	identities := make([]*entity.ExternalIdentity, len(identityModels))
+	for i := range identityModels {
+		identities[i] = identityModels[i].ToEntity()
+	}
+	return identities, nil
+}
+
+// SaveLoginState 写入登录状态，同时顺带清理已经过期、不会再被回调使用的状态
+func (r *ExternalIdentityRepository) SaveLoginState(ctx context.Context, state *entity.ExternalLoginState) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("expires_at < ?", time.Now()).Delete(&model.ExternalLoginStateModel{}).Error; err != nil {
+			return err
+		}
+
+		stateModel := model.FromExternalLoginState(state)
+		if err := tx.Create(stateModel).Error; err != nil {
+			return err
+		}
+		state.ID = stateModel.ID
+		return nil
+	})
+}
+
+// TakeLoginState 先查询再按主键删除，删除影响行数为 0 说明已被并发的回调取走
+func (r *ExternalIdentityRepository) TakeLoginState(ctx context.Context, stateHash string) (*entity.ExternalLoginState, error) {
+	var stateModel model.ExternalLoginStateModel
+
+	if err := r.db.WithContext(ctx).Where("state_hash = ?", stateHash).First(&stateModel).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, repository.ErrExternalLoginStateNotFound
+		}
+		return nil, err
+	}
+
+	result := r.db.WithContext(ctx).Delete(&model.ExternalLoginStateModel{}, stateModel.ID)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, repository.ErrExternalLoginStateNotFound
+	}
+
+	return stateModel.ToEntity(), nil
+}
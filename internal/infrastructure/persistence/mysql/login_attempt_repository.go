@@ -0,0 +1,66 @@
+package mysql
+
+import (
+	"context"
+	"errors"
+	"time"
+	"yiwen/go-ddd/internal/domain/entity"
+	"yiwen/go-ddd/internal/domain/repository"
+	"yiwen/go-ddd/internal/infrastructure/persistence/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// LoginAttemptRepository Mysql 登录失败记录仓库实现，多个实例共享计数
+type LoginAttemptRepository struct {
+	db *gorm.DB
+}
+
+func NewLoginAttemptRepository(db *gorm.DB) repository.LoginAttemptRepository {
+	return &LoginAttemptRepository{db: db}
+}
+
+func (r *LoginAttemptRepository) Find(ctx context.Context, key string) (*entity.LoginAttempt, error) {
+	var attemptModel model.LoginAttemptModel
+
+	if err := r.db.WithContext(ctx).Where("attempt_key = ?", key).First(&attemptModel).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, repository.ErrLoginAttemptNotFound
+		}
+		return nil, err
+	}
+
+	return attemptModel.ToEntity(), nil
+}
+
+// RecordFailure 通过 INSERT ... ON DUPLICATE KEY UPDATE 原子地累加失败次数
+// MySQL 按顺序执行赋值，计算 failures 时 last_failed_at 还是上一次失败的时间
+func (r *LoginAttemptRepository) RecordFailure(ctx context.Context, key string, window time.Duration) (*entity.LoginAttempt, error) {
+	now := time.Now()
+	attemptModel := model.LoginAttemptModel{AttemptKey: key, Failures: 1, LastFailedAt: now}
+
+	err := r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "attempt_key"}},
+		DoUpdates: clause.Set{
+			{Column: clause.Column{Name: "failures"}, Value: gorm.Expr("IF(last_failed_at < ?, 1, failures + 1)", now.Add(-window))},
+			{Column: clause.Column{Name: "last_failed_at"}, Value: now},
+		},
+	}).Create(&attemptModel).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return r.Find(ctx, key)
+}
+
+func (r *LoginAttemptRepository) Lock(ctx context.Context, key string, until time.Time) error {
+	return r.db.WithContext(ctx).
+		Model(&model.LoginAttemptModel{}).
+		Where("attempt_key = ?", key).
+		Updates(map[string]interface{}{"failures": 0, "locked_until": until}).Error
+}
+
+func (r *LoginAttemptRepository) Reset(ctx context.Context, key string) error {
+	return r.db.WithContext(ctx).Where("attempt_key = ?", key).Delete(&model.LoginAttemptModel{}).Error
+}
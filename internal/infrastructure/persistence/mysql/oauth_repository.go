@@ -0,0 +1,139 @@
+package mysql
+
+import (
+	"context"
+	"errors"
+	"time"
+	"yiwen/go-ddd/internal/domain/entity"
+	"yiwen/go-ddd/internal/domain/repository"
+	"yiwen/go-ddd/internal/infrastructure/persistence/model"
+
+	"gorm.io/gorm"
+)
+
+// OAuthRepository Mysql OAuth2 仓库实现
+type OAuthRepository struct {
+	db *gorm.DB
+}
+
+func NewOAuthRepository(db *gorm.DB) repository.OAuthRepository {
+	return &OAuthRepository{db: db}
+}
+
+func (r *OAuthRepository) SaveClient(ctx context.Context, client *entity.OAuthClient) error {
+	clientModel := model.FromOAuthClient(client)
+
+	if client.ID == 0 {
+		if err := r.db.WithContext(ctx).Create(clientModel).Error; err != nil {
+			return err
+		}
+		client.ID = clientModel.ID
+		return nil
+	}
+	return r.db.WithContext(ctx).Save(clientModel).Error
+}
+
+func (r *OAuthRepository) FindClientByClientID(ctx context.Context, clientID string) (*entity.OAuthClient, error) {
+	var clientModel model.OAuthClientModel
+
+	if err := r.db.WithContext(ctx).Where("client_id = ?", clientID).First(&clientModel).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, repository.ErrOAuthClientNotFound
+		}
+		return nil, err
+	}
+
+	return clientModel.ToEntity(), nil
+}
+
+func (r *OAuthRepository) ListClients(ctx context.Context) ([]*entity.OAuthClient, error) {
+	var clientModels []model.OAuthClientModel
+
+	if err := r.db.WithContext(ctx).Order("id ASC").Find(&clientModels).Error; err != nil {
+		return nil, err
+	}
+
+	clients := make([]*entity.OAuthClient, len(clientModels))
+	for i := range clientModels {
+		clients[i] = clientModels[i].ToEntity()
+	}
+	return clients, nil
+}
+
+// DeleteClient 删除客户端及其授权同意记录
+func (r *OAuthRepository) DeleteClient(ctx context.Context, clientID string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("client_id = ?", clientID).Delete(&model.OAuthClientModel{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return repository.ErrOAuthClientNotFound
+		}
+		return tx.Where("client_id = ?", clientID).Delete(&model.OAuthConsentModel{}).Error
+	})
+}
+
+func (r *OAuthRepository) SaveAuthorizationCode(ctx context.Context, code *entity.AuthorizationCode) error {
+	codeModel := model.FromAuthorizationCode(code)
+
+	if err := r.db.WithContext(ctx).Create(codeModel).Error; err != nil {
+		return err
+	}
+	code.ID = codeModel.ID
+	return nil
+}
+
+func (r *OAuthRepository) FindAuthorizationCodeByHash(ctx context.Context, codeHash string) (*entity.AuthorizationCode, error) {
+	var codeModel model.AuthorizationCodeModel
+
+	if err := r.db.WithContext(ctx).Where("code_hash = ?", codeHash).First(&codeModel).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, repository.ErrAuthorizationCodeNotFound
+		}
+		return nil, err
+	}
+
+	return codeModel.ToEntity(), nil
+}
+
+// MarkAuthorizationCodeUsed 通过条件更新保证授权码只能被使用一次
+func (r *OAuthRepository) MarkAuthorizationCodeUsed(ctx context.Context, id uint64) error {
+	result := r.db.WithContext(ctx).
+		Model(&model.AuthorizationCodeModel{}).
+		Where("id = ? AND used_at IS NULL", id).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return repository.ErrAuthorizationCodeUsed
+	}
+	return nil
+}
+
+func (r *OAuthRepository) FindConsent(ctx context.Context, userID uint64, clientID string) (*entity.OAuthConsent, error) {
+	var consentModel model.OAuthConsentModel
+
+	if err := r.db.WithContext(ctx).Where("user_id = ? AND client_id = ?", userID, clientID).First(&consentModel).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, repository.ErrOAuthConsentNotFound
+		}
+		return nil, err
+	}
+
+	return consentModel.ToEntity(), nil
+}
+
+func (r *OAuthRepository) SaveConsent(ctx context.Context, consent *entity.OAuthConsent) error {
+	consentModel := model.FromOAuthConsent(consent)
+
+	if consent.ID == 0 {
+		if err := r.db.WithContext(ctx).Create(consentModel).Error; err != nil {
+			return err
+		}
+		consent.ID = consentModel.ID
+		return nil
+	}
+	return r.db.WithContext(ctx).Save(consentModel).Error
+}
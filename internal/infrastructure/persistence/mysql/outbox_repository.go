@@ -0,0 +1,130 @@
+package mysql
+
+import (
+	"context"
+	"time"
+	"yiwen/go-ddd/internal/domain/event"
+	"yiwen/go-ddd/internal/infrastructure/persistence/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// OutboxRepository outbox 事件仓库
+// 写入由聚合仓库在保存聚合的事务中完成，这里提供给 relay 读取和更新投递状态。
+// 已投递的事件不会删除，按 id 顺序构成全部事件的日志，用于重建投影
+type OutboxRepository struct {
+	db *gorm.DB
+}
+
+func NewOutboxRepository(db *gorm.DB) *OutboxRepository {
+	return &OutboxRepository{db: db}
+}
+
+// FetchPending 按写入顺序领取到期的待投递事件
+// 使用 FOR UPDATE SKIP LOCKED 读取，并在同一事务中把 next_attempt_at 推迟 lease，
+// 多个实例同时投递时不会领取到同一条事件；实例在租期内没有更新状态时，事件到期后被重新领取
+func (r *OutboxRepository) FetchPending(ctx context.Context, limit int, lease time.Duration) ([]*model.OutboxEventModel, error) {
+	var events []*model.OutboxEventModel
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		if err := tx.
+			Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND next_attempt_at <= ?", model.OutboxStatusPending, now).
+			Order("id ASC").
+			Limit(limit).
+			Find(&events).Error; err != nil {
+			return err
+		}
+		if len(events) == 0 {
+			return nil
+		}
+
+		ids := make([]uint64, len(events))
+		for i, e := range events {
+			ids[i] = e.ID
+		}
+		return tx.Model(&model.OutboxEventModel{}).
+			Where("id IN ?", ids).
+			Update("next_attempt_at", now.Add(lease)).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+// MarkPublished 标记事件已投递
+func (r *OutboxRepository) MarkPublished(ctx context.Context, id uint64) error {
+	return r.db.WithContext(ctx).
+		Model(&model.OutboxEventModel{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"status":       model.OutboxStatusPublished,
+			"published_at": time.Now(),
+			"last_error":   "",
+		}).Error
+}
+
+// MarkFailed 记录投递失败，dead 为 true 时不再重试
+func (r *OutboxRepository) MarkFailed(ctx context.Context, id uint64, attempts int, nextAttemptAt time.Time, lastError string, dead bool) error {
+	status := model.OutboxStatusPending
+	if dead {
+		status = model.OutboxStatusFailed
+	}
+	if len(lastError) > 1000 {
+		lastError = lastError[:1000]
+	}
+
+	return r.db.WithContext(ctx).
+		Model(&model.OutboxEventModel{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"status":          status,
+			"attempts":        attempts,
+			"next_attempt_at": nextAttemptAt,
+			"last_error":      lastError,
+		}).Error
+}
+
+// LatestID 返回最新一条事件的 id，没有事件时返回 0
+func (r *OutboxRepository) LatestID(ctx context.Context) (uint64, error) {
+	var id uint64
+	if err := r.db.WithContext(ctx).
+		Model(&model.OutboxEventModel{}).
+		Select("COALESCE(MAX(id), 0)").
+		Scan(&id).Error; err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
+// Tail 按 id 顺序读取 afterID 之后、创建时间早于 settledBefore 的事件，不区分投递状态
+func (r *OutboxRepository) Tail(ctx context.Context, afterID uint64, settledBefore time.Time, limit int) ([]*model.OutboxEventModel, error) {
+	var events []*model.OutboxEventModel
+	if err := r.db.WithContext(ctx).
+		Where("id > ? AND created_at < ?", afterID, settledBefore).
+		Order("id ASC").
+		Limit(limit).
+		Find(&events).Error; err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+// appendOutboxEvents 在给定事务中写入 outbox 事件
+func appendOutboxEvents(tx *gorm.DB, events []event.Event) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	models := make([]*model.OutboxEventModel, len(events))
+	for i, e := range events {
+		m, err := model.NewOutboxEventModel(e)
+		if err != nil {
+			return err
+		}
+		models[i] = m
+	}
+	return tx.Create(&models).Error
+}
@@ -0,0 +1,128 @@
+package mysql
+
+import (
+	"context"
+	"errors"
+	"time"
+	"yiwen/go-ddd/internal/domain/entity"
+	"yiwen/go-ddd/internal/domain/repository"
+	"yiwen/go-ddd/internal/infrastructure/persistence/model"
+
+	"gorm.io/gorm"
+)
+
+// PasskeyRepository Mysql 通行密钥仓库实现
+type PasskeyRepository struct {
+	db *gorm.DB
+}
+
+func NewPasskeyRepository(db *gorm.DB) repository.PasskeyRepository {
+	return &PasskeyRepository{db: db}
+}
+
+func (r *PasskeyRepository) Save(ctx context.Context, passkey *entity.Passkey) error {
+	passkeyModel := model.FromPasskey(passkey)
+
+	if err := r.db.WithContext(ctx).Create(passkeyModel).Error; err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return repository.ErrPasskeyExists
+		}
+		return err
+	}
+	passkey.ID = passkeyModel.ID
+	return nil
+}
+
+func (r *PasskeyRepository) FindByCredentialID(ctx context.Context, credentialID []byte) (*entity.Passkey, error) {
+	var passkeyModel model.PasskeyModel
+
+	if err := r.db.WithContext(ctx).Where("credential_id = ?", credentialID).First(&passkeyModel).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, repository.ErrPasskeyNotFound
+		}
+		return nil, err
+	}
+
+	return passkeyModel.ToEntity(), nil
+}
+
+func (r *PasskeyRepository) ListByUser(ctx context.Context, userID uint64) ([]*entity.Passkey, error) {
+	var passkeyModels []model.PasskeyModel
+
+	if err := r.db.WithContext(ctx).Where("user_id = ?", userID).Order("id ASC").Find(&passkeyModels).Error; err != nil {
+		return nil, err
+	}
+
+	passkeys := make([]*entity.Passkey, len(passkeyModels))
+	for i := range passkeyModels {
+		passkeys[i] = passkeyModels[i].ToEntity()
+	}
+	return passkeys, nil
+}
+
+func (r *PasskeyRepository) Delete(ctx context.Context, userID, id uint64) error {
+	result := r.db.WithContext(ctx).Where("user_id = ?", userID).Delete(&model.PasskeyModel{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return repository.ErrPasskeyNotFound
+	}
+	return nil
+}
+
+// UpdateSignCount 通过条件更新保证并发登录时签名计数只被推进一次
+func (r *PasskeyRepository) UpdateSignCount(ctx context.Context, id uint64, previous, signCount uint32) error {
+	result := r.db.WithContext(ctx).
+		Model(&model.PasskeyModel{}).
+		Where("id = ? AND sign_count = ?", id, previous).
+		Updates(map[string]interface{}{
+			"sign_count":   signCount,
+			"last_used_at": time.Now(),
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return repository.ErrPasskeySignCountStale
+	}
+	return nil
+}
+
+// SaveSession 写入进行中的仪式，同时顺带清理已经过期的记录
+func (r *PasskeyRepository) SaveSession(ctx context.Context, session *entity.WebAuthnSession) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("expires_at < ?", time.Now()).Delete(&model.WebAuthnSessionModel{}).Error; err != nil {
+			return err
+		}
+
+		sessionModel := model.FromWebAuthnSession(session)
+		if err := tx.Create(sessionModel).Error; err != nil {
+			return err
+		}
+		session.ID = sessionModel.ID
+		return nil
+	})
+}
+
+// TakeSession 先查询再按主键删除，删除影响行数为 0 说明已被并发的请求取走
+func (r *PasskeyRepository) TakeSession(ctx context.Context, challengeHash string) (*entity.WebAuthnSession, error) {
+	var sessionModel model.WebAuthnSessionModel
+
+	if err := r.db.WithContext(ctx).Where("challenge_hash = ?", challengeHash).First(&sessionModel).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, repository.ErrWebAuthnSessionNotFound
+		}
+		return nil, err
+	}
+
+	result := r.db.WithContext(ctx).Delete(&model.WebAuthnSessionModel{}, sessionModel.ID)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, repository.ErrWebAuthnSessionNotFound
+	}
+
+	return sessionModel.ToEntity(), nil
+}
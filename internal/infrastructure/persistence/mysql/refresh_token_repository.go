@@ -0,0 +1,73 @@
+package mysql
+
+import (
+	"context"
+	"errors"
+	"time"
+	"yiwen/go-ddd/internal/domain/entity"
+	"yiwen/go-ddd/internal/domain/repository"
+	"yiwen/go-ddd/internal/infrastructure/persistence/model"
+
+	"gorm.io/gorm"
+)
+
+// RefreshTokenRepository Mysql 刷新令牌仓库实现
+type RefreshTokenRepository struct {
+	db *gorm.DB
+}
+
+func NewRefreshTokenRepository(db *gorm.DB) repository.RefreshTokenRepository {
+	return &RefreshTokenRepository{db: db}
+}
+
+func (r *RefreshTokenRepository) Save(ctx context.Context, token *entity.RefreshToken) error {
+	tokenModel := model.FromRefreshToken(token)
+
+	if err := r.db.WithContext(ctx).Create(tokenModel).Error; err != nil {
+		return err
+	}
+	token.ID = tokenModel.ID
+	return nil
+}
+
+func (r *RefreshTokenRepository) FindByHash(ctx context.Context, tokenHash string) (*entity.RefreshToken, error) {
+	var tokenModel model.RefreshTokenModel
+
+	if err := r.db.WithContext(ctx).Where("token_hash = ?", tokenHash).First(&tokenModel).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, repository.ErrRefreshTokenNotFound
+		}
+		return nil, err
+	}
+
+	return tokenModel.ToEntity(), nil
+}
+
+// MarkUsed 通过条件更新保证同一个令牌只能被使用一次
+func (r *RefreshTokenRepository) MarkUsed(ctx context.Context, id uint64) error {
+	result := r.db.WithContext(ctx).
+		Model(&model.RefreshTokenModel{}).
+		Where("id = ? AND used_at IS NULL AND revoked_at IS NULL", id).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return repository.ErrRefreshTokenUsed
+	}
+	return nil
+}
+
+func (r *RefreshTokenRepository) RevokeFamily(ctx context.Context, familyID string) error {
+	return r.db.WithContext(ctx).
+		Model(&model.RefreshTokenModel{}).
+		Where("family_id = ? AND revoked_at IS NULL", familyID).
+		Update("revoked_at", time.Now()).Error
+}
+
+func (r *RefreshTokenRepository) RevokeByUser(ctx context.Context, userID uint64) error {
+	return r.db.WithContext(ctx).
+		Model(&model.RefreshTokenModel{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", time.Now()).Error
+}
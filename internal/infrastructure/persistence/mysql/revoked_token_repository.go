@@ -0,0 +1,47 @@
+package mysql
+
+import (
+	"context"
+	"errors"
+	"time"
+	"yiwen/go-ddd/internal/domain/repository"
+	"yiwen/go-ddd/internal/infrastructure/persistence/model"
+
+	"gorm.io/gorm"
+)
+
+// RevokedTokenRepository Mysql 已注销访问令牌仓库实现
+type RevokedTokenRepository struct {
+	db *gorm.DB
+}
+
+func NewRevokedTokenRepository(db *gorm.DB) repository.RevokedTokenRepository {
+	return &RevokedTokenRepository{db: db}
+}
+
+// Revoke 写入撤销记录，同时顺带清理已经过期的记录，过期令牌本身就无法通过校验
+func (r *RevokedTokenRepository) Revoke(ctx context.Context, tokenID string, userID uint64, expiresAt time.Time) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("expires_at < ?", time.Now()).Delete(&model.RevokedTokenModel{}).Error; err != nil {
+			return err
+		}
+
+		err := tx.Create(&model.RevokedTokenModel{
+			TokenID:   tokenID,
+			UserID:    userID,
+			ExpiresAt: expiresAt,
+		}).Error
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return nil
+		}
+		return err
+	})
+}
+
+func (r *RevokedTokenRepository) IsRevoked(ctx context.Context, tokenID string) (bool, error) {
+	var count int64
+	if err := r.db.WithContext(ctx).Model(&model.RevokedTokenModel{}).Where("token_id = ?", tokenID).Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
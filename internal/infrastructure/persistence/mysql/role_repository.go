@@ -0,0 +1,77 @@
+package mysql
+
+import (
+	"context"
+	"errors"
+	"yiwen/go-ddd/internal/domain/entity"
+	"yiwen/go-ddd/internal/domain/repository"
+	"yiwen/go-ddd/internal/infrastructure/persistence/model"
+
+	"gorm.io/gorm"
+)
+
+// RoleRepository Mysql 角色仓库实现
+type RoleRepository struct {
+	db *gorm.DB
+}
+
+func NewRoleRepository(db *gorm.DB) repository.RoleRepository {
+	return &RoleRepository{db: db}
+}
+
+func (r *RoleRepository) Save(ctx context.Context, role *entity.Role) error {
+	roleModel := model.FromRole(role)
+
+	if role.ID == 0 {
+		if err := r.db.WithContext(ctx).Create(roleModel).Error; err != nil {
+			return err
+		}
+		role.ID = roleModel.ID
+		return nil
+	}
+	return r.db.WithContext(ctx).Save(roleModel).Error
+}
+
+func (r *RoleRepository) FindByID(ctx context.Context, id uint64) (*entity.Role, error) {
+	var roleModel model.RoleModel
+
+	if err := r.db.WithContext(ctx).First(&roleModel, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, repository.ErrRoleNotFound
+		}
+		return nil, err
+	}
+
+	return roleModel.ToEntity(), nil
+}
+
+func (r *RoleRepository) FindByName(ctx context.Context, name string) (*entity.Role, error) {
+	var roleModel model.RoleModel
+
+	if err := r.db.WithContext(ctx).Where("name = ?", name).First(&roleModel).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, repository.ErrRoleNotFound
+		}
+		return nil, err
+	}
+
+	return roleModel.ToEntity(), nil
+}
+
+func (r *RoleRepository) List(ctx context.Context) ([]*entity.Role, error) {
+	var roleModels []model.RoleModel
+
+	if err := r.db.WithContext(ctx).Order("id ASC").Find(&roleModels).Error; err != nil {
+		return nil, err
+	}
+
+	roles := make([]*entity.Role, len(roleModels))
+	for i := range roleModels {
+		roles[i] = roleModels[i].ToEntity()
+	}
+	return roles, nil
+}
+
+func (r *RoleRepository) Delete(ctx context.Context, id uint64) error {
+	return r.db.WithContext(ctx).Delete(&model.RoleModel{}, id).Error
+}
@@ -0,0 +1,124 @@
+package mysql
+
+import (
+	"context"
+	"errors"
+	"time"
+	"yiwen/go-ddd/internal/application/saga"
+	"yiwen/go-ddd/internal/infrastructure/persistence/model"
+
+	"gorm.io/gorm"
+)
+
+// SagaStore 流程实例存储
+type SagaStore struct {
+	db *gorm.DB
+}
+
+func NewSagaStore(db *gorm.DB) *SagaStore {
+	return &SagaStore{db: db}
+}
+
+var _ saga.Store = (*SagaStore)(nil)
+
+func (s *SagaStore) Find(ctx context.Context, sagaName, correlationID string) (*saga.Instance, error) {
+	var record model.SagaInstanceModel
+	if err := s.db.WithContext(ctx).
+		Where("saga_name = ? AND correlation_id = ?", sagaName, correlationID).
+		First(&record).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return record.ToInstance()
+}
+
+// Save 新实例直接插入，已有实例按版本号条件更新
+func (s *SagaStore) Save(ctx context.Context, instance *saga.Instance, eventID string) error {
+	record, err := model.FromSagaInstance(instance)
+	if err != nil {
+		return err
+	}
+
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if eventID != "" {
+			processed := &model.SagaProcessedEventModel{SagaName: instance.SagaName, EventID: eventID}
+			if err := tx.Create(processed).Error; err != nil {
+				if errors.Is(err, gorm.ErrDuplicatedKey) {
+					return saga.ErrEventProcessed
+				}
+				return err
+			}
+		}
+
+		if instance.ID == 0 {
+			record.Version = 1
+			if err := tx.Create(record).Error; err != nil {
+				if errors.Is(err, gorm.ErrDuplicatedKey) {
+					return saga.ErrConcurrencyConflict
+				}
+				return err
+			}
+			return nil
+		}
+
+		result := tx.Model(&model.SagaInstanceModel{}).
+			Where("id = ? AND version = ?", instance.ID, instance.Version).
+			Updates(map[string]interface{}{
+				"state":       record.State,
+				"data":        record.Data,
+				"deadline_at": record.DeadlineAt,
+				"completed":   record.Completed,
+				"version":     instance.Version + 1,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return saga.ErrConcurrencyConflict
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if instance.ID == 0 {
+		instance.ID = record.ID
+	}
+	instance.Version++
+	return nil
+}
+
+func (s *SagaStore) IsProcessed(ctx context.Context, sagaName, eventID string) (bool, error) {
+	var count int64
+	if err := s.db.WithContext(ctx).
+		Model(&model.SagaProcessedEventModel{}).
+		Where("saga_name = ? AND event_id = ?", sagaName, eventID).
+		Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func (s *SagaStore) FindDue(ctx context.Context, now time.Time, limit int) ([]*saga.Instance, error) {
+	var records []model.SagaInstanceModel
+	if err := s.db.WithContext(ctx).
+		Where("completed = ? AND deadline_at <= ?", false, now).
+		Order("deadline_at ASC").
+		Limit(limit).
+		Find(&records).Error; err != nil {
+		return nil, err
+	}
+
+	instances := make([]*saga.Instance, 0, len(records))
+	for i := range records {
+		instance, err := records[i].ToInstance()
+		if err != nil {
+			return nil, err
+		}
+		instances = append(instances, instance)
+	}
+	return instances, nil
+}
@@ -0,0 +1,106 @@
+package mysql
+
+import (
+	"context"
+	"errors"
+	"time"
+	"yiwen/go-ddd/internal/domain/entity"
+	"yiwen/go-ddd/internal/domain/repository"
+	"yiwen/go-ddd/internal/infrastructure/persistence/model"
+
+	"gorm.io/gorm"
+)
+
+// SessionRepository Mysql 登录会话仓库实现
+type SessionRepository struct {
+	db *gorm.DB
+}
+
+func NewSessionRepository(db *gorm.DB) repository.SessionRepository {
+	return &SessionRepository{db: db}
+}
+
+// Save 写入新会话，同时顺带清理该用户已经过期的会话
+func (r *SessionRepository) Save(ctx context.Context, session *entity.Session) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ? AND expires_at < ?", session.UserID, time.Now()).Delete(&model.SessionModel{}).Error; err != nil {
+			return err
+		}
+
+		sessionModel := model.FromSession(session)
+		if err := tx.Create(sessionModel).Error; err != nil {
+			return err
+		}
+		session.ID = sessionModel.ID
+		return nil
+	})
+}
+
+func (r *SessionRepository) FindByID(ctx context.Context, id uint64) (*entity.Session, error) {
+	var sessionModel model.SessionModel
+
+	if err := r.db.WithContext(ctx).First(&sessionModel, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, repository.ErrSessionNotFound
+		}
+		return nil, err
+	}
+
+	return sessionModel.ToEntity(), nil
+}
+
+func (r *SessionRepository) FindBySessionID(ctx context.Context, sessionID string) (*entity.Session, error) {
+	var sessionModel model.SessionModel
+
+	if err := r.db.WithContext(ctx).Where("session_id = ?", sessionID).First(&sessionModel).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, repository.ErrSessionNotFound
+		}
+		return nil, err
+	}
+
+	return sessionModel.ToEntity(), nil
+}
+
+func (r *SessionRepository) ListByUser(ctx context.Context, userID uint64) ([]*entity.Session, error) {
+	var sessionModels []model.SessionModel
+
+	if err := r.db.WithContext(ctx).
+		Where("user_id = ? AND expires_at > ?", userID, time.Now()).
+		Order("last_seen_at DESC").
+		Find(&sessionModels).Error; err != nil {
+		return nil, err
+	}
+
+	sessions := make([]*entity.Session, len(sessionModels))
+	for i := range sessionModels {
+		sessions[i] = sessionModels[i].ToEntity()
+	}
+	return sessions, nil
+}
+
+func (r *SessionRepository) UpdateActivity(ctx context.Context, id uint64, ip string, lastSeenAt, expiresAt time.Time) error {
+	return r.db.WithContext(ctx).
+		Model(&model.SessionModel{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"ip":           ip,
+			"last_seen_at": lastSeenAt,
+			"expires_at":   expiresAt,
+		}).Error
+}
+
+func (r *SessionRepository) Touch(ctx context.Context, id uint64, lastSeenAt time.Time) error {
+	return r.db.WithContext(ctx).
+		Model(&model.SessionModel{}).
+		Where("id = ?", id).
+		Update("last_seen_at", lastSeenAt).Error
+}
+
+func (r *SessionRepository) Delete(ctx context.Context, id uint64) error {
+	return r.db.WithContext(ctx).Delete(&model.SessionModel{}, id).Error
+}
+
+func (r *SessionRepository) DeleteByUser(ctx context.Context, userID uint64) error {
+	return r.db.WithContext(ctx).Where("user_id = ?", userID).Delete(&model.SessionModel{}).Error
+}
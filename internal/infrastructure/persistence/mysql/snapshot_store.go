@@ -0,0 +1,79 @@
+package mysql
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"yiwen/go-ddd/internal/domain/aggregate"
+	"yiwen/go-ddd/internal/infrastructure/persistence/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// SnapshotStore 用户聚合快照存储
+type SnapshotStore struct {
+	db *gorm.DB
+}
+
+func NewSnapshotStore(db *gorm.DB) *SnapshotStore {
+	return &SnapshotStore{db: db}
+}
+
+// Load 读取聚合最新的快照，不存在或结构版本不兼容时返回 nil
+func (s *SnapshotStore) Load(ctx context.Context, aggregateID string) (*aggregate.UserSnapshot, error) {
+	var record model.AggregateSnapshotModel
+	if err := s.db.WithContext(ctx).Where("aggregate_id = ?", aggregateID).First(&record).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	// 聚合结构已变化，旧快照作废，由调用方重放全部事件
+	if record.SchemaVersion != aggregate.UserSnapshotSchemaVersion {
+		return nil, nil
+	}
+
+	var snapshot aggregate.UserSnapshot
+	if err := json.Unmarshal([]byte(record.State), &snapshot); err != nil {
+		return nil, err
+	}
+	return &snapshot, nil
+}
+
+// Save 保存快照，覆盖该聚合已有的版本不高于它的快照
+// 并发保存时较旧的快照不能覆盖较新的快照，相当于带有 WHERE version <= ? 条件的 upsert
+// 同一版本的快照由相同的事件生成，允许覆盖，重建快照时可以写入新的结构版本
+// MySQL 的 ON DUPLICATE KEY UPDATE 不支持 WHERE，通过 IF 判断版本，version 必须最后赋值
+func (s *SnapshotStore) Save(ctx context.Context, snapshot *aggregate.UserSnapshot) error {
+	state, err := json.Marshal(snapshot)
+	if err != nil {
+		return err
+	}
+
+	record := &model.AggregateSnapshotModel{
+		AggregateID:   snapshot.UUID,
+		AggregateType: aggregate.UserAggregateType,
+		Version:       snapshot.Version,
+		SchemaVersion: snapshot.SchemaVersion,
+		State:         string(state),
+	}
+
+	updates := make([]clause.Assignment, 0, 4)
+	for _, column := range []string{"schema_version", "state", "updated_at"} {
+		updates = append(updates, clause.Assignment{
+			Column: clause.Column{Name: column},
+			Value:  gorm.Expr("IF(VALUES(version) >= version, VALUES(" + column + "), " + column + ")"),
+		})
+	}
+	updates = append(updates, clause.Assignment{
+		Column: clause.Column{Name: "version"},
+		Value:  gorm.Expr("GREATEST(version, VALUES(version))"),
+	})
+
+	return s.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "aggregate_id"}},
+		DoUpdates: clause.Set(updates),
+	}).Create(record).Error
+}
@@ -0,0 +1,158 @@
+package mysql
+
+import (
+	"context"
+	"errors"
+	"time"
+	"yiwen/go-ddd/internal/domain/entity"
+	"yiwen/go-ddd/internal/domain/repository"
+	"yiwen/go-ddd/internal/infrastructure/persistence/model"
+
+	"gorm.io/gorm"
+)
+
+// TwoFactorRepository Mysql 两步验证仓库实现
+type TwoFactorRepository struct {
+	db *gorm.DB
+}
+
+func NewTwoFactorRepository(db *gorm.DB) repository.TwoFactorRepository {
+	return &TwoFactorRepository{db: db}
+}
+
+func (r *TwoFactorRepository) FindByUserID(ctx context.Context, userID uint64) (*entity.TwoFactor, error) {
+	var twoFactorModel model.TwoFactorModel
+
+	if err := r.db.WithContext(ctx).Where("user_id = ?", userID).First(&twoFactorModel).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, repository.ErrTwoFactorNotFound
+		}
+		return nil, err
+	}
+
+	return twoFactorModel.ToEntity(), nil
+}
+
+func (r *TwoFactorRepository) Save(ctx context.Context, twoFactor *entity.TwoFactor) error {
+	twoFactorModel := model.FromTwoFactor(twoFactor)
+
+	if twoFactor.ID == 0 {
+		if err := r.db.WithContext(ctx).Create(twoFactorModel).Error; err != nil {
+			return err
+		}
+		twoFactor.ID = twoFactorModel.ID
+		return nil
+	}
+	return r.db.WithContext(ctx).Save(twoFactorModel).Error
+}
+
+func (r *TwoFactorRepository) Delete(ctx context.Context, userID uint64) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&model.TwoFactorModel{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", userID).Delete(&model.RecoveryCodeModel{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("user_id = ?", userID).Delete(&model.TwoFactorChallengeModel{}).Error
+	})
+}
+
+// MarkStepUsed 通过条件更新保证同一个时间步的验证码只能使用一次
+func (r *TwoFactorRepository) MarkStepUsed(ctx context.Context, userID uint64, step int64) error {
+	result := r.db.WithContext(ctx).
+		Model(&model.TwoFactorModel{}).
+		Where("user_id = ? AND last_used_step < ?", userID, step).
+		Update("last_used_step", step)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return repository.ErrTwoFactorStepUsed
+	}
+	return nil
+}
+
+func (r *TwoFactorRepository) ReplaceRecoveryCodes(ctx context.Context, userID uint64, codeHashes []string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&model.RecoveryCodeModel{}).Error; err != nil {
+			return err
+		}
+
+		codes := make([]model.RecoveryCodeModel, len(codeHashes))
+		for i, hash := range codeHashes {
+			codes[i] = model.RecoveryCodeModel{UserID: userID, CodeHash: hash}
+		}
+		return tx.Create(&codes).Error
+	})
+}
+
+// UseRecoveryCode 通过条件更新保证恢复码只能使用一次
+func (r *TwoFactorRepository) UseRecoveryCode(ctx context.Context, userID uint64, codeHash string) error {
+	result := r.db.WithContext(ctx).
+		Model(&model.RecoveryCodeModel{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, codeHash).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return repository.ErrRecoveryCodeNotFound
+	}
+	return nil
+}
+
+func (r *TwoFactorRepository) CountRecoveryCodes(ctx context.Context, userID uint64) (int64, error) {
+	var count int64
+	if err := r.db.WithContext(ctx).Model(&model.RecoveryCodeModel{}).Where("user_id = ? AND used_at IS NULL", userID).Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// SaveChallenge 写入等待中的登录，同时顺带清理已经过期的记录
+func (r *TwoFactorRepository) SaveChallenge(ctx context.Context, challenge *entity.TwoFactorChallenge) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("expires_at < ?", time.Now()).Delete(&model.TwoFactorChallengeModel{}).Error; err != nil {
+			return err
+		}
+
+		challengeModel := model.FromTwoFactorChallenge(challenge)
+		if err := tx.Create(challengeModel).Error; err != nil {
+			return err
+		}
+		challenge.ID = challengeModel.ID
+		return nil
+	})
+}
+
+func (r *TwoFactorRepository) FindChallengeByHash(ctx context.Context, tokenHash string) (*entity.TwoFactorChallenge, error) {
+	var challengeModel model.TwoFactorChallengeModel
+
+	if err := r.db.WithContext(ctx).Where("token_hash = ?", tokenHash).First(&challengeModel).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, repository.ErrTwoFactorChallengeNotFound
+		}
+		return nil, err
+	}
+
+	return challengeModel.ToEntity(), nil
+}
+
+func (r *TwoFactorRepository) IncrementChallengeAttempts(ctx context.Context, id uint64) error {
+	return r.db.WithContext(ctx).
+		Model(&model.TwoFactorChallengeModel{}).
+		Where("id = ?", id).
+		Update("attempts", gorm.Expr("attempts + 1")).Error
+}
+
+func (r *TwoFactorRepository) DeleteChallenge(ctx context.Context, id uint64) error {
+	result := r.db.WithContext(ctx).Delete(&model.TwoFactorChallengeModel{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return repository.ErrTwoFactorChallengeNotFound
+	}
+	return nil
+}
@@ -0,0 +1,38 @@
+package mysql
+
+import (
+	"context"
+	"yiwen/go-ddd/internal/domain/aggregate"
+	"yiwen/go-ddd/internal/domain/repository"
+
+	"gorm.io/gorm"
+)
+
+// UserAggregateRepository 基于状态存储的用户聚合仓库
+// 用户数据保存在 users 表，领域事件在同一事务中写入 outbox_events 表
+type UserAggregateRepository struct {
+	db *gorm.DB
+}
+
+func NewUserAggregateRepository(db *gorm.DB) repository.UserAggregateRepository {
+	return &UserAggregateRepository{db: db}
+}
+
+func (r *UserAggregateRepository) Load(ctx context.Context, id uint64) (*aggregate.UserAggregate, error) {
+	user, err := (&UserRepository{db: r.db}).FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return aggregate.NewUserAggregate(user), nil
+}
+
+// Save 在一个事务中保存用户和未提交的领域事件
+// 任意一步失败都会回滚，避免出现数据已保存但事件丢失的情况
+func (r *UserAggregateRepository) Save(ctx context.Context, agg *aggregate.UserAggregate) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := (&UserRepository{db: tx}).Save(ctx, agg.User); err != nil {
+			return err
+		}
+		return appendOutboxEvents(tx, agg.GetEvents())
+	})
+}
@@ -17,9 +17,17 @@ type UserRepository struct {
 	db *gorm.DB
 }
 
-// FindByUsername implements [repository.UserRepository].
 func (r *UserRepository) FindByUsername(ctx context.Context, username string) (*entity.User, error) {
-	panic("unimplemented")
+	var userModel model.UserModel
+
+	if err := r.db.WithContext(ctx).Where("username = ?", username).First(&userModel).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, repository.ErrUserNotFound
+		}
+		return nil, err
+	}
+
+	return userModel.ToEnitity(), nil
 }
 
 func NewUserRepository(db *gorm.DB) repository.UserRepository {
@@ -58,7 +66,7 @@ func (r *UserRepository) FindByID(ctx context.Context, id uint64) (*entity.User,
 
 	if err := r.db.WithContext(ctx).First(&userModel, id).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("user not found")
+			return nil, repository.ErrUserNotFound
 		}
 		return nil, err
 	}
@@ -71,7 +79,7 @@ func (r *UserRepository) FindByUUID(ctx context.Context, uuid string) (*entity.U
 
 	if err := r.db.WithContext(ctx).Where("uuid = ?", uuid).First(&userModel).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("user not found")
+			return nil, repository.ErrUserNotFound
 		}
 		return nil, err
 	}
@@ -84,7 +92,7 @@ func (r *UserRepository) FindByEmail(ctx context.Context, email string) (*entity
 
 	if err := r.db.WithContext(ctx).Where("email = ?", email).First(&userModel).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("user not found")
+			return nil, repository.ErrUserNotFound
 		}
 		return nil, err
 	}
@@ -143,3 +151,15 @@ func (r *UserRepository) ExistsByEmail(ctx context.Context, email string) (bool,
 	}
 	return count > 0, nil
 }
+
+// ExistsByRole 检查是否有用户拥有指定角色，角色以逗号分隔保存在 role 列
+func (r *UserRepository) ExistsByRole(ctx context.Context, role string) (bool, error) {
+	var count int64
+	if err := r.db.WithContext(ctx).
+		Model(&model.UserModel{}).
+		Where("FIND_IN_SET(?, role) > 0", role).
+		Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
@@ -0,0 +1,169 @@
+package mysql
+
+import (
+	"context"
+	"errors"
+	"time"
+	"yiwen/go-ddd/internal/domain/entity"
+	"yiwen/go-ddd/internal/domain/repository"
+	"yiwen/go-ddd/internal/infrastructure/persistence/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// WebhookRepository Mysql webhook 仓库实现
+type WebhookRepository struct {
+	db *gorm.DB
+}
+
+func NewWebhookRepository(db *gorm.DB) repository.WebhookRepository {
+	return &WebhookRepository{db: db}
+}
+
+func (r *WebhookRepository) SaveEndpoint(ctx context.Context, endpoint *entity.WebhookEndpoint) error {
+	endpointModel := model.FromWebhookEndpoint(endpoint)
+
+	if endpoint.ID == 0 {
+		if err := r.db.WithContext(ctx).Create(endpointModel).Error; err != nil {
+			return err
+		}
+		endpoint.ID = endpointModel.ID
+		return nil
+	}
+	return r.db.WithContext(ctx).Save(endpointModel).Error
+}
+
+func (r *WebhookRepository) FindEndpointByID(ctx context.Context, id uint64) (*entity.WebhookEndpoint, error) {
+	var endpointModel model.WebhookEndpointModel
+
+	if err := r.db.WithContext(ctx).First(&endpointModel, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, repository.ErrWebhookEndpointNotFound
+		}
+		return nil, err
+	}
+
+	return endpointModel.ToEntity(), nil
+}
+
+func (r *WebhookRepository) ListEndpoints(ctx context.Context) ([]*entity.WebhookEndpoint, error) {
+	var endpointModels []model.WebhookEndpointModel
+
+	if err := r.db.WithContext(ctx).Order("id ASC").Find(&endpointModels).Error; err != nil {
+		return nil, err
+	}
+
+	endpoints := make([]*entity.WebhookEndpoint, len(endpointModels))
+	for i := range endpointModels {
+		endpoints[i] = endpointModels[i].ToEntity()
+	}
+	return endpoints, nil
+}
+
+func (r *WebhookRepository) DeleteEndpoint(ctx context.Context, id uint64) error {
+	return r.db.WithContext(ctx).Delete(&model.WebhookEndpointModel{}, id).Error
+}
+
+func (r *WebhookRepository) SaveDelivery(ctx context.Context, delivery *entity.WebhookDelivery) error {
+	deliveryModel := model.FromWebhookDelivery(delivery)
+
+	if delivery.ID == 0 {
+		if err := r.db.WithContext(ctx).Create(deliveryModel).Error; err != nil {
+			return err
+		}
+		delivery.ID = deliveryModel.ID
+		return nil
+	}
+	return r.db.WithContext(ctx).Save(deliveryModel).Error
+}
+
+func (r *WebhookRepository) EnqueueDelivery(ctx context.Context, delivery *entity.WebhookDelivery) error {
+	deliveryModel := model.FromWebhookDelivery(delivery)
+
+	// 依赖 (endpoint_id, event_id) 唯一索引，已存在时不插入
+	if err := r.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(deliveryModel).Error; err != nil {
+		return err
+	}
+	delivery.ID = deliveryModel.ID
+	return nil
+}
+
+func (r *WebhookRepository) FindDeliveryByID(ctx context.Context, id uint64) (*entity.WebhookDelivery, error) {
+	var deliveryModel model.WebhookDeliveryModel
+
+	if err := r.db.WithContext(ctx).First(&deliveryModel, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, repository.ErrWebhookDeliveryNotFound
+		}
+		return nil, err
+	}
+
+	return deliveryModel.ToEntity(), nil
+}
+
+func (r *WebhookRepository) ListDeliveries(ctx context.Context, filter repository.WebhookDeliveryFilter, offset, limit int) ([]*entity.WebhookDelivery, int64, error) {
+	query := r.db.WithContext(ctx).Model(&model.WebhookDeliveryModel{})
+	if filter.EndpointID != 0 {
+		query = query.Where("endpoint_id = ?", filter.EndpointID)
+	}
+	if filter.EventType != "" {
+		query = query.Where("event_type = ?", filter.EventType)
+	}
+	if filter.Status != 0 {
+		query = query.Where("status = ?", int(filter.Status))
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var deliveryModels []model.WebhookDeliveryModel
+	if err := query.Order("id DESC").Offset(offset).Limit(limit).Find(&deliveryModels).Error; err != nil {
+		return nil, 0, err
+	}
+
+	deliveries := make([]*entity.WebhookDelivery, len(deliveryModels))
+	for i := range deliveryModels {
+		deliveries[i] = deliveryModels[i].ToEntity()
+	}
+	return deliveries, total, nil
+}
+
+// ClaimDueDeliveries 与 outbox 的 FetchPending 相同，使用 FOR UPDATE SKIP LOCKED 读取，
+// 并在同一事务中把 next_attempt_at 推迟 lease，多个实例同时投递时不会发送同一条记录
+func (r *WebhookRepository) ClaimDueDeliveries(ctx context.Context, limit int, lease time.Duration) ([]*entity.WebhookDelivery, error) {
+	var deliveryModels []model.WebhookDeliveryModel
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		if err := tx.
+			Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND next_attempt_at <= ?", int(entity.WebhookDeliveryPending), now).
+			Order("id ASC").
+			Limit(limit).
+			Find(&deliveryModels).Error; err != nil {
+			return err
+		}
+		if len(deliveryModels) == 0 {
+			return nil
+		}
+
+		ids := make([]uint64, len(deliveryModels))
+		for i := range deliveryModels {
+			ids[i] = deliveryModels[i].ID
+		}
+		return tx.Model(&model.WebhookDeliveryModel{}).
+			Where("id IN ?", ids).
+			Update("next_attempt_at", now.Add(lease)).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	deliveries := make([]*entity.WebhookDelivery, len(deliveryModels))
+	for i := range deliveryModels {
+		deliveries[i] = deliveryModels[i].ToEntity()
+	}
+	return deliveries, nil
+}
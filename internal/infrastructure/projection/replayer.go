@@ -0,0 +1,155 @@
+package projection
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"yiwen/go-ddd/internal/domain/event"
+	"yiwen/go-ddd/internal/infrastructure/persistence/model"
+)
+
+// Projection 由领域事件生成、可以重建的读模型
+type Projection interface {
+	event.EventHandler
+
+	// Name 投影名称，用于选择要重建的投影和记录检查点
+	Name() string
+
+	// Reset 清空投影的全部数据
+	Reset(ctx context.Context) error
+}
+
+// ErrIncompleteHistory 事件存储没有包含全部历史，清空投影后无法完整重建
+var ErrIncompleteHistory = errors.New("event store does not cover the full history")
+
+// EventSource 按写入顺序读取事件存储中的全部事件，position 为事件的全局顺序号
+type EventSource interface {
+	FetchAfter(ctx context.Context, position uint64, limit int) ([]*model.EventStoreModel, error)
+	CountAfter(ctx context.Context, position uint64) (int64, error)
+
+	// CountMissing 统计不在事件存储中的历史，包括没有事件流的聚合和事件流建立之前产生的事件
+	CountMissing(ctx context.Context) (int64, error)
+}
+
+// CheckpointStore 记录每个投影已重放到的位置
+type CheckpointStore interface {
+	Load(ctx context.Context, name string) (uint64, error)
+	Save(ctx context.Context, name string, position uint64) error
+}
+
+// Options 重放选项
+type Options struct {
+	Resume bool // 从上次的检查点继续，不清空投影
+	DryRun bool // 只统计需要重放的事件，不修改任何数据
+}
+
+// Progress 重放进度
+type Progress struct {
+	Replayed int64  // 已重放的事件数
+	Total    int64  // 开始时需要重放的事件总数，重放期间新写入的事件也会被处理
+	Position uint64 // 当前位置
+}
+
+// Result 重放结果
+type Result struct {
+	Progress
+	ByType  map[string]int64 // 按事件类型统计的数量
+	Missing int64            // 不在事件存储中的历史，不为 0 时不能清空重建
+}
+
+// Replayer 投影重建器
+// 1. 清空投影后从第一个事件开始，按全局顺序把事件存储中的事件交给投影处理
+// 2. 事件存储没有包含全部历史时拒绝清空投影，避免丢失无法重放的数据
+// 3. 每处理完一批保存检查点，中断后可以从检查点继续
+// 4. 重建期间线上的事件仍会投递给投影，投影需要保证幂等
+type Replayer struct {
+	source      EventSource
+	checkpoints CheckpointStore
+	batchSize   int
+	onProgress  func(Progress)
+}
+
+// NewReplayer 创建投影重建器，onProgress 在每批事件处理完后调用，可以为 nil
+func NewReplayer(source EventSource, checkpoints CheckpointStore, batchSize int, onProgress func(Progress)) *Replayer {
+	return &Replayer{
+		source:      source,
+		checkpoints: checkpoints,
+		batchSize:   batchSize,
+		onProgress:  onProgress,
+	}
+}
+
+// Replay 重建投影
+func (r *Replayer) Replay(ctx context.Context, p Projection, opts Options) (*Result, error) {
+	result := &Result{ByType: make(map[string]int64)}
+
+	if opts.Resume {
+		position, err := r.checkpoints.Load(ctx, p.Name())
+		if err != nil {
+			return nil, err
+		}
+		result.Position = position
+	}
+
+	total, err := r.source.CountAfter(ctx, result.Position)
+	if err != nil {
+		return nil, err
+	}
+	result.Total = total
+
+	if !opts.Resume {
+		missing, err := r.source.CountMissing(ctx)
+		if err != nil {
+			return nil, err
+		}
+		result.Missing = missing
+		if missing > 0 && !opts.DryRun {
+			return nil, fmt.Errorf("%w: %d aggregates or events are missing", ErrIncompleteHistory, missing)
+		}
+	}
+
+	if !opts.Resume && !opts.DryRun {
+		if err := p.Reset(ctx); err != nil {
+			return nil, fmt.Errorf("failed to reset projection %s: %w", p.Name(), err)
+		}
+		if err := r.checkpoints.Save(ctx, p.Name(), 0); err != nil {
+			return nil, err
+		}
+	}
+
+	for {
+		records, err := r.source.FetchAfter(ctx, result.Position, r.batchSize)
+		if err != nil {
+			return result, err
+		}
+		if len(records) == 0 {
+			return result, nil
+		}
+
+		for _, record := range records {
+			e, err := record.ToEvent()
+			if err != nil {
+				return result, fmt.Errorf("failed to decode event at position %d: %w", record.ID, err)
+			}
+			if !opts.DryRun {
+				if err := p.Handle(e); err != nil {
+					return result, fmt.Errorf("failed to replay event at position %d: %w", record.ID, err)
+				}
+			}
+			result.ByType[e.EventName()]++
+			result.Replayed++
+		}
+
+		// 检查点以批为单位保存，中断后最多重复处理一批，由投影的幂等保证正确
+		result.Position = records[len(records)-1].ID
+		if !opts.DryRun {
+			if err := r.checkpoints.Save(ctx, p.Name(), result.Position); err != nil {
+				return result, err
+			}
+		}
+
+		if r.onProgress != nil {
+			r.onProgress(result.Progress)
+		}
+	}
+}
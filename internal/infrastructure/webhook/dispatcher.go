@@ -0,0 +1,49 @@
+package webhook
+
+import (
+	"context"
+	"yiwen/go-ddd/internal/domain/entity"
+	"yiwen/go-ddd/internal/domain/event"
+	"yiwen/go-ddd/internal/domain/repository"
+)
+
+// Dispatcher 把领域事件转换为 webhook 投递记录
+// 作为事件处理器订阅事件总线，只负责落库，实际发送由 Worker 完成，
+// 这样发送失败不会阻塞事件总线，并且每次投递都有日志可查。
+// outbox 至少投递一次，同一事件重复到达时每个端点只保留一条投递记录
+type Dispatcher struct {
+	webhookRepo repository.WebhookRepository
+}
+
+func NewDispatcher(webhookRepo repository.WebhookRepository) *Dispatcher {
+	return &Dispatcher{webhookRepo: webhookRepo}
+}
+
+// Handle 实现 event.EventHandler
+func (d *Dispatcher) Handle(e event.Event) error {
+	ctx := context.Background()
+
+	endpoints, err := d.webhookRepo.ListEndpoints(ctx)
+	if err != nil {
+		return err
+	}
+
+	var payload []byte
+	for _, endpoint := range endpoints {
+		if !endpoint.Subscribes(e.EventName()) {
+			continue
+		}
+
+		if payload == nil {
+			if payload, err = event.EncodePublic(e); err != nil {
+				return err
+			}
+		}
+
+		delivery := entity.NewWebhookDelivery(endpoint.ID, e.EventID(), e.EventName(), string(payload))
+		if err := d.webhookRepo.EnqueueDelivery(ctx, delivery); err != nil {
+			return err
+		}
+	}
+	return nil
+}
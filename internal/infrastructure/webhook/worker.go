@@ -0,0 +1,150 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+	"yiwen/go-ddd/internal/domain/entity"
+	"yiwen/go-ddd/internal/domain/repository"
+	"yiwen/go-ddd/internal/infrastructure/config"
+	"yiwen/go-ddd/pkg/backoff"
+)
+
+// maxResponseBody 投递日志中保存的响应内容上限
+const maxResponseBody = 1000
+
+// Worker webhook 投递器
+// 定时读取到期的投递记录并发送：
+// 1. 请求体使用 HMAC-SHA256 签名，并携带时间戳请求头
+// 2. 返回 2xx 视为成功，否则按指数退避重试
+// 3. 超过最大次数后进入死信，可通过管理接口重放
+// 4. 多实例部署时记录在租期内只由领取它的实例发送
+// 5. 端点已删除或已停用时不发送，直接进入死信；查询端点失败按普通失败重试
+type Worker struct {
+	webhookRepo  repository.WebhookRepository
+	client       *http.Client
+	pollInterval time.Duration
+	batchSize    int
+	maxAttempts  int
+	baseBackoff  time.Duration
+	maxBackoff   time.Duration
+	lease        time.Duration
+}
+
+// NewWorker 创建 webhook 投递器
+func NewWorker(webhookRepo repository.WebhookRepository, cfg config.WebhookConfig) *Worker {
+	return &Worker{
+		webhookRepo:  webhookRepo,
+		client:       &http.Client{Timeout: time.Duration(cfg.TimeoutSecond) * time.Second},
+		pollInterval: time.Duration(cfg.PollIntervalSecond) * time.Second,
+		batchSize:    cfg.BatchSize,
+		maxAttempts:  cfg.MaxAttempts,
+		baseBackoff:  time.Duration(cfg.BaseBackoffSecond) * time.Second,
+		maxBackoff:   time.Duration(cfg.MaxBackoffSecond) * time.Second,
+		lease:        time.Duration(cfg.LeaseSecond) * time.Second,
+	}
+}
+
+// Run 启动投递循环，直到 ctx 被取消
+func (w *Worker) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := w.ProcessBatch(ctx); err != nil {
+			log.Printf("webhook: process batch failed: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// ProcessBatch 领取并投递一批到期的记录
+func (w *Worker) ProcessBatch(ctx context.Context) error {
+	deliveries, err := w.webhookRepo.ClaimDueDeliveries(ctx, w.batchSize, w.lease)
+	if err != nil {
+		return err
+	}
+
+	for _, delivery := range deliveries {
+		w.deliver(ctx, delivery)
+		if err := w.webhookRepo.SaveDelivery(ctx, delivery); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// deliver 发送一次请求并把结果记录到投递记录上
+func (w *Worker) deliver(ctx context.Context, delivery *entity.WebhookDelivery) {
+	endpoint, err := w.webhookRepo.FindEndpointByID(ctx, delivery.EndpointID)
+	if err != nil {
+		if errors.Is(err, repository.ErrWebhookEndpointNotFound) {
+			// 端点已被删除，没有重试的意义
+			delivery.Cancel(err.Error())
+			return
+		}
+		w.fail(delivery, 0, "", fmt.Errorf("failed to load endpoint: %w", err))
+		return
+	}
+	// 投递记录生成之后端点被停用，重新启用后可以通过管理接口重放
+	if !endpoint.Active {
+		delivery.Cancel("webhook endpoint is inactive")
+		return
+	}
+
+	status, body, err := w.send(ctx, endpoint, delivery)
+	if err == nil && status >= 200 && status < 300 {
+		delivery.MarkSucceeded(status, body)
+		return
+	}
+
+	if err == nil {
+		err = fmt.Errorf("unexpected status code %d", status)
+	}
+	w.fail(delivery, status, body, err)
+}
+
+// fail 记录一次失败，按指数退避安排重试，超过最大次数后进入死信
+func (w *Worker) fail(delivery *entity.WebhookDelivery, status int, body string, err error) {
+	attempts := delivery.Attempts + 1
+	delivery.MarkFailed(status, body, err.Error(),
+		time.Now().Add(backoff.Exponential(w.baseBackoff, w.maxBackoff, attempts)),
+		attempts >= w.maxAttempts)
+
+	log.Printf("webhook: delivery %d to endpoint %d failed, attempt %d: %v", delivery.ID, delivery.EndpointID, attempts, err)
+}
+
+func (w *Worker) send(ctx context.Context, endpoint *entity.WebhookEndpoint, delivery *entity.WebhookDelivery) (int, string, error) {
+	body := []byte(delivery.Payload)
+	timestamp := time.Now().Unix()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderDeliveryID, strconv.FormatUint(delivery.ID, 10))
+	req.Header.Set(HeaderEventType, delivery.EventType)
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(HeaderSignature, Sign(endpoint.Secret, timestamp, body))
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return 0, "", err
+	}
+	defer resp.Body.Close()
+
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, maxResponseBody))
+	return resp.StatusCode, string(respBody), nil
+}
@@ -0,0 +1,275 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+	"yiwen/go-ddd/internal/domain/entity"
+	"yiwen/go-ddd/internal/domain/repository"
+	"yiwen/go-ddd/internal/infrastructure/config"
+)
+
+const testSecret = "whsec_test"
+
+// memoryWebhookRepository 测试用的内存 webhook 仓库
+type memoryWebhookRepository struct {
+	repository.WebhookRepository
+
+	mu          sync.Mutex
+	endpoints   map[uint64]*entity.WebhookEndpoint
+	deliveries  map[uint64]*entity.WebhookDelivery
+	endpointErr error // 不为 nil 时查询端点返回该错误，模拟数据库故障
+}
+
+func newMemoryWebhookRepository() *memoryWebhookRepository {
+	return &memoryWebhookRepository{
+		endpoints:  make(map[uint64]*entity.WebhookEndpoint),
+		deliveries: make(map[uint64]*entity.WebhookDelivery),
+	}
+}
+
+func (r *memoryWebhookRepository) FindEndpointByID(ctx context.Context, id uint64) (*entity.WebhookEndpoint, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.endpointErr != nil {
+		return nil, r.endpointErr
+	}
+	endpoint, ok := r.endpoints[id]
+	if !ok {
+		return nil, repository.ErrWebhookEndpointNotFound
+	}
+	return endpoint, nil
+}
+
+func (r *memoryWebhookRepository) SaveDelivery(ctx context.Context, delivery *entity.WebhookDelivery) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if delivery.ID == 0 {
+		delivery.ID = uint64(len(r.deliveries) + 1)
+	}
+	r.deliveries[delivery.ID] = delivery
+	return nil
+}
+
+func (r *memoryWebhookRepository) ClaimDueDeliveries(ctx context.Context, limit int, lease time.Duration) ([]*entity.WebhookDelivery, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := time.Now()
+	var due []*entity.WebhookDelivery
+	for _, d := range r.deliveries {
+		if d.Status == entity.WebhookDeliveryPending && !d.NextAttemptAt.After(now) && len(due) < limit {
+			d.NextAttemptAt = now.Add(lease)
+			due = append(due, d)
+		}
+	}
+	return due, nil
+}
+
+// receivedRequest 接收方收到的请求
+type receivedRequest struct {
+	header http.Header
+	body   []byte
+}
+
+// newReceiver 启动本地接收方，按顺序返回 statuses 中的状态码，用完后返回最后一个
+func newReceiver(t *testing.T, statuses ...int) (*httptest.Server, *[]receivedRequest) {
+	t.Helper()
+	var (
+		mu       sync.Mutex
+		requests []receivedRequest
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		requests = append(requests, receivedRequest{header: r.Header.Clone(), body: body})
+		status := statuses[min(len(requests), len(statuses))-1]
+		mu.Unlock()
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte("ok"))
+	}))
+	t.Cleanup(server.Close)
+	return server, &requests
+}
+
+func newTestWorker(repo repository.WebhookRepository, maxAttempts int) *Worker {
+	return NewWorker(repo, config.WebhookConfig{
+		PollIntervalSecond: 1,
+		BatchSize:          10,
+		TimeoutSecond:      5,
+		MaxAttempts:        maxAttempts,
+		BaseBackoffSecond:  1,
+		MaxBackoffSecond:   60,
+		LeaseSecond:        60,
+	})
+}
+
+func setup(t *testing.T, maxAttempts int, statuses ...int) (*Worker, *memoryWebhookRepository, *entity.WebhookDelivery, *[]receivedRequest) {
+	t.Helper()
+	server, requests := newReceiver(t, statuses...)
+
+	repo := newMemoryWebhookRepository()
+	endpoint := entity.NewWebhookEndpoint(server.URL, []string{"user.registered"}, testSecret)
+	endpoint.ID = 1
+	repo.endpoints[endpoint.ID] = endpoint
+
+	delivery := entity.NewWebhookDelivery(endpoint.ID, "event-1", "user.registered", `{"user_id":1}`)
+	if err := repo.SaveDelivery(context.Background(), delivery); err != nil {
+		t.Fatal(err)
+	}
+	return newTestWorker(repo, maxAttempts), repo, delivery, requests
+}
+
+func TestWorkerSignsRequest(t *testing.T) {
+	worker, _, delivery, requests := setup(t, 3, http.StatusOK)
+
+	if err := worker.ProcessBatch(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(*requests) != 1 {
+		t.Fatalf("expected 1 request, got %d", len(*requests))
+	}
+	req := (*requests)[0]
+	if got := req.header.Get(HeaderDeliveryID); got != strconv.FormatUint(delivery.ID, 10) {
+		t.Errorf("delivery id header = %q", got)
+	}
+	if got := req.header.Get(HeaderEventType); got != "user.registered" {
+		t.Errorf("event type header = %q", got)
+	}
+	timestamp, err := strconv.ParseInt(req.header.Get(HeaderTimestamp), 10, 64)
+	if err != nil {
+		t.Fatalf("invalid timestamp header: %v", err)
+	}
+	if !Verify(testSecret, timestamp, req.body, req.header.Get(HeaderSignature)) {
+		t.Errorf("signature %q does not verify", req.header.Get(HeaderSignature))
+	}
+	if Verify("wrong-secret", timestamp, req.body, req.header.Get(HeaderSignature)) {
+		t.Error("signature verifies with a wrong secret")
+	}
+
+	if delivery.Status != entity.WebhookDeliverySucceeded || delivery.Attempts != 1 || delivery.DeliveredAt == nil {
+		t.Errorf("delivery = %+v, want succeeded after 1 attempt", delivery)
+	}
+}
+
+func TestWorkerRetriesWithBackoff(t *testing.T) {
+	worker, _, delivery, requests := setup(t, 5, http.StatusInternalServerError, http.StatusInternalServerError, http.StatusOK)
+
+	for attempt, wantBackoff := range []time.Duration{time.Second, 2 * time.Second} {
+		before := time.Now()
+		if err := worker.ProcessBatch(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+		if delivery.Status != entity.WebhookDeliveryPending || delivery.Attempts != attempt+1 {
+			t.Fatalf("attempt %d: delivery = %+v, want pending", attempt+1, delivery)
+		}
+		if delivery.ResponseStatus != http.StatusInternalServerError || delivery.LastError == "" {
+			t.Errorf("attempt %d: response status %d, last error %q", attempt+1, delivery.ResponseStatus, delivery.LastError)
+		}
+		wait := delivery.NextAttemptAt.Sub(before)
+		if wait < wantBackoff || wait > wantBackoff+time.Second {
+			t.Errorf("attempt %d: next attempt in %v, want about %v", attempt+1, wait, wantBackoff)
+		}
+
+		// 未到重试时间时不会再次投递
+		if err := worker.ProcessBatch(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+		if len(*requests) != attempt+1 {
+			t.Fatalf("delivery retried before its backoff elapsed")
+		}
+		delivery.NextAttemptAt = time.Now()
+	}
+
+	if err := worker.ProcessBatch(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if delivery.Status != entity.WebhookDeliverySucceeded || delivery.Attempts != 3 {
+		t.Errorf("delivery = %+v, want succeeded after 3 attempts", delivery)
+	}
+}
+
+func TestWorkerDeadLettersAfterMaxAttempts(t *testing.T) {
+	const maxAttempts = 3
+	worker, repo, delivery, requests := setup(t, maxAttempts, http.StatusServiceUnavailable)
+
+	for i := 0; i < maxAttempts+2; i++ {
+		if err := worker.ProcessBatch(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+		delivery.NextAttemptAt = time.Now()
+	}
+
+	if delivery.Status != entity.WebhookDeliveryDead {
+		t.Fatalf("delivery status = %d, want dead", delivery.Status)
+	}
+	if delivery.Attempts != maxAttempts || len(*requests) != maxAttempts {
+		t.Errorf("attempts = %d, requests = %d, want %d", delivery.Attempts, len(*requests), maxAttempts)
+	}
+
+	due, _ := repo.ClaimDueDeliveries(context.Background(), 10, time.Minute)
+	if len(due) != 0 {
+		t.Errorf("dead delivery is still due")
+	}
+
+	// 重放后重新投递
+	delivery.Replay()
+	if err := worker.ProcessBatch(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if len(*requests) != maxAttempts+1 {
+		t.Errorf("replayed delivery was not sent")
+	}
+}
+
+func TestWorkerDeadLettersDeletedEndpoint(t *testing.T) {
+	worker, repo, delivery, requests := setup(t, 5, http.StatusOK)
+	delete(repo.endpoints, delivery.EndpointID)
+
+	if err := worker.ProcessBatch(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if delivery.Status != entity.WebhookDeliveryDead || len(*requests) != 0 {
+		t.Errorf("delivery = %+v, requests = %d, want dead without sending", delivery, len(*requests))
+	}
+}
+
+func TestWorkerRetriesWhenEndpointLookupFails(t *testing.T) {
+	worker, repo, delivery, requests := setup(t, 5, http.StatusOK)
+	repo.endpointErr = errors.New("connection refused")
+
+	if err := worker.ProcessBatch(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if delivery.Status != entity.WebhookDeliveryPending || delivery.Attempts != 1 || !delivery.NextAttemptAt.After(time.Now()) {
+		t.Fatalf("delivery = %+v, want pending with a scheduled retry", delivery)
+	}
+
+	repo.endpointErr = nil
+	delivery.NextAttemptAt = time.Now()
+	if err := worker.ProcessBatch(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if delivery.Status != entity.WebhookDeliverySucceeded || len(*requests) != 1 {
+		t.Errorf("delivery = %+v, requests = %d, want succeeded on retry", delivery, len(*requests))
+	}
+}
+
+func TestWorkerCancelsDeliveryToInactiveEndpoint(t *testing.T) {
+	worker, repo, delivery, requests := setup(t, 5, http.StatusOK)
+	endpoint := repo.endpoints[delivery.EndpointID]
+	endpoint.Update(endpoint.URL, endpoint.EventTypes, false)
+
+	if err := worker.ProcessBatch(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if delivery.Status != entity.WebhookDeliveryDead || delivery.Attempts != 0 || len(*requests) != 0 {
+		t.Errorf("delivery = %+v, requests = %d, want dead without sending", delivery, len(*requests))
+	}
+}
@@ -0,0 +1,112 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"time"
+	"yiwen/go-ddd/internal/application/command"
+	"yiwen/go-ddd/internal/application/dto"
+	"yiwen/go-ddd/internal/application/service"
+	"yiwen/go-ddd/internal/domain/repository"
+	"yiwen/go-ddd/internal/interfaces/api/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+type APIKeyHandler struct {
+	apiKeyService *service.APIKeyApplicationService
+}
+
+func NewAPIKeyHandler(apiKeyService *service.APIKeyApplicationService) *APIKeyHandler {
+	return &APIKeyHandler{apiKeyService: apiKeyService}
+}
+
+// CreateAPIKey 创建 API 密钥，完整密钥只在响应中出现一次
+// POST /api/v1/users/me/api-keys
+func (h *APIKeyHandler) CreateAPIKey(c *gin.Context) {
+	var req dto.CreateAPIKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "Invalid request",
+		})
+		return
+	}
+
+	userID, _ := middleware.GetUserIDFromContext(c)
+	expiresIn := time.Duration(req.ExpiresInDays) * 24 * time.Hour
+	apiKey, err := h.apiKeyService.Create(middleware.RequestContext(c), command.NewCreateAPIKeyCommand(userID, req.Name, req.Scopes, expiresIn))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"code":    200,
+		"message": "API key created successfully, store it now as it will not be shown again",
+		"data":    apiKey,
+	})
+}
+
+// ListAPIKeys 查询当前用户的 API 密钥
+// GET /api/v1/users/me/api-keys
+func (h *APIKeyHandler) ListAPIKeys(c *gin.Context) {
+	userID, _ := middleware.GetUserIDFromContext(c)
+
+	apiKeys, err := h.apiKeyService.List(c.Request.Context(), userID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "API keys retrieved successfully",
+		"data":    apiKeys,
+	})
+}
+
+// RevokeAPIKey 删除 API 密钥
+// DELETE /api/v1/users/me/api-keys/:id
+func (h *APIKeyHandler) RevokeAPIKey(c *gin.Context) {
+	id, ok := parseIDParam(c, "id", "Invalid API key ID")
+	if !ok {
+		return
+	}
+
+	userID, _ := middleware.GetUserIDFromContext(c)
+	if err := h.apiKeyService.Revoke(c.Request.Context(), command.NewRevokeAPIKeyCommand(userID, id)); err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "API key revoked successfully",
+	})
+}
+
+func (h *APIKeyHandler) handleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, repository.ErrAPIKeyNotFound):
+		c.JSON(http.StatusNotFound, gin.H{
+			"code":    404,
+			"message": err.Error(),
+		})
+	case errors.Is(err, service.ErrUnknownAPIKeyScope):
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": err.Error(),
+		})
+	case errors.Is(err, service.ErrAPIKeyScopeNotAllowed):
+		c.JSON(http.StatusForbidden, gin.H{
+			"code":    403,
+			"message": err.Error(),
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "Internal server error",
+		})
+	}
+}
@@ -0,0 +1,157 @@
+package handler
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"yiwen/go-ddd/internal/application/command"
+	"yiwen/go-ddd/internal/application/dto"
+	"yiwen/go-ddd/internal/application/service"
+	"yiwen/go-ddd/internal/domain/repository"
+	"yiwen/go-ddd/internal/interfaces/api/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AuthHandler struct {
+	authService *service.AuthApplicationService
+}
+
+func NewAuthHandler(authService *service.AuthApplicationService) *AuthHandler {
+	return &AuthHandler{authService: authService}
+}
+
+// Refresh 使用刷新令牌换取新的令牌，旧的刷新令牌随即失效
+// POST /api/v1/auth/refresh
+func (h *AuthHandler) Refresh(c *gin.Context) {
+	var req dto.RefreshTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "Invalid request",
+		})
+		return
+	}
+
+	tokens, err := h.authService.Refresh(middleware.RequestContext(c), command.NewRefreshTokenCommand(req.RefreshToken, ""))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "Token refreshed successfully",
+		"data":    tokens,
+	})
+}
+
+// Logout 注销当前访问令牌，请求体中可选携带刷新令牌一并撤销
+// POST /api/v1/auth/logout
+func (h *AuthHandler) Logout(c *gin.Context) {
+	var req dto.LogoutRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "Invalid request",
+		})
+		return
+	}
+
+	userID, _ := middleware.GetUserIDFromContext(c)
+	tokenID, expiresAt, ok := middleware.GetTokenFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"code":    401,
+			"message": "Unauthorized",
+		})
+		return
+	}
+
+	if err := h.authService.Logout(c.Request.Context(), command.NewLogoutCommand(userID, tokenID, expiresAt, middleware.GetSessionIDFromContext(c), req.RefreshToken)); err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "Logout successfully",
+	})
+}
+
+// ListSessions 查询当前用户登录过的设备
+// GET /api/v1/users/me/sessions
+func (h *AuthHandler) ListSessions(c *gin.Context) {
+	userID, _ := middleware.GetUserIDFromContext(c)
+
+	sessions, err := h.authService.ListSessions(c.Request.Context(), userID, middleware.GetSessionIDFromContext(c))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "Sessions retrieved successfully",
+		"data":    sessions,
+	})
+}
+
+// RevokeSession 结束当前用户的一个会话，该设备需要重新登录
+// DELETE /api/v1/users/me/sessions/:id
+func (h *AuthHandler) RevokeSession(c *gin.Context) {
+	id, ok := parseIDParam(c, "id", "Invalid session ID")
+	if !ok {
+		return
+	}
+
+	userID, _ := middleware.GetUserIDFromContext(c)
+	if err := h.authService.RevokeSession(c.Request.Context(), command.NewRevokeSessionCommand(userID, id)); err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "Session revoked successfully",
+	})
+}
+
+// RevokeUserSessions 管理员结束用户的全部会话
+// DELETE /api/v1/admin/users/:id/sessions
+func (h *AuthHandler) RevokeUserSessions(c *gin.Context) {
+	id, ok := parseIDParam(c, "id", "Invalid user ID")
+	if !ok {
+		return
+	}
+
+	if err := h.authService.RevokeUserSessions(c.Request.Context(), command.NewRevokeUserSessionsCommand(id)); err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "All sessions of the user have been revoked",
+	})
+}
+
+func (h *AuthHandler) handleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrInvalidRefreshToken), errors.Is(err, service.ErrRefreshTokenReused):
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"code":    401,
+			"message": err.Error(),
+		})
+	case errors.Is(err, repository.ErrSessionNotFound):
+		c.JSON(http.StatusNotFound, gin.H{
+			"code":    404,
+			"message": err.Error(),
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "Internal server error",
+		})
+	}
+}
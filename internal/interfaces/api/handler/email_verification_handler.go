@@ -0,0 +1,88 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"yiwen/go-ddd/internal/application/command"
+	"yiwen/go-ddd/internal/application/dto"
+	"yiwen/go-ddd/internal/application/service"
+	"yiwen/go-ddd/internal/interfaces/api/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+type EmailVerificationHandler struct {
+	verificationService *service.EmailVerificationApplicationService
+}
+
+func NewEmailVerificationHandler(verificationService *service.EmailVerificationApplicationService) *EmailVerificationHandler {
+	return &EmailVerificationHandler{verificationService: verificationService}
+}
+
+// VerifyEmail 使用验证邮件中的令牌验证邮箱，等待验证的用户同时被激活
+// POST /api/v1/users/verify-email
+func (h *EmailVerificationHandler) VerifyEmail(c *gin.Context) {
+	var req dto.VerifyEmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "Invalid request",
+		})
+		return
+	}
+
+	user, err := h.verificationService.Verify(middleware.RequestContext(c), command.NewVerifyEmailCommand(req.Token))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "Email verified successfully",
+		"data":    user,
+	})
+}
+
+// ResendVerification 重新发送验证邮件，邮箱不存在或已验证时返回相同的结果
+// POST /api/v1/users/resend-verification
+func (h *EmailVerificationHandler) ResendVerification(c *gin.Context) {
+	var req dto.ResendEmailVerificationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "Invalid request",
+		})
+		return
+	}
+
+	if err := h.verificationService.Resend(c.Request.Context(), command.NewResendEmailVerificationCommand(req.Email)); err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "If the email is registered and not yet verified, a verification email has been sent",
+	})
+}
+
+func (h *EmailVerificationHandler) handleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrInvalidVerificationToken):
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": err.Error(),
+		})
+	case errors.Is(err, service.ErrVerificationEmailThrottled):
+		c.JSON(http.StatusTooManyRequests, gin.H{
+			"code":    429,
+			"message": err.Error(),
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "Internal server error",
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package handler
+
+import (
+	"io"
+	"strings"
+	"time"
+	"yiwen/go-ddd/internal/infrastructure/eventstream"
+
+	"github.com/gin-contrib/sse"
+	"github.com/gin-gonic/gin"
+)
+
+type EventStreamHandler struct {
+	broker    *eventstream.Broker
+	heartbeat time.Duration
+}
+
+func NewEventStreamHandler(broker *eventstream.Broker, heartbeat time.Duration) *EventStreamHandler {
+	return &EventStreamHandler{broker: broker, heartbeat: heartbeat}
+}
+
+// Stream 以 Server-Sent Events 推送领域事件
+// GET /api/v1/admin/events/stream?types=user.registered,user.banned
+// 断线重连时浏览器会自动带上 Last-Event-ID 请求头，也可以通过 last_event_id 参数指定，多实例部署时可以连到任一实例
+// 错过的事件已不在缓冲区中时先收到 stream.reset 事件，客户端需要重新加载数据
+func (h *EventStreamHandler) Stream(c *gin.Context) {
+	var types []string
+	if raw := c.Query("types"); raw != "" {
+		for _, t := range strings.Split(raw, ",") {
+			if t = strings.TrimSpace(t); t != "" {
+				types = append(types, t)
+			}
+		}
+	}
+
+	lastEventID := c.GetHeader("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = c.Query("last_event_id")
+	}
+
+	sub, missed := h.broker.Subscribe(types, lastEventID)
+	defer h.broker.Unsubscribe(sub)
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no") // 关闭 nginx 缓冲
+
+	for _, msg := range missed {
+		renderMessage(c, msg)
+	}
+	c.Writer.Flush()
+
+	ticker := time.NewTicker(h.heartbeat)
+	defer ticker.Stop()
+
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case <-c.Request.Context().Done():
+			return false
+		case msg, ok := <-sub.C:
+			if !ok {
+				// 处理过慢被断开，客户端会带着 Last-Event-ID 重连
+				return false
+			}
+			renderMessage(c, msg)
+			return true
+		case <-ticker.C:
+			// 注释行作为心跳，防止代理因连接空闲而断开
+			_, err := io.WriteString(w, ": heartbeat\n\n")
+			return err == nil
+		}
+	})
+}
+
+func renderMessage(c *gin.Context, msg eventstream.Message) {
+	c.Render(-1, sse.Event{
+		Id:    msg.ID,
+		Event: msg.Type,
+		Data:  string(msg.Data),
+	})
+}
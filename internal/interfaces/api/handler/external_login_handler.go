@@ -0,0 +1,121 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"yiwen/go-ddd/internal/application/command"
+	"yiwen/go-ddd/internal/application/dto"
+	"yiwen/go-ddd/internal/application/identity"
+	"yiwen/go-ddd/internal/application/service"
+	domainservice "yiwen/go-ddd/internal/domain/service"
+	"yiwen/go-ddd/internal/interfaces/api/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ExternalLoginHandler struct {
+	externalLoginService *service.ExternalLoginApplicationService
+	authService          *service.AuthApplicationService
+	twoFactorService     *service.TwoFactorApplicationService
+}
+
+func NewExternalLoginHandler(externalLoginService *service.ExternalLoginApplicationService, authService *service.AuthApplicationService, twoFactorService *service.TwoFactorApplicationService) *ExternalLoginHandler {
+	return &ExternalLoginHandler{
+		externalLoginService: externalLoginService,
+		authService:          authService,
+		twoFactorService:     twoFactorService,
+	}
+}
+
+// ListProviders 获取可用的外部身份提供方
+// GET /api/v1/auth/sso/providers
+func (h *ExternalLoginHandler) ListProviders(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "Providers retrieved successfully",
+		"data":    h.externalLoginService.ListProviders(),
+	})
+}
+
+// Login 发起外部登录，前端将浏览器跳转到返回的 authorization_url
+// GET /api/v1/auth/sso/:provider/login
+func (h *ExternalLoginHandler) Login(c *gin.Context) {
+	result, err := h.externalLoginService.StartLogin(c.Request.Context(), command.NewStartExternalLoginCommand(c.Param("provider")))
+	if err != nil {
+		h.handleError(c, err, "")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "Redirect to identity provider",
+		"data":    result,
+	})
+}
+
+// Callback 提供方登录完成后的回调，成功时与密码登录相同，返回令牌或两步验证所需的 MFA 令牌
+// GET /api/v1/auth/sso/:provider/callback
+func (h *ExternalLoginHandler) Callback(c *gin.Context) {
+	var req dto.ExternalLoginCallbackRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "Invalid request",
+		})
+		return
+	}
+
+	// 提供方返回 error 时不带授权码，仍然交给应用服务以作废 state
+	code := req.Code
+	if req.Error != "" {
+		code = ""
+	}
+
+	// 首次登录可能即时创建用户，需要携带事件元数据
+	user, err := h.externalLoginService.CompleteLogin(middleware.RequestContext(c), command.NewCompleteExternalLoginCommand(c.Param("provider"), code, req.State))
+	if err != nil {
+		h.handleError(c, err, req.Error)
+		return
+	}
+
+	respondLogin(c, h.authService, h.twoFactorService, user)
+}
+
+func (h *ExternalLoginHandler) handleError(c *gin.Context, err error, providerError string) {
+	switch {
+	case errors.Is(err, service.ErrUnknownIdentityProvider):
+		c.JSON(http.StatusNotFound, gin.H{
+			"code":    404,
+			"message": err.Error(),
+		})
+	case errors.Is(err, service.ErrInvalidLoginState), errors.Is(err, identity.ErrAuthenticationFailed):
+		message := err.Error()
+		if providerError != "" {
+			message = "identity provider returned error: " + providerError
+		}
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"code":    401,
+			"message": message,
+		})
+	case errors.Is(err, domainservice.ErrUserNotActive), errors.Is(err, domainservice.ErrPasswordResetRequired):
+		c.JSON(http.StatusForbidden, gin.H{
+			"code":    403,
+			"message": err.Error(),
+		})
+	case errors.Is(err, service.ErrExternalAccountConflict):
+		c.JSON(http.StatusConflict, gin.H{
+			"code":    409,
+			"message": err.Error(),
+		})
+	case errors.Is(err, service.ErrExternalEmailRequired):
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": err.Error(),
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "Internal server error",
+		})
+	}
+}
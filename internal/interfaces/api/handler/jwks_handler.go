@@ -0,0 +1,24 @@
+package handler
+
+import (
+	"net/http"
+	"yiwen/go-ddd/internal/infrastructure/jwtkeys"
+
+	"github.com/gin-gonic/gin"
+)
+
+type JWKSHandler struct {
+	keys *jwtkeys.KeySet
+}
+
+func NewJWKSHandler(keys *jwtkeys.KeySet) *JWKSHandler {
+	return &JWKSHandler{keys: keys}
+}
+
+// GetJWKS 发布验证令牌所需的公钥，按 RFC 7517 格式直接返回，不使用统一的响应包装
+// GET /.well-known/jwks.json
+func (h *JWKSHandler) GetJWKS(c *gin.Context) {
+	// 允许下游服务缓存一段时间，轮换时新旧密钥会同时发布，缓存不会导致验证失败
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, h.keys.JWKS())
+}
@@ -0,0 +1,228 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"yiwen/go-ddd/internal/application/command"
+	"yiwen/go-ddd/internal/application/dto"
+	"yiwen/go-ddd/internal/application/service"
+	"yiwen/go-ddd/internal/domain/repository"
+	"yiwen/go-ddd/internal/interfaces/api/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+type OAuthHandler struct {
+	oauthService *service.OAuthApplicationService
+}
+
+func NewOAuthHandler(oauthService *service.OAuthApplicationService) *OAuthHandler {
+	return &OAuthHandler{oauthService: oauthService}
+}
+
+// CreateClient 注册 OAuth2 客户端
+// POST /api/v1/admin/oauth/clients
+func (h *OAuthHandler) CreateClient(c *gin.Context) {
+	var req dto.CreateOAuthClientRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "Invalid request",
+		})
+		return
+	}
+
+	cmd := command.NewCreateOAuthClientCommand(req.Name, req.RedirectURIs, req.Scopes, req.GrantTypes, req.Confidential)
+	client, err := h.oauthService.CreateClient(c.Request.Context(), cmd)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"code":    200,
+		"message": "OAuth client created successfully",
+		"data":    client,
+	})
+}
+
+// ListClients 获取 OAuth2 客户端列表
+// GET /api/v1/admin/oauth/clients
+func (h *OAuthHandler) ListClients(c *gin.Context) {
+	clients, err := h.oauthService.ListClients(c.Request.Context())
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "OAuth clients retrieved successfully",
+		"data":    clients,
+	})
+}
+
+// DeleteClient 删除 OAuth2 客户端
+// DELETE /api/v1/admin/oauth/clients/:client_id
+func (h *OAuthHandler) DeleteClient(c *gin.Context) {
+	if err := h.oauthService.DeleteClient(c.Request.Context(), command.NewDeleteOAuthClientCommand(c.Param("client_id"))); err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "OAuth client deleted successfully",
+	})
+}
+
+// Authorize 授权请求，由已登录用户的前端页面调用
+// 返回需要用户确认的客户端和权限范围，或者前端需要跳转的回调地址
+// GET /oauth/authorize
+func (h *OAuthHandler) Authorize(c *gin.Context) {
+	var req dto.AuthorizeRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "Invalid request",
+		})
+		return
+	}
+
+	userID, _ := middleware.GetUserIDFromContext(c)
+	result, err := h.oauthService.Authorize(c.Request.Context(), toAuthorizeCommand(userID, &req))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "Authorization processed",
+		"data":    result,
+	})
+}
+
+// Consent 用户同意或拒绝授权
+// POST /oauth/authorize
+func (h *OAuthHandler) Consent(c *gin.Context) {
+	var req dto.ConsentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "Invalid request",
+		})
+		return
+	}
+
+	userID, _ := middleware.GetUserIDFromContext(c)
+	cmd := command.NewConsentCommand(toAuthorizeCommand(userID, &req.AuthorizeRequest), req.Approved)
+	result, err := h.oauthService.Consent(c.Request.Context(), cmd)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "Authorization processed",
+		"data":    result,
+	})
+}
+
+// Token 令牌端点，请求和响应都按 RFC 6749 的格式，不使用统一的响应包装
+// 客户端认证优先使用 HTTP Basic，也可以在表单中提供 client_id 和 client_secret
+// POST /oauth/token
+func (h *OAuthHandler) Token(c *gin.Context) {
+	c.Header("Cache-Control", "no-store")
+	c.Header("Pragma", "no-cache")
+
+	var req dto.OAuthTokenRequest
+	if err := c.ShouldBind(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":             service.OAuthErrInvalidRequest,
+			"error_description": "grant_type is required",
+		})
+		return
+	}
+
+	clientID, clientSecret, basic := clientCredentialsFromBasicAuth(c)
+	if !basic {
+		clientID, clientSecret = req.ClientID, req.ClientSecret
+	}
+
+	cmd := command.NewOAuthTokenCommand(req.GrantType, clientID, clientSecret, req.Code, req.RedirectURI, req.CodeVerifier, req.RefreshToken, req.Scope)
+	tokens, err := h.oauthService.Token(c.Request.Context(), cmd)
+	if err != nil {
+		var oauthErr *service.OAuthError
+		if !errors.As(err, &oauthErr) {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":             "server_error",
+				"error_description": "Internal server error",
+			})
+			return
+		}
+
+		status := http.StatusBadRequest
+		if oauthErr.Code == service.OAuthErrInvalidClient {
+			status = http.StatusUnauthorized
+			if basic {
+				c.Header("WWW-Authenticate", `Basic realm="oauth"`)
+			}
+		}
+		c.JSON(status, gin.H{
+			"error":             oauthErr.Code,
+			"error_description": oauthErr.Description,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, tokens)
+}
+
+func (h *OAuthHandler) handleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, repository.ErrOAuthClientNotFound):
+		c.JSON(http.StatusNotFound, gin.H{
+			"code":    404,
+			"message": err.Error(),
+		})
+	case errors.Is(err, service.ErrInvalidOAuthClient), errors.Is(err, service.ErrInvalidRedirectURI):
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": err.Error(),
+		})
+	case errors.Is(err, service.ErrOAuthUserRequired):
+		c.JSON(http.StatusForbidden, gin.H{
+			"code":    403,
+			"message": err.Error(),
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "Internal server error",
+		})
+	}
+}
+
+func toAuthorizeCommand(userID uint64, req *dto.AuthorizeRequest) *command.AuthorizeCommand {
+	return command.NewAuthorizeCommand(userID, req.ResponseType, req.ClientID, req.RedirectURI, req.Scope, req.State, req.CodeChallenge, req.CodeChallengeMethod, req.Nonce)
+}
+
+// clientCredentialsFromBasicAuth 解析 HTTP Basic 客户端认证，RFC 6749 要求用户名和密码先做表单编码
+func clientCredentialsFromBasicAuth(c *gin.Context) (string, string, bool) {
+	username, password, ok := c.Request.BasicAuth()
+	if !ok {
+		return "", "", false
+	}
+	clientID, err := url.QueryUnescape(username)
+	if err != nil {
+		return "", "", false
+	}
+	clientSecret, err := url.QueryUnescape(password)
+	if err != nil {
+		return "", "", false
+	}
+	return clientID, clientSecret, true
+}
@@ -0,0 +1,91 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"yiwen/go-ddd/internal/application/query"
+	"yiwen/go-ddd/internal/application/service"
+	"yiwen/go-ddd/internal/interfaces/api/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+type OIDCHandler struct {
+	oauthService     *service.OAuthApplicationService
+	issuer           string
+	authorizePageURL string
+	signingAlgorithm string
+}
+
+// NewOIDCHandler 创建 OIDC 处理器，authorizePageURL 为空时授权端点就是 /oauth/authorize 接口
+func NewOIDCHandler(oauthService *service.OAuthApplicationService, issuer, authorizePageURL, signingAlgorithm string) *OIDCHandler {
+	issuer = strings.TrimSuffix(issuer, "/")
+	if authorizePageURL == "" {
+		authorizePageURL = issuer + "/oauth/authorize"
+	}
+	return &OIDCHandler{
+		oauthService:     oauthService,
+		issuer:           issuer,
+		authorizePageURL: authorizePageURL,
+		signingAlgorithm: signingAlgorithm,
+	}
+}
+
+// Discovery OIDC 发现文档，按 OpenID Connect Discovery 1.0 格式直接返回
+// GET /.well-known/openid-configuration
+// 未配置非对称签名密钥时不提供 OpenID Connect，返回 404
+func (h *OIDCHandler) Discovery(c *gin.Context) {
+	if !h.oauthService.OIDCEnabled() {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":             "not_found",
+			"error_description": service.ErrOIDCDisabled.Error(),
+		})
+		return
+	}
+
+	c.Header("Cache-Control", "public, max-age=3600")
+	c.JSON(http.StatusOK, gin.H{
+		"issuer":                                h.issuer,
+		"authorization_endpoint":                h.authorizePageURL,
+		"token_endpoint":                        h.issuer + "/oauth/token",
+		"userinfo_endpoint":                     h.issuer + "/userinfo",
+		"jwks_uri":                              h.issuer + "/.well-known/jwks.json",
+		"response_types_supported":              []string{"code"},
+		"subject_types_supported":               []string{"public"},
+		"id_token_signing_alg_values_supported": []string{h.signingAlgorithm},
+		"scopes_supported":                      []string{service.ScopeOpenID, service.ScopeProfile, service.ScopeEmail},
+		"grant_types_supported":                 []string{"authorization_code", "refresh_token", "client_credentials"},
+		"token_endpoint_auth_methods_supported": []string{"client_secret_basic", "client_secret_post", "none"},
+		"code_challenge_methods_supported":      []string{"S256"},
+		"claims_supported": []string{
+			"iss", "sub", "aud", "exp", "iat", "nonce",
+			"preferred_username", "name", "picture", "updated_at", "email", "email_verified",
+		},
+	})
+}
+
+// UserInfo 返回访问令牌授权范围内的用户声明，访问令牌必须包含 openid 权限范围
+// GET /userinfo
+// POST /userinfo
+func (h *OIDCHandler) UserInfo(c *gin.Context) {
+	userID, _ := middleware.GetUserIDFromContext(c)
+	claims, err := h.oauthService.UserInfo(c.Request.Context(), query.NewUserInfoQuery(userID, middleware.GetScopeFromContext(c)))
+	if err != nil {
+		if errors.Is(err, service.ErrInsufficientScope) {
+			c.Header("WWW-Authenticate", `Bearer error="insufficient_scope", scope="openid"`)
+			c.JSON(http.StatusForbidden, gin.H{
+				"error":             "insufficient_scope",
+				"error_description": "access token does not include the openid scope",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":             "server_error",
+			"error_description": "Internal server error",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, claims)
+}
@@ -0,0 +1,182 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"yiwen/go-ddd/internal/application/command"
+	"yiwen/go-ddd/internal/application/dto"
+	"yiwen/go-ddd/internal/application/service"
+	"yiwen/go-ddd/internal/domain/repository"
+	domainservice "yiwen/go-ddd/internal/domain/service"
+	"yiwen/go-ddd/internal/interfaces/api/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PasskeyHandler struct {
+	passkeyService *service.PasskeyApplicationService
+}
+
+func NewPasskeyHandler(passkeyService *service.PasskeyApplicationService) *PasskeyHandler {
+	return &PasskeyHandler{passkeyService: passkeyService}
+}
+
+// ListPasskeys 查询当前用户的通行密钥
+// GET /api/v1/users/me/passkeys
+func (h *PasskeyHandler) ListPasskeys(c *gin.Context) {
+	userID, _ := middleware.GetUserIDFromContext(c)
+
+	passkeys, err := h.passkeyService.List(c.Request.Context(), userID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "Passkeys retrieved successfully",
+		"data":    passkeys,
+	})
+}
+
+// BeginRegistration 获取注册选项，传给 navigator.credentials.create
+// POST /api/v1/users/me/passkeys/register/begin
+func (h *PasskeyHandler) BeginRegistration(c *gin.Context) {
+	userID, _ := middleware.GetUserIDFromContext(c)
+
+	options, err := h.passkeyService.BeginRegistration(c.Request.Context(), command.NewBeginPasskeyRegistrationCommand(userID))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "Create a passkey with the options",
+		"data":    options,
+	})
+}
+
+// FinishRegistration 提交认证器创建的凭证
+// POST /api/v1/users/me/passkeys/register/finish
+func (h *PasskeyHandler) FinishRegistration(c *gin.Context) {
+	var req dto.PasskeyRegistrationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "Invalid request",
+		})
+		return
+	}
+
+	userID, _ := middleware.GetUserIDFromContext(c)
+	cmd := command.NewFinishPasskeyRegistrationCommand(userID, req.Name, req.Response.ClientDataJSON, req.Response.AttestationObject)
+	passkey, err := h.passkeyService.FinishRegistration(c.Request.Context(), cmd)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"code":    200,
+		"message": "Passkey registered successfully",
+		"data":    passkey,
+	})
+}
+
+// RevokePasskey 删除通行密钥
+// DELETE /api/v1/users/me/passkeys/:id
+func (h *PasskeyHandler) RevokePasskey(c *gin.Context) {
+	id, ok := parseIDParam(c, "id", "Invalid passkey ID")
+	if !ok {
+		return
+	}
+
+	userID, _ := middleware.GetUserIDFromContext(c)
+	if err := h.passkeyService.Revoke(c.Request.Context(), command.NewRevokePasskeyCommand(userID, id)); err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "Passkey revoked successfully",
+	})
+}
+
+// BeginLogin 获取登录选项，传给 navigator.credentials.get
+// POST /api/v1/auth/passkeys/login/begin
+func (h *PasskeyHandler) BeginLogin(c *gin.Context) {
+	options, err := h.passkeyService.BeginLogin(c.Request.Context())
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "Sign in with a passkey using the options",
+		"data":    options,
+	})
+}
+
+// FinishLogin 提交认证器的签名，通过后签发令牌
+// POST /api/v1/auth/passkeys/login/finish
+func (h *PasskeyHandler) FinishLogin(c *gin.Context) {
+	var req dto.PasskeyLoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "Invalid request",
+		})
+		return
+	}
+
+	cmd := command.NewFinishPasskeyLoginCommand(req.ID, req.Response.ClientDataJSON, req.Response.AuthenticatorData, req.Response.Signature, req.Response.UserHandle)
+	result, err := h.passkeyService.FinishLogin(middleware.RequestContext(c), cmd)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "Login successfully",
+		"data":    result,
+	})
+}
+
+func (h *PasskeyHandler) handleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrInvalidPasskey), errors.Is(err, service.ErrInvalidWebAuthnChallenge):
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": err.Error(),
+		})
+	case errors.Is(err, service.ErrPasskeyAuthenticationFailed):
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"code":    401,
+			"message": err.Error(),
+		})
+	case errors.Is(err, domainservice.ErrUserNotActive), errors.Is(err, domainservice.ErrPasswordResetRequired):
+		c.JSON(http.StatusForbidden, gin.H{
+			"code":    403,
+			"message": err.Error(),
+		})
+	case errors.Is(err, repository.ErrPasskeyNotFound):
+		c.JSON(http.StatusNotFound, gin.H{
+			"code":    404,
+			"message": err.Error(),
+		})
+	case errors.Is(err, repository.ErrPasskeyExists):
+		c.JSON(http.StatusConflict, gin.H{
+			"code":    409,
+			"message": err.Error(),
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "Internal server error",
+		})
+	}
+}
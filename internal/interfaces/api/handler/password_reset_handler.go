@@ -0,0 +1,83 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"yiwen/go-ddd/internal/application/command"
+	"yiwen/go-ddd/internal/application/dto"
+	"yiwen/go-ddd/internal/application/service"
+	"yiwen/go-ddd/internal/domain/valueobject"
+	"yiwen/go-ddd/internal/interfaces/api/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PasswordResetHandler struct {
+	passwordResetService *service.PasswordResetApplicationService
+}
+
+func NewPasswordResetHandler(passwordResetService *service.PasswordResetApplicationService) *PasswordResetHandler {
+	return &PasswordResetHandler{passwordResetService: passwordResetService}
+}
+
+// ForgotPassword 找回密码，无论邮箱是否注册都返回相同的结果
+// POST /api/v1/users/forgot-password
+func (h *PasswordResetHandler) ForgotPassword(c *gin.Context) {
+	var req dto.ForgotPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "Invalid request",
+		})
+		return
+	}
+
+	if err := h.passwordResetService.ForgotPassword(c.Request.Context(), command.NewForgotPasswordCommand(req.Email)); err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "If the email is registered, a password reset email has been sent",
+	})
+}
+
+// ResetPassword 使用重置邮件中的令牌设置新密码，之前的登录全部失效
+// POST /api/v1/users/reset-password
+func (h *PasswordResetHandler) ResetPassword(c *gin.Context) {
+	var req dto.ResetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "Invalid request",
+		})
+		return
+	}
+
+	cmd := command.NewConfirmPasswordResetCommand(req.Token, req.NewPassword)
+	if err := h.passwordResetService.ResetPassword(middleware.RequestContext(c), cmd); err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "Password reset successfully",
+	})
+}
+
+func (h *PasswordResetHandler) handleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrInvalidResetToken), errors.Is(err, valueobject.ErrPasswordToShort), errors.Is(err, valueobject.ErrPasswordTooWeak):
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": err.Error(),
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "Internal server error",
+		})
+	}
+}
@@ -0,0 +1,237 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"yiwen/go-ddd/internal/application/authz"
+	"yiwen/go-ddd/internal/application/command"
+	"yiwen/go-ddd/internal/application/dto"
+	"yiwen/go-ddd/internal/application/query"
+	"yiwen/go-ddd/internal/application/service"
+	"yiwen/go-ddd/internal/domain/repository"
+	domainservice "yiwen/go-ddd/internal/domain/service"
+	"yiwen/go-ddd/internal/interfaces/api/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+type RoleHandler struct {
+	roleService *service.RoleApplicationService
+}
+
+func NewRoleHandler(roleService *service.RoleApplicationService) *RoleHandler {
+	return &RoleHandler{roleService: roleService}
+}
+
+// ListPermissions 获取系统定义的全部权限
+// GET /api/v1/admin/permissions
+func (h *RoleHandler) ListPermissions(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "Permissions retrieved successfully",
+		"data":    h.roleService.ListPermissions(),
+	})
+}
+
+// CreateRole 创建角色
+// POST /api/v1/admin/roles
+func (h *RoleHandler) CreateRole(c *gin.Context) {
+	var req dto.CreateRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "Invalid request",
+		})
+		return
+	}
+
+	cmd := command.NewCreateRoleCommand(req.Name, req.Description, req.ParentID, req.Permissions)
+	role, err := h.roleService.CreateRole(middleware.RequestContext(c), cmd)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"code":    200,
+		"message": "Role created successfully",
+		"data":    role,
+	})
+}
+
+// ListRoles 获取角色列表
+// GET /api/v1/admin/roles
+func (h *RoleHandler) ListRoles(c *gin.Context) {
+	roles, err := h.roleService.ListRoles(c.Request.Context())
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "Roles retrieved successfully",
+		"data":    roles,
+	})
+}
+
+// GetRole 获取角色
+// GET /api/v1/admin/roles/:id
+func (h *RoleHandler) GetRole(c *gin.Context) {
+	id, ok := parseIDParam(c, "id", "Invalid role ID")
+	if !ok {
+		return
+	}
+
+	role, err := h.roleService.GetRole(c.Request.Context(), query.NewGetRoleQuery(id))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "Role retrieved successfully",
+		"data":    role,
+	})
+}
+
+// UpdateRole 更新角色
+// PUT /api/v1/admin/roles/:id
+func (h *RoleHandler) UpdateRole(c *gin.Context) {
+	id, ok := parseIDParam(c, "id", "Invalid role ID")
+	if !ok {
+		return
+	}
+
+	var req dto.UpdateRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "Invalid request",
+		})
+		return
+	}
+
+	cmd := command.NewUpdateRoleCommand(id, req.Description, req.ParentID, req.Permissions)
+	role, err := h.roleService.UpdateRole(middleware.RequestContext(c), cmd)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "Role updated successfully",
+		"data":    role,
+	})
+}
+
+// DeleteRole 删除角色
+// DELETE /api/v1/admin/roles/:id
+func (h *RoleHandler) DeleteRole(c *gin.Context) {
+	id, ok := parseIDParam(c, "id", "Invalid role ID")
+	if !ok {
+		return
+	}
+
+	if err := h.roleService.DeleteRole(middleware.RequestContext(c), command.NewDeleteRoleCommand(id)); err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "Role deleted successfully",
+	})
+}
+
+// AssignRole 为用户分配角色
+// POST /api/v1/admin/users/:id/roles
+func (h *RoleHandler) AssignRole(c *gin.Context) {
+	id, ok := parseIDParam(c, "id", "Invalid user ID")
+	if !ok {
+		return
+	}
+
+	var req dto.AssignRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "Invalid request",
+		})
+		return
+	}
+
+	user, err := h.roleService.AssignRole(middleware.RequestContext(c), command.NewAssignRoleCommand(id, req.Role))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "Role assigned successfully",
+		"data":    user,
+	})
+}
+
+// RevokeRole 收回用户角色
+// DELETE /api/v1/admin/users/:id/roles/:role
+func (h *RoleHandler) RevokeRole(c *gin.Context) {
+	id, ok := parseIDParam(c, "id", "Invalid user ID")
+	if !ok {
+		return
+	}
+
+	user, err := h.roleService.RevokeRole(middleware.RequestContext(c), command.NewRevokeRoleCommand(id, c.Param("role")))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "Role revoked successfully",
+		"data":    user,
+	})
+}
+
+func (h *RoleHandler) handleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, authz.ErrUnauthenticated):
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"code":    401,
+			"message": "Unauthorized",
+		})
+	case errors.Is(err, authz.ErrForbidden):
+		c.JSON(http.StatusForbidden, gin.H{
+			"code":    403,
+			"message": "Forbidden",
+		})
+	case errors.Is(err, repository.ErrRoleNotFound):
+		c.JSON(http.StatusNotFound, gin.H{
+			"code":    404,
+			"message": err.Error(),
+		})
+	case errors.Is(err, domainservice.ErrInvalidRoleName),
+		errors.Is(err, domainservice.ErrUnknownPermission),
+		errors.Is(err, domainservice.ErrRoleHierarchyCycle):
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": err.Error(),
+		})
+	case errors.Is(err, domainservice.ErrRoleAlreadyExists),
+		errors.Is(err, domainservice.ErrBuiltinRole),
+		errors.Is(err, domainservice.ErrRoleInUse):
+		c.JSON(http.StatusConflict, gin.H{
+			"code":    409,
+			"message": err.Error(),
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "Internal server error",
+		})
+	}
+}
@@ -0,0 +1,255 @@
+package handler
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+	"yiwen/go-ddd/internal/application/command"
+	"yiwen/go-ddd/internal/application/dto"
+	"yiwen/go-ddd/internal/application/service"
+	domainservice "yiwen/go-ddd/internal/domain/service"
+	"yiwen/go-ddd/internal/interfaces/api/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+type TwoFactorHandler struct {
+	twoFactorService *service.TwoFactorApplicationService
+}
+
+func NewTwoFactorHandler(twoFactorService *service.TwoFactorApplicationService) *TwoFactorHandler {
+	return &TwoFactorHandler{twoFactorService: twoFactorService}
+}
+
+// Status 查询当前用户的两步验证状态
+// GET /api/v1/auth/2fa
+func (h *TwoFactorHandler) Status(c *gin.Context) {
+	userID, _ := middleware.GetUserIDFromContext(c)
+
+	status, err := h.twoFactorService.Status(c.Request.Context(), userID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "Two-factor status retrieved successfully",
+		"data":    status,
+	})
+}
+
+// Setup 生成 TOTP 密钥，提交第一个验证码确认后才会开启
+// POST /api/v1/auth/2fa/setup
+func (h *TwoFactorHandler) Setup(c *gin.Context) {
+	userID, _ := middleware.GetUserIDFromContext(c)
+
+	setup, err := h.twoFactorService.Setup(c.Request.Context(), command.NewSetupTwoFactorCommand(userID))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "Scan the provisioning uri and confirm with a code",
+		"data":    setup,
+	})
+}
+
+// Confirm 确认开启两步验证，返回只显示一次的恢复码
+// POST /api/v1/auth/2fa/confirm
+func (h *TwoFactorHandler) Confirm(c *gin.Context) {
+	var req dto.TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "Invalid request",
+		})
+		return
+	}
+
+	userID, _ := middleware.GetUserIDFromContext(c)
+	codes, err := h.twoFactorService.Confirm(c.Request.Context(), command.NewConfirmTwoFactorCommand(userID, req.Code))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "Two-factor authentication enabled",
+		"data":    codes,
+	})
+}
+
+// Disable 关闭两步验证
+// POST /api/v1/auth/2fa/disable
+func (h *TwoFactorHandler) Disable(c *gin.Context) {
+	var req dto.TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "Invalid request",
+		})
+		return
+	}
+
+	userID, _ := middleware.GetUserIDFromContext(c)
+	if err := h.twoFactorService.Disable(c.Request.Context(), command.NewDisableTwoFactorCommand(userID, req.Code)); err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "Two-factor authentication disabled",
+	})
+}
+
+// RegenerateRecoveryCodes 重新生成恢复码
+// POST /api/v1/auth/2fa/recovery-codes
+func (h *TwoFactorHandler) RegenerateRecoveryCodes(c *gin.Context) {
+	var req dto.TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "Invalid request",
+		})
+		return
+	}
+
+	userID, _ := middleware.GetUserIDFromContext(c)
+	codes, err := h.twoFactorService.RegenerateRecoveryCodes(c.Request.Context(), command.NewRegenerateRecoveryCodesCommand(userID, req.Code))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "Recovery codes regenerated",
+		"data":    codes,
+	})
+}
+
+// Verify 登录第二步，使用 MFA 令牌和验证码或恢复码换取访问令牌
+// POST /api/v1/auth/2fa/verify
+func (h *TwoFactorHandler) Verify(c *gin.Context) {
+	var req dto.VerifyTwoFactorRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "Invalid request",
+		})
+		return
+	}
+
+	result, err := h.twoFactorService.Verify(middleware.RequestContext(c), command.NewVerifyTwoFactorCommand(req.MFAToken, req.Code))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "Login successfully",
+		"data":    result,
+	})
+}
+
+// ResetTwoFactor 管理员重置用户的两步验证
+// DELETE /api/v1/admin/users/:id/2fa
+func (h *TwoFactorHandler) ResetTwoFactor(c *gin.Context) {
+	id, ok := parseIDParam(c, "id", "Invalid user ID")
+	if !ok {
+		return
+	}
+
+	if err := h.twoFactorService.Reset(c.Request.Context(), command.NewResetTwoFactorCommand(id)); err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "Two-factor authentication reset",
+	})
+}
+
+func (h *TwoFactorHandler) handleError(c *gin.Context, err error) {
+	var blocked *domainservice.LoginBlockedError
+	switch {
+	case errors.As(err, &blocked):
+		c.Header("Retry-After", strconv.Itoa(int(math.Ceil(blocked.RetryAfter.Seconds()))))
+		c.JSON(http.StatusTooManyRequests, gin.H{
+			"code":    429,
+			"message": err.Error(),
+		})
+	case errors.Is(err, service.ErrInvalidTwoFactorCode), errors.Is(err, service.ErrInvalidMFAToken):
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"code":    401,
+			"message": err.Error(),
+		})
+	case errors.Is(err, domainservice.ErrUserNotActive), errors.Is(err, domainservice.ErrPasswordResetRequired):
+		c.JSON(http.StatusForbidden, gin.H{
+			"code":    403,
+			"message": err.Error(),
+		})
+	case errors.Is(err, service.ErrTwoFactorNotEnabled):
+		c.JSON(http.StatusNotFound, gin.H{
+			"code":    404,
+			"message": err.Error(),
+		})
+	case errors.Is(err, service.ErrTwoFactorAlreadyEnabled), errors.Is(err, service.ErrTwoFactorNotSetup):
+		c.JSON(http.StatusConflict, gin.H{
+			"code":    409,
+			"message": err.Error(),
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "Internal server error",
+		})
+	}
+}
+
+// respondLogin 第一步验证通过后签发令牌
+// 用户开启了两步验证时只返回 MFA 令牌，客户端需要再调用 POST /api/v1/auth/2fa/verify
+func respondLogin(c *gin.Context, authService *service.AuthApplicationService, twoFactorService *service.TwoFactorApplicationService, user *dto.UserDTO) {
+	challenge, err := twoFactorService.Challenge(c.Request.Context(), user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "Internal server error",
+		})
+		return
+	}
+	if challenge != nil {
+		c.JSON(http.StatusOK, gin.H{
+			"code":    200,
+			"message": "Two-factor authentication required",
+			"data":    challenge,
+		})
+		return
+	}
+
+	tokens, err := authService.IssueTokens(middleware.RequestContext(c), user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "Internal server error",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "Login successfully",
+		"data": dto.LoginResponse{
+			TokenDTO: *tokens,
+			User:     *user,
+		},
+	})
+}
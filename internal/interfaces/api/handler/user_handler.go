@@ -1,26 +1,32 @@
 package handler
 
 import (
+	"errors"
+	"math"
 	"net/http"
 	"strconv"
+	"yiwen/go-ddd/internal/application/authz"
 	"yiwen/go-ddd/internal/application/command"
 	"yiwen/go-ddd/internal/application/dto"
 	"yiwen/go-ddd/internal/application/query"
 	"yiwen/go-ddd/internal/application/service"
+	domainservice "yiwen/go-ddd/internal/domain/service"
 	"yiwen/go-ddd/internal/interfaces/api/middleware"
 
 	"github.com/gin-gonic/gin"
 )
 
 type UserHandler struct {
-	userService *service.UserApplicationService
-	jwtAuth     *middleware.JWTAuth
+	userService      *service.UserApplicationService
+	authService      *service.AuthApplicationService
+	twoFactorService *service.TwoFactorApplicationService
 }
 
-func NewUserHandler(userService *service.UserApplicationService, jwtAuth *middleware.JWTAuth) *UserHandler {
+func NewUserHandler(userService *service.UserApplicationService, authService *service.AuthApplicationService, twoFactorService *service.TwoFactorApplicationService) *UserHandler {
 	return &UserHandler{
-		userService: userService,
-		jwtAuth:     jwtAuth,
+		userService:      userService,
+		authService:      authService,
+		twoFactorService: twoFactorService,
 	}
 }
 
@@ -37,7 +43,7 @@ func (h *UserHandler) Register(c *gin.Context) {
 	}
 
 	cmd := command.NewRegisterUserCommand(req.Username, req.Email, req.Password, req.Nickname)
-	user, err := h.userService.Register(c.Request.Context(), cmd)
+	user, err := h.userService.Register(middleware.RequestContext(c), cmd)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"code":    500,
@@ -66,33 +72,63 @@ func (h *UserHandler) Login(c *gin.Context) {
 	}
 
 	q := query.NewLoginQuery(req.Username, req.Password)
-	user, err := h.userService.Login(c.Request.Context(), q)
+	user, err := h.userService.Login(middleware.RequestContext(c), q)
 	if err != nil {
+		h.handleLoginError(c, err)
+		return
+	}
+
+	respondLogin(c, h.authService, h.twoFactorService, user)
+}
+
+// handleLoginError 登录失败的响应，被锁定或需要等待时通过 Retry-After 告知客户端等待的秒数
+func (h *UserHandler) handleLoginError(c *gin.Context, err error) {
+	var blocked *domainservice.LoginBlockedError
+	switch {
+	case errors.As(err, &blocked):
+		c.Header("Retry-After", strconv.Itoa(int(math.Ceil(blocked.RetryAfter.Seconds()))))
+		c.JSON(http.StatusTooManyRequests, gin.H{
+			"code":    429,
+			"message": err.Error(),
+		})
+	case errors.Is(err, domainservice.ErrInvalidCredentials):
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"code":    401,
+			"message": "Invalid username or password",
+		})
+	case errors.Is(err, domainservice.ErrUserNotActive), errors.Is(err, domainservice.ErrPasswordResetRequired):
+		// 开启邮箱验证时，验证邮箱之前用户处于未激活状态；密码哈希丢失的用户需要先找回密码
+		c.JSON(http.StatusForbidden, gin.H{
+			"code":    403,
+			"message": err.Error(),
+		})
+	default:
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"code":    500,
 			"message": "Internal server error",
 		})
-		return
 	}
+}
 
-	token, expiresAt, err := h.jwtAuth.GenerateToken(user.ID, user.Username, user.Role)
-	if err != nil {
+// handleError 应用服务错误的响应，未通过授权策略时返回 401 或 403
+func (h *UserHandler) handleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, authz.ErrUnauthenticated):
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"code":    401,
+			"message": "Unauthorized",
+		})
+	case errors.Is(err, authz.ErrForbidden):
+		c.JSON(http.StatusForbidden, gin.H{
+			"code":    403,
+			"message": "Forbidden",
+		})
+	default:
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"code":    500,
 			"message": "Internal server error",
 		})
-		return
 	}
-
-	c.JSON(http.StatusOK, gin.H{
-		"code":    200,
-		"message": "Login successfully",
-		"data": dto.LoginResponse{
-			Token:     token,
-			ExpiresAt: expiresAt,
-			User:      *user,
-		},
-	})
 }
 
 // GetUser 获取用户信息
@@ -109,12 +145,9 @@ func (h *UserHandler) GetUser(c *gin.Context) {
 	}
 
 	q := query.NewGetUserByIDQuery(id)
-	user, err := h.userService.GetUserByID(c.Request.Context(), q)
+	user, err := h.userService.GetUserByID(middleware.RequestContext(c), q)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"code":    500,
-			"message": "Internal server error",
-		})
+		h.handleError(c, err)
 		return
 	}
 
@@ -138,12 +171,9 @@ func (h *UserHandler) ListUsers(c *gin.Context) {
 	}
 
 	q := query.NewListUserQuery(req.Page, req.PageSize)
-	users, err := h.userService.ListUsers(c.Request.Context(), q)
+	users, err := h.userService.ListUsers(middleware.RequestContext(c), q)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"code":    500,
-			"message": "Internal server error",
-		})
+		h.handleError(c, err)
 		return
 	}
 
@@ -167,15 +197,6 @@ func (h *UserHandler) UpdateProfile(c *gin.Context) {
 		return
 	}
 
-	currentUserID, _ := middleware.GetUserIDFromContext(c)
-	if currentUserID != id {
-		c.JSON(http.StatusForbidden, gin.H{
-			"code":    403,
-			"message": "Forbidden",
-		})
-		return
-	}
-
 	var req dto.UpdateProfileRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{
@@ -186,12 +207,9 @@ func (h *UserHandler) UpdateProfile(c *gin.Context) {
 	}
 
 	cmd := command.NewUpdateProfileCommand(id, req.Nickname, req.Avatar)
-	user, err := h.userService.UpdateProfile(c.Request.Context(), cmd)
+	user, err := h.userService.UpdateProfile(middleware.RequestContext(c), cmd)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"code":    500,
-			"message": "Internal server error",
-		})
+		h.handleError(c, err)
 		return
 	}
 
@@ -215,15 +233,6 @@ func (h *UserHandler) ChangePassword(c *gin.Context) {
 		return
 	}
 
-	currentUserID, _ := middleware.GetUserIDFromContext(c)
-	if currentUserID != id {
-		c.JSON(http.StatusForbidden, gin.H{
-			"code":    403,
-			"message": "Forbidden",
-		})
-		return
-	}
-
 	var req dto.ChangePasswordRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{
@@ -234,12 +243,9 @@ func (h *UserHandler) ChangePassword(c *gin.Context) {
 	}
 
 	cmd := command.NewChangePasswordCommand(id, req.OldPassword, req.NewPassword)
-	err = h.userService.ChangePassword(c.Request.Context(), cmd)
+	err = h.userService.ChangePassword(middleware.RequestContext(c), cmd)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"code":    500,
-			"message": "Internal server error",
-		})
+		h.handleError(c, err)
 		return
 	}
 
@@ -249,8 +255,8 @@ func (h *UserHandler) ChangePassword(c *gin.Context) {
 	})
 }
 
-// DeleteUser 删除用户
-// DELETE /api/v1/users/:id
+// DeleteUser 删除用户，用户可以注销自己的账户，可以通过 reason 参数说明删除原因
+// DELETE /api/v1/users/:id?reason=spam
 func (h *UserHandler) DeleteUser(c *gin.Context) {
 	idStr := c.Param("id")
 	id, err := strconv.ParseUint(idStr, 10, 64)
@@ -262,12 +268,9 @@ func (h *UserHandler) DeleteUser(c *gin.Context) {
 		return
 	}
 
-	cmd := command.NewDeleteUserCommand(id)
-	if err := h.userService.DeleteUser(c.Request.Context(), cmd); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"code":    500,
-			"message": "Internal server error",
-		})
+	cmd := command.NewDeleteUserCommand(id, c.Query("reason"))
+	if err := h.userService.DeleteUser(middleware.RequestContext(c), cmd); err != nil {
+		h.handleError(c, err)
 		return
 	}
 
@@ -277,6 +280,81 @@ func (h *UserHandler) DeleteUser(c *gin.Context) {
 	})
 }
 
+// BanUser 禁用用户
+// POST /api/v1/admin/users/:id/ban
+func (h *UserHandler) BanUser(c *gin.Context) {
+	id, ok := parseIDParam(c, "id", "Invalid user ID")
+	if !ok {
+		return
+	}
+
+	var req dto.BanUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "Invalid request",
+		})
+		return
+	}
+
+	cmd := command.NewBanUserCommand(id, req.Reason)
+	user, err := h.userService.BanUser(middleware.RequestContext(c), cmd)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "User banned successfully",
+		"data":    user,
+	})
+}
+
+// PromoteUser 提升为管理员
+// POST /api/v1/admin/users/:id/promote
+func (h *UserHandler) PromoteUser(c *gin.Context) {
+	id, ok := parseIDParam(c, "id", "Invalid user ID")
+	if !ok {
+		return
+	}
+
+	cmd := command.NewPromoteToAdminCommand(id)
+	user, err := h.userService.PromoteToAdmin(middleware.RequestContext(c), cmd)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "User promoted successfully",
+		"data":    user,
+	})
+}
+
+// UnlockUser 解除账户的登录锁定
+// POST /api/v1/admin/users/:id/unlock
+func (h *UserHandler) UnlockUser(c *gin.Context) {
+	id, ok := parseIDParam(c, "id", "Invalid user ID")
+	if !ok {
+		return
+	}
+
+	cmd := command.NewUnlockUserCommand(id)
+	user, err := h.userService.UnlockUser(middleware.RequestContext(c), cmd)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "User unlocked successfully",
+		"data":    user,
+	})
+}
+
 // GetCurrentUser 获取当前用户信息
 // GET /api/v1/users/me
 func (h *UserHandler) GetCurrentUser(c *gin.Context) {
@@ -290,12 +368,9 @@ func (h *UserHandler) GetCurrentUser(c *gin.Context) {
 	}
 
 	q := query.NewGetUserByIDQuery(userID)
-	user, err := h.userService.GetUserByID(c.Request.Context(), q)
+	user, err := h.userService.GetUserByID(middleware.RequestContext(c), q)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"code":    500,
-			"message": "Internal server error",
-		})
+		h.handleError(c, err)
 		return
 	}
 
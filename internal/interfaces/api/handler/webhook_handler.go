@@ -0,0 +1,217 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"yiwen/go-ddd/internal/application/command"
+	"yiwen/go-ddd/internal/application/dto"
+	"yiwen/go-ddd/internal/application/query"
+	"yiwen/go-ddd/internal/application/service"
+	"yiwen/go-ddd/internal/domain/repository"
+	"yiwen/go-ddd/internal/interfaces/api/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+type WebhookHandler struct {
+	webhookService *service.WebhookApplicationService
+}
+
+func NewWebhookHandler(webhookService *service.WebhookApplicationService) *WebhookHandler {
+	return &WebhookHandler{webhookService: webhookService}
+}
+
+// CreateWebhook 注册 webhook 端点
+// POST /api/v1/admin/webhooks
+func (h *WebhookHandler) CreateWebhook(c *gin.Context) {
+	var req dto.CreateWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "Invalid request",
+		})
+		return
+	}
+
+	cmd := command.NewCreateWebhookCommand(req.URL, req.EventTypes, req.Secret)
+	webhook, err := h.webhookService.CreateEndpoint(middleware.RequestContext(c), cmd)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"code":    200,
+		"message": "Webhook created successfully",
+		"data":    webhook,
+	})
+}
+
+// ListWebhooks 获取 webhook 端点列表
+// GET /api/v1/admin/webhooks
+func (h *WebhookHandler) ListWebhooks(c *gin.Context) {
+	webhooks, err := h.webhookService.ListEndpoints(c.Request.Context())
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "Webhooks retrieved successfully",
+		"data":    webhooks,
+	})
+}
+
+// GetWebhook 获取 webhook 端点
+// GET /api/v1/admin/webhooks/:id
+func (h *WebhookHandler) GetWebhook(c *gin.Context) {
+	id, ok := parseIDParam(c, "id", "Invalid webhook ID")
+	if !ok {
+		return
+	}
+
+	webhook, err := h.webhookService.GetEndpoint(c.Request.Context(), query.NewGetWebhookQuery(id))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "Webhook retrieved successfully",
+		"data":    webhook,
+	})
+}
+
+// UpdateWebhook 更新 webhook 端点
+// PUT /api/v1/admin/webhooks/:id
+func (h *WebhookHandler) UpdateWebhook(c *gin.Context) {
+	id, ok := parseIDParam(c, "id", "Invalid webhook ID")
+	if !ok {
+		return
+	}
+
+	var req dto.UpdateWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "Invalid request",
+		})
+		return
+	}
+
+	cmd := command.NewUpdateWebhookCommand(id, req.URL, req.EventTypes, req.Active)
+	webhook, err := h.webhookService.UpdateEndpoint(middleware.RequestContext(c), cmd)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "Webhook updated successfully",
+		"data":    webhook,
+	})
+}
+
+// DeleteWebhook 删除 webhook 端点
+// DELETE /api/v1/admin/webhooks/:id
+func (h *WebhookHandler) DeleteWebhook(c *gin.Context) {
+	id, ok := parseIDParam(c, "id", "Invalid webhook ID")
+	if !ok {
+		return
+	}
+
+	if err := h.webhookService.DeleteEndpoint(middleware.RequestContext(c), command.NewDeleteWebhookCommand(id)); err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "Webhook deleted successfully",
+	})
+}
+
+// ListDeliveries 查询投递日志
+// GET /api/v1/admin/webhooks/deliveries
+func (h *WebhookHandler) ListDeliveries(c *gin.Context) {
+	var req dto.ListWebhookDeliveriesRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "Invalid request",
+		})
+		return
+	}
+
+	page := dto.PaginationRequest{Page: req.Page, PageSize: req.PageSize}
+	q := query.NewListWebhookDeliveriesQuery(req.EndpointID, req.EventType, req.Status, page.GetOffset(), page.GetLimit())
+	deliveries, err := h.webhookService.ListDeliveries(c.Request.Context(), q)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "Deliveries retrieved successfully",
+		"data":    deliveries,
+	})
+}
+
+// ReplayDelivery 重新投递
+// POST /api/v1/admin/webhooks/deliveries/:id/replay
+func (h *WebhookHandler) ReplayDelivery(c *gin.Context) {
+	id, ok := parseIDParam(c, "id", "Invalid delivery ID")
+	if !ok {
+		return
+	}
+
+	delivery, err := h.webhookService.ReplayDelivery(middleware.RequestContext(c), command.NewReplayWebhookDeliveryCommand(id))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": "Delivery scheduled for replay",
+		"data":    delivery,
+	})
+}
+
+func (h *WebhookHandler) handleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, repository.ErrWebhookEndpointNotFound), errors.Is(err, repository.ErrWebhookDeliveryNotFound):
+		c.JSON(http.StatusNotFound, gin.H{
+			"code":    404,
+			"message": err.Error(),
+		})
+	case errors.Is(err, service.ErrUnknownEventType):
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": err.Error(),
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": "Internal server error",
+		})
+	}
+}
+
+// parseIDParam 解析路径中的数字ID，失败时直接返回 400
+func parseIDParam(c *gin.Context, name, message string) (uint64, bool) {
+	id, err := strconv.ParseUint(c.Param(name), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": message,
+		})
+		return 0, false
+	}
+	return id, true
+}
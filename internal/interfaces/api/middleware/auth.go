@@ -1,54 +1,111 @@
 package middleware
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"net/http"
 	"strings"
 	"time"
+	"yiwen/go-ddd/internal/application/authz"
+	"yiwen/go-ddd/internal/application/dto"
+	"yiwen/go-ddd/internal/domain/entity"
+	"yiwen/go-ddd/internal/infrastructure/jwtkeys"
 
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 )
 
 type JWTClaims struct {
-	UserID   uint64 `json:"user_id"`
-	Username string `json:"username"`
-	Role     string `json:"role"`
+	UserID       uint64   `json:"user_id"`
+	Username     string   `json:"username"`
+	Roles        []string `json:"roles,omitempty"` // 签发时用户的角色，权限在请求时根据最新的角色判断
+	TokenVersion int      `json:"ver"`
+	SessionID    string   `json:"sid,omitempty"`       // 自身登录签发的令牌所属的会话
+	ClientID     string   `json:"client_id,omitempty"` // 通过 OAuth2 签发的令牌所属的客户端
+	Scope        string   `json:"scope,omitempty"`     // OAuth2 授权的权限范围，以空格分隔
+	Purpose      string   `json:"purpose,omitempty"`   // 一次性操作令牌的用途，访问令牌为空
+	TokenUse     string   `json:"token_use,omitempty"` // ID Token 为 id，访问令牌为空
 	jwt.RegisteredClaims
 }
 
+// RevocationChecker 判断访问令牌是否已被服务端撤销，由认证应用服务实现
+type RevocationChecker interface {
+	IsTokenRevoked(ctx context.Context, tokenID string, userID uint64, tokenVersion int, sessionID string) (bool, error)
+}
+
+// APIKeyAuthenticator 校验 API 密钥，由 API 密钥应用服务实现，密钥无效时返回 nil
+type APIKeyAuthenticator interface {
+	AuthenticateAPIKey(ctx context.Context, key string) (*dto.APIKeyPrincipalDTO, error)
+}
+
+// Authorizer 按授权策略判断 context 中的主体是否可以执行操作，由 authz.Authorizer 实现
+type Authorizer interface {
+	Authorize(ctx context.Context, policy authz.Policy, ownerID uint64) error
+}
+
 type JWTAuth struct {
-	secret     string
-	expireHour int
+	keys       *jwtkeys.KeySet
+	expire     time.Duration
 	issuser    string
+	revocation RevocationChecker
+	apiKeys    APIKeyAuthenticator
+	authorizer Authorizer
 }
 
-func NewJWTAuth(secret string, expireHour int, issuer string) *JWTAuth {
+// NewJWTAuth 创建 JWT 认证，expire 为访问令牌有效期，过期后通过刷新令牌换取新的访问令牌
+func NewJWTAuth(keys *jwtkeys.KeySet, expire time.Duration, issuer string) *JWTAuth {
 	return &JWTAuth{
-		secret:     secret,
-		expireHour: expireHour,
-		issuser:    issuer,
+		keys:    keys,
+		expire:  expire,
+		issuser: issuer,
 	}
 }
 
-func (j *JWTAuth) GenerateToken(userID uint64, username, role string) (string, int64, error) {
-	expiresAt := time.Now().Add(time.Hour * time.Duration(j.expireHour)).Unix()
+// SetRevocationChecker 设置撤销检查，认证应用服务依赖 JWTAuth 签发令牌，因此只能在创建之后注入
+func (j *JWTAuth) SetRevocationChecker(checker RevocationChecker) {
+	j.revocation = checker
+}
+
+// SetAPIKeyAuthenticator 设置 API 密钥认证，设置后 AuthMiddleware 同时接受 API 密钥
+func (j *JWTAuth) SetAPIKeyAuthenticator(authenticator APIKeyAuthenticator) {
+	j.apiKeys = authenticator
+}
+
+// SetAuthorizer 设置授权器，未设置时 RequirePermission 拒绝所有请求
+func (j *JWTAuth) SetAuthorizer(authorizer Authorizer) {
+	j.authorizer = authorizer
+}
+
+// GenerateToken 签发访问令牌，每个令牌带有唯一的 jti 和签发时用户的令牌版本
+// 自身登录签发时带上会话ID；通过 OAuth2 签发时带上客户端标识和权限范围，客户端凭证令牌的 userID 为 0，sub 为客户端标识
+func (j *JWTAuth) GenerateToken(userID uint64, username string, roles []string, tokenVersion int, sessionID, clientID, scope string) (string, int64, error) {
+	expiresAt := time.Now().Add(j.expire).Unix()
 
 	claims := JWTClaims{
-		UserID:   userID,
-		Username: username,
-		Role:     role,
+		UserID:       userID,
+		Username:     username,
+		Roles:        roles,
+		TokenVersion: tokenVersion,
+		SessionID:    sessionID,
+		ClientID:     clientID,
+		Scope:        scope,
 		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.New().String(),
 			ExpiresAt: jwt.NewNumericDate(time.Unix(expiresAt, 0)),
 			Issuer:    j.issuser,
 			Subject:   fmt.Sprintf("%d", userID),
 		},
 	}
+	if clientID != "" {
+		claims.Audience = jwt.ClaimStrings{clientID}
+	}
+	if userID == 0 {
+		claims.Subject = clientID
+	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-
-	tokenString, err := token.SignedString([]byte(j.secret))
+	tokenString, err := j.keys.Sign(claims)
 	if err != nil {
 		return "", 0, err
 	}
@@ -56,10 +113,78 @@ func (j *JWTAuth) GenerateToken(userID uint64, username, role string) (string, i
 	return tokenString, expiresAt, nil
 }
 
-func (j *JWTAuth) ParseToken(tokenString string) (*JWTClaims, error) {
-	token, err := jwt.ParseWithClaims(tokenString, &JWTClaims{}, func(token *jwt.Token) (interface{}, error) {
-		return []byte(j.secret), nil
+// idTokenUse ID Token 的 token_use 声明，ParseToken 据此拒绝把 ID Token 当作访问令牌使用
+const idTokenUse = "id"
+
+// GenerateIDToken 签发 OIDC ID Token，iss 与访问令牌相同，有效期与访问令牌一致
+// 头部 typ 为 id_token+jwt 并带有 token_use=id，不能作为访问令牌使用
+func (j *JWTAuth) GenerateIDToken(subject, audience, nonce string, claims map[string]interface{}) (string, error) {
+	now := time.Now()
+
+	idClaims := jwt.MapClaims{}
+	for k, v := range claims {
+		idClaims[k] = v
+	}
+	idClaims["iss"] = j.issuser
+	idClaims["sub"] = subject
+	idClaims["aud"] = audience
+	idClaims["iat"] = now.Unix()
+	idClaims["exp"] = now.Add(j.expire).Unix()
+	idClaims["token_use"] = idTokenUse
+	if nonce != "" {
+		idClaims["nonce"] = nonce
+	}
+
+	return j.keys.SignWithType(idClaims, "id_token+jwt")
+}
+
+// GenerateActionToken 签发一次性操作令牌（如邮箱验证），与访问令牌使用相同的密钥，通过 purpose 区分用途
+func (j *JWTAuth) GenerateActionToken(purpose string, userID uint64, tokenID string, expiresAt time.Time) (string, error) {
+	return j.keys.Sign(JWTClaims{
+		UserID:  userID,
+		Purpose: purpose,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        tokenID,
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			Issuer:    j.issuser,
+			Subject:   fmt.Sprintf("%d", userID),
+		},
 	})
+}
+
+// ParseActionToken 校验一次性操作令牌的签名、有效期和用途，返回令牌ID和所属用户
+func (j *JWTAuth) ParseActionToken(purpose, tokenString string) (string, uint64, error) {
+	claims, err := j.parseClaims(tokenString)
+	if err != nil {
+		return "", 0, err
+	}
+	if claims.Purpose == "" || claims.Purpose != purpose || claims.ID == "" {
+		return "", 0, errors.New("invalid token purpose")
+	}
+	return claims.ID, claims.UserID, nil
+}
+
+// Issuer 令牌签发者，同时作为 OIDC issuer
+func (j *JWTAuth) Issuer() string {
+	return j.issuser
+}
+
+// ParseToken 解析访问令牌，一次性操作令牌和 ID Token 不能作为访问令牌使用
+// 访问令牌都带有 jti，没有 jti 的令牌无法撤销，同样拒绝
+func (j *JWTAuth) ParseToken(tokenString string) (*JWTClaims, error) {
+	claims, err := j.parseClaims(tokenString)
+	if err != nil {
+		return nil, err
+	}
+	if claims.Purpose != "" || claims.TokenUse != "" || claims.ID == "" {
+		return nil, errors.New("invalid token")
+	}
+	return claims, nil
+}
+
+func (j *JWTAuth) parseClaims(tokenString string) (*JWTClaims, error) {
+	token, err := jwt.ParseWithClaims(tokenString, &JWTClaims{}, j.keys.Keyfunc)
 
 	if err != nil {
 		return nil, err
@@ -98,6 +223,11 @@ func (j *JWTAuth) AuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
+		if j.apiKeys != nil && strings.HasPrefix(parts[1], entity.APIKeyPrefix) {
+			j.authenticateAPIKey(c, parts[1])
+			return
+		}
+
 		claims, err := j.ParseToken(parts[1])
 		if err != nil {
 			c.JSON(http.StatusUnauthorized, gin.H{
@@ -108,17 +238,108 @@ func (j *JWTAuth) AuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
+		if j.revocation != nil {
+			revoked, err := j.revocation.IsTokenRevoked(c.Request.Context(), claims.ID, claims.UserID, claims.TokenVersion, claims.SessionID)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{
+					"code":    http.StatusInternalServerError,
+					"message": "Internal server error",
+				})
+				c.Abort()
+				return
+			}
+			if revoked {
+				c.JSON(http.StatusUnauthorized, gin.H{
+					"code":    http.StatusUnauthorized,
+					"message": "Token revoked",
+				})
+				c.Abort()
+				return
+			}
+		}
+
 		c.Set("user_id", claims.UserID)
 		c.Set("username", claims.Username)
-		c.Set("role", claims.Role)
+		c.Set("roles", claims.Roles)
+		c.Set("token_id", claims.ID)
+		c.Set("session_id", claims.SessionID)
+		c.Set("client_id", claims.ClientID)
+		c.Set("scope", claims.Scope)
+		if claims.ExpiresAt != nil {
+			c.Set("token_expires_at", claims.ExpiresAt.Time)
+		}
 		c.Next()
 	}
 }
 
-func (j *JWTAuth) AdminMiddleware() gin.HandlerFunc {
+// authenticateAPIKey 使用 API 密钥认证，密钥代表所属用户，权限范围为密钥创建时选择的范围
+func (j *JWTAuth) authenticateAPIKey(c *gin.Context, key string) {
+	principal, err := j.apiKeys.AuthenticateAPIKey(c.Request.Context(), key)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    http.StatusInternalServerError,
+			"message": "Internal server error",
+		})
+		c.Abort()
+		return
+	}
+	if principal == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"code":    http.StatusUnauthorized,
+			"message": "Invalid API key",
+		})
+		c.Abort()
+		return
+	}
+
+	c.Set("user_id", principal.UserID)
+	c.Set("username", principal.Username)
+	c.Set("roles", principal.Roles)
+	c.Set("api_key_id", principal.KeyID)
+	c.Set("scope", strings.Join(principal.Scopes, " "))
+	c.Next()
+}
+
+// RequirePermission 要求当前用户拥有指定权限，需要在 AuthMiddleware 之后使用
+// 用于没有经过应用服务授权策略的接口；权限根据用户当前的角色判断，角色变更后立即生效，不需要重新签发令牌
+func (j *JWTAuth) RequirePermission(permission string) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		role, exists := c.Get("role")
-		if !exists || role != "admin" {
+		if j.authorizer == nil {
+			c.JSON(http.StatusForbidden, gin.H{
+				"code":    http.StatusForbidden,
+				"message": "Forbidden",
+			})
+			c.Abort()
+			return
+		}
+
+		err := j.authorizer.Authorize(RequestContext(c), authz.Require(permission), 0)
+		if errors.Is(err, authz.ErrUnauthenticated) || errors.Is(err, authz.ErrForbidden) {
+			c.JSON(http.StatusForbidden, gin.H{
+				"code":    http.StatusForbidden,
+				"message": "Forbidden",
+			})
+			c.Abort()
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"code":    http.StatusInternalServerError,
+				"message": "Internal server error",
+			})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// RequireFirstParty 只允许用户自身登录签发的令牌，拒绝 OAuth2 令牌和 API 密钥，需要在 AuthMiddleware 之后使用
+// 用于通行密钥、两步验证、会话、API 密钥和 OAuth2 授权等账户安全相关的接口，避免被代表用户的凭证长期接管账户
+func (j *JWTAuth) RequireFirstParty() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		principal, ok := principalFromGin(c)
+		if !ok || principal.UserID == 0 || principal.Delegated {
 			c.JSON(http.StatusForbidden, gin.H{
 				"code":    http.StatusForbidden,
 				"message": "Forbidden",
@@ -145,3 +366,37 @@ func GetUsernameFromContext(c *gin.Context) (string, bool) {
 	}
 	return username.(string), true
 }
+
+// GetTokenFromContext 返回当前访问令牌的 jti 和过期时间
+func GetTokenFromContext(c *gin.Context) (string, time.Time, bool) {
+	tokenID, exists := c.Get("token_id")
+	if !exists {
+		return "", time.Time{}, false
+	}
+	expiresAt, _ := c.Get("token_expires_at")
+	t, _ := expiresAt.(time.Time)
+	return tokenID.(string), t, true
+}
+
+// GetSessionIDFromContext 返回访问令牌所属的会话ID，OAuth2 令牌和 API 密钥为空
+func GetSessionIDFromContext(c *gin.Context) string {
+	sessionID, _ := c.Get("session_id")
+	s, _ := sessionID.(string)
+	return s
+}
+
+// GetAPIKeyIDFromContext 返回使用 API 密钥认证时的密钥ID
+func GetAPIKeyIDFromContext(c *gin.Context) (uint64, bool) {
+	keyID, exists := c.Get("api_key_id")
+	if !exists {
+		return 0, false
+	}
+	return keyID.(uint64), true
+}
+
+// GetScopeFromContext 返回 OAuth2 访问令牌或 API 密钥的权限范围，自身登录签发的令牌为空
+func GetScopeFromContext(c *gin.Context) string {
+	scope, _ := c.Get("scope")
+	s, _ := scope.(string)
+	return s
+}
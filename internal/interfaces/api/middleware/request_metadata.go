@@ -0,0 +1,54 @@
+package middleware
+
+import (
+	"context"
+	"strings"
+	"yiwen/go-ddd/internal/application/authz"
+	"yiwen/go-ddd/internal/domain/event"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequestContext 返回携带事件元数据和主体的请求 context
+// 元数据包含当前登录用户和请求来源，应用服务保存聚合时会附加到领域事件上，用于审计
+// 已认证的请求同时带有主体，应用服务据此按授权策略校验
+func RequestContext(c *gin.Context) context.Context {
+	meta := event.Metadata{
+		IP:        c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	}
+	if userID, ok := GetUserIDFromContext(c); ok {
+		meta.ActorID = userID
+	}
+	if username, ok := GetUsernameFromContext(c); ok {
+		meta.ActorName = username
+	}
+	ctx := event.ContextWithMetadata(c.Request.Context(), meta)
+	if principal, ok := principalFromGin(c); ok {
+		ctx = authz.ContextWithPrincipal(ctx, principal)
+	}
+	return ctx
+}
+
+// principalFromGin 根据 AuthMiddleware 写入的认证信息构造主体
+// OAuth2 令牌和 API 密钥代表用户操作，受权限范围限制
+func principalFromGin(c *gin.Context) (authz.Principal, bool) {
+	userID, ok := GetUserIDFromContext(c)
+	if !ok {
+		return authz.Principal{}, false
+	}
+
+	username, _ := GetUsernameFromContext(c)
+	roles, _ := c.Get("roles")
+	clientID, _ := c.Get("client_id")
+	_, isAPIKey := GetAPIKeyIDFromContext(c)
+
+	principal := authz.Principal{
+		UserID:    userID,
+		Username:  username,
+		Scopes:    strings.Fields(GetScopeFromContext(c)),
+		Delegated: isAPIKey || clientID != nil && clientID != "",
+	}
+	principal.Roles, _ = roles.([]string)
+	return principal, true
+}
@@ -2,6 +2,7 @@ package router
 
 import (
 	"net/http"
+	"yiwen/go-ddd/internal/domain/entity"
 	"yiwen/go-ddd/internal/interfaces/api/handler"
 	"yiwen/go-ddd/internal/interfaces/api/middleware"
 
@@ -9,16 +10,44 @@ import (
 )
 
 type Router struct {
-	engine      *gin.Engine
-	userHandler *handler.UserHandler
-	jwtAuth     *middleware.JWTAuth
+	engine               *gin.Engine
+	userHandler          *handler.UserHandler
+	webhookHandler       *handler.WebhookHandler
+	eventStreamHandler   *handler.EventStreamHandler
+	auditHandler         *handler.AuditHandler
+	authHandler          *handler.AuthHandler
+	jwksHandler          *handler.JWKSHandler
+	oauthHandler         *handler.OAuthHandler
+	oidcHandler          *handler.OIDCHandler
+	ssoHandler           *handler.ExternalLoginHandler
+	twoFactorHandler     *handler.TwoFactorHandler
+	passkeyHandler       *handler.PasskeyHandler
+	apiKeyHandler        *handler.APIKeyHandler
+	verificationHandler  *handler.EmailVerificationHandler
+	passwordResetHandler *handler.PasswordResetHandler
+	roleHandler          *handler.RoleHandler
+	jwtAuth              *middleware.JWTAuth
 }
 
-func NewRouter(userHandler *handler.UserHandler, jwtAuth *middleware.JWTAuth) *Router {
+func NewRouter(userHandler *handler.UserHandler, webhookHandler *handler.WebhookHandler, eventStreamHandler *handler.EventStreamHandler, auditHandler *handler.AuditHandler, authHandler *handler.AuthHandler, jwksHandler *handler.JWKSHandler, oauthHandler *handler.OAuthHandler, oidcHandler *handler.OIDCHandler, ssoHandler *handler.ExternalLoginHandler, twoFactorHandler *handler.TwoFactorHandler, passkeyHandler *handler.PasskeyHandler, apiKeyHandler *handler.APIKeyHandler, verificationHandler *handler.EmailVerificationHandler, passwordResetHandler *handler.PasswordResetHandler, roleHandler *handler.RoleHandler, jwtAuth *middleware.JWTAuth) *Router {
 	return &Router{
-		engine:      gin.New(),
-		userHandler: userHandler,
-		jwtAuth:     jwtAuth,
+		engine:               gin.New(),
+		userHandler:          userHandler,
+		webhookHandler:       webhookHandler,
+		eventStreamHandler:   eventStreamHandler,
+		auditHandler:         auditHandler,
+		authHandler:          authHandler,
+		jwksHandler:          jwksHandler,
+		oauthHandler:         oauthHandler,
+		oidcHandler:          oidcHandler,
+		ssoHandler:           ssoHandler,
+		twoFactorHandler:     twoFactorHandler,
+		passkeyHandler:       passkeyHandler,
+		apiKeyHandler:        apiKeyHandler,
+		verificationHandler:  verificationHandler,
+		passwordResetHandler: passwordResetHandler,
+		roleHandler:          roleHandler,
+		jwtAuth:              jwtAuth,
 	}
 }
 
@@ -34,16 +63,63 @@ func (r *Router) Setup() *gin.Engine {
 		})
 	})
 
+	r.engine.GET("/.well-known/jwks.json", r.jwksHandler.GetJWKS)
+	r.engine.GET("/.well-known/openid-configuration", r.oidcHandler.Discovery)
+
+	// OAuth2 授权服务，同意授权只接受用户自身登录的令牌
+	oauth := r.engine.Group("/oauth")
+	{
+		oauth.GET("/authorize", r.jwtAuth.AuthMiddleware(), r.jwtAuth.RequireFirstParty(), r.oauthHandler.Authorize)
+		oauth.POST("/authorize", r.jwtAuth.AuthMiddleware(), r.jwtAuth.RequireFirstParty(), r.oauthHandler.Consent)
+		oauth.POST("/token", r.oauthHandler.Token)
+	}
+	r.engine.GET("/userinfo", r.jwtAuth.AuthMiddleware(), r.oidcHandler.UserInfo)
+	r.engine.POST("/userinfo", r.jwtAuth.AuthMiddleware(), r.oidcHandler.UserInfo)
+
 	v1 := r.engine.Group("/api/v1")
 	{
+		auth := v1.Group("/auth")
+		{
+			auth.POST("/refresh", r.authHandler.Refresh)
+			auth.POST("/logout", r.jwtAuth.AuthMiddleware(), r.authHandler.Logout)
+
+			// 外部身份提供方登录
+			auth.GET("/sso/providers", r.ssoHandler.ListProviders)
+			auth.GET("/sso/:provider/login", r.ssoHandler.Login)
+			auth.GET("/sso/:provider/callback", r.ssoHandler.Callback)
+
+			// 两步验证，verify 使用密码登录返回的 MFA 令牌，不需要访问令牌
+			// 通行密钥、两步验证、会话和 API 密钥只能通过用户自身登录的令牌管理，OAuth2 令牌和 API 密钥不能访问
+			auth.POST("/2fa/verify", r.twoFactorHandler.Verify)
+			twoFactor := auth.Group("/2fa")
+			twoFactor.Use(r.jwtAuth.AuthMiddleware(), r.jwtAuth.RequireFirstParty())
+			{
+				twoFactor.GET("", r.twoFactorHandler.Status)
+				twoFactor.POST("/setup", r.twoFactorHandler.Setup)
+				twoFactor.POST("/confirm", r.twoFactorHandler.Confirm)
+				twoFactor.POST("/disable", r.twoFactorHandler.Disable)
+				twoFactor.POST("/recovery-codes", r.twoFactorHandler.RegenerateRecoveryCodes)
+			}
+
+			// 通行密钥登录，不需要用户名和密码
+			auth.POST("/passkeys/login/begin", r.passkeyHandler.BeginLogin)
+			auth.POST("/passkeys/login/finish", r.passkeyHandler.FinishLogin)
+		}
+
 		users := v1.Group("/users")
 		{
 			users.POST("/register", r.userHandler.Register)
 			users.POST("/login", r.userHandler.Login)
+			users.POST("/verify-email", r.verificationHandler.VerifyEmail)
+			users.POST("/resend-verification", r.verificationHandler.ResendVerification)
+			users.POST("/forgot-password", r.passwordResetHandler.ForgotPassword)
+			users.POST("/reset-password", r.passwordResetHandler.ResetPassword)
 
+			// 所有者或管理员的授权由用户应用服务的授权策略负责
 			authUsers := users.Group("")
-			authUsers.Use(r.jwtAuth.AdminMiddleware())
+			authUsers.Use(r.jwtAuth.AuthMiddleware())
 			{
+				authUsers.GET("/", r.userHandler.ListUsers)
 				authUsers.GET("/:id", r.userHandler.GetUser)
 				authUsers.PUT("/:id", r.userHandler.UpdateProfile)
 				authUsers.PUT("/:id/change-password", r.userHandler.ChangePassword)
@@ -51,13 +127,79 @@ func (r *Router) Setup() *gin.Engine {
 				authUsers.GET("/me", r.userHandler.GetCurrentUser)
 			}
 
-			//管理员接口
-			adminUsers := users.Group("")
-			adminUsers.Use(r.jwtAuth.AuthMiddleware())
-			adminUsers.Use(r.jwtAuth.AdminMiddleware())
+			// 当前用户的通行密钥
+			passkeys := users.Group("/me/passkeys")
+			passkeys.Use(r.jwtAuth.AuthMiddleware(), r.jwtAuth.RequireFirstParty())
+			{
+				passkeys.GET("", r.passkeyHandler.ListPasskeys)
+				passkeys.POST("/register/begin", r.passkeyHandler.BeginRegistration)
+				passkeys.POST("/register/finish", r.passkeyHandler.FinishRegistration)
+				passkeys.DELETE("/:id", r.passkeyHandler.RevokePasskey)
+			}
+
+			// 当前用户的登录会话
+			sessions := users.Group("/me/sessions")
+			sessions.Use(r.jwtAuth.AuthMiddleware(), r.jwtAuth.RequireFirstParty())
+			{
+				sessions.GET("", r.authHandler.ListSessions)
+				sessions.DELETE("/:id", r.authHandler.RevokeSession)
+			}
+
+			// 当前用户的 API 密钥
+			apiKeys := users.Group("/me/api-keys")
+			apiKeys.Use(r.jwtAuth.AuthMiddleware(), r.jwtAuth.RequireFirstParty())
+			{
+				apiKeys.GET("", r.apiKeyHandler.ListAPIKeys)
+				apiKeys.POST("", r.apiKeyHandler.CreateAPIKey)
+				apiKeys.DELETE("/:id", r.apiKeyHandler.RevokeAPIKey)
+			}
+		}
+
+		// 管理后台接口，每个接口要求对应的权限
+		admin := v1.Group("/admin")
+		admin.Use(r.jwtAuth.AuthMiddleware())
+		{
+			admin.POST("/users/:id/ban", r.jwtAuth.RequirePermission(entity.PermissionUsersBan), r.userHandler.BanUser)
+			admin.POST("/users/:id/promote", r.jwtAuth.RequirePermission(entity.PermissionRolesAssign), r.userHandler.PromoteUser)
+			admin.POST("/users/:id/unlock", r.jwtAuth.RequirePermission(entity.PermissionUsersUnlock), r.userHandler.UnlockUser)
+			admin.DELETE("/users/:id/2fa", r.jwtAuth.RequirePermission(entity.PermissionUsersReset2FA), r.twoFactorHandler.ResetTwoFactor)
+			admin.DELETE("/users/:id/sessions", r.jwtAuth.RequirePermission(entity.PermissionUsersRevokeSessions), r.authHandler.RevokeUserSessions)
+			admin.POST("/users/:id/roles", r.jwtAuth.RequirePermission(entity.PermissionRolesAssign), r.roleHandler.AssignRole)
+			admin.DELETE("/users/:id/roles/:role", r.jwtAuth.RequirePermission(entity.PermissionRolesAssign), r.roleHandler.RevokeRole)
+
+			admin.GET("/permissions", r.jwtAuth.RequirePermission(entity.PermissionRolesRead), r.roleHandler.ListPermissions)
+			roles := admin.Group("/roles")
+			{
+				roles.GET("", r.jwtAuth.RequirePermission(entity.PermissionRolesRead), r.roleHandler.ListRoles)
+				roles.GET("/:id", r.jwtAuth.RequirePermission(entity.PermissionRolesRead), r.roleHandler.GetRole)
+				roles.POST("", r.jwtAuth.RequirePermission(entity.PermissionRolesManage), r.roleHandler.CreateRole)
+				roles.PUT("/:id", r.jwtAuth.RequirePermission(entity.PermissionRolesManage), r.roleHandler.UpdateRole)
+				roles.DELETE("/:id", r.jwtAuth.RequirePermission(entity.PermissionRolesManage), r.roleHandler.DeleteRole)
+			}
+
+			webhooks := admin.Group("/webhooks")
+			webhooks.Use(r.jwtAuth.RequirePermission(entity.PermissionWebhooksManage))
+			{
+				webhooks.POST("", r.webhookHandler.CreateWebhook)
+				webhooks.GET("", r.webhookHandler.ListWebhooks)
+				webhooks.GET("/deliveries", r.webhookHandler.ListDeliveries)
+				webhooks.POST("/deliveries/:id/replay", r.webhookHandler.ReplayDelivery)
+				webhooks.GET("/:id", r.webhookHandler.GetWebhook)
+				webhooks.PUT("/:id", r.webhookHandler.UpdateWebhook)
+				webhooks.DELETE("/:id", r.webhookHandler.DeleteWebhook)
+			}
+
+			admin.GET("/events/stream", r.jwtAuth.RequirePermission(entity.PermissionEventsStream), r.eventStreamHandler.Stream)
+
+			admin.GET("/audit", r.jwtAuth.RequirePermission(entity.PermissionAuditRead), r.auditHandler.ListAuditLogs)
+			admin.GET("/audit/export", r.jwtAuth.RequirePermission(entity.PermissionAuditRead), r.auditHandler.ExportAuditLogs)
+
+			oauthClients := admin.Group("/oauth/clients")
+			oauthClients.Use(r.jwtAuth.RequirePermission(entity.PermissionOAuthClientsManage))
 			{
-				adminUsers.GET("/", r.userHandler.ListUsers)
-				adminUsers.DELETE("/:id", r.userHandler.DeleteUser)
+				oauthClients.POST("", r.oauthHandler.CreateClient)
+				oauthClients.GET("", r.oauthHandler.ListClients)
+				oauthClients.DELETE("/:client_id", r.oauthHandler.DeleteClient)
 			}
 		}
 	}
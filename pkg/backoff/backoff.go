@@ -0,0 +1,18 @@
+package backoff
+
+import "time"
+
+// Exponential 计算第 attempts 次失败后的等待时间：base * 2^(attempts-1)，不超过 max
+func Exponential(base, max time.Duration, attempts int) time.Duration {
+	d := base
+	for i := 1; i < attempts; i++ {
+		d *= 2
+		if d >= max {
+			return max
+		}
+	}
+	if d > max {
+		return max
+	}
+	return d
+}
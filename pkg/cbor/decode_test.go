@@ -0,0 +1,144 @@
+package cbor
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func mustHex(t testing.TB, s string) []byte {
+	t.Helper()
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+// TestDecode 用例来自 RFC 8949 附录 A
+func TestDecode(t *testing.T) {
+	tests := []struct {
+		hex  string
+		want interface{}
+	}{
+		{"00", int64(0)},
+		{"17", int64(23)},
+		{"1818", int64(24)},
+		{"1903e8", int64(1000)},
+		{"1a000f4240", int64(1000000)},
+		{"1b000000e8d4a51000", int64(1000000000000)},
+		{"20", int64(-1)},
+		{"3903e7", int64(-1000)},
+		{"40", []byte(nil)}, // 空字节串解码为 nil 切片
+		{"4401020304", []byte{1, 2, 3, 4}},
+		{"60", ""},
+		{"6449455446", "IETF"},
+		{"80", []interface{}{}},
+		{"83010203", []interface{}{int64(1), int64(2), int64(3)}},
+		{"8301820203820405", []interface{}{int64(1), []interface{}{int64(2), int64(3)}, []interface{}{int64(4), int64(5)}}},
+		{"a0", map[interface{}]interface{}{}},
+		{"a201020304", map[interface{}]interface{}{int64(1): int64(2), int64(3): int64(4)}},
+		{"a26161016162820203", map[interface{}]interface{}{"a": int64(1), "b": []interface{}{int64(2), int64(3)}}},
+		{"f4", false},
+		{"f5", true},
+		{"f6", nil},
+	}
+
+	for _, tt := range tests {
+		got, rest, err := Decode(mustHex(t, tt.hex))
+		if err != nil {
+			t.Errorf("%s: %v", tt.hex, err)
+			continue
+		}
+		if len(rest) != 0 {
+			t.Errorf("%s: %d trailing bytes", tt.hex, len(rest))
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: got %#v, want %#v", tt.hex, got, tt.want)
+		}
+	}
+}
+
+func TestDecodeReturnsRemainingBytes(t *testing.T) {
+	_, rest, err := Decode(mustHex(t, "0102ff"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(rest, []byte{0x02, 0xff}) {
+		t.Errorf("rest = %x", rest)
+	}
+}
+
+func TestDecodeRejectsMalformed(t *testing.T) {
+	tests := []struct {
+		name string
+		hex  string
+		err  error
+	}{
+		{"empty", "", ErrUnexpectedEnd},
+		{"truncated argument", "19ff", ErrUnexpectedEnd},
+		{"truncated byte string", "4401", ErrUnexpectedEnd},
+		{"truncated array", "830102", ErrUnexpectedEnd},
+		{"truncated map", "a201", ErrUnexpectedEnd},
+		{"huge byte string length", "5bffffffffffffffff", ErrUnexpectedEnd},
+		{"huge array length", "9bffffffffffffffff", ErrUnexpectedEnd},
+		{"huge map length", "bbffffffffffffffff", ErrUnexpectedEnd},
+		{"integer overflow", "1bffffffffffffffff", ErrUnsupported},
+		{"negative integer overflow", "3bffffffffffffffff", ErrUnsupported},
+		{"indefinite array", "9f01ff", ErrUnsupported},
+		{"indefinite map", "bf6161f5ff", ErrUnsupported},
+		{"reserved additional info", "1c", ErrUnsupported},
+		{"float", "f93c00", ErrUnsupported},
+		{"undefined", "f7", ErrUnsupported},
+		{"tag", "c11a514b67b0", ErrUnsupported},
+		{"array map key", "a1800f", nil},
+		{"duplicate map key", "a201020103", nil},
+		{"nesting too deep", "818181818181818181818181818181818100", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v, _, err := Decode(mustHex(t, tt.hex))
+			if err == nil {
+				t.Fatalf("decoded %#v, want error", v)
+			}
+			if tt.err != nil && !errors.Is(err, tt.err) {
+				t.Errorf("err = %v, want %v", err, tt.err)
+			}
+		})
+	}
+}
+
+// TestDecodeTruncatedNeverPanics 任意位置截断或修改字节都只能返回错误
+func TestDecodeTruncatedNeverPanics(t *testing.T) {
+	valid := mustHex(t, "a363666d74667061636b65646761747453746d74a263616c672663736967430102036861757468446174615825000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f0500000001")
+	if _, _, err := Decode(valid); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := range valid {
+		if _, _, err := Decode(valid[:i]); err == nil {
+			t.Errorf("truncated at %d: no error", i)
+		}
+		for _, b := range []byte{0x00, 0x1f, 0x5b, 0x9b, 0xbb, 0xff} {
+			mutated := bytes.Clone(valid)
+			mutated[i] = b
+			_, _, _ = Decode(mutated)
+		}
+	}
+}
+
+func FuzzDecode(f *testing.F) {
+	f.Add([]byte{0xa1, 0x01, 0x02})
+	f.Add([]byte{0x9b, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff})
+	f.Add(bytes.Repeat([]byte{0x81}, 64))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		_, rest, err := Decode(data)
+		if err == nil && len(rest) > len(data) {
+			t.Fatalf("rest longer than input")
+		}
+	})
+}
@@ -0,0 +1,77 @@
+package useragent
+
+import "strings"
+
+// UserAgent 从 User-Agent 中识别出的浏览器和操作系统
+// 只做展示用的粗略识别，识别不出时为空
+type UserAgent struct {
+	Browser string
+	OS      string
+}
+
+// 顺序很重要：Edge、Opera 的 UA 中同时带有 Chrome 和 Safari，Chrome 的 UA 中带有 Safari
+var browsers = []struct {
+	token string
+	name  string
+}{
+	{"Edg/", "Edge"},
+	{"OPR/", "Opera"},
+	{"SamsungBrowser/", "Samsung Internet"},
+	{"Firefox/", "Firefox"},
+	{"FxiOS/", "Firefox"},
+	{"CriOS/", "Chrome"},
+	{"Chrome/", "Chrome"},
+	{"Safari/", "Safari"},
+	{"curl/", "curl"},
+	{"Wget/", "Wget"},
+	{"PostmanRuntime/", "Postman"},
+	{"okhttp/", "OkHttp"},
+	{"python-requests/", "Python Requests"},
+	{"Go-http-client/", "Go HTTP Client"},
+}
+
+var systems = []struct {
+	token string
+	name  string
+}{
+	{"Windows", "Windows"},
+	{"iPhone", "iOS"},
+	{"iPad", "iPadOS"},
+	{"Android", "Android"},
+	{"CrOS", "ChromeOS"},
+	{"Mac OS X", "macOS"},
+	{"Macintosh", "macOS"},
+	{"Linux", "Linux"},
+}
+
+// Parse 识别 User-Agent
+func Parse(ua string) UserAgent {
+	var result UserAgent
+	for _, b := range browsers {
+		if strings.Contains(ua, b.token) {
+			result.Browser = b.name
+			break
+		}
+	}
+	for _, s := range systems {
+		if strings.Contains(ua, s.token) {
+			result.OS = s.name
+			break
+		}
+	}
+	return result
+}
+
+// String 返回形如 "Chrome on macOS" 的设备描述
+func (u UserAgent) String() string {
+	switch {
+	case u.Browser != "" && u.OS != "":
+		return u.Browser + " on " + u.OS
+	case u.Browser != "":
+		return u.Browser
+	case u.OS != "":
+		return u.OS
+	default:
+		return "Unknown device"
+	}
+}
@@ -0,0 +1,96 @@
+package webauthn
+
+import (
+	"bytes"
+	"crypto/x509"
+	"fmt"
+	"yiwen/go-ddd/pkg/cbor"
+)
+
+// attestationObject 注册时认证器返回的证明对象
+type attestationObject struct {
+	format      string
+	statement   map[interface{}]interface{}
+	rawAuthData []byte
+	authData    *AuthenticatorData
+}
+
+func parseAttestationObject(raw []byte) (*attestationObject, error) {
+	v, rest, err := cbor.Decode(raw)
+	if err != nil || len(rest) != 0 {
+		return nil, fmt.Errorf("%w: invalid attestation object", ErrVerificationFailed)
+	}
+	m, ok := v.(map[interface{}]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%w: invalid attestation object", ErrVerificationFailed)
+	}
+
+	format, _ := m["fmt"].(string)
+	statement, _ := m["attStmt"].(map[interface{}]interface{})
+	rawAuthData, _ := m["authData"].([]byte)
+	if format == "" || statement == nil || rawAuthData == nil {
+		return nil, fmt.Errorf("%w: incomplete attestation object", ErrVerificationFailed)
+	}
+
+	authData, err := ParseAuthenticatorData(rawAuthData)
+	if err != nil {
+		return nil, err
+	}
+	return &attestationObject{format: format, statement: statement, rawAuthData: rawAuthData, authData: authData}, nil
+}
+
+// verify 校验证明语句
+// 注册选项请求的是 attestation: none，这里只确认证明语句本身有效，不校验证书链是否可信
+// 支持 none 和 packed 两种格式，其他格式的认证器需要浏览器匿名化为 none
+func (o *attestationObject) verify(credentialKey *PublicKey, clientDataHash []byte) error {
+	switch o.format {
+	case "none":
+		if len(o.statement) != 0 {
+			return fmt.Errorf("%w: none attestation must have an empty statement", ErrVerificationFailed)
+		}
+		return nil
+	case "packed":
+		return o.verifyPacked(credentialKey, clientDataHash)
+	default:
+		return fmt.Errorf("%w: unsupported attestation format %q", ErrVerificationFailed, o.format)
+	}
+}
+
+// verifyPacked 校验 packed 格式，见 WebAuthn 8.2
+func (o *attestationObject) verifyPacked(credentialKey *PublicKey, clientDataHash []byte) error {
+	alg, ok := o.statement["alg"].(int64)
+	if !ok {
+		return fmt.Errorf("%w: packed attestation has no alg", ErrVerificationFailed)
+	}
+	sig, ok := o.statement["sig"].([]byte)
+	if !ok {
+		return fmt.Errorf("%w: packed attestation has no sig", ErrVerificationFailed)
+	}
+	signed := append(bytes.Clone(o.rawAuthData), clientDataHash...)
+
+	x5c, ok := o.statement["x5c"].([]interface{})
+	if !ok {
+		// 自证明：使用凭证私钥签名
+		if alg != credentialKey.Algorithm {
+			return fmt.Errorf("%w: self attestation algorithm mismatch", ErrVerificationFailed)
+		}
+		return credentialKey.Verify(signed, sig)
+	}
+
+	if len(x5c) == 0 {
+		return fmt.Errorf("%w: empty attestation certificate chain", ErrVerificationFailed)
+	}
+	der, ok := x5c[0].([]byte)
+	if !ok {
+		return fmt.Errorf("%w: invalid attestation certificate", ErrVerificationFailed)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return fmt.Errorf("%w: invalid attestation certificate: %v", ErrVerificationFailed, err)
+	}
+	attestationKey, err := newPublicKey(alg, cert.PublicKey)
+	if err != nil {
+		return err
+	}
+	return attestationKey.Verify(signed, sig)
+}
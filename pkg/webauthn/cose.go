@@ -0,0 +1,130 @@
+package webauthn
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"fmt"
+	"math/big"
+	"yiwen/go-ddd/pkg/cbor"
+)
+
+// 支持的 COSE 算法，见 RFC 9053 和 IANA COSE Algorithms
+const (
+	AlgES256 int64 = -7
+	AlgEdDSA int64 = -8
+	AlgRS256 int64 = -257
+)
+
+// SupportedAlgorithms 注册选项 pubKeyCredParams 中声明的算法，按优先级排列
+var SupportedAlgorithms = []int64{AlgES256, AlgEdDSA, AlgRS256}
+
+// COSE_Key 参数
+const (
+	coseKty    int64 = 1
+	coseAlg    int64 = 3
+	coseCrv    int64 = -1 // EC2/OKP 曲线
+	coseX      int64 = -2 // EC2/OKP x 坐标
+	coseY      int64 = -3 // EC2 y 坐标
+	coseRSAN   int64 = -1
+	coseRSAE   int64 = -2
+	ktyOKP     int64 = 1
+	ktyEC2     int64 = 2
+	ktyRSA     int64 = 3
+	crvP256    int64 = 1
+	crvEd25519 int64 = 6
+)
+
+// PublicKey 凭证公钥
+type PublicKey struct {
+	Algorithm int64
+	key       crypto.PublicKey
+}
+
+// ParsePublicKey 解析 COSE 编码的公钥，见 RFC 9052 第 7 章
+func ParsePublicKey(raw []byte) (*PublicKey, error) {
+	v, rest, err := cbor.Decode(raw)
+	if err != nil || len(rest) != 0 {
+		return nil, fmt.Errorf("%w: invalid cose key", ErrVerificationFailed)
+	}
+	m, ok := v.(map[interface{}]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%w: invalid cose key", ErrVerificationFailed)
+	}
+
+	kty, _ := m[coseKty].(int64)
+	alg, _ := m[coseAlg].(int64)
+
+	switch {
+	case kty == ktyEC2 && alg == AlgES256:
+		crv, _ := m[coseCrv].(int64)
+		x, _ := m[coseX].([]byte)
+		y, _ := m[coseY].([]byte)
+		if crv != crvP256 || len(x) != 32 || len(y) != 32 {
+			return nil, fmt.Errorf("%w: invalid ec2 key", ErrVerificationFailed)
+		}
+		// 通过非压缩格式解析，顺带校验点是否在曲线上
+		key, err := ecdsa.ParseUncompressedPublicKey(elliptic.P256(), append(append([]byte{4}, x...), y...))
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid ec2 key: %v", ErrVerificationFailed, err)
+		}
+		return &PublicKey{Algorithm: alg, key: key}, nil
+	case kty == ktyOKP && alg == AlgEdDSA:
+		crv, _ := m[coseCrv].(int64)
+		x, _ := m[coseX].([]byte)
+		if crv != crvEd25519 || len(x) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("%w: invalid okp key", ErrVerificationFailed)
+		}
+		return &PublicKey{Algorithm: alg, key: ed25519.PublicKey(x)}, nil
+	case kty == ktyRSA && alg == AlgRS256:
+		n, _ := m[coseRSAN].([]byte)
+		e, _ := m[coseRSAE].([]byte)
+		if len(n) < 256 || len(e) == 0 || len(e) > 4 {
+			return nil, fmt.Errorf("%w: invalid rsa key", ErrVerificationFailed)
+		}
+		return &PublicKey{Algorithm: alg, key: &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}}, nil
+	default:
+		return nil, fmt.Errorf("%w: unsupported key type %d with algorithm %d", ErrVerificationFailed, kty, alg)
+	}
+}
+
+// newPublicKey 使用证书中的公钥构造，要求密钥类型与算法一致
+func newPublicKey(alg int64, key crypto.PublicKey) (*PublicKey, error) {
+	switch k := key.(type) {
+	case *ecdsa.PublicKey:
+		if alg == AlgES256 && k.Curve == elliptic.P256() {
+			return &PublicKey{Algorithm: alg, key: k}, nil
+		}
+	case ed25519.PublicKey:
+		if alg == AlgEdDSA {
+			return &PublicKey{Algorithm: alg, key: k}, nil
+		}
+	case *rsa.PublicKey:
+		if alg == AlgRS256 {
+			return &PublicKey{Algorithm: alg, key: k}, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: certificate key does not match algorithm %d", ErrVerificationFailed, alg)
+}
+
+// Verify 校验签名，ES256 的签名为 ASN.1 DER 编码
+func (k *PublicKey) Verify(data, signature []byte) error {
+	var ok bool
+	switch key := k.key.(type) {
+	case *ecdsa.PublicKey:
+		digest := sha256.Sum256(data)
+		ok = ecdsa.VerifyASN1(key, digest[:], signature)
+	case ed25519.PublicKey:
+		ok = ed25519.Verify(key, data, signature)
+	case *rsa.PublicKey:
+		digest := sha256.Sum256(data)
+		ok = rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], signature) == nil
+	}
+	if !ok {
+		return fmt.Errorf("%w: invalid signature", ErrVerificationFailed)
+	}
+	return nil
+}
@@ -0,0 +1,234 @@
+package webauthn
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"yiwen/go-ddd/pkg/cbor"
+)
+
+// WebAuthn 依赖方（Relying Party）校验，见 W3C Web Authentication Level 2 第 7 章
+// 只实现服务端需要的部分：解析客户端数据、认证器数据和证明对象，并校验签名
+
+// ErrVerificationFailed 所有校验失败都包装该错误
+var ErrVerificationFailed = errors.New("webauthn verification failed")
+
+// 客户端数据中的仪式类型
+const (
+	CeremonyCreate = "webauthn.create"
+	CeremonyGet    = "webauthn.get"
+)
+
+// 认证器数据标志位
+const (
+	flagUserPresent            = 0x01
+	flagUserVerified           = 0x04
+	flagAttestedCredentialData = 0x40
+	flagExtensionData          = 0x80
+)
+
+// ClientData 浏览器生成的 clientDataJSON
+type ClientData struct {
+	Type        string `json:"type"`
+	Challenge   string `json:"challenge"`
+	Origin      string `json:"origin"`
+	CrossOrigin bool   `json:"crossOrigin"`
+}
+
+// ParseClientData 解析 clientDataJSON，调用方根据其中的 challenge 找到对应的仪式
+func ParseClientData(raw []byte) (*ClientData, error) {
+	var clientData ClientData
+	if err := json.Unmarshal(raw, &clientData); err != nil {
+		return nil, fmt.Errorf("%w: invalid client data: %v", ErrVerificationFailed, err)
+	}
+	return &clientData, nil
+}
+
+// AuthenticatorData 认证器数据
+type AuthenticatorData struct {
+	RPIDHash     []byte
+	Flags        byte
+	SignCount    uint32
+	AAGUID       []byte // 以下字段只在注册时存在
+	CredentialID []byte
+	PublicKey    []byte // COSE 编码的凭证公钥
+}
+
+func (a *AuthenticatorData) UserPresent() bool {
+	return a.Flags&flagUserPresent != 0
+}
+
+func (a *AuthenticatorData) UserVerified() bool {
+	return a.Flags&flagUserVerified != 0
+}
+
+// ParseAuthenticatorData 解析认证器数据，见 WebAuthn 6.1
+func ParseAuthenticatorData(raw []byte) (*AuthenticatorData, error) {
+	if len(raw) < 37 {
+		return nil, fmt.Errorf("%w: authenticator data too short", ErrVerificationFailed)
+	}
+
+	data := &AuthenticatorData{
+		RPIDHash:  raw[:32],
+		Flags:     raw[32],
+		SignCount: binary.BigEndian.Uint32(raw[33:37]),
+	}
+	rest := raw[37:]
+
+	if data.Flags&flagAttestedCredentialData != 0 {
+		if len(rest) < 18 {
+			return nil, fmt.Errorf("%w: attested credential data too short", ErrVerificationFailed)
+		}
+		data.AAGUID = rest[:16]
+		idLen := int(binary.BigEndian.Uint16(rest[16:18]))
+		rest = rest[18:]
+		if idLen == 0 || idLen > 1023 || len(rest) < idLen {
+			return nil, fmt.Errorf("%w: invalid credential id", ErrVerificationFailed)
+		}
+		data.CredentialID = rest[:idLen]
+		rest = rest[idLen:]
+
+		// 公钥是一个 CBOR 数据项，之后可能跟着扩展数据
+		_, remaining, err := cbor.Decode(rest)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid credential public key: %v", ErrVerificationFailed, err)
+		}
+		data.PublicKey = rest[:len(rest)-len(remaining)]
+		rest = remaining
+	}
+
+	if data.Flags&flagExtensionData != 0 {
+		_, remaining, err := cbor.Decode(rest)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid extension data: %v", ErrVerificationFailed, err)
+		}
+		rest = remaining
+	}
+	if len(rest) != 0 {
+		return nil, fmt.Errorf("%w: trailing bytes in authenticator data", ErrVerificationFailed)
+	}
+	return data, nil
+}
+
+// RelyingParty 依赖方配置
+type RelyingParty struct {
+	ID      string   // RP ID，通常是站点的有效域名
+	Origins []string // 允许发起仪式的页面来源
+}
+
+// Registration 注册仪式校验通过后得到的凭证
+type Registration struct {
+	CredentialID []byte
+	PublicKey    []byte
+	SignCount    uint32
+	AAGUID       []byte
+}
+
+// VerifyRegistration 校验注册仪式，见 WebAuthn 7.1
+func (rp *RelyingParty) VerifyRegistration(clientDataJSON, attestationObject []byte, challenge string, requireUserVerification bool) (*Registration, error) {
+	if err := rp.verifyClientData(clientDataJSON, CeremonyCreate, challenge); err != nil {
+		return nil, err
+	}
+
+	attestation, err := parseAttestationObject(attestationObject)
+	if err != nil {
+		return nil, err
+	}
+	authData := attestation.authData
+	if err := rp.verifyAuthenticatorData(authData, requireUserVerification); err != nil {
+		return nil, err
+	}
+	if authData.CredentialID == nil {
+		return nil, fmt.Errorf("%w: no attested credential data", ErrVerificationFailed)
+	}
+
+	publicKey, err := ParsePublicKey(authData.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	clientDataHash := sha256.Sum256(clientDataJSON)
+	if err := attestation.verify(publicKey, clientDataHash[:]); err != nil {
+		return nil, err
+	}
+
+	return &Registration{
+		CredentialID: bytes.Clone(authData.CredentialID),
+		PublicKey:    bytes.Clone(authData.PublicKey),
+		SignCount:    authData.SignCount,
+		AAGUID:       bytes.Clone(authData.AAGUID),
+	}, nil
+}
+
+// VerifyAssertion 校验认证仪式，返回认证器的新签名计数，见 WebAuthn 7.2
+// 调用方需要根据返回的计数检测凭证是否被克隆
+func (rp *RelyingParty) VerifyAssertion(clientDataJSON, authenticatorData, signature []byte, challenge string, credentialPublicKey []byte, requireUserVerification bool) (uint32, error) {
+	if err := rp.verifyClientData(clientDataJSON, CeremonyGet, challenge); err != nil {
+		return 0, err
+	}
+
+	authData, err := ParseAuthenticatorData(authenticatorData)
+	if err != nil {
+		return 0, err
+	}
+	if err := rp.verifyAuthenticatorData(authData, requireUserVerification); err != nil {
+		return 0, err
+	}
+
+	publicKey, err := ParsePublicKey(credentialPublicKey)
+	if err != nil {
+		return 0, err
+	}
+
+	clientDataHash := sha256.Sum256(clientDataJSON)
+	signed := append(bytes.Clone(authenticatorData), clientDataHash[:]...)
+	if err := publicKey.Verify(signed, signature); err != nil {
+		return 0, err
+	}
+	return authData.SignCount, nil
+}
+
+func (rp *RelyingParty) verifyClientData(raw []byte, ceremony, challenge string) error {
+	clientData, err := ParseClientData(raw)
+	if err != nil {
+		return err
+	}
+	if clientData.Type != ceremony {
+		return fmt.Errorf("%w: unexpected client data type %q", ErrVerificationFailed, clientData.Type)
+	}
+	if subtle.ConstantTimeCompare([]byte(clientData.Challenge), []byte(challenge)) != 1 {
+		return fmt.Errorf("%w: challenge mismatch", ErrVerificationFailed)
+	}
+	if !slices.Contains(rp.Origins, clientData.Origin) {
+		return fmt.Errorf("%w: origin %q is not allowed", ErrVerificationFailed, clientData.Origin)
+	}
+	if clientData.CrossOrigin {
+		return fmt.Errorf("%w: cross-origin ceremonies are not allowed", ErrVerificationFailed)
+	}
+	return nil
+}
+
+func (rp *RelyingParty) verifyAuthenticatorData(authData *AuthenticatorData, requireUserVerification bool) error {
+	rpIDHash := sha256.Sum256([]byte(rp.ID))
+	if subtle.ConstantTimeCompare(authData.RPIDHash, rpIDHash[:]) != 1 {
+		return fmt.Errorf("%w: rp id hash mismatch", ErrVerificationFailed)
+	}
+	if !authData.UserPresent() {
+		return fmt.Errorf("%w: user not present", ErrVerificationFailed)
+	}
+	if requireUserVerification && !authData.UserVerified() {
+		return fmt.Errorf("%w: user not verified", ErrVerificationFailed)
+	}
+	return nil
+}
+
+// EncodeChallenge 按 clientDataJSON 中的格式编码挑战值
+func EncodeChallenge(challenge []byte) string {
+	return base64.RawURLEncoding.EncodeToString(challenge)
+}
@@ -0,0 +1,233 @@
+package webauthn_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"yiwen/go-ddd/pkg/webauthn"
+	"yiwen/go-ddd/pkg/webauthn/webauthntest"
+)
+
+const (
+	rpID      = "example.com"
+	origin    = "https://example.com"
+	challenge = "dGVzdC1jaGFsbGVuZ2U"
+)
+
+var rp = &webauthn.RelyingParty{ID: rpID, Origins: []string{origin}}
+
+var authenticators = map[string]func(rpID, origin string) *webauthntest.Authenticator{
+	"ES256": webauthntest.NewES256,
+	"EdDSA": webauthntest.NewEdDSA,
+}
+
+func TestRegistrationAndAssertion(t *testing.T) {
+	for name, newAuthenticator := range authenticators {
+		for _, format := range []string{"none", "packed"} {
+			t.Run(name+"/"+format, func(t *testing.T) {
+				authenticator := newAuthenticator(rpID, origin)
+
+				clientDataJSON, attestationObject := authenticator.Create(challenge, webauthntest.Options{Attestation: format})
+				registration, err := rp.VerifyRegistration(clientDataJSON, attestationObject, challenge, true)
+				if err != nil {
+					t.Fatalf("VerifyRegistration: %v", err)
+				}
+				if string(registration.CredentialID) != string(authenticator.CredentialID) {
+					t.Errorf("credential id mismatch")
+				}
+				if string(registration.PublicKey) != string(authenticator.PublicKey()) {
+					t.Errorf("public key mismatch")
+				}
+
+				for want := uint32(1); want <= 2; want++ {
+					clientDataJSON, authData, signature := authenticator.Get(challenge, webauthntest.Options{})
+					signCount, err := rp.VerifyAssertion(clientDataJSON, authData, signature, challenge, registration.PublicKey, true)
+					if err != nil {
+						t.Fatalf("VerifyAssertion: %v", err)
+					}
+					if signCount != want {
+						t.Errorf("sign count = %d, want %d", signCount, want)
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestRegistrationRejected(t *testing.T) {
+	tests := []struct {
+		name      string
+		opts      webauthntest.Options
+		challenge string
+	}{
+		{name: "wrong origin", opts: webauthntest.Options{Origin: "https://evil.example"}},
+		{name: "cross origin", opts: webauthntest.Options{CrossOrigin: true}},
+		{name: "wrong ceremony type", opts: webauthntest.Options{Type: webauthn.CeremonyGet}},
+		{name: "wrong rp id hash", opts: webauthntest.Options{RPID: "evil.example"}},
+		{name: "user not present", opts: webauthntest.Options{Flags: webauthntest.FlagUserVerified}},
+		{name: "user not verified", opts: webauthntest.Options{Flags: webauthntest.FlagUserPresent}},
+		{name: "challenge mismatch", challenge: "b3RoZXItY2hhbGxlbmdl"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			authenticator := webauthntest.NewES256(rpID, origin)
+			clientDataJSON, attestationObject := authenticator.Create(challenge, tt.opts)
+
+			expected := challenge
+			if tt.challenge != "" {
+				expected = tt.challenge
+			}
+			_, err := rp.VerifyRegistration(clientDataJSON, attestationObject, expected, true)
+			if !errors.Is(err, webauthn.ErrVerificationFailed) {
+				t.Fatalf("err = %v, want ErrVerificationFailed", err)
+			}
+		})
+	}
+}
+
+func TestRegistrationAllowsUnverifiedUserWhenNotRequired(t *testing.T) {
+	authenticator := webauthntest.NewES256(rpID, origin)
+	clientDataJSON, attestationObject := authenticator.Create(challenge, webauthntest.Options{Flags: webauthntest.FlagUserPresent})
+	if _, err := rp.VerifyRegistration(clientDataJSON, attestationObject, challenge, false); err != nil {
+		t.Fatalf("VerifyRegistration: %v", err)
+	}
+}
+
+func TestAssertionRejected(t *testing.T) {
+	authenticator := webauthntest.NewEdDSA(rpID, origin)
+	publicKey := authenticator.PublicKey()
+	other := webauthntest.NewEdDSA(rpID, origin)
+
+	tests := []struct {
+		name   string
+		opts   webauthntest.Options
+		tamper func(clientDataJSON, authData, signature []byte) ([]byte, []byte, []byte)
+	}{
+		{name: "wrong origin", opts: webauthntest.Options{Origin: "https://evil.example"}},
+		{name: "wrong ceremony type", opts: webauthntest.Options{Type: webauthn.CeremonyCreate}},
+		{name: "wrong rp id hash", opts: webauthntest.Options{RPID: "evil.example"}},
+		{name: "user not present", opts: webauthntest.Options{Flags: webauthntest.FlagUserVerified}},
+		{name: "user not verified", opts: webauthntest.Options{Flags: webauthntest.FlagUserPresent}},
+		{
+			name: "tampered sign count",
+			tamper: func(clientDataJSON, authData, signature []byte) ([]byte, []byte, []byte) {
+				authData[36]++
+				return clientDataJSON, authData, signature
+			},
+		},
+		{
+			name: "signature from another credential",
+			tamper: func(clientDataJSON, authData, _ []byte) ([]byte, []byte, []byte) {
+				_, _, signature := other.Get(challenge, webauthntest.Options{})
+				return clientDataJSON, authData, signature
+			},
+		},
+		{
+			name: "truncated authenticator data",
+			tamper: func(clientDataJSON, authData, signature []byte) ([]byte, []byte, []byte) {
+				return clientDataJSON, authData[:36], signature
+			},
+		},
+		{
+			name: "malformed client data",
+			tamper: func(_, authData, signature []byte) ([]byte, []byte, []byte) {
+				return []byte("{"), authData, signature
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clientDataJSON, authData, signature := authenticator.Get(challenge, tt.opts)
+			if tt.tamper != nil {
+				clientDataJSON, authData, signature = tt.tamper(clientDataJSON, authData, signature)
+			}
+			_, err := rp.VerifyAssertion(clientDataJSON, authData, signature, challenge, publicKey, true)
+			if !errors.Is(err, webauthn.ErrVerificationFailed) {
+				t.Fatalf("err = %v, want ErrVerificationFailed", err)
+			}
+		})
+	}
+}
+
+// TestAssertionReturnsStaleSignCount 依赖方只返回签名计数，是否被克隆由调用方与保存的计数比较
+func TestAssertionReturnsStaleSignCount(t *testing.T) {
+	authenticator := webauthntest.NewES256(rpID, origin)
+	authenticator.SignCount = 10
+	clientDataJSON, authData, signature := authenticator.Get(challenge, webauthntest.Options{})
+
+	authenticator.SignCount = 3
+	clientDataJSON2, authData2, signature2 := authenticator.Get(challenge, webauthntest.Options{})
+
+	first, err := rp.VerifyAssertion(clientDataJSON, authData, signature, challenge, authenticator.PublicKey(), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := rp.VerifyAssertion(clientDataJSON2, authData2, signature2, challenge, authenticator.PublicKey(), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first != 11 || second != 4 {
+		t.Errorf("sign counts = %d, %d, want 11, 4", first, second)
+	}
+}
+
+func TestMalformedAttestationObject(t *testing.T) {
+	authenticator := webauthntest.NewES256(rpID, origin)
+	clientDataJSON, attestationObject := authenticator.Create(challenge, webauthntest.Options{})
+
+	inputs := map[string][]byte{
+		"empty":         {},
+		"not a map":     webauthntest.EncodeCBOR([]interface{}{int64(1)}),
+		"indefinite":    {0xbf, 0xff},
+		"trailing data": append(append([]byte(nil), attestationObject...), 0x00),
+		"missing authData": webauthntest.EncodeCBOR(map[interface{}]interface{}{
+			"fmt": "none", "attStmt": map[interface{}]interface{}{},
+		}),
+		"unsupported format": webauthntest.EncodeCBOR(map[interface{}]interface{}{
+			"fmt": "tpm", "attStmt": map[interface{}]interface{}{}, "authData": make([]byte, 37),
+		}),
+		"huge length": {0xa1, 0x63, 'f', 'm', 't', 0x7b, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff},
+	}
+	// 每一个前缀都是截断的证明对象
+	for i := range attestationObject {
+		inputs[fmt.Sprintf("truncated/%d", i)] = attestationObject[:i]
+	}
+
+	for name, input := range inputs {
+		_, err := rp.VerifyRegistration(clientDataJSON, input, challenge, true)
+		if !errors.Is(err, webauthn.ErrVerificationFailed) {
+			t.Errorf("%s: err = %v, want ErrVerificationFailed", name, err)
+		}
+	}
+}
+
+func TestParsePublicKeyRejectsInvalidKeys(t *testing.T) {
+	inputs := map[string]map[interface{}]interface{}{
+		"unsupported algorithm": {int64(1): int64(2), int64(3): int64(-35)},
+		"short ec2 coordinate":  {int64(1): int64(2), int64(3): webauthn.AlgES256, int64(-1): int64(1), int64(-2): make([]byte, 31), int64(-3): make([]byte, 32)},
+		"point not on curve":    {int64(1): int64(2), int64(3): webauthn.AlgES256, int64(-1): int64(1), int64(-2): make([]byte, 32), int64(-3): make([]byte, 32)},
+		"wrong okp curve":       {int64(1): int64(1), int64(3): webauthn.AlgEdDSA, int64(-1): int64(4), int64(-2): make([]byte, 32)},
+		"short rsa modulus":     {int64(1): int64(3), int64(3): webauthn.AlgRS256, int64(-1): make([]byte, 128), int64(-2): []byte{1, 0, 1}},
+	}
+	for name, key := range inputs {
+		if _, err := webauthn.ParsePublicKey(webauthntest.EncodeCBOR(key)); !errors.Is(err, webauthn.ErrVerificationFailed) {
+			t.Errorf("%s: err = %v, want ErrVerificationFailed", name, err)
+		}
+	}
+}
+
+func FuzzParseAuthenticatorData(f *testing.F) {
+	authenticator := webauthntest.NewES256(rpID, origin)
+	_, attestationObject := authenticator.Create(challenge, webauthntest.Options{})
+	_, authData, _ := authenticator.Get(challenge, webauthntest.Options{})
+	f.Add(attestationObject)
+	f.Add(authData)
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		_, _ = webauthn.ParseAuthenticatorData(data)
+		_, _ = webauthn.ParsePublicKey(data)
+		_, _ = rp.VerifyRegistration([]byte(`{}`), data, challenge, true)
+	})
+}
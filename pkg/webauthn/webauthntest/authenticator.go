@@ -0,0 +1,240 @@
+// Package webauthntest 提供用于测试依赖方校验的软件认证器
+package webauthntest
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"sort"
+	"yiwen/go-ddd/pkg/webauthn"
+)
+
+// 认证器数据标志位
+const (
+	FlagUserPresent            byte = 0x01
+	FlagUserVerified           byte = 0x04
+	FlagAttestedCredentialData byte = 0x40
+)
+
+// Options 单次仪式的参数，零值表示使用正常值，用于构造各种非法的响应
+type Options struct {
+	Type        string // 客户端数据中的仪式类型
+	Origin      string // 客户端数据中的页面来源
+	CrossOrigin bool
+	RPID        string // 计算 rpIdHash 使用的 RP ID
+	Flags       byte   // 认证器数据标志位，默认为 UP|UV
+	Attestation string // 证明格式 none 或 packed，默认为 none
+}
+
+// Authenticator 软件认证器，使用内存中的私钥完成注册和认证仪式
+type Authenticator struct {
+	RPID         string
+	Origin       string
+	CredentialID []byte
+	UserHandle   []byte
+	SignCount    uint32 // 每次认证前递增，修改它可以模拟被克隆的凭证
+
+	alg    int64
+	signer crypto.Signer
+}
+
+// NewES256 创建使用 P-256 ECDSA 密钥的认证器
+func NewES256(rpID, origin string) *Authenticator {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		panic(err)
+	}
+	return newAuthenticator(rpID, origin, webauthn.AlgES256, key)
+}
+
+// NewEdDSA 创建使用 Ed25519 密钥的认证器
+func NewEdDSA(rpID, origin string) *Authenticator {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		panic(err)
+	}
+	return newAuthenticator(rpID, origin, webauthn.AlgEdDSA, key)
+}
+
+func newAuthenticator(rpID, origin string, alg int64, signer crypto.Signer) *Authenticator {
+	credentialID := make([]byte, 16)
+	if _, err := rand.Read(credentialID); err != nil {
+		panic(err)
+	}
+	return &Authenticator{RPID: rpID, Origin: origin, CredentialID: credentialID, alg: alg, signer: signer}
+}
+
+// PublicKey 返回 COSE 编码的凭证公钥
+func (a *Authenticator) PublicKey() []byte {
+	switch key := a.signer.Public().(type) {
+	case *ecdsa.PublicKey:
+		point, err := key.Bytes()
+		if err != nil {
+			panic(err)
+		}
+		return EncodeCBOR(map[interface{}]interface{}{
+			int64(1): int64(2), int64(3): a.alg, int64(-1): int64(1), int64(-2): point[1:33], int64(-3): point[33:],
+		})
+	case ed25519.PublicKey:
+		return EncodeCBOR(map[interface{}]interface{}{
+			int64(1): int64(1), int64(3): a.alg, int64(-1): int64(6), int64(-2): []byte(key),
+		})
+	}
+	panic("webauthntest: unsupported key")
+}
+
+// Create 执行注册仪式，返回 clientDataJSON 和 attestationObject
+func (a *Authenticator) Create(challenge string, opts Options) (clientDataJSON, attestationObject []byte) {
+	clientDataJSON = a.clientData(webauthn.CeremonyCreate, challenge, opts)
+
+	attested := binary.BigEndian.AppendUint16(make([]byte, 16), uint16(len(a.CredentialID)))
+	attested = append(append(attested, a.CredentialID...), a.PublicKey()...)
+	authData := a.authData(opts, FlagAttestedCredentialData, attested)
+
+	statement := map[interface{}]interface{}{}
+	format := opts.Attestation
+	switch format {
+	case "", "none":
+		format = "none"
+	case "packed":
+		// 自证明：使用凭证私钥签名
+		clientDataHash := sha256.Sum256(clientDataJSON)
+		statement["alg"] = a.alg
+		statement["sig"] = a.sign(append(append([]byte(nil), authData...), clientDataHash[:]...))
+	}
+
+	attestationObject = EncodeCBOR(map[interface{}]interface{}{
+		"fmt":      format,
+		"attStmt":  statement,
+		"authData": authData,
+	})
+	return clientDataJSON, attestationObject
+}
+
+// Get 执行认证仪式，签名计数先递增，返回 clientDataJSON、authenticatorData 和签名
+func (a *Authenticator) Get(challenge string, opts Options) (clientDataJSON, authenticatorData, signature []byte) {
+	a.SignCount++
+	clientDataJSON = a.clientData(webauthn.CeremonyGet, challenge, opts)
+	authenticatorData = a.authData(opts, 0, nil)
+
+	clientDataHash := sha256.Sum256(clientDataJSON)
+	signature = a.sign(append(append([]byte(nil), authenticatorData...), clientDataHash[:]...))
+	return clientDataJSON, authenticatorData, signature
+}
+
+func (a *Authenticator) clientData(ceremony, challenge string, opts Options) []byte {
+	clientData := webauthn.ClientData{
+		Type:        ceremony,
+		Challenge:   challenge,
+		Origin:      a.Origin,
+		CrossOrigin: opts.CrossOrigin,
+	}
+	if opts.Type != "" {
+		clientData.Type = opts.Type
+	}
+	if opts.Origin != "" {
+		clientData.Origin = opts.Origin
+	}
+	raw, err := json.Marshal(clientData)
+	if err != nil {
+		panic(err)
+	}
+	return raw
+}
+
+func (a *Authenticator) authData(opts Options, extraFlags byte, attested []byte) []byte {
+	rpID := a.RPID
+	if opts.RPID != "" {
+		rpID = opts.RPID
+	}
+	flags := opts.Flags
+	if flags == 0 {
+		flags = FlagUserPresent | FlagUserVerified
+	}
+
+	rpIDHash := sha256.Sum256([]byte(rpID))
+	data := append(rpIDHash[:], flags|extraFlags)
+	data = binary.BigEndian.AppendUint32(data, a.SignCount)
+	return append(data, attested...)
+}
+
+// sign 按凭证算法签名，ES256 签名为 ASN.1 DER 编码
+func (a *Authenticator) sign(data []byte) []byte {
+	var (
+		signature []byte
+		err       error
+	)
+	switch a.alg {
+	case webauthn.AlgES256:
+		digest := sha256.Sum256(data)
+		signature, err = a.signer.Sign(rand.Reader, digest[:], crypto.SHA256)
+	default:
+		signature, err = a.signer.Sign(rand.Reader, data, crypto.Hash(0))
+	}
+	if err != nil {
+		panic(err)
+	}
+	return signature
+}
+
+// EncodeCBOR 编码 cbor.Decode 支持的数据类型，映射的键按编码后的字节排序
+func EncodeCBOR(v interface{}) []byte {
+	switch v := v.(type) {
+	case int:
+		return EncodeCBOR(int64(v))
+	case int64:
+		if v < 0 {
+			return cborHead(1, uint64(-1-v))
+		}
+		return cborHead(0, uint64(v))
+	case []byte:
+		return append(cborHead(2, uint64(len(v))), v...)
+	case string:
+		return append(cborHead(3, uint64(len(v))), v...)
+	case []interface{}:
+		out := cborHead(4, uint64(len(v)))
+		for _, item := range v {
+			out = append(out, EncodeCBOR(item)...)
+		}
+		return out
+	case map[interface{}]interface{}:
+		entries := make([][]byte, 0, len(v))
+		for k, item := range v {
+			entries = append(entries, append(EncodeCBOR(k), EncodeCBOR(item)...))
+		}
+		sort.Slice(entries, func(i, j int) bool { return string(entries[i]) < string(entries[j]) })
+		out := cborHead(5, uint64(len(v)))
+		for _, entry := range entries {
+			out = append(out, entry...)
+		}
+		return out
+	case bool:
+		if v {
+			return []byte{0xf5}
+		}
+		return []byte{0xf4}
+	case nil:
+		return []byte{0xf6}
+	}
+	panic("webauthntest: unsupported cbor type")
+}
+
+func cborHead(major byte, arg uint64) []byte {
+	switch {
+	case arg < 24:
+		return []byte{major<<5 | byte(arg)}
+	case arg <= 0xff:
+		return []byte{major<<5 | 24, byte(arg)}
+	case arg <= 0xffff:
+		return binary.BigEndian.AppendUint16([]byte{major<<5 | 25}, uint16(arg))
+	case arg <= 0xffffffff:
+		return binary.BigEndian.AppendUint32([]byte{major<<5 | 26}, uint32(arg))
+	default:
+		return binary.BigEndian.AppendUint64([]byte{major<<5 | 27}, arg)
+	}
+}
@@ -19,12 +19,15 @@ CREAT TABLE IF NOT EXISTS users (
     username VARCHAR(50) NOT NULL COMMENT '用户名',
     email VARCHAR(100) NOT NULL COMMENT '邮箱',
     password_hash VARCHAR(255) NOT NULL COMMENT '密码哈希值',
+    password_reset_required TINYINT(1) NOT NULL DEFAULT 0 COMMENT '密码哈希在旧版本事件中丢失, 需要通过找回密码重新设置',
     nickname VARCHAR(50) DEFAULT '' COMMENT '昵称',
     avatar VARCHAR(255) DEFAULT '' COMMENT '头像URL',
+    email_verified TINYINT(1) NOT NULL DEFAULT 0 COMMENT '邮箱是否已验证',
 
     --- 状态 和 角色
     status TINYINT NOT NULL DEFAULT 1 COMMENT '状态: 1-激活 2-未激活 3-禁用',
-    role VARCHAR(20) NOT NULL DEFAULT 'user' COMMENT '角色: user-普通用户 admin-管理员',
+    role VARCHAR(255) NOT NULL DEFAULT 'user' COMMENT '角色名称, 多个角色以逗号分隔, 对应 roles 表',
+    token_version INT NOT NULL DEFAULT 0 COMMENT '令牌版本, 递增后已签发的令牌全部失效',
 
     --- 时间戳
     created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
@@ -43,6 +46,481 @@ CREAT TABLE IF NOT EXISTS users (
     INDEX idx_deleted_at(deleted_at)
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE utf8mb4_unicode_ci COMMENT='用户表';
 
+--- ==============================
+--- outbox 事件表 (事务性发件箱)
+--- ==============================
+CREATE TABLE IF NOT EXISTS outbox_events (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY COMMENT '主键ID, 同时作为投递顺序',
+
+    --- 事件内容
+    event_name VARCHAR(100) NOT NULL COMMENT '事件名称',
+    aggregate_id VARCHAR(36) NOT NULL COMMENT '聚合根UUID',
+    payload TEXT NOT NULL COMMENT '事件信封JSON',
+
+    --- 投递状态
+    status TINYINT NOT NULL DEFAULT 1 COMMENT '状态: 1-待投递 2-已投递 3-失败',
+    attempts INT NOT NULL DEFAULT 0 COMMENT '已尝试次数',
+    next_attempt_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '下次投递时间',
+    last_error VARCHAR(1000) DEFAULT '' COMMENT '最近一次错误',
+
+    --- 时间戳
+    occurred_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '事件发生时间',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+    published_at TIMESTAMP NULL COMMENT '投递时间',
+
+    INDEX idx_aggregate_id(aggregate_id),
+    INDEX idx_outbox_status_next(status, next_attempt_at)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE utf8mb4_unicode_ci COMMENT='outbox事件表';
+
+--- ==============================
+--- 事件存储表 (事件溯源模式)
+--- ==============================
+CREATE TABLE IF NOT EXISTS event_store (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY COMMENT '主键ID, 全局事件顺序',
+
+    --- 聚合信息
+    aggregate_id VARCHAR(36) NOT NULL COMMENT '聚合根UUID',
+    aggregate_type VARCHAR(50) NOT NULL COMMENT '聚合类型',
+    version INT NOT NULL COMMENT '聚合内版本号, 从1开始',
+
+    --- 事件内容
+    event_name VARCHAR(100) NOT NULL COMMENT '事件名称',
+    payload TEXT NOT NULL COMMENT '事件信封JSON',
+
+    --- 时间戳
+    occurred_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '事件发生时间',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+
+    --- 乐观并发控制
+    UNIQUE INDEX uk_aggregate_version(aggregate_id, version)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE utf8mb4_unicode_ci COMMENT='事件存储表';
+
+--- ==============================
+--- 聚合快照表 (事件溯源模式)
+--- ==============================
+CREATE TABLE IF NOT EXISTS aggregate_snapshots (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY COMMENT '主键ID',
+
+    --- 聚合信息
+    aggregate_id VARCHAR(36) NOT NULL COMMENT '聚合根UUID',
+    aggregate_type VARCHAR(50) NOT NULL COMMENT '聚合类型',
+    version INT NOT NULL COMMENT '快照对应的聚合版本号',
+    schema_version INT NOT NULL COMMENT '快照结构版本',
+    state TEXT NOT NULL COMMENT '聚合状态JSON',
+
+    --- 时间戳
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+
+    UNIQUE INDEX uk_aggregate_id(aggregate_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE utf8mb4_unicode_ci COMMENT='聚合快照表';
+
+--- ==============================
+--- webhook 端点表
+--- ==============================
+CREATE TABLE IF NOT EXISTS webhook_endpoints (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY COMMENT '主键ID',
+
+    url VARCHAR(500) NOT NULL COMMENT '回调地址',
+    event_types VARCHAR(1000) NOT NULL COMMENT '订阅的事件类型, 逗号分隔, *表示全部',
+    secret VARCHAR(100) NOT NULL COMMENT 'HMAC-SHA256签名密钥',
+    active TINYINT(1) NOT NULL DEFAULT 1 COMMENT '是否启用',
+
+    --- 时间戳
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间'
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE utf8mb4_unicode_ci COMMENT='webhook端点表';
+
+--- ==============================
+--- webhook 投递记录表
+--- ==============================
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY COMMENT '主键ID',
+
+    --- 投递内容
+    endpoint_id BIGINT UNSIGNED NOT NULL COMMENT '端点ID',
+    event_id VARCHAR(36) NOT NULL COMMENT '事件ID',
+    event_type VARCHAR(100) NOT NULL COMMENT '事件类型',
+    payload TEXT NOT NULL COMMENT '请求体',
+
+    --- 投递状态
+    status TINYINT NOT NULL DEFAULT 1 COMMENT '状态: 1-待投递 2-成功 3-死信',
+    attempts INT NOT NULL DEFAULT 0 COMMENT '已尝试次数',
+    next_attempt_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '下次投递时间',
+    response_status INT NOT NULL DEFAULT 0 COMMENT '最近一次响应状态码',
+    response_body VARCHAR(1000) DEFAULT '' COMMENT '最近一次响应内容',
+    last_error VARCHAR(1000) DEFAULT '' COMMENT '最近一次错误',
+    delivered_at TIMESTAMP NULL COMMENT '投递成功时间',
+
+    --- 时间戳
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+
+    UNIQUE KEY uk_webhook_endpoint_event(endpoint_id, event_id),
+    INDEX idx_event_id(event_id),
+    INDEX idx_event_type(event_type),
+    INDEX idx_webhook_status_next(status, next_attempt_at)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE utf8mb4_unicode_ci COMMENT='webhook投递记录表';
+
+--- ==============================
+--- 审计日志表
+--- ==============================
+CREATE TABLE IF NOT EXISTS audit_logs (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY COMMENT '主键ID',
+
+    --- 审计内容
+    event_id VARCHAR(36) NOT NULL COMMENT '来源事件ID, 管理命令为随机ID',
+    source VARCHAR(20) NOT NULL DEFAULT 'event' COMMENT '来源: event 事件投影, command 管理命令',
+    actor_id BIGINT UNSIGNED NOT NULL DEFAULT 0 COMMENT '操作人ID, 0 表示匿名',
+    actor_name VARCHAR(50) DEFAULT '' COMMENT '操作人用户名',
+    target_uuid VARCHAR(36) NOT NULL COMMENT '被操作对象, 用户为UUID, 角色和 webhook 为ID',
+    action VARCHAR(100) NOT NULL COMMENT '操作(事件类型)',
+    reason VARCHAR(500) DEFAULT '' COMMENT '操作原因',
+    ip VARCHAR(45) DEFAULT '' COMMENT '请求IP',
+    user_agent VARCHAR(255) DEFAULT '' COMMENT '请求User-Agent',
+    occurred_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '操作时间',
+
+    --- 时间戳
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+
+    UNIQUE KEY uk_event_id(event_id),
+    INDEX idx_actor_id(actor_id),
+    INDEX idx_target_uuid(target_uuid),
+    INDEX idx_action(action),
+    INDEX idx_occurred_at(occurred_at)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE utf8mb4_unicode_ci COMMENT='审计日志表';
+
+--- ==============================
+--- 投影重建检查点表
+--- ==============================
+CREATE TABLE IF NOT EXISTS projection_checkpoints (
+    name VARCHAR(100) PRIMARY KEY COMMENT '投影名称',
+    position BIGINT UNSIGNED NOT NULL DEFAULT 0 COMMENT '已处理到的 outbox 事件ID',
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间'
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE utf8mb4_unicode_ci COMMENT='投影重建检查点表';
+
+--- ==============================
+--- 流程实例表
+--- ==============================
+CREATE TABLE IF NOT EXISTS saga_instances (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY COMMENT '主键ID',
+
+    --- 流程标识
+    saga_name VARCHAR(50) NOT NULL COMMENT '流程名称',
+    correlation_id VARCHAR(64) NOT NULL COMMENT '关联ID, 例如用户UUID',
+
+    --- 流程状态
+    state VARCHAR(50) NOT NULL COMMENT '当前状态',
+    data TEXT COMMENT '流程数据(JSON)',
+    deadline_at TIMESTAMP NULL COMMENT '超时时间',
+    completed TINYINT(1) NOT NULL DEFAULT 0 COMMENT '是否已结束',
+    version INT NOT NULL DEFAULT 0 COMMENT '乐观锁版本号',
+
+    --- 时间戳
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+
+    UNIQUE KEY uk_saga_correlation(saga_name, correlation_id),
+    INDEX idx_saga_completed_deadline(completed, deadline_at)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE utf8mb4_unicode_ci COMMENT='流程实例表';
+
+--- ==============================
+--- 流程已处理事件表
+--- ==============================
+CREATE TABLE IF NOT EXISTS saga_processed_events (
+    saga_name VARCHAR(50) NOT NULL COMMENT '流程名称',
+    event_id VARCHAR(36) NOT NULL COMMENT '事件ID',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+
+    PRIMARY KEY (saga_name, event_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE utf8mb4_unicode_ci COMMENT='流程已处理事件表';
+
+--- ==============================
+--- 刷新令牌表
+--- ==============================
+CREATE TABLE IF NOT EXISTS refresh_tokens (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY COMMENT '主键ID',
+    user_id BIGINT UNSIGNED NOT NULL COMMENT '用户ID',
+    client_id VARCHAR(64) NOT NULL DEFAULT '' COMMENT 'OAuth2客户端标识, 自身登录签发时为空',
+    scope VARCHAR(1000) NOT NULL DEFAULT '' COMMENT 'OAuth2授权的权限范围',
+    family_id VARCHAR(36) NOT NULL COMMENT '令牌家族, 同一次登录轮换出的令牌属于同一家族',
+    token_hash CHAR(64) NOT NULL COMMENT '令牌SHA-256哈希',
+    token_version INT NOT NULL DEFAULT 0 COMMENT '签发时的用户令牌版本',
+    expires_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '过期时间',
+    used_at TIMESTAMP NULL COMMENT '轮换时间',
+    revoked_at TIMESTAMP NULL COMMENT '撤销时间',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+
+    UNIQUE KEY uk_token_hash(token_hash),
+    INDEX idx_user_id(user_id),
+    INDEX idx_family_id(family_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE utf8mb4_unicode_ci COMMENT='刷新令牌表';
+
+--- ==============================
+--- 已注销访问令牌表
+--- ==============================
+CREATE TABLE IF NOT EXISTS revoked_tokens (
+    token_id VARCHAR(36) NOT NULL PRIMARY KEY COMMENT '访问令牌jti',
+    user_id BIGINT UNSIGNED NOT NULL COMMENT '用户ID',
+    expires_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '令牌过期时间, 过期后记录可以删除',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+
+    INDEX idx_user_id(user_id),
+    INDEX idx_expires_at(expires_at)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE utf8mb4_unicode_ci COMMENT='已注销访问令牌表';
+
+--- ==============================
+--- OAuth2 客户端表
+--- ==============================
+CREATE TABLE IF NOT EXISTS oauth_clients (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY COMMENT '主键ID',
+    client_id VARCHAR(64) NOT NULL COMMENT '客户端标识',
+    secret_hash CHAR(64) NOT NULL DEFAULT '' COMMENT '客户端密钥SHA-256哈希, 公共客户端为空',
+    name VARCHAR(100) NOT NULL COMMENT '客户端名称',
+    redirect_uris VARCHAR(2000) NOT NULL COMMENT '回调地址, 空格分隔',
+    scopes VARCHAR(1000) NOT NULL COMMENT '允许的权限范围, 空格分隔',
+    grant_types VARCHAR(200) NOT NULL COMMENT '允许的授权类型, 空格分隔',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+
+    UNIQUE KEY uk_client_id(client_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE utf8mb4_unicode_ci COMMENT='OAuth2客户端表';
+
+--- ==============================
+--- OAuth2 授权码表
+--- ==============================
+CREATE TABLE IF NOT EXISTS oauth_authorization_codes (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY COMMENT '主键ID',
+    code_hash CHAR(64) NOT NULL COMMENT '授权码SHA-256哈希',
+    client_id VARCHAR(64) NOT NULL COMMENT '客户端标识',
+    user_id BIGINT UNSIGNED NOT NULL COMMENT '授权用户ID',
+    redirect_uri VARCHAR(500) NOT NULL DEFAULT '' COMMENT '授权请求中的redirect_uri参数',
+    scopes VARCHAR(1000) NOT NULL COMMENT '授权的权限范围, 空格分隔',
+    code_challenge VARCHAR(128) NOT NULL DEFAULT '' COMMENT 'PKCE S256 code_challenge',
+    nonce VARCHAR(255) NOT NULL DEFAULT '' COMMENT 'OIDC nonce',
+    expires_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '过期时间',
+    used_at TIMESTAMP NULL COMMENT '使用时间',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+
+    UNIQUE KEY uk_code_hash(code_hash),
+    INDEX idx_client_id(client_id),
+    INDEX idx_user_id(user_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE utf8mb4_unicode_ci COMMENT='OAuth2授权码表';
+
+--- ==============================
+--- OAuth2 授权同意表
+--- ==============================
+CREATE TABLE IF NOT EXISTS oauth_consents (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY COMMENT '主键ID',
+    user_id BIGINT UNSIGNED NOT NULL COMMENT '用户ID',
+    client_id VARCHAR(64) NOT NULL COMMENT '客户端标识',
+    scopes VARCHAR(1000) NOT NULL COMMENT '已同意的权限范围, 空格分隔',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+
+    UNIQUE KEY uk_oauth_consent_user_client(user_id, client_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE utf8mb4_unicode_ci COMMENT='OAuth2授权同意表';
+
+--- ==============================
+--- 外部身份表
+--- ==============================
+CREATE TABLE IF NOT EXISTS external_identities (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY COMMENT '主键ID',
+    user_id BIGINT UNSIGNED NOT NULL COMMENT '关联的用户ID',
+    provider VARCHAR(50) NOT NULL COMMENT '身份提供方标识',
+    subject VARCHAR(255) NOT NULL COMMENT '提供方内的用户标识',
+    email VARCHAR(100) NOT NULL DEFAULT '' COMMENT '最近一次登录时提供方返回的邮箱',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '关联时间',
+    last_login_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '最近登录时间',
+
+    UNIQUE KEY uk_provider_subject(provider, subject),
+    INDEX idx_external_identity_user(user_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE utf8mb4_unicode_ci COMMENT='外部身份表';
+
+--- ==============================
+--- 外部登录流程状态表
+--- ==============================
+CREATE TABLE IF NOT EXISTS external_login_states (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY COMMENT '主键ID',
+    state_hash CHAR(64) NOT NULL COMMENT 'state 参数的SHA-256哈希',
+    provider VARCHAR(50) NOT NULL COMMENT '身份提供方标识',
+    nonce VARCHAR(64) NOT NULL COMMENT 'ID Token nonce',
+    code_verifier VARCHAR(128) NOT NULL COMMENT 'PKCE code_verifier',
+    expires_at TIMESTAMP NOT NULL COMMENT '过期时间',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+
+    UNIQUE KEY uk_external_login_state(state_hash),
+    INDEX idx_external_login_state_expires(expires_at)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE utf8mb4_unicode_ci COMMENT='外部登录流程状态表';
+
+--- ==============================
+--- 两步验证表
+--- ==============================
+CREATE TABLE IF NOT EXISTS user_two_factors (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY COMMENT '主键ID',
+    user_id BIGINT UNSIGNED NOT NULL COMMENT '用户ID',
+    secret VARCHAR(64) NOT NULL COMMENT 'base32编码的TOTP密钥',
+    confirmed_at TIMESTAMP NULL DEFAULT NULL COMMENT '确认开启时间, 为空表示尚未开启',
+    last_used_step BIGINT NOT NULL DEFAULT 0 COMMENT '最近使用的验证码时间步, 防止重复使用',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+
+    UNIQUE KEY uk_two_factor_user(user_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE utf8mb4_unicode_ci COMMENT='两步验证表';
+
+--- ==============================
+--- 两步验证恢复码表
+--- ==============================
+CREATE TABLE IF NOT EXISTS two_factor_recovery_codes (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY COMMENT '主键ID',
+    user_id BIGINT UNSIGNED NOT NULL COMMENT '用户ID',
+    code_hash CHAR(64) NOT NULL COMMENT '恢复码的SHA-256哈希',
+    used_at TIMESTAMP NULL DEFAULT NULL COMMENT '使用时间',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+
+    UNIQUE KEY uk_user_code(user_id, code_hash)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE utf8mb4_unicode_ci COMMENT='两步验证恢复码表';
+
+--- ==============================
+--- 等待两步验证的登录表
+--- ==============================
+CREATE TABLE IF NOT EXISTS two_factor_challenges (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY COMMENT '主键ID',
+    token_hash CHAR(64) NOT NULL COMMENT 'MFA令牌的SHA-256哈希',
+    user_id BIGINT UNSIGNED NOT NULL COMMENT '用户ID',
+    attempts INT NOT NULL DEFAULT 0 COMMENT '验证失败次数',
+    expires_at TIMESTAMP NOT NULL COMMENT '过期时间',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+
+    UNIQUE KEY uk_two_factor_challenge(token_hash),
+    INDEX idx_two_factor_challenge_user(user_id),
+    INDEX idx_two_factor_challenge_expires(expires_at)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE utf8mb4_unicode_ci COMMENT='等待两步验证的登录表';
+
+--- ==============================
+--- 通行密钥表
+--- ==============================
+CREATE TABLE IF NOT EXISTS user_passkeys (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY COMMENT '主键ID',
+    user_id BIGINT UNSIGNED NOT NULL COMMENT '用户ID',
+    credential_id VARBINARY(1023) NOT NULL COMMENT '认证器生成的凭证ID',
+    public_key BLOB NOT NULL COMMENT 'COSE编码的凭证公钥',
+    sign_count INT UNSIGNED NOT NULL DEFAULT 0 COMMENT '签名计数',
+    name VARCHAR(100) NOT NULL DEFAULT '' COMMENT '通行密钥名称',
+    last_used_at TIMESTAMP NULL DEFAULT NULL COMMENT '最近登录时间',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+
+    UNIQUE KEY uk_passkey_credential(credential_id),
+    INDEX idx_passkey_user(user_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE utf8mb4_unicode_ci COMMENT='通行密钥表';
+
+--- ==============================
+--- 通行密钥仪式表
+--- ==============================
+CREATE TABLE IF NOT EXISTS webauthn_sessions (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY COMMENT '主键ID',
+    challenge_hash CHAR(64) NOT NULL COMMENT '挑战值的SHA-256哈希',
+    user_id BIGINT UNSIGNED NOT NULL DEFAULT 0 COMMENT '注册时为当前用户, 登录时为0',
+    ceremony VARCHAR(20) NOT NULL COMMENT '仪式类型: registration, authentication',
+    expires_at TIMESTAMP NOT NULL COMMENT '过期时间',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+
+    UNIQUE KEY uk_webauthn_challenge(challenge_hash),
+    INDEX idx_webauthn_session_expires(expires_at)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE utf8mb4_unicode_ci COMMENT='通行密钥仪式表';
+
+--- ==============================
+--- API 密钥表
+--- ==============================
+CREATE TABLE IF NOT EXISTS api_keys (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY COMMENT '主键ID',
+    user_id BIGINT UNSIGNED NOT NULL COMMENT '用户ID',
+    name VARCHAR(100) NOT NULL COMMENT '密钥名称',
+    prefix VARCHAR(20) NOT NULL COMMENT '密钥前缀, 仅用于展示',
+    key_hash CHAR(64) NOT NULL COMMENT '密钥的SHA-256哈希',
+    scopes VARCHAR(1000) NOT NULL COMMENT '权限范围, 以空格分隔',
+    expires_at TIMESTAMP NULL DEFAULT NULL COMMENT '过期时间, 为空表示不过期',
+    last_used_at TIMESTAMP NULL DEFAULT NULL COMMENT '最近使用时间',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+
+    UNIQUE KEY uk_api_key_hash(key_hash),
+    INDEX idx_api_key_user(user_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE utf8mb4_unicode_ci COMMENT='API密钥表';
+
+--- ==============================
+--- 登录会话表
+--- ==============================
+CREATE TABLE IF NOT EXISTS user_sessions (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY COMMENT '主键ID',
+    session_id VARCHAR(36) NOT NULL COMMENT '会话ID, 即刷新令牌家族ID',
+    user_id BIGINT UNSIGNED NOT NULL COMMENT '用户ID',
+    device VARCHAR(100) NOT NULL DEFAULT '' COMMENT '设备描述',
+    user_agent VARCHAR(512) NOT NULL DEFAULT '' COMMENT '登录时的User-Agent',
+    ip VARCHAR(45) NOT NULL DEFAULT '' COMMENT '最近一次刷新令牌时的客户端IP',
+    expires_at TIMESTAMP NOT NULL COMMENT '过期时间',
+    last_seen_at TIMESTAMP NOT NULL COMMENT '最近活跃时间',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '登录时间',
+
+    UNIQUE KEY uk_session_id(session_id),
+    INDEX idx_session_user(user_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE utf8mb4_unicode_ci COMMENT='登录会话表';
+
+--- ==============================
+--- 一次性操作令牌表
+--- ==============================
+CREATE TABLE IF NOT EXISTS action_tokens (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY COMMENT '主键ID',
+    token_id VARCHAR(36) NOT NULL COMMENT '令牌ID, 即签名令牌中的jti',
+    user_id BIGINT UNSIGNED NOT NULL COMMENT '用户ID',
+    purpose VARCHAR(32) NOT NULL COMMENT '用途: email_verification, password_reset',
+    email VARCHAR(100) NOT NULL COMMENT '发送令牌时用户的邮箱',
+    expires_at TIMESTAMP NOT NULL COMMENT '过期时间',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '发送时间',
+
+    UNIQUE KEY uk_token_id(token_id),
+    INDEX idx_user_purpose(user_id, purpose),
+    INDEX idx_action_token_expires(expires_at)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE utf8mb4_unicode_ci COMMENT='一次性操作令牌表';
+
+--- ==============================
+--- 登录失败记录表
+--- ==============================
+CREATE TABLE IF NOT EXISTS login_attempts (
+    attempt_key VARCHAR(128) PRIMARY KEY COMMENT '统计对象: account:用户名(小写) 或 ip:IP地址',
+    failures INT NOT NULL DEFAULT 0 COMMENT '统计窗口内连续失败次数',
+    last_failed_at TIMESTAMP NOT NULL COMMENT '最近一次失败时间',
+    locked_until TIMESTAMP NULL COMMENT '锁定截止时间, 为空表示未锁定',
+
+    INDEX idx_login_attempt_last_failed(last_failed_at)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE utf8mb4_unicode_ci COMMENT='登录失败记录表';
+
+--- ==============================
+--- 角色表
+--- ==============================
+CREATE TABLE IF NOT EXISTS roles (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY COMMENT '主键ID',
+    name VARCHAR(50) NOT NULL COMMENT '角色名称, 分配给用户后作为标识, 不能修改',
+    description VARCHAR(255) DEFAULT '' COMMENT '说明',
+    parent_id BIGINT UNSIGNED NOT NULL DEFAULT 0 COMMENT '父角色ID, 继承父角色的全部权限, 0 表示不继承',
+    permissions VARCHAR(1000) NOT NULL DEFAULT '' COMMENT '空格分隔的权限名称, * 表示全部权限',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+
+    UNIQUE KEY uk_role_name(name),
+    INDEX idx_role_parent(parent_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE utf8mb4_unicode_ci COMMENT='角色表';
+
+--- ==============================
+--- 插入内置角色
+--- ==============================
+INSERT INTO roles (name, description, parent_id, permissions)
+VALUES ('user', '普通用户', 0, 'profile:read profile:update password:change account:delete')
+ON DUPLICATE KEY UPDATE updated_at = CURRENT_TIMESTAMP;
+
+INSERT INTO roles (name, description, parent_id, permissions)
+SELECT 'admin', '管理员', u.id, '*' FROM roles AS u WHERE u.name = 'user'
+ON DUPLICATE KEY UPDATE roles.updated_at = CURRENT_TIMESTAMP;
+
 --- ==============================
 --- 插入测试管理员账户
 --- 密码: Admin123 (bcrypt加密)
@@ -77,8 +555,120 @@ VALUES (
 ---
 --- 4. 角色说明
 ---   - user: 普通用户
----   - admin: 管理员
+---   - admin: 管理员, 继承 user 并拥有全部权限
+---   - 其他角色通过管理接口创建, 见第 25 条
 --- 5. 测试账户
 ---    - 用户名: admin
 ---    - 密码: Admin123
----    - 生产环境需修改或删除该账户
\ No newline at end of file
+---    - 生产环境需修改或删除该账户
+--- 6. outbox 事件表:
+---    - 与 users 表在同一事务中写入，避免事件丢失
+---    - relay 按 id 顺序投递，失败后按指数退避重试; 事件总线同步分发, 全部处理器成功后才标记为已投递
+---    - relay 通过 SELECT ... FOR UPDATE SKIP LOCKED 领取事件并把 next_attempt_at 推迟一个租期 (outbox.lease_second), 多实例部署时同一事件只由一个实例投递
+---    - 管理后台事件流不经过 relay, 每个实例各自按 id 轮询读取, SSE 的 id 即 outbox 的 id, 客户端可以连到任一实例续传
+--- 7. 事件存储表:
+---    - persistence.mode = event_sourced 时启用，只追加不修改
+---    - users 表作为查询投影，与事件在同一事务中更新
+---    - 切换到事件溯源之前已存在的用户（包括上面的测试管理员）没有事件流, 切换前执行 go run ./cmd/backfill 补写 user.imported 起始事件
+---    - v1 版本的注册和修改密码事件没有密码哈希, 升级后用户标记为 password_reset_required, 需要通过找回密码重新设置密码, 在此之前密码登录、通行密钥登录、第三方登录和两步验证均返回 403 "password reset required"
+--- 8. 聚合快照表:
+---    - 每追加 persistence.snapshot_every 个事件保存一次，每个聚合只保留最新快照
+---    - 快照结构变化后使用 go run ./cmd/snapshot 重建
+--- 9. webhook:
+---    - 请求头 X-Webhook-Signature = sha256=HMAC-SHA256(secret, "<X-Webhook-Timestamp>.<body>")
+---    - 失败按指数退避重试, 超过 webhook.max_attempts 次进入死信(status=3), 可通过管理接口重放
+---    - 端点已删除或已停用时不发送, 直接进入死信; 查询端点出错按普通失败重试
+---    - worker 与 outbox relay 相同, 通过 SELECT ... FOR UPDATE SKIP LOCKED 领取记录并把 next_attempt_at 推迟一个租期 (webhook.lease_second), 多实例部署时同一记录只由一个实例发送
+---    - (endpoint_id, event_id) 唯一, outbox 重复投递同一事件时不会产生重复的投递记录
+--- 10. 审计日志:
+---    - 由领域事件投影生成, 操作人、IP、User-Agent 来自事件元数据
+---    - 按 event_id 去重, 删除用户为软删除, 日志中的 target_uuid 仍可追溯
+---    - 角色的创建/修改/删除和 webhook 端点的管理、投递重放没有领域事件, 由应用服务直接写入 (source=command); 角色分配和收回是用户事件
+---    - 重建审计投影时只清空 source=event 的记录
+--- 11. 投影重建:
+---    - 按 event_store.id 的全局顺序重放事件存储中的事件, outbox 只是投递队列, 不作为事件日志
+---    - 有用户没有事件流, 或 outbox 中有早于事件流的事件 (状态存储模式下产生) 时拒绝清空投影, -dry-run 显示缺失的数量
+---    - 检查点记录的是 event_store.id
+---    - go run ./cmd/replay -projection audit [-resume] [-dry-run]
+--- 12. 流程管理器:
+---    - 开启 email_verification.required 时注册后发送验证邮件
+---    - 开启 saga.deactivate_unverified 时 saga.verification_timeout_day 天内未验证邮箱则设为未激活, 为 0 时不设超时
+---    - 超时到期时用户已验证或已被禁用则不处理, 被禁用的用户不会因此变为未激活
+---    - 用户被禁用后撤销全部会话
+---    - saga_processed_events 记录每个流程处理过的事件, 重复投递的事件直接忽略
+--- 13. 刷新令牌:
+---    - 访问令牌有效期 jwt.access_expire_minute, 过期后通过 POST /api/v1/auth/refresh 换取新令牌
+---    - 每次刷新都会轮换刷新令牌, 已轮换的令牌再次使用时撤销整个家族
+---    - 用户被禁用后撤销其全部刷新令牌
+--- 14. 注销与令牌撤销:
+---    - 访问令牌带有唯一的 jti, POST /api/v1/auth/logout 把当前令牌记入 revoked_tokens
+---    - 修改密码、禁用、封禁会递增 users.token_version, 之前签发的访问令牌和刷新令牌全部失效
+--- 15. OAuth2 授权服务:
+---    - 管理员通过 /api/v1/admin/oauth/clients 注册客户端, 机密客户端的密钥只在创建时返回一次
+---    - 授权码模式: 已登录用户的前端调用 GET /oauth/authorize, 需要确认时 POST /oauth/authorize 提交同意结果, 然后跳转到返回的 redirect_to
+---    - 公共客户端必须使用 PKCE (S256), POST /oauth/token 支持 authorization_code、refresh_token、client_credentials
+---    - 签发的访问令牌与自身登录的令牌格式相同, 额外带有 client_id 和 scope
+---    - 授权码只能使用一次, 重复使用时撤销通过该授权码签发的刷新令牌家族 (RFC 6749 4.1.2); 只有代表用户的登录令牌才能发起授权
+--- 16. OpenID Connect:
+---    - 发现文档 /.well-known/openid-configuration, issuer 取 jwt.issuer, 需要配置为服务对外的地址
+---    - 授权请求的 scope 包含 openid 时, 令牌端点同时返回 id_token, sub 为用户 UUID
+---    - profile 权限范围返回 preferred_username、name、picture, email 权限范围返回 email、email_verified
+---    - 下游应用需要通过 JWKS 验证 id_token, 因此要配置非对称签名密钥 (jwt.signing_key_id); 未配置时不提供 OpenID Connect, 发现文档返回 404, 授权请求不接受 openid 权限范围
+---    - ID Token 头部 typ 为 id_token+jwt 并带有 token_use=id, 不能作为访问令牌使用
+--- 17. 外部身份登录:
+---    - 在 sso.providers 中配置 OIDC 身份提供方, 回调地址为 /api/v1/auth/sso/{name}/callback
+---    - GET /api/v1/auth/sso/{name}/login 返回提供方登录地址, state、nonce、PKCE 保存在 external_login_states, 只能使用一次
+---    - 首次登录时, 双方都验证过的邮箱自动关联已有用户, 邮箱未被使用则自动创建没有本地密码的用户
+---    - 已关联的外部身份记录在 external_identities, 之后按 provider + subject 直接登录
+---    - 自动创建的用户信任提供方的 email_verified; 提供方未验证邮箱且开启了邮箱验证时, 用户需要先通过验证邮件激活
+--- 18. 两步验证:
+---    - POST /api/v1/auth/2fa/setup 生成 TOTP 密钥和 otpauth 地址, POST /api/v1/auth/2fa/confirm 提交第一个验证码后开启, 同时返回 10 个一次性恢复码
+---    - 开启后密码登录和外部身份登录只返回 mfa_token, 再通过 POST /api/v1/auth/2fa/verify 提交验证码或恢复码换取令牌
+---    - 每个 mfa_token 最多失败 5 次, 每个时间步的验证码只能使用一次
+---    - 验证码错误次数按用户累计, 与登录失败记录保存在一起, 密码登录成功不会清零, 达到 login_protection.max_account_failures 后锁定, 返回 429 和 Retry-After
+---    - 管理员通过 DELETE /api/v1/admin/users/:id/2fa 为丢失设备的用户重置两步验证, 同时撤销该用户的全部令牌和会话
+--- 19. 通行密钥:
+---    - 在 webauthn 中配置 rp_id 和前端页面来源 origins, rp_id 修改后已注册的通行密钥全部失效
+---    - 登录后通过 POST /api/v1/users/me/passkeys/register/begin 获取选项, 浏览器创建凭证后提交到 register/finish
+---    - POST /api/v1/auth/passkeys/login/begin 和 login/finish 完成无密码登录, 通行密钥已验证用户, 不再要求两步验证
+---    - 签名计数不递增时视为凭证被克隆, 拒绝登录; 通过 GET/DELETE /api/v1/users/me/passkeys 查看和删除通行密钥
+--- 20. API 密钥:
+---    - 通过 POST /api/v1/users/me/api-keys 创建带名称、权限范围和过期时间的密钥, 完整密钥只在创建时返回一次, 之后只显示前缀
+---    - 脚本使用 Authorization: Bearer gdk_... 调用接口, 密钥代表所属用户, 权限范围写入请求上下文的 scope
+---    - 用户被禁用或删除后密钥随之失效, 通过 DELETE /api/v1/users/me/api-keys/:id 撤销密钥
+---    - 权限范围只能是系统定义的权限; 通过 OAuth2 令牌或 API 密钥创建时不能超出调用方的权限范围, 只有拥有全部权限的用户可以创建范围为 * 的密钥
+--- 21. 登录会话:
+---    - 密码、外部身份、两步验证和通行密钥登录都会在 user_sessions 记录会话, 包括设备、User-Agent、IP、登录时间和最近活跃时间
+---    - 会话ID即刷新令牌家族ID, 访问令牌通过 sid 声明关联会话, 会话结束后其访问令牌和刷新令牌立即失效
+---    - GET /api/v1/users/me/sessions 查看登录过的设备, DELETE /api/v1/users/me/sessions/:id 结束会话, 注销也会结束当前会话
+---    - 管理员通过 DELETE /api/v1/admin/users/:id/sessions 结束用户的全部会话
+--- 22. 邮箱验证:
+---    - 注册后向用户邮箱发送验证令牌, 令牌经过签名并带有过期时间, action_tokens 只登记令牌ID, 使用后删除, 只能使用一次
+---    - email_verification.required 开启后注册的用户处于未激活状态, 通过 POST /api/v1/users/verify-email 验证邮箱后激活才能登录
+---    - POST /api/v1/users/resend-verification 重新发送验证邮件, 之前的令牌随之失效, 同一用户按 resend_interval_second 限制频率
+---    - 验证超时被设为未激活的用户验证邮箱后重新激活, 被禁用的用户不会因此解除禁用
+--- 23. 找回密码:
+---    - POST /api/v1/users/forgot-password 向邮箱发送重置令牌, 邮箱是否注册都返回相同的结果, 发送过于频繁时静默跳过
+---    - 重置令牌与验证令牌使用相同的签名和 action_tokens 登记方式, 通过 purpose 区分用途, 不能互相冒用
+---    - POST /api/v1/users/reset-password 使用令牌设置新密码, 新密码需满足 valueobject.NewPassword 的要求, 不满足时令牌仍可使用
+---    - 重置后产生 UserPasswordChangedEvent, 之前签发的访问令牌、刷新令牌和登录会话全部失效
+--- 24. 登录保护:
+---    - 按账户和IP分别统计连续失败次数, 每次失败后下一次尝试需要等待的时间逐次翻倍, 等待期间登录返回 429 和 Retry-After
+---    - 账户失败达到 login_protection.max_account_failures 次后临时锁定, IP 达到 max_ip_failures 次后临时限制, 锁定期间即使密码正确也不能登录
+---    - 失败记录通过 login_protection.store 选择保存在内存(单实例)或 login_attempts 表(多实例共享)
+---    - 账户被锁定时产生 UserLockedOutEvent, 通过 POST /api/v1/admin/users/:id/unlock 解除锁定并产生 UserUnlockedEvent
+---    - 用户名或密码错误统一返回 401, 不存在的用户名同样计数, 不能通过返回结果探测账户是否存在
+--- 25. 角色和权限:
+---    - 权限使用 "资源:操作" 的格式 (如 users:ban), 由系统定义, GET /api/v1/admin/permissions 查看全部权限
+---    - 角色保存在 roles 表, 包含一组权限, 可以通过 parent_id 继承父角色及其祖先角色的全部权限, 继承关系不能形成环
+---    - 用户可以拥有多个角色, users.role 以逗号分隔保存, 权限为全部角色权限的并集
+---    - 管理接口通过 RequirePermission 中间件按权限授权, 权限在请求时根据用户当前的角色判断, 分配或收回角色后立即生效
+---    - /api/v1/admin/roles 管理角色, POST /api/v1/admin/users/:id/roles 分配角色, DELETE /api/v1/admin/users/:id/roles/:role 收回角色, 产生 UserRoleAssignedEvent、UserRoleRevokedEvent
+---    - 内置角色 user 和 admin 在启动时不存在则自动创建, 不能删除; 已分配给用户或被其他角色继承的角色也不能删除
+--- 26. 资源所有权授权:
+---    - 认证后的主体 (用户ID、角色、权限范围) 通过 context 传入应用服务, 由用户应用服务按声明式授权策略校验, HTTP、gRPC、CLI 等入口使用相同的规则
+---    - 查看、修改资料和删除用户为 "所有者或管理员": 本人需要 profile:read、profile:update、account:delete, 操作他人需要 users:read、users:update、users:delete
+---    - 修改密码需要旧密码, 只有本人可以修改; 用户列表、禁用、解锁和分配角色只有拥有对应权限的用户可以执行
+---    - OAuth2 令牌和 API 密钥代表用户操作, 除角色权限外, 所需权限还必须在令牌或密钥的权限范围 (scope) 内
+---    - 已有数据库的内置 user 角色需要手动添加 account:delete 权限, 用户才能注销自己的账户
+---    - 通行密钥、两步验证、会话、API 密钥和 OAuth2 授权同意等账户安全相关的接口只接受用户自身登录的令牌, OAuth2 令牌和 API 密钥返回 403
\ No newline at end of file